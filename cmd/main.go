@@ -1,16 +1,36 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"github.com/gin-contrib/cors"
 	ginZap "github.com/gin-contrib/zap"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 	"secrets-operator/config"
+	"secrets-operator/internal/adapters/handlers/authHdl"
 	"secrets-operator/internal/adapters/handlers/findingHdl"
+	"secrets-operator/internal/adapters/handlers/gitleaksHdl"
+	"secrets-operator/internal/adapters/handlers/outboxHdl"
+	"secrets-operator/internal/adapters/handlers/routingHdl"
 	"secrets-operator/internal/adapters/handlers/searchHdl"
+	"secrets-operator/internal/adapters/handlers/slackHdl"
+	"secrets-operator/internal/adapters/handlers/templateHdl"
+	"secrets-operator/internal/adapters/handlers/webhookHdl"
+	"secrets-operator/internal/adapters/repositories/identity"
 	"secrets-operator/internal/adapters/repositories/notification"
 	"secrets-operator/internal/adapters/repositories/storage"
+	"secrets-operator/internal/adapters/repositories/webhook"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/core/ports"
+	"secrets-operator/internal/core/services/authsrv"
+	"secrets-operator/internal/core/services/digestsrv"
 	"secrets-operator/internal/core/services/findingsrv"
+	"secrets-operator/internal/core/services/gitleakssrv"
+	"secrets-operator/internal/core/services/outboxsrv"
+	"secrets-operator/internal/core/services/routingsrv"
+	"secrets-operator/internal/core/services/templatesrv"
+	"secrets-operator/internal/core/services/webhooksrv"
 	"time"
 )
 
@@ -35,35 +55,507 @@ func main() {
 
 	sugaredLogger.Infof("Active running profile: %s", cfg.ActiveEnvProfile)
 
+	redactionPolicy, err := domain.NewRedactionPolicy(cfg.RedactionMode, cfg.RedactionKeepPrefix, cfg.RedactionKeepSuffix, cfg.RedactionHMACKey)
+	if err != nil {
+		sugaredLogger.Fatalln("Invalid redaction configuration.", err)
+	}
+	if !redactionPolicy.HashesSecrets() {
+		sugaredLogger.Warnln("REDACTION_HMAC_KEY is not set, hashes of secrets are not stored")
+	}
+
+	findingIdentity, err := domain.ParseFindingIdentity(cfg.DedupIdentity)
+	if err != nil {
+		sugaredLogger.Fatalln("Invalid deduplication configuration.", err)
+	}
+
+	verdictPolicies, err := setupVerdictPolicies(cfg)
+	if err != nil {
+		sugaredLogger.Fatalln("Invalid verdict policy configuration.", err)
+	}
+
+	templates, err := setupTemplates(cfg)
+	if err != nil {
+		sugaredLogger.Fatalln("Invalid notification templates.", err)
+	}
+
 	// setup handlers, services, ports and etc
-	findingsRepository := storage.NewMongoDb(cfg, sugaredLogger)
-	notifier := notification.NewSlackNotifier(cfg, sugaredLogger)
-	findingService := findingsrv.NewFindingService(sugaredLogger, findingsRepository, notifier)
-	findingsHandler := findingHdl.NewFindingsHandler(cfg, sugaredLogger, findingService)
-	searchHandler := searchHdl.NewSearchHandler(cfg, sugaredLogger, findingService)
+	repository := setupRepository(cfg, sugaredLogger)
+	notifier := setupNotifier(cfg, sugaredLogger, repository, templates)
+	outboxService := setupOutboxService(cfg, sugaredLogger, repository, notifier)
+	setupDigestService(cfg, sugaredLogger, repository, notifier)
+	webhookService := setupWebhookService(cfg, sugaredLogger, repository)
+	findingService := findingsrv.NewFindingService(sugaredLogger, repository, webhookService, redactionPolicy, findingIdentity, verdictPolicies)
+	routingService := routingsrv.NewRoutingService(sugaredLogger, repository, templates)
+	templateService := templatesrv.NewTemplateService(sugaredLogger, templates)
+	gitleaksConfigService := setupGitleaksConfigService(cfg, sugaredLogger, repository)
+
+	accessPolicy, err := setupAccessPolicy(cfg)
+	if err != nil {
+		sugaredLogger.Fatalln("Invalid access policy configuration.", err)
+	}
+
+	tokenVerifier := setupTokenVerifier(cfg, sugaredLogger)
+	authService := authsrv.NewAuthService(sugaredLogger, repository, tokenVerifier, accessPolicy, cfg.AuthAdminKey)
 
 	// setup http router
-	router := setupRouter(logger)
+	router := setupRouter(logger, cfg, findingService, authService, accessPolicy, webhookService, outboxService, routingService, templateService, gitleaksConfigService)
 
-	router.StaticFile("/api/v1/config.toml", cfg.ConfigFilePath)
-	router.StaticFile("/api/v1/pipelineScript.sh", cfg.ScriptFilePath)
+	sugaredLogger.Fatalln(router.Run(cfg.ServerAddr))
+}
 
-	findingsGroup := router.Group("/api/v1/findings")
-	findingsGroup.POST("/upload", findingsHandler.Create)
-	findingsGroup.GET("/:id", findingsHandler.Get)
+// setupVerdictPolicies builds default verdict policy and per repository overrides from policy file
+func setupVerdictPolicies(cfg *config.Config) (domain.VerdictPolicies, error) {
 
-	searchGroup := router.Group("/api/v1/search")
-	searchGroup.GET("/repos", searchHandler.SearchRepositories)
+	defaultPolicy, err := domain.NewVerdictPolicy(cfg.VerdictOnNew, cfg.VerdictOnKnown, cfg.VerdictOnSuppressed)
+	if err != nil {
+		return domain.VerdictPolicies{}, err
+	}
 
-	sugaredLogger.Fatalln(router.Run(cfg.ServerAddr))
+	repoPolicies, err := config.LoadRepoPolicies(cfg.PolicyFilePath)
+	if err != nil {
+		return domain.VerdictPolicies{}, err
+	}
+
+	policies := domain.VerdictPolicies{Default: defaultPolicy, Repos: map[int]domain.VerdictPolicy{}}
+
+	for repoId, repoPolicy := range repoPolicies {
+		policy, err := domain.NewVerdictPolicy(
+			valueOrDefault(repoPolicy.OnNew, cfg.VerdictOnNew),
+			valueOrDefault(repoPolicy.OnKnown, cfg.VerdictOnKnown),
+			valueOrDefault(repoPolicy.OnSuppressed, cfg.VerdictOnSuppressed),
+		)
+		if err != nil {
+			return domain.VerdictPolicies{}, fmt.Errorf("repository %d: %w", repoId, err)
+		}
+		policies.Repos[repoId] = policy
+	}
+
+	return policies, nil
+}
+
+// setupTemplates parses notification templates of NOTIFICATION_TEMPLATES_PATH on top of the default ones
+func setupTemplates(cfg *config.Config) (domain.NotificationTemplates, error) {
+
+	sources, err := config.LoadTemplates(cfg.TemplatesPath)
+	if err != nil {
+		return domain.NotificationTemplates{}, err
+	}
+
+	return domain.NewNotificationTemplates(sources)
+}
+
+func valueOrDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+// setupAccessPolicy maps roles and groups of access policy file to roles of identity provider users
+func setupAccessPolicy(cfg *config.Config) (domain.AccessPolicy, error) {
+
+	file, err := config.LoadAccessPolicy(cfg.AccessPolicyFilePath)
+	if err != nil {
+		return domain.AccessPolicy{}, err
+	}
+
+	policy := domain.AccessPolicy{Roles: map[domain.Role][]string{}, AllRepos: file.AllRepos, Repos: file.Repos, SlackUsers: map[string]domain.Claims{}}
+
+	for role, values := range file.Roles {
+		switch domain.Role(role) {
+		case domain.RoleViewer, domain.RoleTriager, domain.RoleAdmin:
+			policy.Roles[domain.Role(role)] = values
+		default:
+			return domain.AccessPolicy{}, fmt.Errorf("unknown role %q, valid roles are viewer, triager and admin", role)
+		}
+	}
+
+	for id, user := range file.SlackUsers {
+		policy.SlackUsers[id] = domain.Claims{Roles: user.Roles, Groups: user.Groups}
+	}
+
+	return policy, nil
+}
+
+// setupGitleaksConfigService imports gitleaks config file of CONFIG_FILE_PATH as the first version, if no version is stored yet
+func setupGitleaksConfigService(cfg *config.Config, l *zap.SugaredLogger, gitleaksConfigRepository ports.GitleaksConfigRepository) ports.GitleaksConfigService {
+
+	gitleaksConfigService := gitleakssrv.NewGitleaksConfigService(l, gitleaksConfigRepository)
+
+	file, exists, err := config.LoadGitleaksFile(cfg.ConfigFilePath)
+	if err != nil {
+		l.Fatalln("Cannot load gitleaks config file.", err)
+	}
+	if !exists {
+		return gitleaksConfigService
+	}
+
+	if err = gitleaksConfigService.Seed(gitleaksConfig(file), "imported from "+cfg.ConfigFilePath); err != nil {
+		l.Fatalln("Cannot import gitleaks config file.", err)
+	}
+
+	return gitleaksConfigService
+}
+
+func gitleaksConfig(file config.GitleaksFile) domain.GitleaksConfig {
+
+	gitleaksConfig := domain.GitleaksConfig{
+		Title:      file.Title,
+		UseDefault: file.Extend.UseDefault,
+		Allowlist:  gitleaksAllowlist(file.Allowlist),
+		Rules:      []domain.GitleaksRule{},
+	}
+
+	for _, rule := range file.Rules {
+		gitleaksConfig.Rules = append(gitleaksConfig.Rules, domain.GitleaksRule{
+			ID:          rule.ID,
+			Description: rule.Description,
+			Regex:       rule.Regex,
+			SecretGroup: rule.SecretGroup,
+			Entropy:     rule.Entropy,
+			Path:        rule.Path,
+			Keywords:    rule.Keywords,
+			Tags:        rule.Tags,
+			Allowlist:   gitleaksAllowlist(rule.Allowlist),
+		})
+	}
+
+	return gitleaksConfig
+}
+
+func gitleaksAllowlist(file *config.GitleaksFileAllowlist) *domain.GitleaksAllowlist {
+
+	if file == nil {
+		return nil
+	}
+
+	return &domain.GitleaksAllowlist{
+		Description: file.Description,
+		RegexTarget: file.RegexTarget,
+		Regexes:     file.Regexes,
+		Paths:       file.Paths,
+		Commits:     file.Commits,
+		StopWords:   file.StopWords,
+	}
+}
+
+// setupTokenVerifier returns verifier of identity provider tokens, or nil if OIDC_ENABLED is not set
+func setupTokenVerifier(cfg *config.Config, l *zap.SugaredLogger) ports.TokenVerifier {
+
+	if !cfg.OIDCEnabled {
+		return nil
+	}
+
+	verifier, err := identity.NewJWKSVerifier(cfg, l)
+	if err != nil {
+		l.Fatalln("Invalid OIDC configuration.", err)
+	}
+
+	return verifier
+}
+
+// repository is implemented by every storage backend
+type repository interface {
+	ports.FindingsRepository
+	ports.APIKeyRepository
+	ports.WebhookRepository
+	ports.OutboxRepository
+	ports.RoutingRuleRepository
+	ports.GitleaksConfigRepository
+	ports.DigestRepository
+	ports.AnnouncementRepository
 }
 
-func setupRouter(logger *zap.Logger) *gin.Engine {
+// setupRepository selects storage backend by STORAGE_DRIVER configuration variable
+func setupRepository(cfg *config.Config, l *zap.SugaredLogger) repository {
+
+	switch cfg.StorageDriver {
+	case "mongo":
+		return storage.NewMongoDb(cfg, l)
+	case "postgres":
+		db, err := storage.NewPostgres(cfg, l)
+		if err != nil {
+			l.Fatalln("Cannot connect to PostgreSQL.", err)
+		}
+		return db
+	case "sqlite":
+		db, err := storage.NewSQLite(cfg, l)
+		if err != nil {
+			l.Fatalln("Cannot open SQLite database.", err)
+		}
+		return db
+	case "memory":
+		return storage.NewMemory(cfg, l)
+	default:
+		l.Fatalln("Unknown storage driver", cfg.StorageDriver)
+		return nil
+	}
+}
+
+// notifier delivers queued notifications and digests
+type notifier interface {
+	ports.ChannelNotifier
+	ports.DigestNotifier
+}
+
+// setupNotifier routes notifications to every enabled channel and to targets of stored routing rules,
+// NOTIFICATION_DRIVER=memory records them instead
+func setupNotifier(cfg *config.Config, l *zap.SugaredLogger, repository repository, templates domain.NotificationTemplates) notifier {
+
+	var channels []notification.Channel
+	targetNotifiers := map[domain.NotificationTargetType]notification.TargetNotifier{}
+
+	switch cfg.NotificationDriver {
+	case "channels", "slack":
+		settings, err := config.LoadEmailSettings(cfg.NotificationFilePath)
+		if err != nil {
+			l.Fatalln("Invalid email notification settings.", err)
+		}
+
+		if cfg.SlackActionsEnabled && cfg.SlackSigningSecret == "" {
+			l.Fatalln("SLACK_SIGNING_SECRET is required when Slack actions are enabled")
+		}
+		if cfg.SlackSigningSecret != "" && (cfg.SlackTeamID == "" || cfg.SlackAppID == "") {
+			l.Fatalln("SLACK_TEAM_ID and SLACK_APP_ID are required when SLACK_SIGNING_SECRET is set")
+		}
+
+		// routed targets give their own destinations, only Slack needs credentials
+		if cfg.SlackAuthToken != "" {
+			targetNotifiers[domain.NotificationTargetSlack] = notification.NewSlackNotifier(cfg, l, templates)
+		}
+		targetNotifiers[domain.NotificationTargetTeams] = notification.NewTeamsNotifier(cfg, l, templates)
+		targetNotifiers[domain.NotificationTargetEmail] = notification.NewEmailNotifier(cfg, l, settings)
+		targetNotifiers[domain.NotificationTargetWebhook] = notification.NewWebhookNotifier(cfg, l)
+
+		if cfg.SlackNotificationEnabled {
+			channels = append(channels, notification.Channel{Name: "slack", Notifier: notification.NewSlackNotifier(cfg, l, templates)})
+		}
+		if cfg.TeamsNotificationEnabled {
+			if cfg.TeamsWebhookURL == "" {
+				l.Fatalln("TEAMS_WEBHOOK_URL is required when Teams notifications are enabled")
+			}
+			channels = append(channels, notification.Channel{Name: "teams", Notifier: notification.NewTeamsNotifier(cfg, l, templates)})
+		}
+		if cfg.EmailNotificationEnabled {
+			channels = append(channels, notification.Channel{Name: "email", Notifier: notification.NewEmailNotifier(cfg, l, settings)})
+		}
+	case "memory":
+		recorder := notification.NewRecordingNotifier(cfg, l)
+		channels = append(channels, notification.Channel{Name: "memory", Notifier: recorder})
+		for _, targetType := range []domain.NotificationTargetType{domain.NotificationTargetSlack, domain.NotificationTargetTeams, domain.NotificationTargetEmail, domain.NotificationTargetWebhook} {
+			targetNotifiers[targetType] = recorder
+		}
+	default:
+		l.Fatalln("Unknown notification driver", cfg.NotificationDriver)
+		return nil
+	}
+
+	channels, err := applyChannelFilters(cfg, channels)
+	if err != nil {
+		l.Fatalln("Invalid notification channel filters.", err)
+	}
+
+	if len(channels) == 0 {
+		l.Warnln("No default notification channel is enabled, only routing rules are notified")
+	}
+
+	if cfg.DigestDailyHour < 0 || cfg.DigestDailyHour > 23 {
+		l.Fatalln("DIGEST_DAILY_HOUR must be between 0 and 23")
+	}
+
+	ledger := notification.NewLedger(l, repository, repository, cfg.DigestDailyHour)
+	routes := notification.NewRoutes(l, repository, targetNotifiers)
+
+	return notification.NewRouter(l, time.Duration(cfg.NotificationTimeout)*time.Second, ledger, routes, channels...)
+}
+
+// setupOutboxService creates service delivering notifications queued with uploaded findings.
+// Its delivery worker runs unless OUTBOX_WORKER_ENABLED is unset, replicas sharing a database should run a single worker.
+func setupOutboxService(cfg *config.Config, l *zap.SugaredLogger, outboxRepository ports.OutboxRepository, notifier ports.ChannelNotifier) ports.OutboxService {
+
+	retryPolicy := domain.RetryPolicy{
+		MaxAttempts: cfg.OutboxMaxAttempts,
+		BaseDelay:   time.Duration(cfg.OutboxBackoffSeconds) * time.Second,
+		MaxDelay:    time.Duration(cfg.OutboxMaxBackoffSeconds) * time.Second,
+	}
+
+	if retryPolicy.MaxAttempts < 1 || retryPolicy.BaseDelay <= 0 || retryPolicy.MaxDelay < retryPolicy.BaseDelay {
+		l.Fatalln("Invalid notification outbox retry configuration.")
+	}
+
+	service := outboxsrv.NewOutboxService(l, outboxRepository, notifier, retryPolicy)
+
+	if cfg.OutboxWorkerEnabled {
+		go service.Run(context.Background(), time.Duration(cfg.OutboxPollSeconds)*time.Second)
+	}
+
+	return service
+}
+
+// setupDigestService starts worker sending digests of hourly and daily notification channels unless
+// DIGEST_WORKER_ENABLED is unset, replicas sharing a database should run a single worker.
+func setupDigestService(cfg *config.Config, l *zap.SugaredLogger, digestRepository ports.DigestRepository, notifier ports.DigestNotifier) {
+
+	if !cfg.DigestWorkerEnabled {
+		return
+	}
+
+	if cfg.DigestPollSeconds < 1 {
+		l.Fatalln("DIGEST_POLL_SECONDS must be positive")
+	}
+
+	// digests are notifications as well, they are retried like the outbox
+	retryPolicy := domain.RetryPolicy{
+		MaxAttempts: cfg.OutboxMaxAttempts,
+		BaseDelay:   time.Duration(cfg.OutboxBackoffSeconds) * time.Second,
+		MaxDelay:    time.Duration(cfg.OutboxMaxBackoffSeconds) * time.Second,
+	}
+
+	service := digestsrv.NewDigestService(l, digestRepository, notifier, retryPolicy)
+	go service.Run(context.Background(), time.Duration(cfg.DigestPollSeconds)*time.Second)
+}
+
+// setupWebhookService creates service publishing finding events to webhook subscribers.
+// Its delivery worker runs unless WEBHOOK_WORKER_ENABLED is unset, replicas sharing a database should run a single worker.
+func setupWebhookService(cfg *config.Config, l *zap.SugaredLogger, webhookRepository ports.WebhookRepository) ports.WebhookService {
+
+	retryPolicy := domain.RetryPolicy{
+		MaxAttempts: cfg.WebhookMaxAttempts,
+		BaseDelay:   time.Duration(cfg.WebhookBackoffSeconds) * time.Second,
+		MaxDelay:    time.Duration(cfg.WebhookMaxBackoffSeconds) * time.Second,
+	}
+
+	if retryPolicy.MaxAttempts < 1 || retryPolicy.BaseDelay <= 0 || retryPolicy.MaxDelay < retryPolicy.BaseDelay {
+		l.Fatalln("Invalid webhook retry configuration.")
+	}
+
+	service := webhooksrv.NewWebhookService(l, webhookRepository, webhook.NewHTTPSender(cfg, l), retryPolicy)
+
+	if cfg.WebhookWorkerEnabled {
+		go service.Run(context.Background(), time.Duration(cfg.WebhookPollSeconds)*time.Second)
+	}
+
+	return service
+}
+
+// knownChannels lists channel names allowed in notification filters file, so typos are not silently ignored
+var knownChannels = map[string]bool{"slack": true, "teams": true, "email": true, "memory": true}
+
+// applyChannelFilters sets filters and modes of notification filters file to the channels
+func applyChannelFilters(cfg *config.Config, channels []notification.Channel) ([]notification.Channel, error) {
+
+	filters, err := config.LoadChannelFilters(cfg.NotificationFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	for name := range filters {
+		if !knownChannels[name] {
+			return nil, fmt.Errorf("unknown notification channel %q", name)
+		}
+	}
+
+	for i, channel := range channels {
+		filter := filters[channel.Name]
+		channels[i].Filter = domain.NotificationFilter{RepoIDs: filter.Repos, RuleIDs: filter.Rules, ExcludeRuleIDs: filter.ExcludeRules}
+
+		mode := domain.NotificationMode(filter.Mode)
+		if !mode.Valid() {
+			return nil, fmt.Errorf("unknown notification mode %q of channel %q", filter.Mode, channel.Name)
+		}
+		channels[i].Mode = mode
+	}
+
+	return channels, nil
+}
+
+func setupRouter(logger *zap.Logger, cfg *config.Config, findingService ports.FindingService, authService ports.AuthService, accessPolicy domain.AccessPolicy, webhookService ports.WebhookService, outboxService ports.OutboxService, routingService ports.RoutingService, templateService ports.TemplateService, gitleaksConfigService ports.GitleaksConfigService) *gin.Engine {
+
+	sugaredLogger := logger.Sugar()
+
+	findingsHandler := findingHdl.NewFindingsHandler(cfg, sugaredLogger, findingService)
+	searchHandler := searchHdl.NewSearchHandler(cfg, sugaredLogger, findingService)
+	authHandler := authHdl.NewAuthHandler(cfg, sugaredLogger, authService)
+	webhookHandler := webhookHdl.NewWebhookHandler(cfg, sugaredLogger, webhookService)
+	outboxHandler := outboxHdl.NewOutboxHandler(cfg, sugaredLogger, outboxService)
+	routingHandler := routingHdl.NewRoutingHandler(cfg, sugaredLogger, routingService)
+	templateHandler := templateHdl.NewTemplateHandler(cfg, sugaredLogger, templateService)
+	slackHandler := slackHdl.NewSlackHandler(cfg, sugaredLogger, findingService, accessPolicy)
+	gitleaksHandler := gitleaksHdl.NewGitleaksHandler(cfg, sugaredLogger, gitleaksConfigService)
+
+	authenticate := authHandler.Authenticate
+	if !cfg.AuthEnabled {
+		sugaredLogger.Warnln("Authentication is disabled, every request is treated as admin")
+		authenticate = authHdl.WithPrincipal(domain.Principal{Name: "anonymous", Role: domain.RoleAdmin})
+	}
 
 	router := gin.New()
+	// fingerprints in path parameters contain URL encoded slashes
+	router.UseRawPath = true
 	router.Use(gin.Recovery())
 	router.Use(cors.Default())
 	router.Use(ginZap.Ginzap(logger, time.RFC3339, true))
 
+	// gitleaks config and pipeline script are fetched before the pipeline has a token, they stay public
+	router.GET("/api/v1/config.toml", gitleaksHandler.TOML)
+	router.StaticFile("/api/v1/pipelineScript.sh", cfg.ScriptFilePath)
+
+	findingsGroup := router.Group("/api/v1/findings", authenticate)
+	findingsGroup.POST("/upload", authHdl.RequireUpload, findingsHandler.Create)
+	// read and triage permissions depend on the repository, handlers check them
+	findingsGroup.GET("/:id", findingsHandler.Get)
+	findingsGroup.GET("/:id/export", findingsHandler.Export)
+	findingsGroup.PATCH("/:repoId/:fingerprint", findingsHandler.UpdateStatus)
+
+	reposGroup := router.Group("/api/v1/repos", authenticate)
+	reposGroup.GET("/:id/baseline", findingsHandler.Baseline)
+
+	searchGroup := router.Group("/api/v1/search", authenticate)
+	searchGroup.GET("/repos", searchHandler.SearchRepositories)
+
+	tokensGroup := router.Group("/api/v1/tokens", authenticate, authHdl.RequireAdmin)
+	tokensGroup.POST("", authHandler.Create)
+	tokensGroup.GET("", authHandler.List)
+	tokensGroup.DELETE("/:id", authHandler.Revoke)
+
+	webhooksGroup := router.Group("/api/v1/webhooks", authenticate, authHdl.RequireAdmin)
+	webhooksGroup.POST("", webhookHandler.Create)
+	webhooksGroup.GET("", webhookHandler.List)
+	webhooksGroup.DELETE("/:id", webhookHandler.Delete)
+	webhooksGroup.GET("/:id/deliveries", webhookHandler.Deliveries)
+
+	outboxGroup := router.Group("/api/v1/outbox", authenticate, authHdl.RequireAdmin)
+	outboxGroup.GET("", outboxHandler.List)
+	outboxGroup.POST("/:id/replay", outboxHandler.Replay)
+
+	routesGroup := router.Group("/api/v1/routes", authenticate, authHdl.RequireAdmin)
+	routesGroup.POST("", routingHandler.Create)
+	routesGroup.GET("", routingHandler.List)
+	routesGroup.PUT("/:id", routingHandler.Update)
+	routesGroup.DELETE("/:id", routingHandler.Delete)
+
+	templatesGroup := router.Group("/api/v1/templates", authenticate, authHdl.RequireAdmin)
+	templatesGroup.GET("", templateHandler.List)
+	templatesGroup.POST("/preview", templateHandler.Preview)
+
+	gitleaksGroup := router.Group("/api/v1/gitleaks", authenticate)
+	gitleaksGroup.GET("/config", gitleaksHandler.Get)
+	gitleaksGroup.PUT("/config", authHdl.RequireAdmin, gitleaksHandler.Replace)
+	gitleaksGroup.PUT("/allowlist", authHdl.RequireAdmin, gitleaksHandler.UpdateAllowlist)
+	gitleaksGroup.GET("/rules", gitleaksHandler.ListRules)
+	gitleaksGroup.POST("/rules", authHdl.RequireAdmin, gitleaksHandler.CreateRule)
+	gitleaksGroup.GET("/rules/:id", gitleaksHandler.GetRule)
+	gitleaksGroup.PUT("/rules/:id", authHdl.RequireAdmin, gitleaksHandler.UpdateRule)
+	gitleaksGroup.DELETE("/rules/:id", authHdl.RequireAdmin, gitleaksHandler.DeleteRule)
+	gitleaksGroup.GET("/versions", gitleaksHandler.ListVersions)
+	gitleaksGroup.GET("/versions/:version", gitleaksHandler.GetVersion)
+	gitleaksGroup.GET("/versions/:version/diff", gitleaksHandler.Diff)
+	gitleaksGroup.POST("/versions/:version/rollback", authHdl.RequireAdmin, gitleaksHandler.Rollback)
+
+	// Slack signs interactions with the signing secret instead of sending a token
+	if cfg.SlackSigningSecret != "" {
+		router.POST("/api/v1/slack/interactions", slackHandler.Interactions)
+	}
+
 	return router
 }
@@ -0,0 +1,956 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"secrets-operator/config"
+	"secrets-operator/internal/adapters/repositories/notification"
+	"secrets-operator/internal/adapters/repositories/storage"
+	"secrets-operator/internal/adapters/repositories/webhook"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/core/ports"
+	"secrets-operator/internal/core/services/authsrv"
+	"secrets-operator/internal/core/services/digestsrv"
+	"secrets-operator/internal/core/services/findingsrv"
+	"secrets-operator/internal/core/services/outboxsrv"
+	"secrets-operator/internal/core/services/routingsrv"
+	"secrets-operator/internal/core/services/templatesrv"
+	"secrets-operator/internal/core/services/webhooksrv"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// EndToEndTestSuite drives the real router, handlers and service against in-memory storage and notifier
+type EndToEndTestSuite struct {
+	suite.Suite
+	cfg      *config.Config
+	notifier interface {
+		Messages() []domain.FindingsReport
+		RoutedMessages(destination string) []domain.FindingsReport
+	}
+	// teams is a channel which tests can take down
+	teams *unavailableNotifier
+	// outbox delivers queued notifications when tests call DeliverDue, no worker runs in tests
+	outbox interface {
+		ports.OutboxService
+		DeliverDue() (int, error)
+	}
+	// digests sends due digests when tests call SendDue, no worker runs in tests
+	digests interface {
+		SendDue() (int, error)
+	}
+	// digestRepository lets tests make queued digests due
+	digestRepository ports.DigestRepository
+	// webhooks delivers queued webhook deliveries when tests call DeliverDue, no worker runs in tests
+	webhooks interface {
+		ports.WebhookService
+		DeliverDue() (int, error)
+	}
+	router *gin.Engine
+	// token is sent with every request made by do, tests switch it to act as another client
+	token string
+	// signingKey signs identity provider tokens, its public key is the local key set of the verifier
+	signingKey *rsa.PrivateKey
+}
+
+func TestSuiteEndToEnd(t *testing.T) {
+	suite.Run(t, new(EndToEndTestSuite))
+}
+
+func (s *EndToEndTestSuite) SetupTest() {
+
+	var err error
+
+	gin.SetMode(gin.TestMode)
+	logger := zap.NewNop()
+
+	s.cfg, err = config.LoadConfig("test")
+	if err != nil {
+		s.T().Fatalf("cannot load configuration variables. %v", err.Error())
+	}
+	s.cfg.StorageDriver = "memory"
+	s.cfg.NotificationDriver = "memory"
+	s.cfg.SlackNotificationEnabled = true
+	s.cfg.AuthEnabled = true
+	s.cfg.AuthAdminKey = "test admin key"
+	s.cfg.SlackSigningSecret = "test signing secret"
+	s.cfg.SlackTeamID = "T0TEST"
+	s.cfg.SlackAppID = "A0TEST"
+	// tests run in cmd directory
+	s.cfg.ConfigFilePath = "../config/config.toml"
+
+	s.signingKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+	s.cfg.OIDCEnabled = true
+	s.cfg.OIDCIssuer = "https://idp.example.com"
+	s.cfg.OIDCAudience = "secrets-operator"
+	s.cfg.OIDCJWKSFile = filepath.Join(s.T().TempDir(), "jwks.json")
+	s.cfg.AccessPolicyFilePath = filepath.Join(s.T().TempDir(), "access.toml")
+
+	keySet, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: &s.signingKey.PublicKey, KeyID: "k1", Algorithm: "RS256", Use: "sig"}}})
+	if err != nil {
+		s.T().Fatal(err)
+	}
+	if err = os.WriteFile(s.cfg.OIDCJWKSFile, keySet, 0600); err != nil {
+		s.T().Fatal(err)
+	}
+
+	accessPolicyFile := `
+[roles]
+viewer = ["developers"]
+triager = ["security-champions"]
+
+[repos.444]
+groups = ["team-testing"]
+
+[[slack_users]]
+id = "U0JANE"
+roles = ["security-champions"]
+groups = ["team-testing"]
+`
+	if err = os.WriteFile(s.cfg.AccessPolicyFilePath, []byte(accessPolicyFile), 0600); err != nil {
+		s.T().Fatal(err)
+	}
+
+	s.cfg.TemplatesPath = s.T().TempDir()
+	paymentsTemplate := `{{len .Findings}} new {{plural (len .Findings) "secret" "secrets"}} in {{.RepoName}}`
+	if err = os.WriteFile(filepath.Join(s.cfg.TemplatesPath, "payments.tmpl"), []byte(paymentsTemplate), 0600); err != nil {
+		s.T().Fatal(err)
+	}
+
+	redactionPolicy, err := domain.NewRedactionPolicy("mask", 0, 0, "test key")
+	if err != nil {
+		s.T().Fatal(err)
+	}
+
+	findingsRepository := storage.NewMemory(s.cfg, logger.Sugar())
+	notifier := notification.NewRecordingNotifier(s.cfg, logger.Sugar())
+	verdictPolicies, err := setupVerdictPolicies(s.cfg)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+
+	// recording channel is routed like channels of a real deployment
+	s.teams = &unavailableNotifier{}
+	routes := notification.NewRoutes(logger.Sugar(), findingsRepository, map[domain.NotificationTargetType]notification.TargetNotifier{
+		domain.NotificationTargetSlack: notifier,
+		domain.NotificationTargetEmail: notifier,
+	})
+	ledger := notification.NewLedger(logger.Sugar(), findingsRepository, findingsRepository, 9)
+	router := notification.NewRouter(logger.Sugar(), time.Second, ledger, routes,
+		notification.Channel{Name: "memory", Notifier: notifier},
+		notification.Channel{Name: "teams", Notifier: s.teams},
+	)
+
+	// failed deliveries are due again right away, so tests do not wait for retries
+	s.outbox = outboxsrv.NewOutboxService(logger.Sugar(), findingsRepository, router,
+		domain.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Nanosecond, MaxDelay: time.Nanosecond})
+
+	s.digests = digestsrv.NewDigestService(logger.Sugar(), findingsRepository, router,
+		domain.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Nanosecond, MaxDelay: time.Nanosecond})
+	s.digestRepository = findingsRepository
+
+	s.webhooks = webhooksrv.NewWebhookService(logger.Sugar(), findingsRepository, webhook.NewHTTPSender(s.cfg, logger.Sugar()),
+		domain.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Hour, MaxDelay: time.Hour})
+
+	findingService := findingsrv.NewFindingService(logger.Sugar(), findingsRepository, s.webhooks, redactionPolicy, domain.FindingIdentityFingerprint, verdictPolicies)
+	accessPolicy, err := setupAccessPolicy(s.cfg)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+
+	authService := authsrv.NewAuthService(logger.Sugar(), findingsRepository, setupTokenVerifier(s.cfg, logger.Sugar()), accessPolicy, s.cfg.AuthAdminKey)
+
+	templates, err := setupTemplates(s.cfg)
+	if err != nil {
+		s.T().Fatal(err)
+	}
+
+	routingService := routingsrv.NewRoutingService(logger.Sugar(), findingsRepository, templates)
+	templateService := templatesrv.NewTemplateService(logger.Sugar(), templates)
+	gitleaksConfigService := setupGitleaksConfigService(s.cfg, logger.Sugar(), findingsRepository)
+
+	s.notifier = notifier
+	s.router = setupRouter(logger, s.cfg, findingService, authService, accessPolicy, s.webhooks, s.outbox, routingService, templateService, gitleaksConfigService)
+	s.token = s.cfg.AuthAdminKey
+}
+
+// unavailableNotifier fails while down is set
+type unavailableNotifier struct {
+	down bool
+}
+
+func (n *unavailableNotifier) SendMessage(ctx context.Context, message domain.FindingsReport) error {
+
+	if n.down {
+		return errors.New("channel is down")
+	}
+
+	return nil
+}
+
+// notifications delivers queued notifications and returns every message received by the recording channel
+func (s *EndToEndTestSuite) notifications() []domain.FindingsReport {
+
+	_, err := s.outbox.DeliverDue()
+	s.Require().NoError(err)
+
+	return s.notifier.Messages()
+}
+
+func (s *EndToEndTestSuite) do(method, target string, headers map[string]string, body interface{}) *httptest.ResponseRecorder {
+
+	reqBodyBytes := new(bytes.Buffer)
+	if body != nil {
+		if err := json.NewEncoder(reqBodyBytes).Encode(body); err != nil {
+			s.T().Fatal("could not encode request body.", err)
+		}
+	}
+
+	request := httptest.NewRequest(method, target, reqBodyBytes)
+	request.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		request.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	for k, v := range headers {
+		request.Header.Set(k, v)
+	}
+
+	recorder := httptest.NewRecorder()
+	s.router.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+func (s *EndToEndTestSuite) upload(pipelineId string, notify string) *httptest.ResponseRecorder {
+
+	// headers exactly as config/pipelineScript.sh sends them
+	headers := map[string]string{
+		"pipelineId":    pipelineId,
+		"repoName":      "testing repo",
+		"repoId":        "444",
+		"repoURL":       "https://gitlab.com/testing-repo",
+		"commitAuthor":  "test user",
+		"commitSHA":     "a85af84d39a32da2c8eba1d88019079aeb0741b0",
+		"timestamp":     "1670071694",
+		"notify":        notify,
+		"configVersion": "1",
+	}
+
+	findings := domain.Findings{
+		{
+			Description: "test",
+			StartLine:   1,
+			EndLine:     1,
+			StartColumn: 1,
+			EndColumn:   1,
+			Match:       "password = 'test secret'",
+			Secret:      "test secret",
+			File:        "src/main.go",
+			Commit:      "a85af84d39a32da2c8eba1d88019079aeb0741b0",
+			Entropy:     3.3822913,
+			Author:      "test author",
+			Email:       "test@mail.com",
+			Date:        time.Date(2022, 12, 3, 12, 48, 14, 0, time.UTC),
+			Message:     "test message",
+			Tags:        []string{"test", "tags"},
+			RuleID:      "test-rule",
+			Fingerprint: "a85af84d39a32da2c8eba1d88019079aeb0741b0:src/main.go:test-rule:1",
+		},
+	}
+
+	return s.do("POST", "/api/v1/findings/upload", headers, findings)
+}
+
+func (s *EndToEndTestSuite) getFindings(query string) domain.RepoFindings {
+
+	recorder := s.do("GET", "/api/v1/findings/444"+query, nil, nil)
+	s.Require().Equal(http.StatusOK, recorder.Code, recorder.Body.String())
+
+	repoFindings := domain.RepoFindings{}
+	s.Require().NoError(json.NewDecoder(recorder.Body).Decode(&repoFindings))
+
+	return repoFindings
+}
+
+func (s *EndToEndTestSuite) TestUploadGetAndSearch() {
+
+	// upload
+	recorder := s.upload("2", "true")
+	s.Require().Equal(http.StatusCreated, recorder.Code, recorder.Body.String())
+	s.Len(s.notifications(), 1)
+
+	// get
+	repoFindings := s.getFindings("")
+	s.Equal("testing repo", repoFindings.RepoName)
+	s.Require().Len(repoFindings.Findings, 1)
+	s.Equal(domain.RedactedPlaceholder, repoFindings.Findings[0].Secret, "raw secret must never be returned")
+	s.Equal("password = 'REDACTED'", repoFindings.Findings[0].Match)
+	s.NotEmpty(repoFindings.Findings[0].SecretHash)
+	s.Equal(domain.FindingStatusOpen, repoFindings.Findings[0].Status)
+
+	// search
+	recorder = s.do("GET", "/api/v1/search/repos?query=TESTING", nil, nil)
+	s.Require().Equal(http.StatusOK, recorder.Code)
+
+	items := map[string][]map[string]string{}
+	s.Require().NoError(json.NewDecoder(recorder.Body).Decode(&items))
+	s.Equal([]map[string]string{{"id": "444", "name": "testing repo"}}, items["items"])
+
+	// unknown repositories
+	s.Equal(http.StatusNotFound, s.do("GET", "/api/v1/findings/555", nil, nil).Code)
+	s.Equal(http.StatusNotFound, s.do("GET", "/api/v1/search/repos?query=unknown", nil, nil).Code)
+}
+
+func (s *EndToEndTestSuite) TestUploadWithoutNotification() {
+
+	recorder := s.upload("2", "false")
+
+	s.Require().Equal(http.StatusCreated, recorder.Code, recorder.Body.String())
+	s.Empty(s.notifications())
+}
+
+func (s *EndToEndTestSuite) TestNotificationOutbox() {
+
+	s.teams.down = true
+
+	// upload does not wait for notification channels
+	s.Require().Equal(http.StatusCreated, s.upload("2", "true").Code)
+	s.Len(s.notifications(), 1)
+
+	// only the failed channel is retried, until attempts are exhausted
+	s.Len(s.notifications(), 1, "channel which received the message must not be notified again")
+
+	recorder := s.do("GET", "/api/v1/outbox", nil, nil)
+	s.Require().Equal(http.StatusOK, recorder.Code, recorder.Body.String())
+
+	dead := map[string][]domain.OutboxMessage{}
+	s.Require().NoError(json.NewDecoder(recorder.Body).Decode(&dead))
+	s.Require().Len(dead["items"], 1)
+	s.Equal([]string{"teams"}, dead["items"][0].Channels)
+	s.Equal(2, dead["items"][0].AttemptCount)
+	s.Require().Len(dead["items"][0].Report.Findings, 1)
+	s.Equal(domain.RedactedPlaceholder, dead["items"][0].Report.Findings[0].Secret, "raw secret must never be queued")
+
+	// replay once the channel is back
+	s.teams.down = false
+	s.Require().Equal(http.StatusOK, s.do("POST", "/api/v1/outbox/"+dead["items"][0].ID+"/replay", nil, nil).Code)
+	s.Equal(http.StatusConflict, s.do("POST", "/api/v1/outbox/"+dead["items"][0].ID+"/replay", nil, nil).Code, "pending message can not be replayed")
+
+	s.Len(s.notifications(), 1)
+
+	recorder = s.do("GET", "/api/v1/outbox?status=delivered", nil, nil)
+	s.Require().Equal(http.StatusOK, recorder.Code)
+
+	delivered := map[string][]domain.OutboxMessage{}
+	s.Require().NoError(json.NewDecoder(recorder.Body).Decode(&delivered))
+	s.Len(delivered["items"], 1)
+
+	// outbox is managed by admins only
+	s.token = s.userToken([]string{"developers"}, nil)
+	s.Equal(http.StatusForbidden, s.do("GET", "/api/v1/outbox", nil, nil).Code)
+}
+
+func (s *EndToEndTestSuite) TestNotificationRouting() {
+
+	createRule := func(rule map[string]interface{}) domain.RoutingRule {
+		recorder := s.do("POST", "/api/v1/routes", nil, rule)
+		s.Require().Equal(http.StatusCreated, recorder.Code, recorder.Body.String())
+
+		created := struct {
+			Rule domain.RoutingRule `json:"rule"`
+		}{}
+		s.Require().NoError(json.NewDecoder(recorder.Body).Decode(&created))
+		return created.Rule
+	}
+
+	payments := createRule(map[string]interface{}{
+		"repoNameGlob": "testing *",
+		"targets":      []map[string]string{{"type": "slack", "destination": "C0PAYMENTS"}},
+	})
+	createRule(map[string]interface{}{
+		"repoIds": []int{555},
+		"targets": []map[string]string{{"type": "slack", "destination": "C0OTHER"}},
+	})
+	s.Equal(http.StatusBadRequest, s.do("POST", "/api/v1/routes", nil, map[string]interface{}{
+		"targets": []map[string]string{{"type": "email", "destination": "not an address"}},
+	}).Code)
+
+	// routed targets are notified besides the default channels
+	s.Require().Equal(http.StatusCreated, s.upload("2", "true").Code)
+	s.Len(s.notifications(), 1)
+	s.Len(s.notifier.RoutedMessages("C0PAYMENTS"), 1)
+	s.Empty(s.notifier.RoutedMessages("C0OTHER"))
+
+	// update keeps author of the rule
+	recorder := s.do("PUT", "/api/v1/routes/"+payments.ID, nil, map[string]interface{}{
+		"groupPrefix": "testing-repo",
+		"ruleIds":     []string{"test-rule"},
+		"targets":     []map[string]string{{"type": "email", "destination": "payments@example.com"}},
+	})
+	s.Require().Equal(http.StatusOK, recorder.Code, recorder.Body.String())
+
+	recorder = s.do("GET", "/api/v1/routes", nil, nil)
+	s.Require().Equal(http.StatusOK, recorder.Code)
+
+	rules := map[string][]domain.RoutingRule{}
+	s.Require().NoError(json.NewDecoder(recorder.Body).Decode(&rules))
+	s.Require().Len(rules["items"], 2)
+	s.Equal(payments.ID, rules["items"][0].ID)
+	s.Equal("testing-repo", rules["items"][0].GroupPrefix)
+	s.Empty(rules["items"][0].RepoNameGlob)
+	s.Equal(payments.CreatedBy, rules["items"][0].CreatedBy)
+
+	s.Equal(http.StatusOK, s.do("DELETE", "/api/v1/routes/"+payments.ID, nil, nil).Code)
+	s.Equal(http.StatusNotFound, s.do("DELETE", "/api/v1/routes/"+payments.ID, nil, nil).Code)
+
+	// routing rules are managed by admins only
+	s.token = s.userToken([]string{"developers"}, nil)
+	s.Equal(http.StatusForbidden, s.do("GET", "/api/v1/routes", nil, nil).Code)
+}
+
+func (s *EndToEndTestSuite) TestNotificationDigests() {
+
+	for _, target := range []map[string]string{
+		{"type": "slack", "destination": "C0DIGEST", "mode": "hourly"},
+		{"type": "email", "destination": "quiet@example.com", "mode": "suppressed"},
+	} {
+		s.Require().Equal(http.StatusCreated, s.do("POST", "/api/v1/routes", nil, map[string]interface{}{
+			"targets": []map[string]string{target},
+		}).Code)
+	}
+	s.Equal(http.StatusBadRequest, s.do("POST", "/api/v1/routes", nil, map[string]interface{}{
+		"targets": []map[string]string{{"type": "slack", "destination": "C0DIGEST", "mode": "weekly"}},
+	}).Code)
+
+	// findings of digest targets are queued until the digest is due
+	s.Require().Equal(http.StatusCreated, s.upload("2", "true").Code)
+	s.Len(s.notifications(), 1)
+	s.Empty(s.notifier.RoutedMessages("C0DIGEST"))
+	s.Empty(s.notifier.RoutedMessages("quiet@example.com"))
+
+	sent, err := s.digests.SendDue()
+	s.Require().NoError(err)
+	s.Zero(sent)
+
+	entries, err := s.digestRepository.GetDueDigestEntries(time.Now().Add(time.Hour), 10, "digests")
+	s.Require().NoError(err)
+	s.Require().Len(entries, 1)
+	s.Equal(0, entries[0].DueAt.Minute(), "hourly digest is sent at the start of an hour")
+
+	entries[0].DueAt = time.Now().UTC()
+	s.Require().NoError(s.digestRepository.SaveDigestEntry(entries[0], "digests"))
+
+	sent, err = s.digests.SendDue()
+	s.Require().NoError(err)
+	s.Equal(1, sent)
+
+	digest := s.notifier.RoutedMessages("C0DIGEST")
+	s.Require().Len(digest, 1)
+	s.Equal(444, digest[0].RepoID)
+	s.Len(digest[0].Findings, 1)
+
+	// every entry was sent
+	sent, err = s.digests.SendDue()
+	s.Require().NoError(err)
+	s.Zero(sent)
+	s.Len(s.notifier.RoutedMessages("C0DIGEST"), 1)
+}
+
+func (s *EndToEndTestSuite) TestNotificationTemplates() {
+
+	recorder := s.do("GET", "/api/v1/templates", nil, nil)
+	s.Require().Equal(http.StatusOK, recorder.Code)
+	s.JSONEq(`{"items":["payments","slack","slack-dm","teams"]}`, recorder.Body.String())
+
+	preview := func(body map[string]interface{}) (int, map[string]string) {
+		recorder := s.do("POST", "/api/v1/templates/preview", nil, body)
+		resp := map[string]string{}
+		s.Require().NoError(json.NewDecoder(recorder.Body).Decode(&resp))
+		return recorder.Code, resp
+	}
+
+	code, resp := preview(map[string]interface{}{"name": "payments"})
+	s.Equal(http.StatusOK, code)
+	s.Equal("1 new secret in payments-api", resp["rendered"], "templates are previewed with a sample report")
+
+	code, resp = preview(map[string]interface{}{"source": "{{.RepoName}}: {{commitURL .}}", "report": map[string]interface{}{
+		"repoName": "testing repo", "repoURL": "https://gitlab.com/testing-repo", "commitSHA": "a85af84d39a32da2c8eba1d88019079aeb0741b0",
+	}})
+	s.Equal(http.StatusOK, code)
+	s.Equal("testing repo: https://gitlab.com/testing-repo/-/commit/a85af84d39a32da2c8eba1d88019079aeb0741b0", resp["rendered"])
+
+	code, resp = preview(map[string]interface{}{"source": "{{.Repository}}"})
+	s.Equal(http.StatusBadRequest, code)
+	s.Contains(resp["error"], "Repository")
+
+	code, _ = preview(map[string]interface{}{"name": "missing"})
+	s.Equal(http.StatusNotFound, code)
+
+	// routing rules may only use loaded templates
+	s.Equal(http.StatusCreated, s.do("POST", "/api/v1/routes", nil, map[string]interface{}{
+		"targets": []map[string]string{{"type": "slack", "destination": "C0PAYMENTS", "template": "payments"}},
+	}).Code)
+	s.Equal(http.StatusBadRequest, s.do("POST", "/api/v1/routes", nil, map[string]interface{}{
+		"targets": []map[string]string{{"type": "slack", "destination": "C0PAYMENTS", "template": "missing"}},
+	}).Code)
+
+	// templates are managed by admins only
+	s.token = s.userToken([]string{"developers"}, nil)
+	s.Equal(http.StatusForbidden, s.do("GET", "/api/v1/templates", nil, nil).Code)
+}
+
+func (s *EndToEndTestSuite) TestTriageFinding() {
+
+	s.Require().Equal(http.StatusCreated, s.upload("2", "true").Code)
+
+	fingerprint := url.PathEscape("a85af84d39a32da2c8eba1d88019079aeb0741b0:src/main.go:test-rule:1")
+
+	// author of the change is the authenticated user, whatever the body claims
+	s.token = s.userToken([]string{"security-champions"}, []string{"team-testing"})
+	recorder := s.do("PATCH", "/api/v1/findings/444/"+fingerprint, nil, map[string]string{
+		"status":    "revoked",
+		"changedBy": "test user",
+		"comment":   "key rotated",
+	})
+	s.Require().Equal(http.StatusOK, recorder.Code, recorder.Body.String())
+
+	s.Empty(s.getFindings("?status=open").Findings)
+
+	revoked := s.getFindings("?status=revoked").Findings
+	s.Require().Len(revoked, 1)
+	s.Require().Len(revoked[0].StatusHistory, 1)
+	s.Equal("u1", revoked[0].StatusHistory[0].ChangedBy, "users are recorded by subject, not by editable names")
+
+	s.Equal(http.StatusNotFound, s.do("PATCH", "/api/v1/findings/444/unknown", nil, map[string]string{
+		"status": "revoked",
+	}).Code)
+}
+
+func (s *EndToEndTestSuite) TestSlackInteractions() {
+
+	s.Require().Equal(http.StatusCreated, s.upload("2", "true").Code)
+
+	click := func(actionId string, userId string, secret string) int {
+		payload := `{"type": "block_actions", "team": {"id": "T0TEST"}, "api_app_id": "A0TEST", "user": {"id": "` + userId + `", "name": "jane"},
+			"actions": [{"action_id": "` + actionId + `", "block_id": "finding-0", "value": "444:a85af84d39a32da2c8eba1d88019079aeb0741b0:src/main.go:test-rule:1"}]}`
+		body := url.Values{"payload": {payload}}.Encode()
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+		mac := hmac.New(sha256.New, []byte(secret))
+		_, _ = io.WriteString(mac, "v0:"+timestamp+":"+body)
+
+		request := httptest.NewRequest("POST", "/api/v1/slack/interactions", strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		request.Header.Set("X-Slack-Request-Timestamp", timestamp)
+		request.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+
+		recorder := httptest.NewRecorder()
+		s.router.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	// Slack requests carry no token, they are trusted by the signature alone
+	s.Equal(http.StatusUnauthorized, click("triage_false_positive", "U0JANE", "forged secret"))
+	s.Len(s.getFindings("?status=open").Findings, 1)
+
+	// members of the workspace need a role granted to their Slack user
+	s.Equal(http.StatusOK, click("triage_false_positive", "U0EVE", s.cfg.SlackSigningSecret))
+	s.Never(func() bool {
+		return len(s.getFindings("?status=open").Findings) != 1
+	}, 100*time.Millisecond, 10*time.Millisecond)
+
+	// findings are triaged after Slack got its response
+	s.Equal(http.StatusOK, click("triage_assign", "U0JANE", s.cfg.SlackSigningSecret))
+	s.Require().Eventually(func() bool {
+		return len(s.getFindings("?status=acknowledged").Findings) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	acknowledged := s.getFindings("?status=acknowledged").Findings
+	s.Equal("slack:U0JANE", acknowledged[0].Assignee)
+
+	s.Equal(http.StatusOK, click("triage_false_positive", "U0JANE", s.cfg.SlackSigningSecret))
+	s.Require().Eventually(func() bool {
+		return len(s.getFindings("?status=false_positive").Findings) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	falsePositives := s.getFindings("?status=false_positive").Findings
+	s.Equal("slack:U0JANE", falsePositives[0].Assignee)
+	s.Require().Len(falsePositives[0].StatusHistory, 2)
+	s.Equal("slack:U0JANE", falsePositives[0].StatusHistory[1].ChangedBy)
+}
+
+func (s *EndToEndTestSuite) TestWebhookDeliveries() {
+
+	// endpoint verifies signatures the way subscribers are expected to
+	type received struct {
+		event   string
+		payload domain.WebhookPayload
+	}
+	deliveries := make(chan received, 10)
+	var secret string
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(webhook.HeaderTimestamp), 10, 64)
+		if r.Header.Get(webhook.HeaderSignature) != domain.SignWebhookPayload(secret, timestamp, body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		payload := domain.WebhookPayload{}
+		_ = json.Unmarshal(body, &payload)
+		deliveries <- received{event: r.Header.Get(webhook.HeaderEvent), payload: payload}
+	}))
+	defer endpoint.Close()
+
+	// subscribe
+	recorder := s.do("POST", "/api/v1/webhooks", nil, map[string]interface{}{
+		"url":     endpoint.URL,
+		"events":  []string{"finding.new", "finding.status_changed"},
+		"repoIds": []int{444},
+	})
+	s.Require().Equal(http.StatusCreated, recorder.Code, recorder.Body.String())
+
+	created := struct {
+		Secret       string                     `json:"secret"`
+		Subscription domain.WebhookSubscription `json:"subscription"`
+	}{}
+	s.Require().NoError(json.NewDecoder(recorder.Body).Decode(&created))
+	secret = created.Secret
+
+	// new finding is delivered, report.received is not subscribed
+	s.Require().Equal(http.StatusCreated, s.upload("2", "false").Code)
+	attempted, err := s.webhooks.DeliverDue()
+	s.Require().NoError(err)
+	s.Equal(1, attempted)
+
+	delivered := <-deliveries
+	s.Equal("finding.new", delivered.event)
+	s.Require().NotNil(delivered.payload.Repository)
+	s.Require().Len(delivered.payload.Repository.Findings, 1)
+	s.Equal(domain.RedactedPlaceholder, delivered.payload.Repository.Findings[0].Secret, "raw secret must never be delivered")
+
+	// known finding is not new again, its triage is delivered
+	s.Require().Equal(http.StatusCreated, s.upload("3", "false").Code)
+	fingerprint := url.PathEscape("a85af84d39a32da2c8eba1d88019079aeb0741b0:src/main.go:test-rule:1")
+	s.Require().Equal(http.StatusOK, s.do("PATCH", "/api/v1/findings/444/"+fingerprint, nil, map[string]string{
+		"status":    "revoked",
+		"changedBy": "test user",
+	}).Code)
+
+	attempted, err = s.webhooks.DeliverDue()
+	s.Require().NoError(err)
+	s.Equal(1, attempted)
+
+	delivered = <-deliveries
+	s.Equal("finding.status_changed", delivered.event)
+	s.Require().NotNil(delivered.payload.Repository)
+	s.Equal(domain.FindingStatusRevoked, delivered.payload.Repository.Findings[0].Status)
+
+	// delivery log
+	recorder = s.do("GET", "/api/v1/webhooks/"+created.Subscription.ID+"/deliveries", nil, nil)
+	s.Require().Equal(http.StatusOK, recorder.Code)
+
+	deliveryLog := map[string][]domain.WebhookDelivery{}
+	s.Require().NoError(json.NewDecoder(recorder.Body).Decode(&deliveryLog))
+	s.Require().Len(deliveryLog["items"], 2)
+	for _, delivery := range deliveryLog["items"] {
+		s.Equal(domain.WebhookDeliveryDelivered, delivery.Status)
+		s.Require().Len(delivery.Attempts, 1)
+		s.Equal(http.StatusOK, delivery.Attempts[0].StatusCode)
+	}
+
+	// webhooks are managed by admins only
+	s.token = s.userToken([]string{"developers"}, nil)
+	s.Equal(http.StatusForbidden, s.do("GET", "/api/v1/webhooks", nil, nil).Code)
+}
+
+func (s *EndToEndTestSuite) TestRepeatedUploadMergesOccurrences() {
+
+	s.Require().Equal(http.StatusCreated, s.upload("2", "true").Code)
+	first := s.getFindings("").Findings[0]
+
+	s.Require().Equal(http.StatusCreated, s.upload("3", "true").Code)
+	s.Require().Equal(http.StatusCreated, s.upload("3", "true").Code)
+
+	findings := s.getFindings("").Findings
+	s.Require().Len(findings, 1)
+	s.Equal(first.ID, findings[0].ID)
+	s.Equal(2, findings[0].OccurrenceCount)
+	s.Len(findings[0].Occurrences, 2)
+	s.True(first.FirstSeen.Equal(findings[0].FirstSeen))
+	s.Len(s.notifications(), 1, "known findings must not be notified again")
+}
+
+func (s *EndToEndTestSuite) TestUploadVerdict() {
+
+	type uploadResponse struct {
+		ReportID        string   `json:"reportId"`
+		New             int      `json:"new"`
+		Known           int      `json:"known"`
+		Suppressed      int      `json:"suppressed"`
+		NewFingerprints []string `json:"newFingerprints"`
+		Verdict         string   `json:"verdict"`
+	}
+
+	uploadAndDecode := func(pipelineId string) uploadResponse {
+		recorder := s.upload(pipelineId, "false")
+		s.Require().Equal(http.StatusCreated, recorder.Code, recorder.Body.String())
+
+		resp := uploadResponse{}
+		s.Require().NoError(json.NewDecoder(recorder.Body).Decode(&resp))
+		return resp
+	}
+
+	baseline := func() []domain.BaselineFinding {
+		recorder := s.do("GET", "/api/v1/repos/444/baseline", nil, nil)
+		s.Require().Equal(http.StatusOK, recorder.Code, recorder.Body.String())
+
+		var findings []domain.BaselineFinding
+		s.Require().NoError(json.NewDecoder(recorder.Body).Decode(&findings))
+		s.Require().NotNil(findings, "baseline must be a JSON array")
+		return findings
+	}
+
+	// first pipeline of the repository has an empty baseline
+	s.Empty(baseline())
+
+	// new secret fails the pipeline
+	first := uploadAndDecode("2")
+	s.NotEmpty(first.ReportID)
+	s.Equal(1, first.New)
+	s.Equal([]string{"a85af84d39a32da2c8eba1d88019079aeb0741b0:src/main.go:test-rule:1"}, first.NewFingerprints)
+	s.Equal("fail", first.Verdict)
+
+	// already known secret only warns
+	second := uploadAndDecode("3")
+	s.NotEqual(first.ReportID, second.ReportID)
+	s.Equal(0, second.New)
+	s.Equal(1, second.Known)
+	s.Empty(second.NewFingerprints)
+	s.Equal("warn", second.Verdict)
+
+	// triaged secret is suppressed
+	fingerprint := url.PathEscape(first.NewFingerprints[0])
+	recorder := s.do("PATCH", "/api/v1/findings/444/"+fingerprint, nil, map[string]string{"status": "false_positive", "changedBy": "test user"})
+	s.Require().Equal(http.StatusOK, recorder.Code, recorder.Body.String())
+
+	third := uploadAndDecode("4")
+	s.Equal(0, third.Known)
+	s.Equal(1, third.Suppressed)
+	s.Equal("pass", third.Verdict)
+
+	// false positive stays in the baseline, so gitleaks keeps skipping it
+	findings := baseline()
+	s.Require().Len(findings, 1)
+	s.Equal(first.NewFingerprints[0], findings[0].Fingerprint)
+	s.Equal(domain.RedactedPlaceholder, findings[0].Secret)
+}
+
+func (s *EndToEndTestSuite) TestAuthentication() {
+
+	issue := func(body map[string]interface{}) (string, string) {
+		recorder := s.do("POST", "/api/v1/tokens", nil, body)
+		s.Require().Equal(http.StatusCreated, recorder.Code, recorder.Body.String())
+
+		resp := struct {
+			Token string        `json:"token"`
+			Key   domain.APIKey `json:"key"`
+		}{}
+		s.Require().NoError(json.NewDecoder(recorder.Body).Decode(&resp))
+		return resp.Token, resp.Key.ID
+	}
+
+	// admin key issues tokens
+	uploadToken, uploadKeyId := issue(map[string]interface{}{"name": "pipeline", "scope": "upload", "repoId": 444})
+	readToken, _ := issue(map[string]interface{}{"name": "dashboard", "scope": "read"})
+
+	// anonymous requests are rejected, static files stay public
+	s.token = ""
+	s.Equal(http.StatusUnauthorized, s.upload("2", "false").Code)
+	s.Equal(http.StatusUnauthorized, s.do("GET", "/api/v1/findings/444", nil, nil).Code)
+	s.Equal(http.StatusOK, s.do("GET", "/api/v1/config.toml", nil, nil).Code)
+	s.Equal(http.StatusUnauthorized, s.do("GET", "/api/v1/gitleaks/config", nil, nil).Code)
+
+	// upload token uploads and reads baseline of its own repository only
+	s.token = uploadToken
+	s.Require().Equal(http.StatusCreated, s.upload("2", "false").Code)
+	s.Equal(http.StatusOK, s.do("GET", "/api/v1/findings/444", nil, nil).Code)
+	s.Equal(http.StatusForbidden, s.do("GET", "/api/v1/findings/555", nil, nil).Code)
+	s.Equal(http.StatusOK, s.do("GET", "/api/v1/repos/444/baseline", nil, nil).Code)
+	s.Equal(http.StatusForbidden, s.do("GET", "/api/v1/repos/555/baseline", nil, nil).Code)
+	s.Equal(http.StatusOK, s.do("GET", "/api/v1/search/repos?query=test", nil, nil).Code)
+	s.Equal(http.StatusForbidden, s.do("GET", "/api/v1/tokens", nil, nil).Code)
+	s.Equal(http.StatusOK, s.do("GET", "/api/v1/gitleaks/rules", nil, nil).Code)
+	s.Equal(http.StatusForbidden, s.do("DELETE", "/api/v1/gitleaks/rules/ec-private-key", nil, nil).Code)
+
+	// read token can not upload or triage
+	s.token = readToken
+	s.Equal(http.StatusOK, s.do("GET", "/api/v1/search/repos?query=test", nil, nil).Code)
+	s.Equal(http.StatusForbidden, s.upload("3", "false").Code)
+	s.Equal(http.StatusForbidden, s.do("PATCH", "/api/v1/findings/444/fingerprint", nil, map[string]string{"status": "revoked", "changedBy": "test"}).Code)
+
+	// last use is tracked and revoked token is rejected
+	s.token = s.cfg.AuthAdminKey
+	recorder := s.do("GET", "/api/v1/tokens", nil, nil)
+	s.Require().Equal(http.StatusOK, recorder.Code)
+	keys := struct {
+		Items []domain.APIKey `json:"items"`
+	}{}
+	s.Require().NoError(json.NewDecoder(recorder.Body).Decode(&keys))
+	s.Require().Len(keys.Items, 2)
+	s.NotNil(keys.Items[0].LastUsedAt)
+
+	s.Require().Equal(http.StatusOK, s.do("DELETE", "/api/v1/tokens/"+uploadKeyId, nil, nil).Code)
+
+	s.token = uploadToken
+	s.Equal(http.StatusUnauthorized, s.upload("4", "false").Code)
+}
+
+func (s *EndToEndTestSuite) TestGitleaksConfig() {
+
+	current := func() domain.GitleaksConfigVersion {
+		recorder := s.do("GET", "/api/v1/gitleaks/config", nil, nil)
+		s.Require().Equal(http.StatusOK, recorder.Code, recorder.Body.String())
+		version := domain.GitleaksConfigVersion{}
+		s.Require().NoError(json.NewDecoder(recorder.Body).Decode(&version))
+		return version
+	}
+
+	// config file is imported as the first version
+	imported := current()
+	s.Equal(1, imported.Version)
+	s.True(imported.Config.UseDefault)
+	s.Len(imported.Config.Rules, 4)
+	hardCodedPassword, ok := imported.Config.Rule("hard-coded-password")
+	s.Require().True(ok)
+	s.Require().NotNil(hardCodedPassword.Allowlist)
+	s.Len(hardCodedPassword.Allowlist.Regexes, 1)
+
+	recorder := s.do("GET", "/api/v1/config.toml", nil, nil)
+	s.Require().Equal(http.StatusOK, recorder.Code)
+	s.Equal("1", recorder.Header().Get("X-Gitleaks-Config-Version"))
+	s.True(strings.HasPrefix(recorder.Body.String(), "# gitleaks config of secrets operator, version 1\n"), recorder.Body.String())
+	s.Contains(recorder.Body.String(), `id = "ec-private-key"`)
+
+	// admin adds a rule
+	rule := domain.GitleaksRule{ID: "test-token", Description: "Test token", Regex: `test_[a-z0-9]{32}`, Keywords: []string{"test_"}}
+	recorder = s.do("POST", "/api/v1/gitleaks/rules", nil, rule)
+	s.Require().Equal(http.StatusCreated, recorder.Code, recorder.Body.String())
+	s.Equal(http.StatusConflict, s.do("POST", "/api/v1/gitleaks/rules", nil, rule).Code)
+	s.Equal(http.StatusBadRequest, s.do("POST", "/api/v1/gitleaks/rules", nil, domain.GitleaksRule{ID: "broken", Regex: "("}).Code)
+	s.Equal(2, current().Version)
+	s.Contains(s.do("GET", "/api/v1/config.toml", nil, nil).Body.String(), `id = "test-token"`)
+
+	// diff tells what the version changed
+	recorder = s.do("GET", "/api/v1/gitleaks/versions/2/diff", nil, nil)
+	s.Require().Equal(http.StatusOK, recorder.Code, recorder.Body.String())
+	diff := domain.GitleaksConfigDiff{}
+	s.Require().NoError(json.NewDecoder(recorder.Body).Decode(&diff))
+	s.Equal(1, diff.From)
+	s.Require().Len(diff.Added, 1)
+	s.Equal("test-token", diff.Added[0].ID)
+	s.Empty(diff.Removed)
+
+	// rollback stores config of the imported version as the next version
+	s.Require().Equal(http.StatusCreated, s.do("POST", "/api/v1/gitleaks/versions/1/rollback", nil, nil).Code)
+	rolledBack := current()
+	s.Equal(3, rolledBack.Version)
+	s.Equal(imported.Config, rolledBack.Config)
+	s.NotContains(s.do("GET", "/api/v1/config.toml", nil, nil).Body.String(), `id = "test-token"`)
+
+	// pipelines may still fetch the version they started with
+	recorder = s.do("GET", "/api/v1/config.toml?version=2", nil, nil)
+	s.Require().Equal(http.StatusOK, recorder.Code)
+	s.Contains(recorder.Body.String(), `id = "test-token"`)
+	s.Equal(http.StatusNotFound, s.do("GET", "/api/v1/config.toml?version=9", nil, nil).Code)
+
+	recorder = s.do("GET", "/api/v1/gitleaks/versions", nil, nil)
+	s.Require().Equal(http.StatusOK, recorder.Code)
+	versions := struct {
+		Items []domain.GitleaksConfigVersion `json:"items"`
+	}{}
+	s.Require().NoError(json.NewDecoder(recorder.Body).Decode(&versions))
+	s.Require().Len(versions.Items, 3)
+	s.Equal("rolled back to version 1", versions.Items[0].Change)
+	s.Equal("rule test-token created", versions.Items[1].Change)
+	s.Equal("imported from ../config/config.toml", versions.Items[2].Change)
+
+	// reports record version of the config which found their findings
+	recorder = s.upload("2", "false")
+	s.Equal(http.StatusCreated, recorder.Code, recorder.Body.String())
+}
+
+// userToken signs identity provider token of the user with roles and groups claims
+func (s *EndToEndTestSuite) userToken(roles []string, groups []string) string {
+
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.RS256, Key: jose.JSONWebKey{Key: s.signingKey, KeyID: "k1"}},
+		(&jose.SignerOptions{}).WithType("JWT"),
+	)
+	s.Require().NoError(err)
+
+	token, err := jwt.Signed(signer).
+		Claims(jwt.Claims{
+			Issuer:   s.cfg.OIDCIssuer,
+			Subject:  "u1",
+			Audience: jwt.Audience{s.cfg.OIDCAudience},
+			Expiry:   jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		}).
+		Claims(map[string]interface{}{"preferred_username": "jane", "roles": roles, "groups": groups}).
+		CompactSerialize()
+	s.Require().NoError(err)
+
+	return token
+}
+
+func (s *EndToEndTestSuite) TestIdentityProviderUsers() {
+
+	s.Require().Equal(http.StatusCreated, s.upload("2", "false").Code)
+
+	fingerprint := url.PathEscape("a85af84d39a32da2c8eba1d88019079aeb0741b0:src/main.go:test-rule:1")
+	triage := map[string]string{"status": "acknowledged", "changedBy": "jane"}
+
+	// developer of the team sees its repository but can not triage
+	s.token = s.userToken([]string{"developers"}, []string{"team-testing"})
+	s.Equal(http.StatusOK, s.do("GET", "/api/v1/findings/444", nil, nil).Code)
+	s.Equal(http.StatusOK, s.do("GET", "/api/v1/search/repos?query=test", nil, nil).Code)
+	s.Equal(http.StatusForbidden, s.do("PATCH", "/api/v1/findings/444/"+fingerprint, nil, triage).Code)
+	s.Equal(http.StatusForbidden, s.do("POST", "/api/v1/tokens", nil, map[string]interface{}{"name": "x", "scope": "admin"}).Code)
+
+	// developer of another team does not see it, not even in search results
+	s.token = s.userToken([]string{"developers"}, []string{"team-other"})
+	s.Equal(http.StatusForbidden, s.do("GET", "/api/v1/findings/444", nil, nil).Code)
+	s.Equal(http.StatusNotFound, s.do("GET", "/api/v1/search/repos?query=test", nil, nil).Code)
+
+	// triager of the team changes status
+	s.token = s.userToken([]string{"security-champions"}, []string{"team-testing"})
+	s.Equal(http.StatusOK, s.do("PATCH", "/api/v1/findings/444/"+fingerprint, nil, triage).Code)
+
+	// user without role and forged tokens are rejected
+	s.token = s.userToken(nil, []string{"team-testing"})
+	s.Equal(http.StatusUnauthorized, s.do("GET", "/api/v1/findings/444", nil, nil).Code)
+	s.token = s.userToken([]string{"developers"}, []string{"team-testing"}) + "x"
+	s.Equal(http.StatusUnauthorized, s.do("GET", "/api/v1/findings/444", nil, nil).Code)
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/viper"
+	"os"
+	"strconv"
+)
+
+// AccessPolicy maps role and group claims of identity provider users to roles and visible repositories
+type AccessPolicy struct {
+	Roles    map[string][]string
+	AllRepos []string
+	Repos    map[int][]string
+	// SlackUsers grants roles and groups to Slack users by their IDs
+	SlackUsers map[string]SlackUser
+}
+
+// SlackUser lists roles and groups of a Slack user, they are matched like claims of identity provider users
+type SlackUser struct {
+	Roles  []string
+	Groups []string
+}
+
+// LoadAccessPolicy reads access policy of identity provider users, e.g.
+//
+//	all_repos = ["auditors"]
+//
+//	[roles]
+//	viewer = ["developers"]
+//
+//	[repos.444]
+//	groups = ["team-payments"]
+//
+//	[[slack_users]]
+//	id = "U024BE7LH"
+//	roles = ["developers"]
+//	groups = ["team-payments"]
+//
+// Missing policy file is not an error, no user is granted a role then.
+func LoadAccessPolicy(path string) (AccessPolicy, error) {
+
+	policy := AccessPolicy{Roles: map[string][]string{}, Repos: map[int][]string{}, SlackUsers: map[string]SlackUser{}}
+
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return policy, nil
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+
+	if err := v.ReadInConfig(); err != nil {
+		return AccessPolicy{}, err
+	}
+
+	file := struct {
+		AllRepos []string            `mapstructure:"all_repos"`
+		Roles    map[string][]string `mapstructure:"roles"`
+		Repos    map[string]struct {
+			Groups []string `mapstructure:"groups"`
+		} `mapstructure:"repos"`
+		// Slack users are listed rather than keyed by ID, keys of the file are not case sensitive and Slack IDs are
+		SlackUsers []struct {
+			ID     string   `mapstructure:"id"`
+			Roles  []string `mapstructure:"roles"`
+			Groups []string `mapstructure:"groups"`
+		} `mapstructure:"slack_users"`
+	}{}
+
+	if err := v.Unmarshal(&file); err != nil {
+		return AccessPolicy{}, err
+	}
+
+	policy.AllRepos = file.AllRepos
+
+	for role, values := range file.Roles {
+		policy.Roles[role] = values
+	}
+
+	for key, repo := range file.Repos {
+		repoId, err := strconv.Atoi(key)
+		if err != nil {
+			return AccessPolicy{}, fmt.Errorf("invalid repository id %q in access policy file %s", key, path)
+		}
+		policy.Repos[repoId] = repo.Groups
+	}
+
+	for _, user := range file.SlackUsers {
+		if user.ID == "" {
+			return AccessPolicy{}, fmt.Errorf("access policy file %s lists a Slack user without id", path)
+		}
+		policy.SlackUsers[user.ID] = SlackUser{Roles: user.Roles, Groups: user.Groups}
+	}
+
+	return policy, nil
+}
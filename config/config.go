@@ -4,11 +4,14 @@ import (
 	"fmt"
 	"github.com/spf13/viper"
 	"log"
+	"net"
+	"net/url"
 )
 
 type Config struct {
 	ServerAddr               string
 	MongoURI                 string
+	PostgresDSN              string
 	ActiveEnvProfile         string `mapstructure:"ACTIVE_ENV_PROFILE"`
 	ServerHost               string `mapstructure:"SERVER_HOST"`
 	ServerPort               string `mapstructure:"SERVER_PORT"`
@@ -17,12 +20,73 @@ type Config struct {
 	MongoUser                string `mapstructure:"MONGO_USER"`
 	MongoPass                string `mapstructure:"MONGO_PASS"`
 	MongoDBName              string `mapstructure:"MONGO_DBNAME"`
+	StorageDriver            string `mapstructure:"STORAGE_DRIVER"`
+	PostgresHost             string `mapstructure:"POSTGRES_HOST"`
+	PostgresPort             string `mapstructure:"POSTGRES_PORT"`
+	PostgresUser             string `mapstructure:"POSTGRES_USER"`
+	PostgresPass             string `mapstructure:"POSTGRES_PASS"`
+	PostgresDBName           string `mapstructure:"POSTGRES_DBNAME"`
+	PostgresSSLMode          string `mapstructure:"POSTGRES_SSLMODE"`
+	SQLitePath               string `mapstructure:"SQLITE_PATH"`
+	NotificationDriver       string `mapstructure:"NOTIFICATION_DRIVER"`
+	NotificationTimeout      int    `mapstructure:"NOTIFICATION_TIMEOUT_SECONDS"`
+	NotificationFilePath     string `mapstructure:"NOTIFICATION_FILE_PATH"`
+	TemplatesPath            string `mapstructure:"NOTIFICATION_TEMPLATES_PATH"`
 	SlackAuthToken           string `mapstructure:"SLACK_AUTH_TOKEN"`
 	SlackChannelId           string `mapstructure:"SLACK_CHANNEL_ID"`
 	SlackDebugEnabled        bool   `mapstructure:"SLACK_DEBUG_ENABLED"`
 	SlackNotificationEnabled bool   `mapstructure:"SLACK_NOTIFICATION_ENABLED"`
+	SlackActionsEnabled      bool   `mapstructure:"SLACK_ACTIONS_ENABLED"`
+	SlackSigningSecret       string `mapstructure:"SLACK_SIGNING_SECRET"`
+	SlackTeamID              string `mapstructure:"SLACK_TEAM_ID"`
+	SlackAppID               string `mapstructure:"SLACK_APP_ID"`
+	SlackDMAuthors           bool   `mapstructure:"SLACK_DM_AUTHORS"`
+	SlackUserCacheMinutes    int    `mapstructure:"SLACK_USER_CACHE_MINUTES"`
+	TeamsWebhookURL          string `mapstructure:"TEAMS_WEBHOOK_URL"`
+	TeamsNotificationEnabled bool   `mapstructure:"TEAMS_NOTIFICATION_ENABLED"`
+	SMTPHost                 string `mapstructure:"SMTP_HOST"`
+	SMTPPort                 string `mapstructure:"SMTP_PORT"`
+	SMTPUser                 string `mapstructure:"SMTP_USER"`
+	SMTPPass                 string `mapstructure:"SMTP_PASS"`
+	EmailFrom                string `mapstructure:"EMAIL_FROM"`
+	EmailRemediationURL      string `mapstructure:"EMAIL_REMEDIATION_URL"`
+	EmailNotificationEnabled bool   `mapstructure:"EMAIL_NOTIFICATION_ENABLED"`
+	WebhookWorkerEnabled     bool   `mapstructure:"WEBHOOK_WORKER_ENABLED"`
+	WebhookTimeoutSeconds    int    `mapstructure:"WEBHOOK_TIMEOUT_SECONDS"`
+	WebhookMaxAttempts       int    `mapstructure:"WEBHOOK_MAX_ATTEMPTS"`
+	WebhookBackoffSeconds    int    `mapstructure:"WEBHOOK_BACKOFF_SECONDS"`
+	WebhookMaxBackoffSeconds int    `mapstructure:"WEBHOOK_MAX_BACKOFF_SECONDS"`
+	WebhookPollSeconds       int    `mapstructure:"WEBHOOK_POLL_SECONDS"`
+	OutboxWorkerEnabled      bool   `mapstructure:"OUTBOX_WORKER_ENABLED"`
+	OutboxMaxAttempts        int    `mapstructure:"OUTBOX_MAX_ATTEMPTS"`
+	OutboxBackoffSeconds     int    `mapstructure:"OUTBOX_BACKOFF_SECONDS"`
+	OutboxMaxBackoffSeconds  int    `mapstructure:"OUTBOX_MAX_BACKOFF_SECONDS"`
+	OutboxPollSeconds        int    `mapstructure:"OUTBOX_POLL_SECONDS"`
+	DigestWorkerEnabled      bool   `mapstructure:"DIGEST_WORKER_ENABLED"`
+	DigestPollSeconds        int    `mapstructure:"DIGEST_POLL_SECONDS"`
+	DigestDailyHour          int    `mapstructure:"DIGEST_DAILY_HOUR"`
 	ConfigFilePath           string `mapstructure:"CONFIG_FILE_PATH"`
 	ScriptFilePath           string `mapstructure:"SCRIPT_FILE_PATH"`
+	RedactionMode            string `mapstructure:"REDACTION_MODE"`
+	RedactionKeepPrefix      int    `mapstructure:"REDACTION_KEEP_PREFIX"`
+	RedactionKeepSuffix      int    `mapstructure:"REDACTION_KEEP_SUFFIX"`
+	RedactionHMACKey         string `mapstructure:"REDACTION_HMAC_KEY"`
+	DedupIdentity            string `mapstructure:"DEDUP_IDENTITY"`
+	BaselineExcludeResolved  bool   `mapstructure:"BASELINE_EXCLUDE_RESOLVED"`
+	VerdictOnNew             string `mapstructure:"VERDICT_ON_NEW"`
+	VerdictOnKnown           string `mapstructure:"VERDICT_ON_KNOWN"`
+	VerdictOnSuppressed      string `mapstructure:"VERDICT_ON_SUPPRESSED"`
+	PolicyFilePath           string `mapstructure:"POLICY_FILE_PATH"`
+	AuthEnabled              bool   `mapstructure:"AUTH_ENABLED"`
+	AuthAdminKey             string `mapstructure:"AUTH_ADMIN_KEY"`
+	OIDCEnabled              bool   `mapstructure:"OIDC_ENABLED"`
+	OIDCIssuer               string `mapstructure:"OIDC_ISSUER"`
+	OIDCAudience             string `mapstructure:"OIDC_AUDIENCE"`
+	OIDCJWKSURL              string `mapstructure:"OIDC_JWKS_URL"`
+	OIDCJWKSFile             string `mapstructure:"OIDC_JWKS_FILE"`
+	OIDCRolesClaim           string `mapstructure:"OIDC_ROLES_CLAIM"`
+	OIDCGroupsClaim          string `mapstructure:"OIDC_GROUPS_CLAIM"`
+	AccessPolicyFilePath     string `mapstructure:"ACCESS_POLICY_FILE_PATH"`
 }
 
 func LoadConfig(filename string) (config *Config, err error) {
@@ -41,10 +105,71 @@ func LoadConfig(filename string) (config *Config, err error) {
 	viper.SetDefault("MONGO_USER", "root")
 	viper.SetDefault("MONGO_PASS", "password")
 	viper.SetDefault("MONGO_DBNAME", "secrets-operator")
+	viper.SetDefault("STORAGE_DRIVER", "mongo")
+	viper.SetDefault("POSTGRES_HOST", "localhost")
+	viper.SetDefault("POSTGRES_PORT", "5432")
+	viper.SetDefault("POSTGRES_USER", "postgres")
+	viper.SetDefault("POSTGRES_PASS", "password")
+	viper.SetDefault("POSTGRES_DBNAME", "secrets-operator")
+	viper.SetDefault("POSTGRES_SSLMODE", "disable")
+	viper.SetDefault("SQLITE_PATH", "secrets-operator.db")
+	viper.SetDefault("NOTIFICATION_DRIVER", "channels")
+	viper.SetDefault("NOTIFICATION_TIMEOUT_SECONDS", 30)
+	viper.SetDefault("NOTIFICATION_FILE_PATH", "config/notifications.toml")
+	viper.SetDefault("NOTIFICATION_TEMPLATES_PATH", "config/templates")
 	viper.SetDefault("SLACK_DEBUG_ENABLED", false)
 	viper.SetDefault("SLACK_NOTIFICATION_ENABLED", false)
+	viper.SetDefault("SLACK_ACTIONS_ENABLED", false)
+	viper.SetDefault("SLACK_SIGNING_SECRET", "")
+	viper.SetDefault("SLACK_TEAM_ID", "")
+	viper.SetDefault("SLACK_APP_ID", "")
+	viper.SetDefault("SLACK_DM_AUTHORS", false)
+	viper.SetDefault("SLACK_USER_CACHE_MINUTES", 1440)
+	viper.SetDefault("TEAMS_WEBHOOK_URL", "")
+	viper.SetDefault("TEAMS_NOTIFICATION_ENABLED", false)
+	viper.SetDefault("SMTP_HOST", "localhost")
+	viper.SetDefault("SMTP_PORT", "587")
+	viper.SetDefault("SMTP_USER", "")
+	viper.SetDefault("SMTP_PASS", "")
+	viper.SetDefault("EMAIL_FROM", "secrets-operator@localhost")
+	viper.SetDefault("EMAIL_REMEDIATION_URL", "")
+	viper.SetDefault("EMAIL_NOTIFICATION_ENABLED", false)
+	viper.SetDefault("WEBHOOK_WORKER_ENABLED", true)
+	viper.SetDefault("WEBHOOK_TIMEOUT_SECONDS", 10)
+	viper.SetDefault("WEBHOOK_MAX_ATTEMPTS", 8)
+	viper.SetDefault("WEBHOOK_BACKOFF_SECONDS", 30)
+	viper.SetDefault("WEBHOOK_MAX_BACKOFF_SECONDS", 3600)
+	viper.SetDefault("WEBHOOK_POLL_SECONDS", 5)
+	viper.SetDefault("OUTBOX_WORKER_ENABLED", true)
+	viper.SetDefault("OUTBOX_MAX_ATTEMPTS", 10)
+	viper.SetDefault("OUTBOX_BACKOFF_SECONDS", 30)
+	viper.SetDefault("OUTBOX_MAX_BACKOFF_SECONDS", 3600)
+	viper.SetDefault("OUTBOX_POLL_SECONDS", 5)
+	viper.SetDefault("DIGEST_WORKER_ENABLED", true)
+	viper.SetDefault("DIGEST_POLL_SECONDS", 60)
+	viper.SetDefault("DIGEST_DAILY_HOUR", 9)
 	viper.SetDefault("CONFIG_FILE_PATH", "config/config.toml")
 	viper.SetDefault("SCRIPT_FILE_PATH", "config/pipelineScript.sh")
+	viper.SetDefault("REDACTION_MODE", "mask")
+	viper.SetDefault("REDACTION_KEEP_PREFIX", 4)
+	viper.SetDefault("REDACTION_KEEP_SUFFIX", 4)
+	viper.SetDefault("REDACTION_HMAC_KEY", "")
+	viper.SetDefault("DEDUP_IDENTITY", "fingerprint")
+	viper.SetDefault("BASELINE_EXCLUDE_RESOLVED", false)
+	viper.SetDefault("VERDICT_ON_NEW", "fail")
+	viper.SetDefault("VERDICT_ON_KNOWN", "warn")
+	viper.SetDefault("VERDICT_ON_SUPPRESSED", "pass")
+	viper.SetDefault("POLICY_FILE_PATH", "config/policies.toml")
+	viper.SetDefault("AUTH_ENABLED", true)
+	viper.SetDefault("AUTH_ADMIN_KEY", "")
+	viper.SetDefault("OIDC_ENABLED", false)
+	viper.SetDefault("OIDC_ISSUER", "")
+	viper.SetDefault("OIDC_AUDIENCE", "")
+	viper.SetDefault("OIDC_JWKS_URL", "")
+	viper.SetDefault("OIDC_JWKS_FILE", "")
+	viper.SetDefault("OIDC_ROLES_CLAIM", "roles")
+	viper.SetDefault("OIDC_GROUPS_CLAIM", "groups")
+	viper.SetDefault("ACCESS_POLICY_FILE_PATH", "config/access.toml")
 
 	// load from env and override defaults and values loaded from config file
 	// first one in row takes precedence:
@@ -66,6 +191,14 @@ func LoadConfig(filename string) (config *Config, err error) {
 	config.ServerAddr = fmt.Sprintf("%s:%s", config.ServerHost, config.ServerPort)
 	config.MongoURI = fmt.Sprintf("mongodb://%s:%s@%s:%s/%s?serverSelectionTimeoutMS=5000&connectTimeoutMS=10000&authSource=admin&authMechanism=SCRAM-SHA-256",
 		config.MongoUser, config.MongoPass, config.MongoHost, config.MongoPort, config.MongoDBName)
+	// userinfo has its own escaping, query escaping turns spaces of passwords into "+" which lib/pq takes literally
+	config.PostgresDSN = (&url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(config.PostgresUser, config.PostgresPass),
+		Host:     net.JoinHostPort(config.PostgresHost, config.PostgresPort),
+		Path:     config.PostgresDBName,
+		RawQuery: "sslmode=" + url.QueryEscape(config.PostgresSSLMode),
+	}).String()
 
 	return config, nil
 }
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/viper"
+	"os"
+	"strconv"
+)
+
+// ChannelFilter limits findings delivered to a notification channel, empty lists match everything.
+// Mode tells when the channel is notified: immediate (default), hourly, daily or suppressed.
+type ChannelFilter struct {
+	Repos        []int    `mapstructure:"repos"`
+	Rules        []string `mapstructure:"rules"`
+	ExcludeRules []string `mapstructure:"exclude_rules"`
+	Mode         string   `mapstructure:"mode"`
+}
+
+// LoadChannelFilters reads filters of notification channels keyed by channel name, e.g.
+//
+//	[channels.slack]
+//	repos = [444]
+//	exclude_rules = ["generic-api-key"]
+//	mode = "daily"
+//
+// Missing file is not an error, every enabled channel is notified about every report then.
+func LoadChannelFilters(path string) (map[string]ChannelFilter, error) {
+
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return map[string]ChannelFilter{}, nil
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	file := struct {
+		Channels map[string]ChannelFilter `mapstructure:"channels"`
+	}{}
+
+	if err := v.Unmarshal(&file); err != nil {
+		return nil, err
+	}
+
+	if file.Channels == nil {
+		file.Channels = map[string]ChannelFilter{}
+	}
+
+	return file.Channels, nil
+}
+
+// EmailSettings are recipients and remediation links of email notifications
+type EmailSettings struct {
+	// CC receives every email notification, e.g. security team
+	CC []string
+	// Owners are mailed about findings of their repositories in addition to commit authors
+	Owners map[int][]string
+	// RemediationURLs link rotation guides by rule id, EMAIL_REMEDIATION_URL is linked for other rules
+	RemediationURLs map[string]string
+}
+
+// LoadEmailSettings reads email section of notification filters file, e.g.
+//
+//	[email]
+//	cc = ["security@example.com"]
+//
+//	[email.remediation]
+//	aws-access-token = "https://wiki.example.com/rotate-aws-keys"
+//
+//	[email.repos.444]
+//	owners = ["payments-lead@example.com"]
+func LoadEmailSettings(path string) (EmailSettings, error) {
+
+	settings := EmailSettings{Owners: map[int][]string{}, RemediationURLs: map[string]string{}}
+
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return settings, nil
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+
+	if err := v.ReadInConfig(); err != nil {
+		return EmailSettings{}, err
+	}
+
+	file := struct {
+		Email struct {
+			CC          []string          `mapstructure:"cc"`
+			Remediation map[string]string `mapstructure:"remediation"`
+			Repos       map[string]struct {
+				Owners []string `mapstructure:"owners"`
+			} `mapstructure:"repos"`
+		} `mapstructure:"email"`
+	}{}
+
+	if err := v.Unmarshal(&file); err != nil {
+		return EmailSettings{}, err
+	}
+
+	settings.CC = file.Email.CC
+
+	for ruleId, remediationURL := range file.Email.Remediation {
+		settings.RemediationURLs[ruleId] = remediationURL
+	}
+
+	for key, repo := range file.Email.Repos {
+		repoId, err := strconv.Atoi(key)
+		if err != nil {
+			return EmailSettings{}, fmt.Errorf("invalid repository id %q in notification file %s", key, path)
+		}
+		settings.Owners[repoId] = repo.Owners
+	}
+
+	return settings, nil
+}
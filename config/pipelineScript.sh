@@ -3,22 +3,31 @@
 ## step 1: get base findings and config
 ## step 2: gitleaks detect with basepath
 ## step 3: post new findings
+## step 4: fail the job according to verdict
 
 SECRETS_OPERATOR_URL=http://secrets-operator-dev.apps.test.ocp.ibar.az
 PREFIX=/api/v1/findings
 
 # step 1
 echo Fetching baseline findings and gitleaks config.toml ...
-curl "${SECRETS_OPERATOR_URL}${PREFIX}/${CI_PROJECT_ID}" | jq '.findings' > base-findings.json
-curl "${SECRETS_OPERATOR_URL}/api/v1/config.toml" > config.toml
+# SECRETS_OPERATOR_TOKEN is an upload token issued for this project, keep it in masked CI/CD variables
+# baseline is empty for projects scanned for the first time
+curl --fail --header "Authorization: Bearer ${SECRETS_OPERATOR_TOKEN}" "${SECRETS_OPERATOR_URL}/api/v1/repos/${CI_PROJECT_ID}/baseline" > base-findings.json || exit 1
+curl --fail "${SECRETS_OPERATOR_URL}/api/v1/config.toml" > config.toml || exit 1
+# first line of config.toml tells its version, the report records which rules found its findings
+CONFIG_VERSION=$(sed -n '1s/.*version \([0-9][0-9]*\)$/\1/p' config.toml)
 
 # step 2
+# --redact keeps secrets in the pipeline, the baseline can only match redacted findings anyway.
+# Redacted uploads carry no secret, so secrets operator keeps no SecretHash for them, only uploads with raw
+# secrets (e.g. TruffleHog or SARIF reports of other pipelines) are hashed.
 echo Running gitleaks ...
-gitleaks detect --config config.toml --baseline-path base-findings.json --source . --report-path findings.json
+gitleaks detect --redact --config config.toml --baseline-path base-findings.json --source . --report-path findings.json
 
 #step3
 echo Publishing findings report to secrets operator ...
 curl --location --request POST "${SECRETS_OPERATOR_URL}${PREFIX}/upload" \
+--header "Authorization: Bearer ${SECRETS_OPERATOR_TOKEN}" \
 --header "pipelineId: ${CI_PIPELINE_ID}" \
 --header "repoName: ${CI_PROJECT_NAME}" \
 --header "repoId: ${CI_PROJECT_ID}" \
@@ -27,8 +36,32 @@ curl --location --request POST "${SECRETS_OPERATOR_URL}${PREFIX}/upload" \
 --header "commitSHA: ${CI_COMMIT_SHA}" \
 --header "timestamp: $(date +%s)" \
 --header "notify: true" \
+--header "configVersion: ${CONFIG_VERSION:-0}" \
 --header "Content-Type: application/json" \
--d @findings.json
+--dump-header upload-headers.txt \
+-d @findings.json > upload-result.json
+
+cat upload-result.json
+# verdict is repeated in a response header, so the job needs no JSON parser; failed uploads have none
+VERDICT=$(grep -i '^x-secrets-operator-verdict:' upload-headers.txt | cut -d: -f2 | tr -d ' \r')
 
 echo -e "\nDone."
-echo Check details here: $SECRETS_OPERATOR_URL
\ No newline at end of file
+echo Check details here: $SECRETS_OPERATOR_URL
+
+# step 4
+# verdict is decided by repository policy of secrets operator: pass, warn or fail
+case "$VERDICT" in
+  fail)
+    echo "New secrets introduced: $(grep -o '"newFingerprints":\[[^]]*\]' upload-result.json | cut -d: -f2-)"
+    exit 1
+    ;;
+  warn)
+    echo "Known secrets are still present, please rotate them and mark them as revoked."
+    ;;
+  pass)
+    ;;
+  *)
+    echo "Findings report was not accepted by secrets operator."
+    exit 1
+    ;;
+esac
\ No newline at end of file
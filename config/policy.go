@@ -0,0 +1,56 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/viper"
+	"os"
+	"strconv"
+)
+
+// RepoPolicy overrides verdicts for a single repository, empty values fall back to VERDICT_* variables
+type RepoPolicy struct {
+	OnNew        string `mapstructure:"on_new"`
+	OnKnown      string `mapstructure:"on_known"`
+	OnSuppressed string `mapstructure:"on_suppressed"`
+}
+
+// LoadRepoPolicies reads per repository policies keyed by repository id, e.g.
+//
+//	[repos.444]
+//	on_new = "warn"
+//
+// Missing policy file is not an error, default verdicts apply to every repository then.
+func LoadRepoPolicies(path string) (map[int]RepoPolicy, error) {
+
+	policies := map[int]RepoPolicy{}
+
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return policies, nil
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	file := struct {
+		Repos map[string]RepoPolicy `mapstructure:"repos"`
+	}{}
+
+	if err := v.Unmarshal(&file); err != nil {
+		return nil, err
+	}
+
+	for key, policy := range file.Repos {
+		repoId, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid repository id %q in policy file %s", key, path)
+		}
+		policies[repoId] = policy
+	}
+
+	return policies, nil
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// templateExtension marks notification templates, other files of the directory are ignored
+const templateExtension = ".tmpl"
+
+// LoadTemplates reads notification templates of the directory keyed by file name without extension,
+// e.g. slack.tmpl overrides the default Slack message, slack-dm.tmpl the direct message to commit authors and payments.tmpl
+// can be used by routing rules.
+// Missing directory is not an error, default templates apply then.
+func LoadTemplates(dir string) (map[string]string, error) {
+
+	templates := map[string]string{}
+
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return templates, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+
+		if entry.IsDir() || filepath.Ext(entry.Name()) != templateExtension {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		templates[strings.TrimSuffix(entry.Name(), templateExtension)] = string(content)
+	}
+
+	return templates, nil
+}
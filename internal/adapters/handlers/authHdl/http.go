@@ -0,0 +1,161 @@
+package authHdl
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+	"net/http"
+	"secrets-operator/config"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/core/ports"
+	"secrets-operator/internal/errors"
+	"strings"
+)
+
+type httpHandler struct {
+	cfg         *config.Config
+	l           *zap.SugaredLogger
+	validate    *validator.Validate
+	authService ports.AuthService
+}
+
+func NewAuthHandler(cfg *config.Config, l *zap.SugaredLogger, authService ports.AuthService) *httpHandler {
+
+	return &httpHandler{
+		cfg:         cfg,
+		l:           l,
+		validate:    validator.New(),
+		authService: authService,
+	}
+}
+
+// Authenticate is a middleware resolving "Authorization: Bearer <token>" header to principal of the request
+func (handler *httpHandler) Authenticate(c *gin.Context) {
+
+	header := c.GetHeader("Authorization")
+	token := ""
+	if strings.HasPrefix(header, "Bearer ") {
+		token = strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+	}
+
+	principal, err := handler.authService.Authenticate(token)
+	if err != nil {
+		if err == errors.ErrCouldNotAuthenticate {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"message": "Could not authenticate, something went wrong",
+			})
+			return
+		}
+
+		c.Header("WWW-Authenticate", `Bearer realm="secrets-operator"`)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"message": "Unauthorized",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.Set(principalKey, principal)
+	c.Next()
+}
+
+// Create issues new API key, the token is included in response only once
+func (handler *httpHandler) Create(c *gin.Context) {
+
+	request := domain.APIKey{}
+
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		handler.l.Errorln("could not bind api key request.", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Cannot extract payload from request",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	err = handler.validate.Struct(request)
+	if err != nil {
+		handler.l.Errorln("api key validation failed.", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Validation failed.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	principal, _ := PrincipalFrom(c)
+
+	token, apiKey, err := handler.authService.IssueKey(request, principal.Name)
+	if err != nil {
+		handler.l.Errorln(err)
+		if err == errors.ErrInvalidAPIKey {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"message": "Invalid api key",
+				"error":   err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not issue api key, something went wrong",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Created",
+		"token":   token,
+		"key":     apiKey,
+	})
+}
+
+func (handler *httpHandler) List(c *gin.Context) {
+
+	apiKeys, err := handler.authService.ListKeys()
+	if err != nil {
+		handler.l.Errorln(err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not get api keys, something went wrong",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": apiKeys,
+	})
+}
+
+func (handler *httpHandler) Revoke(c *gin.Context) {
+
+	id := c.Param("id")
+
+	err := handler.validate.Var(id, "required,hexadecimal,len=24")
+	if err != nil {
+		handler.l.Errorln("validation failed for id parameter", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Could not process id parameter in request URI",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	err = handler.authService.RevokeKey(id)
+	if err != nil {
+		switch err {
+		case errors.ErrAPIKeyNotFound:
+			c.JSON(http.StatusNotFound, gin.H{
+				"message": "Api key not found",
+				"error":   err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"message": "Could not revoke api key, something went wrong",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Revoked",
+	})
+}
@@ -0,0 +1,287 @@
+package authHdl
+
+import (
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-json"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"net/http/httptest"
+	"secrets-operator/config"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/core/ports/mocks"
+	"secrets-operator/internal/errors"
+	"testing"
+)
+
+type AuthHandlerTestSuite struct {
+	suite.Suite
+	sugaredLogger *zap.SugaredLogger
+	cfg           *config.Config
+	ctrl          *gomock.Controller
+}
+
+func TestSuiteAuthHandler(t *testing.T) {
+	suite.Run(t, new(AuthHandlerTestSuite))
+}
+
+func (s *AuthHandlerTestSuite) SetupTest() {
+
+	var err error
+
+	s.sugaredLogger = zap.NewNop().Sugar()
+
+	// setup configs
+	s.cfg, err = config.LoadConfig("test")
+	if err != nil {
+		s.T().Fatalf("cannot load configuration variables. %v", err.Error())
+	}
+
+	// setup gomock controller
+	s.ctrl = gomock.NewController(s.T())
+	defer s.ctrl.Finish()
+}
+
+func (s *AuthHandlerTestSuite) serve(router *gin.Engine, method, target, token string, body interface{}) *httptest.ResponseRecorder {
+
+	reqBodyBytes := new(bytes.Buffer)
+	if body != nil {
+		if err := json.NewEncoder(reqBodyBytes).Encode(body); err != nil {
+			s.T().Fatal("could not encode request body for testing.", err)
+		}
+	}
+
+	request := httptest.NewRequest(method, target, reqBodyBytes)
+	request.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+func (s *AuthHandlerTestSuite) TestHttpHandler_AuthenticateTableDriven() {
+
+	tests := []struct {
+		name                 string
+		token                string
+		authenticateReturn   domain.Principal
+		authenticateErr      error
+		wantStatusCode       int
+		wantPrincipalInRoute bool
+	}{
+		{"valid token", "so_valid", domain.Principal{Name: "pipeline", Role: domain.RoleViewer, RepoIDs: []int{444}}, nil, 200, true},
+		{"missing token", "", domain.Principal{}, errors.ErrUnauthorized, 401, false},
+		{"revoked or unknown token", "so_revoked", domain.Principal{}, errors.ErrUnauthorized, 401, false},
+		{"service error", "so_valid", domain.Principal{}, errors.ErrCouldNotAuthenticate, 500, false},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// arrange
+			mockAuthService := mocks.NewMockAuthService(s.ctrl)
+			mockAuthService.EXPECT().Authenticate(tt.token).Return(tt.authenticateReturn, tt.authenticateErr)
+
+			sut := NewAuthHandler(s.cfg, s.sugaredLogger, mockAuthService)
+
+			reached := false
+			router := gin.New()
+			router.GET("/protected", sut.Authenticate, func(c *gin.Context) {
+				principal, ok := PrincipalFrom(c)
+				reached = ok && assert.Equal(s.T(), tt.authenticateReturn, principal)
+				c.Status(200)
+			})
+
+			// act
+			recorder := s.serve(router, "GET", "/protected", tt.token, nil)
+
+			// assert
+			assert.Equal(s.T(), tt.wantStatusCode, recorder.Code)
+			assert.Equal(s.T(), tt.wantPrincipalInRoute, reached)
+			if tt.wantStatusCode == 401 {
+				assert.NotEmpty(s.T(), recorder.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func (s *AuthHandlerTestSuite) TestRequireTableDriven() {
+
+	admin := domain.Principal{Role: domain.RoleAdmin}
+	upload := domain.Principal{Role: domain.RoleUploader, RepoIDs: []int{444}}
+	read := domain.Principal{Role: domain.RoleViewer}
+
+	tests := []struct {
+		name           string
+		principal      *domain.Principal
+		middleware     gin.HandlerFunc
+		path           string
+		wantStatusCode int
+	}{
+		{"admin passes admin check", &admin, RequireAdmin, "/444", 200},
+		{"read token fails admin check", &read, RequireAdmin, "/444", 403},
+		{"missing principal is forbidden", nil, RequireAdmin, "/444", 403},
+		{"upload token may upload", &upload, RequireUpload, "/444", 200},
+		{"read token may not upload", &read, RequireUpload, "/444", 403},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// arrange
+			router := gin.New()
+			if tt.principal != nil {
+				router.Use(WithPrincipal(*tt.principal))
+			}
+			router.GET("/:id", tt.middleware, func(c *gin.Context) {
+				c.Status(200)
+			})
+
+			// act
+			recorder := s.serve(router, "GET", tt.path, "", nil)
+
+			// assert
+			assert.Equal(s.T(), tt.wantStatusCode, recorder.Code)
+		})
+	}
+}
+
+func (s *AuthHandlerTestSuite) TestHttpHandler_CreateTableDriven() {
+
+	tests := []struct {
+		name                   string
+		inputBody              interface{}
+		issueKeyReturnErr      error
+		wantIssueKeyInvocation bool
+		wantStatusCode         int
+	}{
+		{"valid upload key", map[string]interface{}{"name": "pipeline", "scope": "upload", "repoId": 444}, nil, true, 201},
+		{"valid read key", map[string]interface{}{"name": "dashboard", "scope": "read"}, nil, true, 201},
+		{"upload key without repository", map[string]interface{}{"name": "pipeline", "scope": "upload"}, nil, false, 400},
+		{"unknown scope", map[string]interface{}{"name": "pipeline", "scope": "write"}, nil, false, 400},
+		{"missing name", map[string]interface{}{"scope": "read"}, nil, false, 400},
+		{"service rejects key", map[string]interface{}{"name": "pipeline", "scope": "read"}, errors.ErrInvalidAPIKey, true, 400},
+		{"service error", map[string]interface{}{"name": "pipeline", "scope": "read"}, errors.ErrCouldNotIssueAPIKey, true, 500},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// arrange
+			mockAuthService := mocks.NewMockAuthService(s.ctrl)
+
+			invoked := false
+			mockAuthService.
+				EXPECT().
+				IssueKey(gomock.Any(), "test admin").
+				DoAndReturn(func(apiKey domain.APIKey, createdBy string) (string, domain.APIKey, error) {
+					invoked = true
+					apiKey.ID = "a1"
+					return "so_token", apiKey, tt.issueKeyReturnErr
+				}).
+				AnyTimes()
+
+			sut := NewAuthHandler(s.cfg, s.sugaredLogger, mockAuthService)
+
+			router := gin.New()
+			router.Use(WithPrincipal(domain.Principal{Name: "test admin", Role: domain.RoleAdmin}))
+			router.POST("/api/v1/tokens", sut.Create)
+
+			// act
+			recorder := s.serve(router, "POST", "/api/v1/tokens", "", tt.inputBody)
+
+			// assert
+			assert.Equal(s.T(), tt.wantStatusCode, recorder.Code, recorder.Body.String())
+			assert.Equal(s.T(), tt.wantIssueKeyInvocation, invoked)
+
+			if tt.wantStatusCode == 201 {
+				resp := struct {
+					Token string        `json:"token"`
+					Key   domain.APIKey `json:"key"`
+				}{}
+				if err := json.NewDecoder(recorder.Body).Decode(&resp); err != nil {
+					s.T().Fatal("could not decode response body.", err)
+				}
+				assert.Equal(s.T(), "so_token", resp.Token)
+				assert.Equal(s.T(), "a1", resp.Key.ID)
+				assert.Empty(s.T(), resp.Key.KeyHash, "key hash must never be returned")
+			}
+		})
+	}
+}
+
+func (s *AuthHandlerTestSuite) TestHttpHandler_List() {
+
+	// arrange
+	mockAuthService := mocks.NewMockAuthService(s.ctrl)
+	mockAuthService.EXPECT().ListKeys().Return([]domain.APIKey{{ID: "a1", Name: "pipeline", KeyHash: "secret hash"}}, nil)
+
+	sut := NewAuthHandler(s.cfg, s.sugaredLogger, mockAuthService)
+
+	router := gin.New()
+	router.GET("/api/v1/tokens", sut.List)
+
+	// act
+	recorder := s.serve(router, "GET", "/api/v1/tokens", "", nil)
+
+	// assert
+	assert.Equal(s.T(), 200, recorder.Code)
+	assert.Contains(s.T(), recorder.Body.String(), `"id":"a1"`)
+	assert.NotContains(s.T(), recorder.Body.String(), "secret hash")
+}
+
+func (s *AuthHandlerTestSuite) TestHttpHandler_RevokeTableDriven() {
+
+	tests := []struct {
+		name                    string
+		inputId                 string
+		revokeKeyReturnErr      error
+		wantRevokeKeyInvocation bool
+		wantStatusCode          int
+	}{
+		{"revoked", "0123456789abcdef01234567", nil, true, 200},
+		{"malformed id", "a1", nil, false, 400},
+		{"unknown key", "0123456789abcdef01234567", errors.ErrAPIKeyNotFound, true, 404},
+		{"service error", "0123456789abcdef01234567", errors.ErrCouldNotRevokeAPIKey, true, 500},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// arrange
+			mockAuthService := mocks.NewMockAuthService(s.ctrl)
+
+			invoked := false
+			mockAuthService.
+				EXPECT().
+				RevokeKey(tt.inputId).
+				DoAndReturn(func(id string) error {
+					invoked = true
+					return tt.revokeKeyReturnErr
+				}).
+				AnyTimes()
+
+			sut := NewAuthHandler(s.cfg, s.sugaredLogger, mockAuthService)
+
+			router := gin.New()
+			router.DELETE("/api/v1/tokens/:id", sut.Revoke)
+
+			// act
+			recorder := s.serve(router, "DELETE", "/api/v1/tokens/"+tt.inputId, "", nil)
+
+			// assert
+			assert.Equal(s.T(), tt.wantStatusCode, recorder.Code)
+			assert.Equal(s.T(), tt.wantRevokeKeyInvocation, invoked)
+		})
+	}
+}
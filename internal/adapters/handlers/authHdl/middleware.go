@@ -0,0 +1,55 @@
+package authHdl
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"secrets-operator/internal/core/domain"
+)
+
+const principalKey = "principal"
+
+// WithPrincipal sets principal of every request, it is used when authentication is disabled
+func WithPrincipal(principal domain.Principal) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(principalKey, principal)
+		c.Next()
+	}
+}
+
+// PrincipalFrom returns principal set by authentication middleware
+func PrincipalFrom(c *gin.Context) (domain.Principal, bool) {
+
+	value, ok := c.Get(principalKey)
+	if !ok {
+		return domain.Principal{}, false
+	}
+
+	principal, ok := value.(domain.Principal)
+	return principal, ok
+}
+
+func RequireAdmin(c *gin.Context) {
+	require(c, func(principal domain.Principal) bool {
+		return principal.IsAdmin()
+	})
+}
+
+// RequireUpload allows upload and admin tokens, repository of the report is checked by the handler once it is resolved
+func RequireUpload(c *gin.Context) {
+	require(c, func(principal domain.Principal) bool {
+		return principal.IsAdmin() || principal.Role == domain.RoleUploader
+	})
+}
+
+func require(c *gin.Context, allowed func(principal domain.Principal) bool) {
+
+	principal, ok := PrincipalFrom(c)
+	if !ok || !allowed(principal) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"message": "Token is not allowed to access this resource",
+		})
+		return
+	}
+
+	c.Next()
+}
@@ -0,0 +1,95 @@
+package findingHdl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"secrets-operator/internal/core/domain"
+	"sort"
+)
+
+// detectSecretsBaseline is a Yelp detect-secrets baseline, results are keyed by file
+type detectSecretsBaseline struct {
+	Version string                           `json:"version"`
+	Results map[string][]detectSecretsResult `json:"results"`
+}
+
+type detectSecretsResult struct {
+	Type         string `json:"type"`
+	Filename     string `json:"filename"`
+	HashedSecret string `json:"hashed_secret"`
+	IsVerified   bool   `json:"is_verified"`
+	LineNumber   int    `json:"line_number"`
+	// IsSecret is set by detect-secrets audit, false marks a false positive
+	IsSecret *bool `json:"is_secret,omitempty"`
+}
+
+// decodeDetectSecrets maps results of a baseline to findings, the plugin type is the rule. Baselines never contain
+// secrets, so the SHA-1 hash of the secret stands in for the secret and identifies the finding together with file and
+// rule, line numbers of baselines move with every edit. Results audited as false positives are skipped.
+func decodeDetectSecrets(body []byte) (upload, error) {
+
+	baseline := detectSecretsBaseline{}
+	if err := json.Unmarshal(body, &baseline); err != nil {
+		return upload{}, err
+	}
+
+	files := make([]string, 0, len(baseline.Results))
+	for file := range baseline.Results {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	decoded := newUpload()
+	decoded.optional = []string{"Entropy", "Email", "Message"}
+	decoded.inheritCommit = true
+
+	for _, file := range files {
+		for i, result := range baseline.Results[file] {
+
+			path := fmt.Sprintf("results[%q][%d]", file, i)
+
+			if result.IsSecret != nil && !*result.IsSecret {
+				continue
+			}
+
+			finding, err := detectSecretsFinding(file, result)
+			decoded.add(path, finding, err)
+		}
+	}
+
+	return decoded, nil
+}
+
+func detectSecretsFinding(file string, result detectSecretsResult) (domain.Finding, error) {
+
+	if result.Type == "" {
+		return domain.Finding{}, errors.New("type is required")
+	}
+	if result.HashedSecret == "" {
+		return domain.Finding{}, errors.New("hashed_secret is required")
+	}
+	if result.Filename != "" {
+		file = result.Filename
+	}
+
+	tags := []string{"detect-secrets"}
+	if result.IsVerified {
+		tags = append(tags, "verified")
+	}
+
+	return domain.Finding{
+		Description: fmt.Sprintf("%s found by detect-secrets", result.Type),
+		StartLine:   result.LineNumber,
+		EndLine:     result.LineNumber,
+		StartColumn: 1,
+		EndColumn:   1,
+		Match:       result.HashedSecret,
+		Secret:      result.HashedSecret,
+		File:        file,
+		Tags:        tags,
+		RuleID:      result.Type,
+		Fingerprint: fmt.Sprintf("%s:%s:%s", file, result.Type, result.HashedSecret),
+		Verified:    result.IsVerified,
+	}, nil
+}
@@ -0,0 +1,290 @@
+package findingHdl
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// exportFormat is the format of exported findings, chosen by the "format" query parameter
+type exportFormat string
+
+const (
+	exportSARIF    exportFormat = "sarif"
+	exportCSV      exportFormat = "csv"
+	exportBaseline exportFormat = "gitleaks-baseline"
+)
+
+// sarifSchema is the schema of SARIF 2.1.0 logs
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+var csvHeader = []string{
+	"ID", "Status", "Assignee", "RuleID", "Description", "File", "StartLine", "EndLine", "StartColumn", "EndColumn",
+	"Secret", "Match", "Commit", "Author", "Email", "Date", "Message", "Tags", "Fingerprint", "Verified", "FirstSeen",
+	"LastSeen", "OccurrenceCount",
+}
+
+// Export writes stored findings of the repository as a SARIF 2.1.0 log for code scanning views, CSV for spreadsheets or
+// a gitleaks baseline, ?format=sarif|csv|gitleaks-baseline, sarif by default. Findings are filtered by ?status= like
+// Get. Secrets are exported as stored, redacted by the redaction policy, baselines mask them the way gitleaks does.
+func (handler *httpHandler) Export(c *gin.Context) {
+
+	format := exportFormat(strings.ToLower(strings.TrimSpace(c.DefaultQuery("format", string(exportSARIF)))))
+	if format != exportSARIF && format != exportCSV && format != exportBaseline {
+		handler.l.Errorln("unknown export format", format)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Could not process format query parameter",
+			"error":   fmt.Sprintf("unknown format %q, use %s, %s or %s", format, exportSARIF, exportCSV, exportBaseline),
+		})
+		return
+	}
+
+	payload, ok := handler.readFindings(c)
+	if !ok {
+		return
+	}
+
+	var body []byte
+	var contentType, extension string
+	var err error
+
+	switch format {
+	case exportCSV:
+		body, err = findingsCSV(payload.Findings)
+		contentType, extension = "text/csv; charset=utf-8", "csv"
+	case exportBaseline:
+		body, err = json.Marshal(payload.Findings.Baseline())
+		contentType, extension = "application/json; charset=utf-8", "json"
+	default:
+		body, err = json.Marshal(findingsSARIF(payload.Findings))
+		contentType, extension = contentTypeSARIF, "sarif"
+	}
+	if err != nil {
+		handler.l.Errorln("could not export findings", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not export findings, something went wrong",
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="repo-%d-findings.%s"`, payload.RepoID, extension))
+	c.Data(http.StatusOK, contentType, body)
+}
+
+// Baseline writes the gitleaks baseline of the repository, the JSON array gitleaks --baseline-path reads. Findings of
+// every status are included, so false positives stay suppressed, resolved ones only unless BASELINE_EXCLUDE_RESOLVED
+// is set. Repositories nothing was uploaded for yet have an empty baseline, so first pipelines need no special case.
+func (handler *httpHandler) Baseline(c *gin.Context) {
+
+	repoIdParam, ok := handler.readRepoId(c)
+	if !ok {
+		return
+	}
+
+	payload, err := handler.findingService.GetById(repoIdParam)
+	if err == errors.ErrRepositoryNotFound {
+		c.JSON(http.StatusOK, []domain.BaselineFinding{})
+		return
+	}
+	if err != nil {
+		handler.l.Errorln("could not get findings for baseline", err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not get baseline, something went wrong",
+		})
+		return
+	}
+
+	findings := payload.Findings
+	if handler.cfg.BaselineExcludeResolved {
+		findings = findings.ExcludeStatus(domain.FindingStatusResolved)
+	}
+
+	c.JSON(http.StatusOK, findings.Baseline())
+}
+
+// findingsSARIF describes findings as results of a single run, rules are listed in the order they are first seen.
+// Findings triaged to any status other than open are suppressed, as they are for upload verdicts.
+func findingsSARIF(findings domain.Findings) sarifLog {
+
+	run := sarifRun{
+		Tool:    sarifTool{Driver: sarifDriver{Name: "secrets-operator", Rules: []sarifRule{}}},
+		Results: []sarifResult{},
+	}
+	ruleIndexes := map[string]int{}
+
+	for _, finding := range findings {
+
+		ruleIndex, ok := ruleIndexes[finding.RuleID]
+		if !ok {
+			ruleIndex = len(run.Tool.Driver.Rules)
+			ruleIndexes[finding.RuleID] = ruleIndex
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+				ID:               finding.RuleID,
+				Name:             finding.RuleID,
+				ShortDescription: &sarifMessage{Text: finding.Description},
+			})
+		}
+		index := ruleIndex
+
+		properties := sarifProperties{
+			"match":           finding.Match,
+			"tags":            finding.Tags,
+			"status":          string(findingStatus(finding)),
+			"verified":        finding.Verified,
+			"occurrenceCount": finding.OccurrenceCount,
+		}
+		if finding.Entropy > 0 {
+			properties["entropy"] = finding.Entropy
+		}
+		if finding.Assignee != "" {
+			properties["assignee"] = finding.Assignee
+		}
+		if !finding.FirstSeen.IsZero() {
+			properties["firstSeen"] = finding.FirstSeen.UTC().Format(time.RFC3339)
+			properties["lastSeen"] = finding.LastSeen.UTC().Format(time.RFC3339)
+		}
+
+		partialFingerprints := map[string]string{
+			"commitSha":     finding.Commit,
+			"author":        finding.Author,
+			"email":         finding.Email,
+			"commitMessage": finding.Message,
+			"fingerprint":   finding.Fingerprint,
+		}
+		if !finding.Date.IsZero() {
+			partialFingerprints["date"] = finding.Date.UTC().Format(time.RFC3339)
+		}
+
+		result := sarifResult{
+			RuleID:    finding.RuleID,
+			RuleIndex: &index,
+			Level:     "error",
+			Message:   sarifMessage{Text: fmt.Sprintf("%s found in %s", finding.Description, finding.File)},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: finding.File},
+				Region: sarifRegion{
+					StartLine:   finding.StartLine,
+					StartColumn: finding.StartColumn,
+					EndLine:     finding.EndLine,
+					EndColumn:   finding.EndColumn,
+					Snippet:     &sarifSnippet{Text: finding.Secret},
+				},
+			}}},
+			PartialFingerprints: partialFingerprints,
+			Properties:          properties,
+		}
+
+		if status := findingStatus(finding); status != domain.FindingStatusOpen {
+			result.Suppressions = []sarifSuppression{{Kind: "external", Status: "accepted", Justification: justification(finding)}}
+		}
+
+		run.Results = append(run.Results, result)
+	}
+
+	return sarifLog{Version: sarifVersion, Schema: sarifSchema, Runs: []sarifRun{run}}
+}
+
+// findingsCSV writes a row per finding under csvHeader
+func findingsCSV(findings domain.Findings) ([]byte, error) {
+
+	buffer := &bytes.Buffer{}
+	writer := csv.NewWriter(buffer)
+
+	if err := writer.Write(csvHeader); err != nil {
+		return nil, err
+	}
+
+	for _, finding := range findings {
+
+		row := []string{
+			finding.ID,
+			string(findingStatus(finding)),
+			finding.Assignee,
+			finding.RuleID,
+			finding.Description,
+			finding.File,
+			strconv.Itoa(finding.StartLine),
+			strconv.Itoa(finding.EndLine),
+			strconv.Itoa(finding.StartColumn),
+			strconv.Itoa(finding.EndColumn),
+			finding.Secret,
+			finding.Match,
+			finding.Commit,
+			finding.Author,
+			finding.Email,
+			csvTime(finding.Date),
+			finding.Message,
+			strings.Join(finding.Tags, ";"),
+			finding.Fingerprint,
+			strconv.FormatBool(finding.Verified),
+			csvTime(finding.FirstSeen),
+			csvTime(finding.LastSeen),
+			strconv.Itoa(finding.OccurrenceCount),
+		}
+
+		for i := range row {
+			row[i] = csvCell(row[i])
+		}
+
+		if err := writer.Write(row); err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+
+	return buffer.Bytes(), writer.Error()
+}
+
+// csvCell keeps spreadsheets from evaluating values, secrets and commit messages included, as formulas
+func csvCell(value string) string {
+
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+
+	return value
+}
+
+func csvTime(value time.Time) string {
+
+	if value.IsZero() {
+		return ""
+	}
+
+	return value.UTC().Format(time.RFC3339)
+}
+
+// findingStatus returns status of the finding, findings stored before statuses existed are open
+func findingStatus(finding domain.Finding) domain.FindingStatus {
+
+	if finding.Status == "" {
+		return domain.FindingStatusOpen
+	}
+
+	return finding.Status
+}
+
+// justification describes the last status change of the finding
+func justification(finding domain.Finding) string {
+
+	if len(finding.StatusHistory) == 0 {
+		return "status " + string(findingStatus(finding))
+	}
+
+	change := finding.StatusHistory[len(finding.StatusHistory)-1]
+	text := fmt.Sprintf("status %s by %s", change.Status, change.ChangedBy)
+	if change.Comment != "" {
+		text += ": " + change.Comment
+	}
+
+	return text
+}
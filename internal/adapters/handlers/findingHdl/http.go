@@ -1,17 +1,23 @@
 package findingHdl
 
 import (
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"go.uber.org/zap"
 	"net/http"
 	"secrets-operator/config"
+	"secrets-operator/internal/adapters/handlers/authHdl"
 	"secrets-operator/internal/core/domain"
 	"secrets-operator/internal/core/ports"
+	"secrets-operator/internal/errors"
 	"strconv"
 	"time"
 )
 
+// headerVerdict repeats the verdict of an upload, so pipelines can read it without parsing the JSON response
+const headerVerdict = "X-Secrets-Operator-Verdict"
+
 type httpHandler struct {
 	cfg            *config.Config
 	l              *zap.SugaredLogger
@@ -31,6 +37,53 @@ func NewFindingsHandler(cfg *config.Config, l *zap.SugaredLogger, findingService
 
 func (handler *httpHandler) Get(c *gin.Context) {
 
+	payload, ok := handler.readFindings(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, payload)
+}
+
+// readFindings returns findings of the repository in the request URI filtered by the status query parameter, or
+// responds with the problem when the repository can not be read
+func (handler *httpHandler) readFindings(c *gin.Context) (domain.RepoFindings, bool) {
+
+	repoIdParam, ok := handler.readRepoId(c)
+	if !ok {
+		return domain.RepoFindings{}, false
+	}
+
+	// optional comma separated status filter, e.g. ?status=open,acknowledged
+	statuses, invalidStatus := domain.ParseFindingStatuses(c.Query("status"))
+	if invalidStatus != "" {
+		handler.l.Errorln("invalid status filter", invalidStatus)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Could not process status query parameter",
+			"error":   fmt.Sprintf("unknown status %q", invalidStatus),
+		})
+		return domain.RepoFindings{}, false
+	}
+
+	payload, err := handler.findingService.GetById(repoIdParam)
+	if err != nil {
+		handler.l.Errorln("could not get findings with provided id", err.Error())
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "Could not get findings with provided id",
+			"error":   err.Error(),
+		})
+		return domain.RepoFindings{}, false
+	}
+
+	payload.Findings = payload.Findings.FilterByStatus(statuses...)
+
+	return payload, true
+}
+
+// readRepoId returns the repository id of the request URI, or responds with the problem when it is invalid or the
+// repository can not be read
+func (handler *httpHandler) readRepoId(c *gin.Context) (int, bool) {
+
 	repoIdParamString := c.Param("id")
 
 	repoIdParam, err := strconv.Atoi(repoIdParamString)
@@ -40,7 +93,7 @@ func (handler *httpHandler) Get(c *gin.Context) {
 			"message": "Could not convert parameter from request URI to int",
 			"error":   err.Error(),
 		})
-		return
+		return 0, false
 	}
 
 	err = handler.validate.Var(repoIdParam, "required,number,min=0")
@@ -50,30 +103,59 @@ func (handler *httpHandler) Get(c *gin.Context) {
 			"message": "Could not process id parameter in request URI",
 			"error":   err.Error(),
 		})
-		return
+		return 0, false
 	}
 
-	payload, err := handler.findingService.GetById(repoIdParam)
+	principal, _ := authHdl.PrincipalFrom(c)
+	if !principal.CanRead(repoIdParam) {
+		handler.l.Warnln("reading repository not allowed for principal", principal.Name, repoIdParam)
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "Not allowed to read findings of this repository",
+		})
+		return 0, false
+	}
+
+	return repoIdParam, true
+}
+
+// UpdateStatus changes status of a single finding identified by repository id and fingerprint.
+// Fingerprints contain slashes of file paths, so clients must URL encode them.
+func (handler *httpHandler) UpdateStatus(c *gin.Context) {
+
+	repoId, err := strconv.Atoi(c.Param("repoId"))
 	if err != nil {
-		handler.l.Errorln("could not get findings with provided id", err.Error())
-		c.JSON(http.StatusNotFound, gin.H{
-			"message": "Could not get findings with provided id",
+		handler.l.Errorln("could not convert repoId to int", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Could not convert parameter from request URI to int",
 			"error":   err.Error(),
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, payload)
-}
+	principal, _ := authHdl.PrincipalFrom(c)
+	if !principal.CanTriage(repoId) {
+		handler.l.Warnln("triage of repository not allowed for principal", principal.Name, repoId)
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "Not allowed to change status of findings of this repository",
+		})
+		return
+	}
 
-// Create method
-// TODO: find ways to remove type conversions to somewhere else
-func (handler *httpHandler) Create(c *gin.Context) {
+	fingerprint := c.Param("fingerprint")
 
-	payload := &domain.Findings{}
+	err = handler.validate.Var(fingerprint, "required,ascii,max=1000")
+	if err != nil {
+		handler.l.Errorln("validation failed for fingerprint parameter", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Could not process fingerprint parameter in request URI",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	change := domain.StatusChange{}
 
-	// decode request body
-	err := c.ShouldBindJSON(payload)
+	err = c.ShouldBindJSON(&change)
 	if err != nil {
 		handler.l.Errorln("could not decode request body.", err)
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -82,57 +164,113 @@ func (handler *httpHandler) Create(c *gin.Context) {
 		return
 	}
 
-	// checking if the payload is empty slice of findingsReport
-	if len(*payload) == 0 {
-		handler.l.Errorln("empty request body.", err)
+	// author and time of change are always set by the server
+	change.ChangedBy = principal.Actor()
+	change.ChangedAt = time.Time{}
+
+	err = handler.validate.Struct(change)
+	if err != nil {
+		handler.l.Errorln("status change validation failed.", err)
 		c.JSON(http.StatusBadRequest, gin.H{
-			"message": "Empty findingsReport set",
+			"message": "Validation failed.",
+			"error":   err.Error(),
 		})
 		return
 	}
 
-	// type conversion of parameters from string to int
-	repoId, err := strconv.Atoi(c.Query("repoId"))
+	err = handler.findingService.UpdateStatus(repoId, fingerprint, change)
 	if err != nil {
 		handler.l.Errorln(err)
+		switch err {
+		case errors.ErrFindingNotFound:
+			c.JSON(http.StatusNotFound, gin.H{
+				"message": "Finding not found",
+				"error":   err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"message": "Could not update finding status, something went wrong",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Updated",
+	})
+}
+
+// Create method
+// Body is a gitleaks JSON report, a SARIF 2.1.0 log, TruffleHog JSON lines or a detect-secrets baseline, see
+// detectFormat.
+// Report metadata is resolved from headers, query parameters or a JSON envelope body, see metadataResolver.
+func (handler *httpHandler) Create(c *gin.Context) {
+
+	body, err := c.GetRawData()
+	if err != nil {
+		handler.l.Errorln("could not read request body.", err)
 		c.JSON(http.StatusBadRequest, gin.H{
-			"message": "Error occurred when converting repoId parameter to int",
+			"message": "Cannot extract payload from request",
 		})
 		return
 	}
 
-	pipelineId, err := strconv.Atoi(c.Query("pipelineId"))
+	// decode request body in its format, see detectFormat
+	decoded, err := decodeUpload(c, body)
 	if err != nil {
-		handler.l.Errorln(err)
+		handler.l.Errorln("could not decode request body.", err)
 		c.JSON(http.StatusBadRequest, gin.H{
-			"message": "Error occurred when converting pipelineId parameter to int",
+			"message": "Cannot extract payload from request",
+			"error":   err.Error(),
 		})
 		return
 	}
 
-	// convert int to int64, because further operations (e.g. converting to Unix time) will require int64
-	timestamp, err := strconv.ParseInt(c.Query("timestamp"), 10, 64)
+	// checking if the payload carries no findings, clean scans upload an empty slice
+	if decoded.empty() {
+		handler.l.Errorln("empty request body.")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Empty findingsReport set",
+		})
+		return
+	}
+
+	findingsReport, notify, err := newMetadataResolver(c, decoded.metadata).Resolve(decoded.findings)
 	if err != nil {
-		handler.l.Errorln(err)
+		handler.l.Errorln("could not resolve report metadata.", err)
 		c.JSON(http.StatusBadRequest, gin.H{
-			"message": "Error occurred when converting timestamp parameter to int64",
+			"message": "Invalid report metadata",
+			"errors":  err,
 		})
 		return
 	}
 
-	// we need to create struct matching domain.FindingsReport, where both body and data from query parameters should be set
-	findingsReport := domain.FindingsReport{
-		PipelineID:   pipelineId,
-		RepoName:     c.Query("repoName"),
-		RepoID:       repoId,
-		RepoURL:      c.Query("repoURL"),
-		CommitAuthor: c.Query("commitAuthor"),
-		CommitSHA:    c.Query("commitSHA"),
-		Timestamp:    time.Unix(timestamp, 0),
-		Findings:     *payload,
+	// upload tokens are scoped to a single repository, which is known only after metadata is resolved
+	principal, _ := authHdl.PrincipalFrom(c)
+	if !principal.CanUpload(findingsReport.RepoID) {
+		handler.l.Warnln("upload to repository not allowed for token", principal.KeyID, findingsReport.RepoID)
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "Token is not allowed to upload findings of this repository",
+		})
+		return
 	}
 
-	err = handler.validate.Struct(findingsReport)
+	// scanners without git history leave commit details of findings to the report
+	if decoded.inheritCommit {
+		findingsReport.InheritCommit()
+	}
+
+	err = decoded.validateResults(handler.validate, findingsReport.Findings)
+	if err != nil {
+		handler.l.Errorln("Findings validation failed.", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid results in scanner report",
+			"errors":  err,
+		})
+		return
+	}
+
+	err = decoded.validateReport(handler.validate, findingsReport)
 	if err != nil {
 		// if there is a problem with validation itself, not user input validation errors
 		if _, ok := err.(*validator.InvalidValidationError); ok {
@@ -147,7 +285,9 @@ func (handler *httpHandler) Create(c *gin.Context) {
 		return
 	}
 
-	err = handler.findingService.Add(findingsReport)
+	// channels are enabled by configuration, uploader can opt out with notify=false resolved like other metadata.
+	// Notification is queued with the findings and delivered in background, so slow channels do not fail the upload.
+	result, err := handler.findingService.Add(findingsReport, notify)
 	if err != nil {
 		handler.l.Errorln(err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -156,17 +296,14 @@ func (handler *httpHandler) Create(c *gin.Context) {
 		return
 	}
 
-	if handler.cfg.SlackNotificationEnabled {
-		err = handler.findingService.Notify(findingsReport)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"message": "Something went wrong when notifying",
-			})
-			return
-		}
-	}
-
+	c.Header(headerVerdict, string(result.Verdict))
 	c.JSON(http.StatusCreated, gin.H{
-		"message": "Created",
+		"message":         "Created",
+		"reportId":        result.ReportID,
+		"new":             len(result.New),
+		"known":           len(result.Known),
+		"suppressed":      len(result.Suppressed),
+		"newFingerprints": result.NewFingerprints(),
+		"verdict":         result.Verdict,
 	})
 }
@@ -0,0 +1,246 @@
+package findingHdl
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"mime"
+	"secrets-operator/internal/core/domain"
+	"sort"
+	"strings"
+)
+
+// uploadFormat is the format of the upload body, chosen by the "format" query parameter, Content-Type header or,
+// when neither tells, by the body itself
+type uploadFormat string
+
+const (
+	// formatGitleaks is the gitleaks JSON findings array or the envelope carrying it, see decodeUploadBody
+	formatGitleaks uploadFormat = "gitleaks"
+	// formatSARIF is a SARIF 2.1.0 log, e.g. gitleaks --report-format sarif
+	formatSARIF uploadFormat = "sarif"
+	// formatTruffleHog is TruffleHog v3 --json output, a JSON object per line
+	formatTruffleHog uploadFormat = "trufflehog"
+	// formatDetectSecrets is a Yelp detect-secrets baseline, e.g. .secrets.baseline
+	formatDetectSecrets uploadFormat = "detect-secrets"
+)
+
+var uploadFormats = []uploadFormat{formatGitleaks, formatSARIF, formatTruffleHog, formatDetectSecrets}
+
+const (
+	keyFormat = "format"
+	// contentTypeSARIF is the media type registered for SARIF logs
+	contentTypeSARIF = "application/sarif+json"
+)
+
+// upload is the decoded upload body
+type upload struct {
+	findings domain.Findings
+	// results locate findings in reports of scanners other than gitleaks, e.g. "runs[0].results[3]", so problems of
+	// findings are reported by them
+	results []string
+	// errs are problems of results which could not be mapped to findings
+	errs resultErrors
+	// metadata is only carried by gitleaks envelopes
+	metadata map[string]string
+	// optional are fields of findings the scanner may not report, they are only validated when set, see optionalFields
+	optional []string
+	// optionalByResult are fields optional for single results only, e.g. git details of SARIF results of scanners
+	// which did not scan git history
+	optionalByResult map[string][]string
+	// inheritCommit tells that the scanner may not know commits of findings, they get the one of the report, see
+	// domain.FindingsReport.InheritCommit
+	inheritCommit bool
+}
+
+func newUpload() upload {
+	return upload{findings: domain.Findings{}, results: []string{}, errs: resultErrors{}, optionalByResult: map[string][]string{}}
+}
+
+// add appends the finding mapped from the result or the problem of mapping it
+func (u *upload) add(result string, finding domain.Finding, err error) {
+
+	if err != nil {
+		u.errs[result] = err.Error()
+		return
+	}
+
+	u.findings = append(u.findings, finding)
+	u.results = append(u.results, result)
+}
+
+// empty tells whether the body carried no findings at all. An empty list is the report of a clean scan, e.g. "[]" of
+// gitleaks finding nothing, and is a valid upload.
+func (u upload) empty() bool {
+	return u.findings == nil && len(u.errs) == 0
+}
+
+// resultErrors maps results of a scanner report, e.g. "runs[0].results[3]", to their problems
+type resultErrors map[string]string
+
+func (re resultErrors) Error() string {
+
+	results := make([]string, 0, len(re))
+	for result := range re {
+		results = append(results, result)
+	}
+	sort.Strings(results)
+
+	messages := make([]string, 0, len(results))
+	for _, result := range results {
+		messages = append(messages, fmt.Sprintf("%s: %s", result, re[result]))
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// detectFormat picks the format of the upload body, the first of "format" query parameter, Content-Type header and
+// shape of the body that tells wins
+func detectFormat(c *gin.Context, body []byte) (uploadFormat, error) {
+
+	if value := strings.ToLower(strings.TrimSpace(c.Query(keyFormat))); value != "" {
+		for _, format := range uploadFormats {
+			if uploadFormat(value) == format {
+				return format, nil
+			}
+		}
+		return "", fmt.Errorf("unknown format %q, use one of %v", value, uploadFormats)
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(c.GetHeader("Content-Type")); err == nil && mediaType == contentTypeSARIF {
+		return formatSARIF, nil
+	}
+
+	// only the first value is looked at, TruffleHog writes one object per line
+	var shape struct {
+		Runs           json.RawMessage `json:"runs"`
+		Results        json.RawMessage `json:"results"`
+		PluginsUsed    json.RawMessage `json:"plugins_used"`
+		SourceMetadata json.RawMessage `json:"SourceMetadata"`
+		DetectorName   json.RawMessage `json:"DetectorName"`
+	}
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 || body[0] != '{' || json.NewDecoder(bytes.NewReader(body)).Decode(&shape) != nil {
+		return formatGitleaks, nil
+	}
+
+	switch {
+	case shape.Runs != nil:
+		return formatSARIF, nil
+	case shape.SourceMetadata != nil || shape.DetectorName != nil:
+		return formatTruffleHog, nil
+	case shape.PluginsUsed != nil || bytes.HasPrefix(shape.Results, []byte("{")):
+		return formatDetectSecrets, nil
+	}
+
+	return formatGitleaks, nil
+}
+
+// decodeUpload decodes findings of the upload body in its format
+func decodeUpload(c *gin.Context, body []byte) (upload, error) {
+
+	format, err := detectFormat(c, body)
+	if err != nil {
+		return upload{}, err
+	}
+
+	switch format {
+	case formatSARIF:
+		return decodeSARIF(body)
+	case formatTruffleHog:
+		return decodeTruffleHog(body)
+	case formatDetectSecrets:
+		return decodeDetectSecrets(body)
+	default:
+		findings, metadata, err := decodeUploadBody(body)
+		return upload{findings: findings, metadata: metadata}, err
+	}
+}
+
+// validateResults validates findings decoded from scanner reports one by one, problems are reported by result together
+// with those of mapping results. Gitleaks findings have no results, they are validated with the report.
+func (u upload) validateResults(validate *validator.Validate, findings domain.Findings) error {
+
+	if u.results == nil {
+		return nil
+	}
+
+	errs := resultErrors{}
+	for result, problem := range u.errs {
+		errs[result] = problem
+	}
+	for i, finding := range findings {
+		optional := append(append([]string{}, u.optional...), u.optionalByResult[u.results[i]]...)
+		if err := validateFinding(validate, finding, optional...); err != nil {
+			errs[u.results[i]] = err.Error()
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// validateReport validates the report, findings of scanner reports are left to validateResults
+func (u upload) validateReport(validate *validator.Validate, report domain.FindingsReport) error {
+
+	if u.results == nil {
+		return validate.Struct(report)
+	}
+
+	return validate.StructExcept(report, "Findings")
+}
+
+// optionalFields constrain fields of domain.Finding which gitleaks always reports and other scanners may not, they
+// replace constraints of the finding for fields listed in upload.optional
+type optionalFields struct {
+	Entropy float64 `validate:"omitempty,number,min=0,max=20"`
+	Email   string  `validate:"omitempty,ascii,email"`
+	Message string  `validate:"omitempty,ascii,max=1000"`
+}
+
+// validateFinding reports failed constraints of the finding by field, optional fields are only validated when set
+func validateFinding(validate *validator.Validate, finding domain.Finding, optional ...string) error {
+
+	var validationErrors validator.ValidationErrors
+
+	for _, err := range []error{
+		validate.StructExcept(finding, optional...),
+		validate.StructPartial(optionalFields{Entropy: finding.Entropy, Email: finding.Email, Message: finding.Message}, optional...),
+	} {
+		var fieldErrors validator.ValidationErrors
+		if errors.As(err, &fieldErrors) {
+			validationErrors = append(validationErrors, fieldErrors...)
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if len(validationErrors) == 0 {
+		return nil
+	}
+
+	messages := make([]string, 0, len(validationErrors))
+	for _, fieldError := range validationErrors {
+		messages = append(messages, fmt.Sprintf("%s failed on %s", fieldError.Field(), fieldError.Tag()))
+	}
+
+	return errors.New(strings.Join(messages, ", "))
+}
+
+// scannerFingerprint identifies findings of scanners without own fingerprints the way gitleaks does,
+// "<commit>:<file>:<rule>:<line>", findings without a commit leave it out, so filesystem scans repeat fingerprints
+func scannerFingerprint(commit string, file string, ruleId string, line int) string {
+
+	fingerprint := fmt.Sprintf("%s:%s:%d", file, ruleId, line)
+	if commit == "" {
+		return fingerprint
+	}
+
+	return commit + ":" + fingerprint
+}
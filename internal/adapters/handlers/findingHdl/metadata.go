@@ -0,0 +1,197 @@
+package findingHdl
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"secrets-operator/internal/core/domain"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// metadata keys, as sent by config/pipelineScript.sh
+const (
+	keyPipelineID   = "pipelineId"
+	keyRepoName     = "repoName"
+	keyRepoID       = "repoId"
+	keyRepoURL      = "repoURL"
+	keyCommitAuthor = "commitAuthor"
+	keyCommitSHA    = "commitSHA"
+	keyTimestamp    = "timestamp"
+	keyNotify       = "notify"
+	// keyConfigVersion is the optional version of the gitleaks config, the first line of config.toml tells it
+	keyConfigVersion = "configVersion"
+)
+
+var errUnsupportedBody = errors.New("request body must be a findings array or an object with metadata and findings")
+
+// metadataErrors maps metadata field names to human-readable error messages
+type metadataErrors map[string]string
+
+func (me metadataErrors) Error() string {
+
+	fields := make([]string, 0, len(me))
+	for field := range me {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	messages := make([]string, 0, len(fields))
+	for _, field := range fields {
+		messages = append(messages, fmt.Sprintf("%s: %s", field, me[field]))
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// uploadEnvelope is the optional object form of the upload body:
+// {"metadata": {...}, "findings": [...]}
+type uploadEnvelope struct {
+	Metadata map[string]interface{} `json:"metadata"`
+	Findings domain.Findings        `json:"findings"`
+}
+
+// metadataResolver extracts report metadata from an upload request.
+// Every field is looked up in the following order, the first non-empty value wins:
+//  1. HTTP header (as sent by config/pipelineScript.sh)
+//  2. query parameter
+//  3. "metadata" object of a JSON envelope body
+type metadataResolver struct {
+	c        *gin.Context
+	envelope map[string]string
+}
+
+// decodeUploadBody accepts either a plain gitleaks findings array or a JSON envelope.
+// Envelope metadata is returned flattened to strings, so it can be resolved the same way as headers and query parameters.
+func decodeUploadBody(body []byte) (domain.Findings, map[string]string, error) {
+
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil, nil, errUnsupportedBody
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	switch body[0] {
+	case '[':
+		findings := domain.Findings{}
+		if err := decoder.Decode(&findings); err != nil {
+			return nil, nil, err
+		}
+		return findings, nil, nil
+	case '{':
+		envelope := uploadEnvelope{}
+		if err := decoder.Decode(&envelope); err != nil {
+			return nil, nil, err
+		}
+
+		metadata := make(map[string]string, len(envelope.Metadata))
+		for k, v := range envelope.Metadata {
+			if v == nil {
+				continue
+			}
+			metadata[k] = fmt.Sprint(v)
+		}
+		return envelope.Findings, metadata, nil
+	default:
+		return nil, nil, errUnsupportedBody
+	}
+}
+
+func newMetadataResolver(c *gin.Context, envelope map[string]string) *metadataResolver {
+
+	return &metadataResolver{
+		c:        c,
+		envelope: envelope,
+	}
+}
+
+func (mr *metadataResolver) lookup(key string) string {
+
+	if value := strings.TrimSpace(mr.c.GetHeader(key)); value != "" {
+		return value
+	}
+
+	if value := strings.TrimSpace(mr.c.Query(key)); value != "" {
+		return value
+	}
+
+	return strings.TrimSpace(mr.envelope[key])
+}
+
+// Resolve builds a domain.FindingsReport from request metadata and given findings.
+// notify reports whether the uploader asked for notifications; it is true unless disabled. Like every field it is
+// taken from the first source that sets it, so an explicit notify=true header wins over notify=false in the envelope.
+// All field problems are collected and returned together as metadataErrors.
+func (mr *metadataResolver) Resolve(findings domain.Findings) (report domain.FindingsReport, notify bool, err error) {
+
+	errs := metadataErrors{}
+
+	requireString := func(key string) string {
+		value := mr.lookup(key)
+		if value == "" {
+			errs[key] = "is required, provide it as header, query parameter or envelope metadata"
+		}
+		return value
+	}
+
+	requireInt := func(key string) int {
+		value := requireString(key)
+		if value == "" {
+			return 0
+		}
+		number, convErr := strconv.Atoi(value)
+		if convErr != nil {
+			errs[key] = fmt.Sprintf("must be an integer, got %q", value)
+		}
+		return number
+	}
+
+	report.PipelineID = requireInt(keyPipelineID)
+	report.RepoID = requireInt(keyRepoID)
+	report.RepoName = requireString(keyRepoName)
+	report.RepoURL = requireString(keyRepoURL)
+	report.CommitAuthor = requireString(keyCommitAuthor)
+	report.CommitSHA = requireString(keyCommitSHA)
+
+	// timestamp is unix time in seconds, RFC3339 is accepted as well for envelope bodies
+	if value := requireString(keyTimestamp); value != "" {
+		if seconds, convErr := strconv.ParseInt(value, 10, 64); convErr == nil {
+			report.Timestamp = time.Unix(seconds, 0)
+		} else if parsed, parseErr := time.Parse(time.RFC3339, value); parseErr == nil {
+			report.Timestamp = parsed
+		} else {
+			errs[keyTimestamp] = fmt.Sprintf("must be unix time in seconds or RFC3339, got %q", value)
+		}
+	}
+
+	if value := mr.lookup(keyConfigVersion); value != "" {
+		version, convErr := strconv.Atoi(value)
+		if convErr != nil || version < 0 {
+			errs[keyConfigVersion] = fmt.Sprintf("must be a non-negative integer, got %q", value)
+		}
+		report.ConfigVersion = version
+	}
+
+	notify = true
+	if value := mr.lookup(keyNotify); value != "" {
+		parsed, convErr := strconv.ParseBool(value)
+		if convErr != nil {
+			errs[keyNotify] = fmt.Sprintf("must be a boolean, got %q", value)
+		}
+		notify = parsed
+	}
+
+	report.Findings = findings
+
+	if len(errs) > 0 {
+		return domain.FindingsReport{}, false, errs
+	}
+
+	return report, notify, nil
+}
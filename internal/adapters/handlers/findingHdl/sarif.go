@@ -0,0 +1,304 @@
+package findingHdl
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"secrets-operator/internal/core/domain"
+	"strings"
+	"time"
+)
+
+const sarifVersion = "2.1.0"
+
+// sarifGitDetails are fields of findings optional for results without a commit, like those of scanners which do not
+// read git history. Their commit, author and date are inherited from the report.
+var sarifGitDetails = []string{"Email", "Message"}
+
+// sarifLog is the subset of SARIF 2.1.0 describing secrets found by a scanner
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema,omitempty"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Version        string      `json:"version,omitempty"`
+	Rules          []sarifRule `json:"rules,omitempty"`
+}
+
+type sarifRule struct {
+	ID               string          `json:"id"`
+	Name             string          `json:"name,omitempty"`
+	ShortDescription *sarifMessage   `json:"shortDescription,omitempty"`
+	FullDescription  *sarifMessage   `json:"fullDescription,omitempty"`
+	Properties       sarifProperties `json:"properties,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string             `json:"ruleId"`
+	RuleIndex           *int               `json:"ruleIndex,omitempty"`
+	Level               string             `json:"level,omitempty"`
+	Message             sarifMessage       `json:"message"`
+	Locations           []sarifLocation    `json:"locations"`
+	PartialFingerprints map[string]string  `json:"partialFingerprints,omitempty"`
+	Suppressions        []sarifSuppression `json:"suppressions,omitempty"`
+	Properties          sarifProperties    `json:"properties,omitempty"`
+}
+
+// sarifSuppression tells code scanning views the result was triaged outside of the source code
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Status        string `json:"status,omitempty"`
+	Justification string `json:"justification,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+	ContextRegion    *sarifRegion          `json:"contextRegion,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int           `json:"startLine,omitempty"`
+	StartColumn int           `json:"startColumn,omitempty"`
+	EndLine     int           `json:"endLine,omitempty"`
+	EndColumn   int           `json:"endColumn,omitempty"`
+	Snippet     *sarifSnippet `json:"snippet,omitempty"`
+}
+
+type sarifSnippet struct {
+	Text string `json:"text"`
+}
+
+// sarifProperties is a SARIF property bag, scanners put values SARIF has no field for there
+type sarifProperties map[string]interface{}
+
+// text returns the first non-empty string property of the keys
+func (sp sarifProperties) text(keys ...string) string {
+
+	for _, key := range keys {
+		if value, ok := sp[key].(string); ok && strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value)
+		}
+	}
+
+	return ""
+}
+
+func (sp sarifProperties) number(key string) float64 {
+
+	switch value := sp[key].(type) {
+	case json.Number:
+		number, _ := value.Float64()
+		return number
+	case float64:
+		return value
+	}
+
+	return 0
+}
+
+func (sp sarifProperties) strings(key string) []string {
+
+	values, ok := sp[key].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var result []string
+	for _, value := range values {
+		if text, ok := value.(string); ok {
+			result = append(result, text)
+		}
+	}
+
+	return result
+}
+
+// decodeSARIF maps results of every run to findings. Commit details and fingerprint are read from partialFingerprints
+// as written by gitleaks (commitSha, author, email, date, commitMessage) or from properties of the result, entropy, tags
+// and match from properties only. Results without a commit get the commit of the report, see sarifGitDetails.
+func decodeSARIF(body []byte) (upload, error) {
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	log := sarifLog{}
+	if err := decoder.Decode(&log); err != nil {
+		return upload{}, err
+	}
+
+	if log.Version != sarifVersion {
+		return upload{}, fmt.Errorf("unsupported SARIF version %q, only %s is supported", log.Version, sarifVersion)
+	}
+
+	decoded := newUpload()
+	// gitleaks is the only SARIF producer known to report entropy
+	decoded.optional = []string{"Entropy"}
+	decoded.inheritCommit = true
+
+	for i, run := range log.Runs {
+		for j, result := range run.Results {
+
+			path := fmt.Sprintf("runs[%d].results[%d]", i, j)
+			finding, err := sarifFinding(run, result)
+			decoded.add(path, finding, err)
+
+			// results with a commit come from git history, their details are required like those of gitleaks reports
+			if err == nil && finding.Commit == "" {
+				decoded.optionalByResult[path] = sarifGitDetails
+			}
+		}
+	}
+
+	return decoded, nil
+}
+
+func sarifFinding(run sarifRun, result sarifResult) (domain.Finding, error) {
+
+	rule := sarifRule{}
+	if result.RuleIndex != nil && *result.RuleIndex >= 0 && *result.RuleIndex < len(run.Tool.Driver.Rules) {
+		rule = run.Tool.Driver.Rules[*result.RuleIndex]
+	}
+	for _, candidate := range run.Tool.Driver.Rules {
+		if result.RuleID != "" && candidate.ID == result.RuleID {
+			rule = candidate
+			break
+		}
+	}
+
+	ruleId := result.RuleID
+	if ruleId == "" {
+		ruleId = rule.ID
+	}
+	if ruleId == "" {
+		return domain.Finding{}, errors.New("ruleId is required")
+	}
+
+	if len(result.Locations) == 0 {
+		return domain.Finding{}, errors.New("a physical location is required")
+	}
+	location := result.Locations[0].PhysicalLocation
+	region := location.Region
+
+	file := strings.TrimPrefix(location.ArtifactLocation.URI, "file://")
+	if file == "" {
+		return domain.Finding{}, errors.New("artifactLocation.uri is required")
+	}
+
+	secret := ""
+	if region.Snippet != nil {
+		secret = region.Snippet.Text
+	}
+	match := result.Properties.text("match")
+	if match == "" && location.ContextRegion != nil && location.ContextRegion.Snippet != nil {
+		match = location.ContextRegion.Snippet.Text
+	}
+	if match == "" {
+		match = secret
+	}
+
+	fingerprint := func(key string) string {
+		if value := strings.TrimSpace(result.PartialFingerprints[key]); value != "" {
+			return value
+		}
+		return result.Properties.text(key)
+	}
+
+	finding := domain.Finding{
+		Description: firstNonEmpty(sarifText(rule.ShortDescription), rule.Name, sarifText(rule.FullDescription), result.Message.Text, ruleId),
+		StartLine:   region.StartLine,
+		EndLine:     region.EndLine,
+		StartColumn: region.StartColumn,
+		EndColumn:   region.EndColumn,
+		Match:       match,
+		Secret:      secret,
+		File:        file,
+		Commit:      fingerprint("commitSha"),
+		Entropy:     result.Properties.number("entropy"),
+		Author:      fingerprint("author"),
+		Email:       fingerprint("email"),
+		Message:     fingerprint("commitMessage"),
+		Tags:        result.Properties.strings("tags"),
+		RuleID:      ruleId,
+		Fingerprint: fingerprint("fingerprint"),
+	}
+
+	// regions may only tell where they start
+	if finding.EndLine == 0 {
+		finding.EndLine = finding.StartLine
+	}
+	if finding.StartColumn == 0 {
+		finding.StartColumn = 1
+	}
+	if finding.EndColumn == 0 {
+		finding.EndColumn = finding.StartColumn
+	}
+
+	if finding.Tags == nil {
+		finding.Tags = rule.Properties.strings("tags")
+	}
+	if finding.Tags == nil {
+		finding.Tags = []string{}
+	}
+
+	if date := fingerprint("date"); date != "" {
+		parsed, err := time.Parse(time.RFC3339, date)
+		if err != nil {
+			return domain.Finding{}, fmt.Errorf("date must be RFC3339, got %q", date)
+		}
+		finding.Date = parsed
+	}
+
+	// same fingerprint as gitleaks JSON reports, so findings are deduplicated across formats
+	if finding.Fingerprint == "" {
+		finding.Fingerprint = scannerFingerprint(finding.Commit, finding.File, finding.RuleID, finding.StartLine)
+	}
+
+	return finding, nil
+}
+
+func sarifText(message *sarifMessage) string {
+
+	if message == nil {
+		return ""
+	}
+
+	return message.Text
+}
+
+func firstNonEmpty(values ...string) string {
+
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,165 @@
+package findingHdl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"secrets-operator/internal/core/domain"
+	"strings"
+	"time"
+)
+
+// truffleHogTimestamps are layouts of commit timestamps of TruffleHog git sources, written by time.Time.String. Offsets
+// without a zone name on the machine running TruffleHog are repeated in place of the name.
+var truffleHogTimestamps = []string{
+	"2006-01-02 15:04:05 -0700 MST",
+	"2006-01-02 15:04:05 -0700 -0700",
+	time.RFC3339,
+}
+
+// truffleHogResult is a line of TruffleHog v3 --json output
+type truffleHogResult struct {
+	SourceMetadata struct {
+		// Data holds a single source keyed by its type, e.g. "Git", "Github", "Gitlab" or "Filesystem"
+		Data map[string]truffleHogSource `json:"Data"`
+	} `json:"SourceMetadata"`
+	SourceName   string `json:"SourceName"`
+	DetectorName string `json:"DetectorName"`
+	DecoderName  string `json:"DecoderName"`
+	Verified     bool   `json:"Verified"`
+	Raw          string `json:"Raw"`
+	RawV2        string `json:"RawV2"`
+	Redacted     string `json:"Redacted"`
+}
+
+// truffleHogSource is the subset of fields git and filesystem sources have in common
+type truffleHogSource struct {
+	Commit    string `json:"commit"`
+	File      string `json:"file"`
+	Email     string `json:"email"`
+	Timestamp string `json:"timestamp"`
+	Line      int    `json:"line"`
+}
+
+// decodeTruffleHog maps every line of TruffleHog output to a finding, the detector name is the rule. Commit details
+// are only known for git sources.
+func decodeTruffleHog(body []byte) (upload, error) {
+
+	decoded := newUpload()
+	decoded.optional = []string{"Entropy", "Email", "Message"}
+	decoded.inheritCommit = true
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	// raw secrets like private keys make long lines
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+
+	for number := 1; scanner.Scan(); number++ {
+
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		path := fmt.Sprintf("line %d", number)
+
+		result := truffleHogResult{}
+		if err := json.Unmarshal(line, &result); err != nil {
+			decoded.add(path, domain.Finding{}, err)
+			continue
+		}
+
+		finding, err := truffleHogFinding(result)
+		decoded.add(path, finding, err)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return upload{}, err
+	}
+
+	return decoded, nil
+}
+
+func truffleHogFinding(result truffleHogResult) (domain.Finding, error) {
+
+	if result.DetectorName == "" {
+		return domain.Finding{}, errors.New("DetectorName is required")
+	}
+
+	var source truffleHogSource
+	for _, data := range result.SourceMetadata.Data {
+		source = data
+	}
+	if source.File == "" {
+		return domain.Finding{}, errors.New("SourceMetadata with a file is required")
+	}
+
+	secret := result.Raw
+	if secret == "" {
+		secret = result.Redacted
+	}
+
+	line := source.Line
+	if line == 0 {
+		line = 1
+	}
+
+	tags := []string{"trufflehog"}
+	if result.Verified {
+		tags = append(tags, "verified")
+	}
+
+	finding := domain.Finding{
+		Description: fmt.Sprintf("%s secret found by TruffleHog", result.DetectorName),
+		StartLine:   line,
+		EndLine:     line,
+		StartColumn: 1,
+		EndColumn:   1,
+		Match:       secret,
+		Secret:      secret,
+		File:        source.File,
+		Commit:      source.Commit,
+		Tags:        tags,
+		RuleID:      result.DetectorName,
+		Verified:    result.Verified,
+	}
+
+	// git sources write the author as "Name <email>"
+	if source.Email != "" {
+		address, err := mail.ParseAddress(source.Email)
+		if err != nil {
+			return domain.Finding{}, fmt.Errorf("email must be an address, got %q", source.Email)
+		}
+		finding.Author = address.Name
+		finding.Email = address.Address
+	}
+	if finding.Author == "" {
+		finding.Author = strings.SplitN(finding.Email, "@", 2)[0]
+	}
+
+	if source.Timestamp != "" {
+		date, ok := parseTruffleHogTimestamp(source.Timestamp)
+		if !ok {
+			return domain.Finding{}, fmt.Errorf("timestamp must be like %q, got %q", truffleHogTimestamps[0], source.Timestamp)
+		}
+		finding.Date = date
+	}
+
+	finding.Fingerprint = scannerFingerprint(finding.Commit, finding.File, finding.RuleID, line)
+
+	return finding, nil
+}
+
+// parseTruffleHogTimestamp parses the timestamp by the first of truffleHogTimestamps that fits
+func parseTruffleHogTimestamp(value string) (time.Time, bool) {
+
+	for _, layout := range truffleHogTimestamps {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, true
+		}
+	}
+
+	return time.Time{}, false
+}
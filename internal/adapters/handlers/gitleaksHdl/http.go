@@ -0,0 +1,419 @@
+package gitleaksHdl
+
+import (
+	stdErrors "errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+	"net/http"
+	"secrets-operator/config"
+	"secrets-operator/internal/adapters/handlers/authHdl"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/core/ports"
+	"secrets-operator/internal/errors"
+	"strconv"
+)
+
+// headerConfigVersion tells the version of config.toml responses, its first line tells it as well
+const headerConfigVersion = "X-Gitleaks-Config-Version"
+
+type httpHandler struct {
+	cfg                   *config.Config
+	l                     *zap.SugaredLogger
+	validate              *validator.Validate
+	gitleaksConfigService ports.GitleaksConfigService
+}
+
+func NewGitleaksHandler(cfg *config.Config, l *zap.SugaredLogger, gitleaksConfigService ports.GitleaksConfigService) *httpHandler {
+
+	return &httpHandler{
+		cfg:                   cfg,
+		l:                     l,
+		validate:              validator.New(),
+		gitleaksConfigService: gitleaksConfigService,
+	}
+}
+
+// TOML writes the current config, or the version of ?version=, as gitleaks detect --config reads it
+func (handler *httpHandler) TOML(c *gin.Context) {
+
+	var version domain.GitleaksConfigVersion
+	var err error
+
+	if value := c.Query("version"); value != "" {
+		number, convErr := strconv.Atoi(value)
+		if convErr != nil || number < 0 {
+			handler.l.Errorln("invalid version query parameter", value)
+			c.JSON(http.StatusBadRequest, gin.H{
+				"message": "Could not process version query parameter",
+			})
+			return
+		}
+		version, err = handler.gitleaksConfigService.GetVersion(number)
+	} else {
+		version, err = handler.gitleaksConfigService.Current()
+	}
+	if err != nil {
+		handler.l.Errorln(err)
+		handler.fail(c, err, "Could not get gitleaks config, something went wrong")
+		return
+	}
+
+	c.Header(headerConfigVersion, strconv.Itoa(version.Version))
+	c.Data(http.StatusOK, "application/toml; charset=utf-8", []byte(version.TOML()))
+}
+
+// Get returns the current version
+func (handler *httpHandler) Get(c *gin.Context) {
+
+	version, err := handler.gitleaksConfigService.Current()
+	if err != nil {
+		handler.l.Errorln(err)
+		handler.fail(c, err, "Could not get gitleaks config, something went wrong")
+		return
+	}
+
+	c.JSON(http.StatusOK, version)
+}
+
+// Replace stores the whole config of the request as the next version, e.g. to import a config file
+func (handler *httpHandler) Replace(c *gin.Context) {
+
+	request := domain.GitleaksConfig{}
+	if !handler.bind(c, &request) {
+		return
+	}
+
+	principal, _ := authHdl.PrincipalFrom(c)
+
+	version, err := handler.gitleaksConfigService.Replace(request, principal.Name)
+	if err != nil {
+		handler.l.Errorln(err)
+		handler.fail(c, err, "Could not replace gitleaks config, something went wrong")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Updated",
+		"version": version,
+	})
+}
+
+// ListRules returns rules of the current version
+func (handler *httpHandler) ListRules(c *gin.Context) {
+
+	version, err := handler.gitleaksConfigService.Current()
+	if err != nil {
+		handler.l.Errorln(err)
+		handler.fail(c, err, "Could not get gitleaks rules, something went wrong")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"version": version.Version,
+		"items":   version.Config.Rules,
+	})
+}
+
+func (handler *httpHandler) GetRule(c *gin.Context) {
+
+	id, ok := handler.ruleId(c)
+	if !ok {
+		return
+	}
+
+	version, err := handler.gitleaksConfigService.Current()
+	if err != nil {
+		handler.l.Errorln(err)
+		handler.fail(c, err, "Could not get gitleaks rule, something went wrong")
+		return
+	}
+
+	rule, ok := version.Config.Rule(id)
+	if !ok {
+		handler.fail(c, errors.ErrGitleaksRuleNotFound, "")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"version": version.Version,
+		"rule":    rule,
+	})
+}
+
+func (handler *httpHandler) CreateRule(c *gin.Context) {
+
+	request := domain.GitleaksRule{}
+	if !handler.bind(c, &request) {
+		return
+	}
+
+	principal, _ := authHdl.PrincipalFrom(c)
+
+	version, err := handler.gitleaksConfigService.CreateRule(request, principal.Name)
+	if err != nil {
+		handler.l.Errorln(err)
+		handler.fail(c, err, "Could not create gitleaks rule, something went wrong")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Created",
+		"version": version,
+	})
+}
+
+// UpdateRule replaces the rule, the ID in the request URI wins over the one of the body
+func (handler *httpHandler) UpdateRule(c *gin.Context) {
+
+	id, ok := handler.ruleId(c)
+	if !ok {
+		return
+	}
+
+	request := domain.GitleaksRule{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		handler.l.Errorln("could not bind gitleaks rule request.", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Cannot extract payload from request",
+			"error":   err.Error(),
+		})
+		return
+	}
+	request.ID = id
+	if !handler.valid(c, request) {
+		return
+	}
+
+	principal, _ := authHdl.PrincipalFrom(c)
+
+	version, err := handler.gitleaksConfigService.UpdateRule(id, request, principal.Name)
+	if err != nil {
+		handler.l.Errorln(err)
+		handler.fail(c, err, "Could not update gitleaks rule, something went wrong")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Updated",
+		"version": version,
+	})
+}
+
+func (handler *httpHandler) DeleteRule(c *gin.Context) {
+
+	id, ok := handler.ruleId(c)
+	if !ok {
+		return
+	}
+
+	principal, _ := authHdl.PrincipalFrom(c)
+
+	version, err := handler.gitleaksConfigService.DeleteRule(id, principal.Name)
+	if err != nil {
+		handler.l.Errorln(err)
+		handler.fail(c, err, "Could not delete gitleaks rule, something went wrong")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Deleted",
+		"version": version,
+	})
+}
+
+// UpdateAllowlist replaces the global allowlist, an allowlist without conditions removes it
+func (handler *httpHandler) UpdateAllowlist(c *gin.Context) {
+
+	request := domain.GitleaksAllowlist{}
+	if !handler.bind(c, &request) {
+		return
+	}
+
+	principal, _ := authHdl.PrincipalFrom(c)
+
+	version, err := handler.gitleaksConfigService.UpdateAllowlist(request, principal.Name)
+	if err != nil {
+		handler.l.Errorln(err)
+		handler.fail(c, err, "Could not update gitleaks allowlist, something went wrong")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Updated",
+		"version": version,
+	})
+}
+
+func (handler *httpHandler) ListVersions(c *gin.Context) {
+
+	versions, err := handler.gitleaksConfigService.ListVersions()
+	if err != nil {
+		handler.l.Errorln(err)
+		handler.fail(c, err, "Could not get gitleaks config versions, something went wrong")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": versions,
+	})
+}
+
+func (handler *httpHandler) GetVersion(c *gin.Context) {
+
+	number, ok := handler.versionNumber(c)
+	if !ok {
+		return
+	}
+
+	version, err := handler.gitleaksConfigService.GetVersion(number)
+	if err != nil {
+		handler.l.Errorln(err)
+		handler.fail(c, err, "Could not get gitleaks config version, something went wrong")
+		return
+	}
+
+	c.JSON(http.StatusOK, version)
+}
+
+// Diff compares the version with the version of ?from=, the previous version by default
+func (handler *httpHandler) Diff(c *gin.Context) {
+
+	number, ok := handler.versionNumber(c)
+	if !ok {
+		return
+	}
+
+	from := number - 1
+	if from < 0 {
+		from = 0
+	}
+	if value := c.Query("from"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			handler.l.Errorln("invalid from query parameter", value)
+			c.JSON(http.StatusBadRequest, gin.H{
+				"message": "Could not process from query parameter",
+			})
+			return
+		}
+		from = parsed
+	}
+
+	diff, err := handler.gitleaksConfigService.Diff(from, number)
+	if err != nil {
+		handler.l.Errorln(err)
+		handler.fail(c, err, "Could not compare gitleaks config versions, something went wrong")
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// Rollback stores config of the version as the next version, versions in between are kept
+func (handler *httpHandler) Rollback(c *gin.Context) {
+
+	number, ok := handler.versionNumber(c)
+	if !ok {
+		return
+	}
+
+	principal, _ := authHdl.PrincipalFrom(c)
+
+	version, err := handler.gitleaksConfigService.Rollback(number, principal.Name)
+	if err != nil {
+		handler.l.Errorln(err)
+		handler.fail(c, err, "Could not roll back gitleaks config, something went wrong")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Rolled back",
+		"version": version,
+	})
+}
+
+func (handler *httpHandler) fail(c *gin.Context, err error, message string) {
+
+	switch {
+	case err == errors.ErrGitleaksRuleNotFound, err == errors.ErrGitleaksConfigVersionNotFound:
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "Not found",
+			"error":   err.Error(),
+		})
+	case err == errors.ErrGitleaksRuleExists, err == errors.ErrConcurrentGitleaksConfigUpdate:
+		c.JSON(http.StatusConflict, gin.H{
+			"message": "Conflicting change of gitleaks config",
+			"error":   err.Error(),
+		})
+	case stdErrors.Is(err, errors.ErrInvalidGitleaksConfig):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid gitleaks config",
+			"error":   err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": message,
+		})
+	}
+}
+
+// bind binds and validates the request body
+func (handler *httpHandler) bind(c *gin.Context, request interface{}) bool {
+
+	if err := c.ShouldBindJSON(request); err != nil {
+		handler.l.Errorln("could not bind gitleaks config request.", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Cannot extract payload from request",
+			"error":   err.Error(),
+		})
+		return false
+	}
+
+	return handler.valid(c, request)
+}
+
+func (handler *httpHandler) valid(c *gin.Context, request interface{}) bool {
+
+	if err := handler.validate.Struct(request); err != nil {
+		handler.l.Errorln("gitleaks config validation failed.", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Validation failed.",
+			"error":   err.Error(),
+		})
+		return false
+	}
+
+	return true
+}
+
+func (handler *httpHandler) ruleId(c *gin.Context) (string, bool) {
+
+	id := c.Param("id")
+
+	err := handler.validate.Var(id, "required,max=200")
+	if err != nil {
+		handler.l.Errorln("validation failed for id parameter", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Could not process id parameter in request URI",
+			"error":   err.Error(),
+		})
+		return "", false
+	}
+
+	return id, true
+}
+
+func (handler *httpHandler) versionNumber(c *gin.Context) (int, bool) {
+
+	number, err := strconv.Atoi(c.Param("version"))
+	if err != nil || number < 0 {
+		handler.l.Errorln("invalid version parameter", c.Param("version"))
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Could not process version parameter in request URI",
+		})
+		return 0, false
+	}
+
+	return number, true
+}
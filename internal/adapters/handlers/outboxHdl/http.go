@@ -0,0 +1,101 @@
+package outboxHdl
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+	"net/http"
+	"secrets-operator/config"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/core/ports"
+	"secrets-operator/internal/errors"
+)
+
+type httpHandler struct {
+	cfg           *config.Config
+	l             *zap.SugaredLogger
+	validate      *validator.Validate
+	outboxService ports.OutboxService
+}
+
+func NewOutboxHandler(cfg *config.Config, l *zap.SugaredLogger, outboxService ports.OutboxService) *httpHandler {
+
+	return &httpHandler{
+		cfg:           cfg,
+		l:             l,
+		validate:      validator.New(),
+		outboxService: outboxService,
+	}
+}
+
+// List returns queued notifications in the status, dead ones unless ?status=pending or ?status=delivered is given
+func (handler *httpHandler) List(c *gin.Context) {
+
+	status := c.DefaultQuery("status", string(domain.OutboxDead))
+
+	err := handler.validate.Var(status, "oneof=pending delivered dead")
+	if err != nil {
+		handler.l.Errorln("validation failed for status parameter", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Could not process status query parameter",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	messages, err := handler.outboxService.List(domain.OutboxStatus(status))
+	if err != nil {
+		handler.l.Errorln(err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not get outbox messages, something went wrong",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": messages,
+	})
+}
+
+// Replay queues dead notification again, channels which already received it are not notified twice
+func (handler *httpHandler) Replay(c *gin.Context) {
+
+	id := c.Param("id")
+
+	err := handler.validate.Var(id, "required,hexadecimal,len=24")
+	if err != nil {
+		handler.l.Errorln("validation failed for id parameter", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Could not process id parameter in request URI",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	message, err := handler.outboxService.Replay(id)
+	if err != nil {
+		handler.l.Errorln(err)
+		switch err {
+		case errors.ErrOutboxMessageNotFound:
+			c.JSON(http.StatusNotFound, gin.H{
+				"message": "Outbox message not found",
+				"error":   err.Error(),
+			})
+		case errors.ErrOutboxMessageNotDead:
+			c.JSON(http.StatusConflict, gin.H{
+				"message": "Outbox message is not dead",
+				"error":   err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"message": "Could not replay outbox message, something went wrong",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Replayed",
+		"item":    message,
+	})
+}
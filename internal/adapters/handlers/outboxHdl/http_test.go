@@ -0,0 +1,144 @@
+package outboxHdl
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"net/http/httptest"
+	"secrets-operator/config"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/core/ports/mocks"
+	"secrets-operator/internal/errors"
+	"testing"
+)
+
+type OutboxHandlerTestSuite struct {
+	suite.Suite
+	sugaredLogger *zap.SugaredLogger
+	cfg           *config.Config
+	ctrl          *gomock.Controller
+}
+
+func TestSuiteOutboxHandler(t *testing.T) {
+	suite.Run(t, new(OutboxHandlerTestSuite))
+}
+
+func (s *OutboxHandlerTestSuite) SetupTest() {
+
+	var err error
+
+	s.sugaredLogger = zap.NewNop().Sugar()
+
+	// setup configs
+	s.cfg, err = config.LoadConfig("test")
+	if err != nil {
+		s.T().Fatalf("cannot load configuration variables. %v", err.Error())
+	}
+
+	// setup gomock controller
+	s.ctrl = gomock.NewController(s.T())
+	defer s.ctrl.Finish()
+}
+
+func (s *OutboxHandlerTestSuite) TestHttpHandler_ListTableDriven() {
+
+	tests := []struct {
+		name            string
+		target          string
+		wantStatus      domain.OutboxStatus
+		listReturnErr   error
+		wantListInvoked bool
+		wantStatusCode  int
+	}{
+		{"dead messages by default", "/api/v1/outbox", domain.OutboxDead, nil, true, 200},
+		{"pending messages", "/api/v1/outbox?status=pending", domain.OutboxPending, nil, true, 200},
+		{"unknown status", "/api/v1/outbox?status=lost", "", nil, false, 400},
+		{"service error", "/api/v1/outbox", domain.OutboxDead, errors.ErrCouldNotGetOutboxMessages, true, 500},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// arrange
+			mockOutboxService := mocks.NewMockOutboxService(s.ctrl)
+
+			invoked := false
+			mockOutboxService.
+				EXPECT().
+				List(tt.wantStatus).
+				DoAndReturn(func(status domain.OutboxStatus) ([]domain.OutboxMessage, error) {
+					invoked = true
+					return []domain.OutboxMessage{{ID: "m1", Status: status}}, tt.listReturnErr
+				}).
+				AnyTimes()
+
+			sut := NewOutboxHandler(s.cfg, s.sugaredLogger, mockOutboxService)
+
+			router := gin.New()
+			router.GET("/api/v1/outbox", sut.List)
+
+			// act
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest("GET", tt.target, nil))
+
+			// assert
+			assert.Equal(s.T(), tt.wantStatusCode, recorder.Code)
+			assert.Equal(s.T(), tt.wantListInvoked, invoked)
+			if tt.wantStatusCode == 200 {
+				assert.Contains(s.T(), recorder.Body.String(), `"id":"m1"`)
+			}
+		})
+	}
+}
+
+func (s *OutboxHandlerTestSuite) TestHttpHandler_ReplayTableDriven() {
+
+	tests := []struct {
+		name              string
+		inputId           string
+		replayReturnErr   error
+		wantReplayInvoked bool
+		wantStatusCode    int
+	}{
+		{"replayed", "0123456789abcdef01234567", nil, true, 200},
+		{"malformed id", "m1", nil, false, 400},
+		{"unknown message", "0123456789abcdef01234567", errors.ErrOutboxMessageNotFound, true, 404},
+		{"message is not dead", "0123456789abcdef01234567", errors.ErrOutboxMessageNotDead, true, 409},
+		{"service error", "0123456789abcdef01234567", errors.ErrCouldNotReplayOutboxMessage, true, 500},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// arrange
+			mockOutboxService := mocks.NewMockOutboxService(s.ctrl)
+
+			invoked := false
+			mockOutboxService.
+				EXPECT().
+				Replay(tt.inputId).
+				DoAndReturn(func(id string) (domain.OutboxMessage, error) {
+					invoked = true
+					return domain.OutboxMessage{ID: id, Status: domain.OutboxPending}, tt.replayReturnErr
+				}).
+				AnyTimes()
+
+			sut := NewOutboxHandler(s.cfg, s.sugaredLogger, mockOutboxService)
+
+			router := gin.New()
+			router.POST("/api/v1/outbox/:id/replay", sut.Replay)
+
+			// act
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest("POST", "/api/v1/outbox/"+tt.inputId+"/replay", nil))
+
+			// assert
+			assert.Equal(s.T(), tt.wantStatusCode, recorder.Code)
+			assert.Equal(s.T(), tt.wantReplayInvoked, invoked)
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package routingHdl
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+	"net/http"
+	"secrets-operator/config"
+	"secrets-operator/internal/adapters/handlers/authHdl"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/core/ports"
+	"secrets-operator/internal/errors"
+)
+
+type httpHandler struct {
+	cfg            *config.Config
+	l              *zap.SugaredLogger
+	validate       *validator.Validate
+	routingService ports.RoutingService
+}
+
+func NewRoutingHandler(cfg *config.Config, l *zap.SugaredLogger, routingService ports.RoutingService) *httpHandler {
+
+	return &httpHandler{
+		cfg:            cfg,
+		l:              l,
+		validate:       validator.New(),
+		routingService: routingService,
+	}
+}
+
+func (handler *httpHandler) Create(c *gin.Context) {
+
+	request, ok := handler.rule(c)
+	if !ok {
+		return
+	}
+
+	principal, _ := authHdl.PrincipalFrom(c)
+
+	rule, err := handler.routingService.CreateRule(request, principal.Name)
+	if err != nil {
+		handler.l.Errorln(err)
+		handler.fail(c, err, "Could not create routing rule, something went wrong")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Created",
+		"rule":    rule,
+	})
+}
+
+func (handler *httpHandler) List(c *gin.Context) {
+
+	rules, err := handler.routingService.ListRules()
+	if err != nil {
+		handler.l.Errorln(err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not get routing rules, something went wrong",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": rules,
+	})
+}
+
+// Update replaces conditions and targets of the rule
+func (handler *httpHandler) Update(c *gin.Context) {
+
+	id, ok := handler.ruleId(c)
+	if !ok {
+		return
+	}
+
+	request, ok := handler.rule(c)
+	if !ok {
+		return
+	}
+
+	rule, err := handler.routingService.UpdateRule(id, request)
+	if err != nil {
+		handler.l.Errorln(err)
+		handler.fail(c, err, "Could not update routing rule, something went wrong")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Updated",
+		"rule":    rule,
+	})
+}
+
+func (handler *httpHandler) Delete(c *gin.Context) {
+
+	id, ok := handler.ruleId(c)
+	if !ok {
+		return
+	}
+
+	err := handler.routingService.DeleteRule(id)
+	if err != nil {
+		handler.l.Errorln(err)
+		handler.fail(c, err, "Could not delete routing rule, something went wrong")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Deleted",
+	})
+}
+
+func (handler *httpHandler) fail(c *gin.Context, err error, message string) {
+
+	switch err {
+	case errors.ErrInvalidRoutingRule:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid routing rule",
+			"error":   err.Error(),
+		})
+	case errors.ErrRoutingRuleNotFound:
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "Routing rule not found",
+			"error":   err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": message,
+		})
+	}
+}
+
+func (handler *httpHandler) rule(c *gin.Context) (domain.RoutingRule, bool) {
+
+	request := domain.RoutingRule{}
+
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		handler.l.Errorln("could not bind routing rule request.", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Cannot extract payload from request",
+			"error":   err.Error(),
+		})
+		return domain.RoutingRule{}, false
+	}
+
+	err = handler.validate.Struct(request)
+	if err != nil {
+		handler.l.Errorln("routing rule validation failed.", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Validation failed.",
+			"error":   err.Error(),
+		})
+		return domain.RoutingRule{}, false
+	}
+
+	return request, true
+}
+
+func (handler *httpHandler) ruleId(c *gin.Context) (string, bool) {
+
+	id := c.Param("id")
+
+	err := handler.validate.Var(id, "required,hexadecimal,len=24")
+	if err != nil {
+		handler.l.Errorln("validation failed for id parameter", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Could not process id parameter in request URI",
+			"error":   err.Error(),
+		})
+		return "", false
+	}
+
+	return id, true
+}
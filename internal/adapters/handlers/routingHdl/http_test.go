@@ -0,0 +1,246 @@
+package routingHdl
+
+import (
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-json"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"net/http/httptest"
+	"secrets-operator/config"
+	"secrets-operator/internal/adapters/handlers/authHdl"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/core/ports/mocks"
+	"secrets-operator/internal/errors"
+	"testing"
+)
+
+type RoutingHandlerTestSuite struct {
+	suite.Suite
+	sugaredLogger *zap.SugaredLogger
+	cfg           *config.Config
+	ctrl          *gomock.Controller
+}
+
+func TestSuiteRoutingHandler(t *testing.T) {
+	suite.Run(t, new(RoutingHandlerTestSuite))
+}
+
+func (s *RoutingHandlerTestSuite) SetupTest() {
+
+	var err error
+
+	s.sugaredLogger = zap.NewNop().Sugar()
+
+	// setup configs
+	s.cfg, err = config.LoadConfig("test")
+	if err != nil {
+		s.T().Fatalf("cannot load configuration variables. %v", err.Error())
+	}
+
+	// setup gomock controller
+	s.ctrl = gomock.NewController(s.T())
+	defer s.ctrl.Finish()
+}
+
+func (s *RoutingHandlerTestSuite) serve(router *gin.Engine, method, target string, body interface{}) *httptest.ResponseRecorder {
+
+	reqBodyBytes := new(bytes.Buffer)
+	if body != nil {
+		if err := json.NewEncoder(reqBodyBytes).Encode(body); err != nil {
+			s.T().Fatal("could not encode request body for testing.", err)
+		}
+	}
+
+	request := httptest.NewRequest(method, target, reqBodyBytes)
+	request.Header.Set("Content-Type", "application/json")
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+var slackTargets = []map[string]string{{"type": "slack", "destination": "C0123456"}}
+
+func (s *RoutingHandlerTestSuite) TestHttpHandler_CreateTableDriven() {
+
+	tests := []struct {
+		name                 string
+		inputBody            interface{}
+		createReturnErr      error
+		wantCreateInvocation bool
+		wantStatusCode       int
+	}{
+		{"valid rule", map[string]interface{}{"repoNameGlob": "payments-*", "targets": slackTargets}, nil, true, 201},
+		{"rule of findings", map[string]interface{}{"groupPrefix": "platform", "ruleIds": []string{"aws-access-token"}, "tags": []string{"key"}, "targets": slackTargets}, nil, true, 201},
+		{"missing targets", map[string]interface{}{"repoIds": []int{444}}, nil, false, 400},
+		{"unknown target type", map[string]interface{}{"targets": []map[string]string{{"type": "pager", "destination": "payments"}}}, nil, false, 400},
+		{"missing destination", map[string]interface{}{"targets": []map[string]string{{"type": "slack"}}}, nil, false, 400},
+		{"invalid repository id", map[string]interface{}{"repoIds": []int{0}, "targets": slackTargets}, nil, false, 400},
+		{"service rejects rule", map[string]interface{}{"repoNameGlob": "payments-[", "targets": slackTargets}, errors.ErrInvalidRoutingRule, true, 400},
+		{"service error", map[string]interface{}{"targets": slackTargets}, errors.ErrCouldNotSaveRoutingRule, true, 500},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// arrange
+			mockRoutingService := mocks.NewMockRoutingService(s.ctrl)
+
+			invoked := false
+			mockRoutingService.
+				EXPECT().
+				CreateRule(gomock.Any(), "test admin").
+				DoAndReturn(func(rule domain.RoutingRule, createdBy string) (domain.RoutingRule, error) {
+					invoked = true
+					rule.ID = "a1"
+					return rule, tt.createReturnErr
+				}).
+				AnyTimes()
+
+			sut := NewRoutingHandler(s.cfg, s.sugaredLogger, mockRoutingService)
+
+			router := gin.New()
+			router.Use(authHdl.WithPrincipal(domain.Principal{Name: "test admin", Role: domain.RoleAdmin}))
+			router.POST("/api/v1/routes", sut.Create)
+
+			// act
+			recorder := s.serve(router, "POST", "/api/v1/routes", tt.inputBody)
+
+			// assert
+			assert.Equal(s.T(), tt.wantStatusCode, recorder.Code, recorder.Body.String())
+			assert.Equal(s.T(), tt.wantCreateInvocation, invoked)
+
+			if tt.wantStatusCode == 201 {
+				resp := struct {
+					Rule domain.RoutingRule `json:"rule"`
+				}{}
+				if err := json.NewDecoder(recorder.Body).Decode(&resp); err != nil {
+					s.T().Fatal("could not decode response body.", err)
+				}
+				assert.Equal(s.T(), "a1", resp.Rule.ID)
+			}
+		})
+	}
+}
+
+func (s *RoutingHandlerTestSuite) TestHttpHandler_List() {
+
+	// arrange
+	mockRoutingService := mocks.NewMockRoutingService(s.ctrl)
+	mockRoutingService.EXPECT().ListRules().Return([]domain.RoutingRule{{ID: "a1", Targets: []domain.NotificationTarget{{Type: domain.NotificationTargetSlack, Destination: "C0123456"}}}}, nil)
+
+	sut := NewRoutingHandler(s.cfg, s.sugaredLogger, mockRoutingService)
+
+	router := gin.New()
+	router.GET("/api/v1/routes", sut.List)
+
+	// act
+	recorder := s.serve(router, "GET", "/api/v1/routes", nil)
+
+	// assert
+	assert.Equal(s.T(), 200, recorder.Code)
+	assert.Contains(s.T(), recorder.Body.String(), `"id":"a1"`)
+	assert.Contains(s.T(), recorder.Body.String(), `"destination":"C0123456"`)
+}
+
+func (s *RoutingHandlerTestSuite) TestHttpHandler_UpdateTableDriven() {
+
+	tests := []struct {
+		name                 string
+		inputId              string
+		inputBody            interface{}
+		updateReturnErr      error
+		wantUpdateInvocation bool
+		wantStatusCode       int
+	}{
+		{"updated", "0123456789abcdef01234567", map[string]interface{}{"targets": slackTargets}, nil, true, 200},
+		{"malformed id", "a1", map[string]interface{}{"targets": slackTargets}, nil, false, 400},
+		{"invalid rule", "0123456789abcdef01234567", map[string]interface{}{}, nil, false, 400},
+		{"unknown rule", "0123456789abcdef01234567", map[string]interface{}{"targets": slackTargets}, errors.ErrRoutingRuleNotFound, true, 404},
+		{"service error", "0123456789abcdef01234567", map[string]interface{}{"targets": slackTargets}, errors.ErrCouldNotSaveRoutingRule, true, 500},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// arrange
+			mockRoutingService := mocks.NewMockRoutingService(s.ctrl)
+
+			invoked := false
+			mockRoutingService.
+				EXPECT().
+				UpdateRule(tt.inputId, gomock.Any()).
+				DoAndReturn(func(id string, rule domain.RoutingRule) (domain.RoutingRule, error) {
+					invoked = true
+					rule.ID = id
+					return rule, tt.updateReturnErr
+				}).
+				AnyTimes()
+
+			sut := NewRoutingHandler(s.cfg, s.sugaredLogger, mockRoutingService)
+
+			router := gin.New()
+			router.PUT("/api/v1/routes/:id", sut.Update)
+
+			// act
+			recorder := s.serve(router, "PUT", "/api/v1/routes/"+tt.inputId, tt.inputBody)
+
+			// assert
+			assert.Equal(s.T(), tt.wantStatusCode, recorder.Code, recorder.Body.String())
+			assert.Equal(s.T(), tt.wantUpdateInvocation, invoked)
+		})
+	}
+}
+
+func (s *RoutingHandlerTestSuite) TestHttpHandler_DeleteTableDriven() {
+
+	tests := []struct {
+		name                 string
+		inputId              string
+		deleteReturnErr      error
+		wantDeleteInvocation bool
+		wantStatusCode       int
+	}{
+		{"deleted", "0123456789abcdef01234567", nil, true, 200},
+		{"malformed id", "a1", nil, false, 400},
+		{"unknown rule", "0123456789abcdef01234567", errors.ErrRoutingRuleNotFound, true, 404},
+		{"service error", "0123456789abcdef01234567", errors.ErrCouldNotDeleteRoutingRule, true, 500},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// arrange
+			mockRoutingService := mocks.NewMockRoutingService(s.ctrl)
+
+			invoked := false
+			mockRoutingService.
+				EXPECT().
+				DeleteRule(tt.inputId).
+				DoAndReturn(func(id string) error {
+					invoked = true
+					return tt.deleteReturnErr
+				}).
+				AnyTimes()
+
+			sut := NewRoutingHandler(s.cfg, s.sugaredLogger, mockRoutingService)
+
+			router := gin.New()
+			router.DELETE("/api/v1/routes/:id", sut.Delete)
+
+			// act
+			recorder := s.serve(router, "DELETE", "/api/v1/routes/"+tt.inputId, nil)
+
+			// assert
+			assert.Equal(s.T(), tt.wantStatusCode, recorder.Code)
+			assert.Equal(s.T(), tt.wantDeleteInvocation, invoked)
+		})
+	}
+}
@@ -6,7 +6,11 @@ import (
 	"go.uber.org/zap"
 	"net/http"
 	"secrets-operator/config"
+	"secrets-operator/internal/adapters/handlers/authHdl"
+	"secrets-operator/internal/core/domain"
 	"secrets-operator/internal/core/ports"
+	"secrets-operator/internal/errors"
+	"strconv"
 )
 
 type httpHandler struct {
@@ -41,6 +45,16 @@ func (handler *httpHandler) SearchRepositories(c *gin.Context) {
 	}
 
 	repoNames, err := handler.findingService.GetByName(queryParam)
+	if err == nil {
+		principal, _ := authHdl.PrincipalFrom(c)
+		repoNames = visibleRepositories(principal, repoNames)
+
+		// repositories hidden from the caller are reported the same way as missing ones
+		if len(repoNames) == 0 {
+			err = errors.ErrNoRepositoriesFound
+		}
+	}
+
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"message": "Repositories not found with provided name",
@@ -54,3 +68,18 @@ func (handler *httpHandler) SearchRepositories(c *gin.Context) {
 	})
 
 }
+
+// visibleRepositories drops search results the principal is not allowed to read
+func visibleRepositories(principal domain.Principal, repoNames []map[string]string) []map[string]string {
+
+	visible := make([]map[string]string, 0, len(repoNames))
+	for _, repo := range repoNames {
+		repoId, err := strconv.Atoi(repo["id"])
+		if err != nil || !principal.CanRead(repoId) {
+			continue
+		}
+		visible = append(visible, repo)
+	}
+
+	return visible
+}
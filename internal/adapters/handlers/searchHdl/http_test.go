@@ -11,6 +11,8 @@ import (
 	"go.uber.org/zap"
 	"net/http/httptest"
 	"secrets-operator/config"
+	"secrets-operator/internal/adapters/handlers/authHdl"
+	"secrets-operator/internal/core/domain"
 	"secrets-operator/internal/core/ports/mocks"
 	"secrets-operator/internal/errors"
 	"testing"
@@ -55,6 +57,7 @@ func (s *SearchHandlerTestSuite) SetupTest() {
 		router.Use(gin.Recovery())
 		router.Use(cors.Default())
 		router.Use(ginZap.Ginzap(logger, time.RFC3339, true))
+		router.Use(authHdl.WithPrincipal(domain.Principal{Name: "test admin", Role: domain.RoleAdmin}))
 
 		return router
 	}
@@ -150,3 +153,54 @@ func (s *SearchHandlerTestSuite) TestHttpHandler_SearchRepositories() {
 		})
 	}
 }
+
+func (s *SearchHandlerTestSuite) TestHttpHandler_SearchRepositoriesFiltersVisible() {
+
+	repos := []map[string]string{{"id": "444", "name": "payments"}, {"id": "555", "name": "payments-ui"}}
+
+	tests := []struct {
+		name           string
+		principal      domain.Principal
+		wantStatusCode int
+		wantItems      []map[string]string
+	}{
+		{"admin sees every repository", domain.Principal{Role: domain.RoleAdmin}, 200, repos},
+		{"viewer sees visible repositories only", domain.Principal{Role: domain.RoleViewer, RepoIDs: []int{555}}, 200, repos[1:]},
+		{"upload token sees its own repository", domain.Principal{Role: domain.RoleUploader, RepoIDs: []int{444}}, 200, repos[:1]},
+		{"hidden repositories are not found", domain.Principal{Role: domain.RoleViewer, RepoIDs: []int{}}, 404, nil},
+		{"principal without role sees nothing", domain.Principal{}, 404, nil},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// arrange
+			mockFindingService := mocks.NewMockFindingService(s.ctrl)
+			mockFindingService.EXPECT().GetByName("pay").Return(repos, nil)
+
+			sut := NewSearchHandler(s.cfg, s.sugaredLogger, mockFindingService)
+
+			router := s.setupRouterFunc()
+			router.Use(authHdl.WithPrincipal(tt.principal))
+			router.GET("/api/v1/search/repos", sut.SearchRepositories)
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest("GET", "/api/v1/search/repos?query=pay", nil)
+
+			// act
+			router.ServeHTTP(recorder, request)
+
+			// assert
+			assert.Equal(s.T(), tt.wantStatusCode, recorder.Code)
+
+			if tt.wantStatusCode == 200 {
+				resp := map[string][]map[string]string{}
+				if err := json.NewDecoder(recorder.Body).Decode(&resp); err != nil {
+					s.T().Fatal("could not decode response body into struct.", err)
+				}
+				assert.Equal(s.T(), tt.wantItems, resp["items"])
+			}
+		})
+	}
+}
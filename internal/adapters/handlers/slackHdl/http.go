@@ -0,0 +1,248 @@
+package slackHdl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"net/url"
+	"secrets-operator/config"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/core/ports"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxPayloadSize limits interaction requests, Slack sends the whole message with every click
+	maxPayloadSize = 1 << 20
+	// triageTimeout limits triage of an interaction and updates of its message, they run after Slack got its response
+	triageTimeout = 30 * time.Second
+)
+
+type httpHandler struct {
+	cfg            *config.Config
+	l              *zap.SugaredLogger
+	findingService ports.FindingService
+	// accessPolicy grants Slack users roles, like users of the API they may only triage repositories they can see
+	accessPolicy domain.AccessPolicy
+	client       *http.Client
+	// triages counts interactions which are still being triaged
+	triages sync.WaitGroup
+}
+
+func NewSlackHandler(cfg *config.Config, l *zap.SugaredLogger, findingService ports.FindingService, accessPolicy domain.AccessPolicy) *httpHandler {
+
+	return &httpHandler{
+		cfg:            cfg,
+		l:              l,
+		findingService: findingService,
+		accessPolicy:   accessPolicy,
+		client:         &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Interactions handles triage buttons of Slack notifications. Requests are signed by Slack with the signing secret and
+// must come from the configured workspace and app.
+// Slack gets its response right away, which it expects within 3 seconds, then the finding status is changed on behalf
+// of the Slack user, if the access policy allows the user to triage the repository, and buttons of the finding are
+// replaced by the outcome.
+func (handler *httpHandler) Interactions(c *gin.Context) {
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxPayloadSize))
+	if err != nil {
+		handler.l.Errorln("could not read Slack interaction.", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Cannot read request body",
+		})
+		return
+	}
+
+	if err = handler.verify(c.Request.Header, body); err != nil {
+		handler.l.Warnln("Slack interaction signature verification failed.", err)
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"message": "Invalid Slack signature",
+		})
+		return
+	}
+
+	callback := slack.InteractionCallback{}
+
+	form, err := url.ParseQuery(string(body))
+	if err == nil {
+		err = json.Unmarshal([]byte(form.Get("payload")), &callback)
+	}
+	if err != nil {
+		handler.l.Errorln("could not decode Slack interaction.", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Cannot extract payload from request",
+		})
+		return
+	}
+
+	// signing secrets are per app, the check keeps a secret shared by mistake from opening triage to other workspaces
+	if callback.Team.ID != handler.cfg.SlackTeamID || callback.APIAppID != handler.cfg.SlackAppID {
+		handler.l.Warnln("Slack interaction of unknown workspace or app.", callback.Team.ID, callback.APIAppID)
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "Unknown Slack workspace or app",
+		})
+		return
+	}
+
+	if callback.Type != slack.InteractionTypeBlockActions {
+		c.Status(http.StatusOK)
+		return
+	}
+
+	handler.triages.Add(1)
+	go func() {
+		defer handler.triages.Done()
+		defer func() {
+			// a panicking triage must not take the server down
+			if recovered := recover(); recovered != nil {
+				handler.l.Errorln("Slack interaction panicked.", recovered)
+			}
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), triageTimeout)
+		defer cancel()
+
+		handler.triage(ctx, callback)
+	}()
+
+	c.Status(http.StatusOK)
+}
+
+// triage changes statuses of findings whose buttons were clicked and updates the message through its response URL
+func (handler *httpHandler) triage(ctx context.Context, callback slack.InteractionCallback) {
+
+	mention := fmt.Sprintf("<@%s>", callback.User.ID)
+
+	// Slack user IDs are stable, names can be changed by the users themselves
+	principal, ok := handler.accessPolicy.SlackPrincipal(callback.User.ID)
+	if !ok {
+		handler.l.Warnln("Slack user is not granted a role.", callback.User.ID)
+		handler.respond(ctx, callback.ResponseURL, &slack.WebhookMessage{
+			ResponseType: slack.ResponseTypeEphemeral,
+			Text:         "You are not allowed to triage findings, ask admins of secrets operator to grant your Slack user a role",
+		})
+		return
+	}
+
+	message := callback.Message
+	triaged := false
+	var failures []string
+
+	for _, action := range callback.ActionCallback.BlockActions {
+
+		if ctx.Err() != nil {
+			failures = append(failures, "Triage did not finish in time, please try again")
+			break
+		}
+
+		request, err := domain.ParseTriageRequest(action.ActionID, action.Value)
+		if err != nil {
+			handler.l.Warnln("unknown Slack action.", err)
+			continue
+		}
+
+		if !principal.CanTriage(request.RepoID) {
+			handler.l.Warnln("triage of repository not allowed for Slack user", callback.User.ID, request.RepoID)
+			failures = append(failures, fmt.Sprintf("You are not allowed to triage findings of repository %d", request.RepoID))
+			continue
+		}
+
+		err = handler.findingService.UpdateStatus(request.RepoID, request.Fingerprint, request.StatusChange(principal.Actor()))
+		if err != nil {
+			handler.l.Errorln("could not triage finding from Slack.", request.RepoID, request.Fingerprint, err)
+			failures = append(failures, fmt.Sprintf("Could not triage the finding: %v", err))
+			continue
+		}
+
+		message = replaceBlock(message, action.BlockID, fmt.Sprintf(":white_check_mark: Finding %s", request.Outcome(mention)))
+		triaged = true
+	}
+
+	if triaged {
+		handler.respond(ctx, callback.ResponseURL, &slack.WebhookMessage{
+			ReplaceOriginal: true,
+			Text:            message.Text,
+			Attachments:     message.Attachments,
+			Blocks:          nonEmpty(message.Blocks),
+		})
+	}
+
+	if len(failures) > 0 {
+		handler.respond(ctx, callback.ResponseURL, &slack.WebhookMessage{
+			ResponseType: slack.ResponseTypeEphemeral,
+			Text:         strings.Join(failures, "\n"),
+		})
+	}
+}
+
+// verify checks signature of the request made with the signing secret, requests older than 5 minutes are rejected
+func (handler *httpHandler) verify(header http.Header, body []byte) error {
+
+	verifier, err := slack.NewSecretsVerifier(header, handler.cfg.SlackSigningSecret)
+	if err != nil {
+		return err
+	}
+
+	if _, err = verifier.Write(body); err != nil {
+		return err
+	}
+
+	return verifier.Ensure()
+}
+
+// respond posts to response URL of the interaction, failures only leave the original message as it was
+func (handler *httpHandler) respond(ctx context.Context, responseURL string, message *slack.WebhookMessage) {
+
+	if responseURL == "" {
+		return
+	}
+
+	if err := slack.PostWebhookCustomHTTPContext(ctx, responseURL, handler.client, message); err != nil {
+		handler.l.Errorln("could not update Slack message.", err)
+	}
+}
+
+// replaceBlock replaces actions block with the ID, in the message or its attachments, by context block with the text
+func replaceBlock(message slack.Message, blockID string, text string) slack.Message {
+
+	replace := func(blocks slack.Blocks) slack.Blocks {
+		replaced := make([]slack.Block, 0, len(blocks.BlockSet))
+		for _, block := range blocks.BlockSet {
+			if action, ok := block.(*slack.ActionBlock); ok && blockID != "" && action.BlockID == blockID {
+				block = slack.NewContextBlock(blockID, slack.NewTextBlockObject(slack.MarkdownType, text, false, false))
+			}
+			replaced = append(replaced, block)
+		}
+		return slack.Blocks{BlockSet: replaced}
+	}
+
+	message.Blocks = replace(message.Blocks)
+
+	attachments := make([]slack.Attachment, 0, len(message.Attachments))
+	for _, attachment := range message.Attachments {
+		attachment.Blocks = replace(attachment.Blocks)
+		attachments = append(attachments, attachment)
+	}
+	message.Attachments = attachments
+
+	return message
+}
+
+func nonEmpty(blocks slack.Blocks) *slack.Blocks {
+
+	if len(blocks.BlockSet) == 0 {
+		return nil
+	}
+
+	return &blocks
+}
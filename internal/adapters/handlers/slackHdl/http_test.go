@@ -0,0 +1,257 @@
+package slackHdl
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-json"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"secrets-operator/config"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/core/ports/mocks"
+	"secrets-operator/internal/errors"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type SlackHandlerTestSuite struct {
+	suite.Suite
+	sugaredLogger *zap.SugaredLogger
+	cfg           *config.Config
+	ctrl          *gomock.Controller
+	// responses are posted by the handler to response URL of interactions
+	responses   []map[string]interface{}
+	mu          sync.Mutex
+	responseURL string
+	policy      domain.AccessPolicy
+}
+
+func TestSuiteSlackHandler(t *testing.T) {
+	suite.Run(t, new(SlackHandlerTestSuite))
+}
+
+func (s *SlackHandlerTestSuite) SetupTest() {
+
+	var err error
+
+	s.sugaredLogger = zap.NewNop().Sugar()
+
+	// setup configs
+	s.cfg, err = config.LoadConfig("test")
+	if err != nil {
+		s.T().Fatalf("cannot load configuration variables. %v", err.Error())
+	}
+	s.cfg.SlackSigningSecret = "test signing secret"
+	s.cfg.SlackTeamID = "T0TEST"
+	s.cfg.SlackAppID = "A0TEST"
+
+	s.policy = domain.AccessPolicy{
+		Roles: map[domain.Role][]string{
+			domain.RoleViewer:  {"developers"},
+			domain.RoleTriager: {"security-champions"},
+		},
+		Repos: map[int][]string{
+			444: {"team-testing"},
+			555: {"team-other"},
+		},
+		SlackUsers: map[string]domain.Claims{
+			"U0JANE": {Roles: []string{"security-champions"}, Groups: []string{"team-testing"}},
+			"U0JOHN": {Roles: []string{"developers"}, Groups: []string{"team-testing"}},
+			"U0MARY": {Roles: []string{"security-champions"}, Groups: []string{"team-other"}},
+		},
+	}
+
+	// setup gomock controller
+	s.ctrl = gomock.NewController(s.T())
+	defer s.ctrl.Finish()
+
+	s.responses = nil
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := map[string]interface{}{}
+		_ = json.NewDecoder(r.Body).Decode(&response)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.responses = append(s.responses, response)
+	}))
+	s.T().Cleanup(server.Close)
+	s.responseURL = server.URL
+}
+
+// payload is a click of the user on the button on a message with triage buttons of two findings
+func (s *SlackHandlerTestSuite) payload(interactionType string, actionId string, userId string, teamId string) string {
+
+	return fmt.Sprintf(`{
+		"type": %q,
+		"team": {"id": %q},
+		"api_app_id": "A0TEST",
+		"user": {"id": %q, "name": "jane"},
+		"response_url": %q,
+		"actions": [{"type": "button", "action_id": %q, "block_id": "finding-0", "value": "444:a85af84d:src/config.go:aws-access-token:12"}],
+		"message": {
+			"type": "message",
+			"attachments": [{
+				"color": "FF0000",
+				"fallback": "Found new hard coded secrets in testing repo",
+				"blocks": [
+					{"type": "section", "text": {"type": "mrkdwn", "text": "Found new hard coded secrets"}},
+					{"type": "actions", "block_id": "finding-0", "elements": [{"type": "button", "action_id": "triage_assign", "value": "444:f0", "text": {"type": "plain_text", "text": "Assign to me"}}]},
+					{"type": "actions", "block_id": "finding-1", "elements": [{"type": "button", "action_id": "triage_assign", "value": "444:f1", "text": {"type": "plain_text", "text": "Assign to me"}}]}
+				]
+			}]
+		}
+	}`, interactionType, teamId, userId, s.responseURL, actionId)
+}
+
+// serve sends the payload signed with the secret at the time
+func (s *SlackHandlerTestSuite) serve(router *gin.Engine, payload string, secret string, at time.Time) *httptest.ResponseRecorder {
+
+	body := url.Values{"payload": {payload}}.Encode()
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = io.WriteString(mac, "v0:"+timestamp+":"+body)
+
+	request := httptest.NewRequest("POST", "/api/v1/slack/interactions", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	request.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+func (s *SlackHandlerTestSuite) TestHandler_InteractionsTableDriven() {
+
+	tests := []struct {
+		name            string
+		interactionType string
+		actionId        string
+		userId          string
+		teamId          string
+		secret          string
+		at              time.Time
+		updateTimes     int
+		updateErr       error
+		wantCode        int
+		wantResponse    string
+	}{
+		{"assign to me", "block_actions", "triage_assign", "U0JANE", "T0TEST", s.cfg.SlackSigningSecret, time.Now(), 1, nil, http.StatusOK, "replace"},
+		{"finding not found", "block_actions", "triage_revoked", "U0JANE", "T0TEST", s.cfg.SlackSigningSecret, time.Now(), 1, errors.ErrFindingNotFound, http.StatusOK, "not found"},
+		{"unknown action is ignored", "block_actions", "triage_delete", "U0JANE", "T0TEST", s.cfg.SlackSigningSecret, time.Now(), 0, nil, http.StatusOK, ""},
+		{"other interactions are ignored", "view_submission", "triage_assign", "U0JANE", "T0TEST", s.cfg.SlackSigningSecret, time.Now(), 0, nil, http.StatusOK, ""},
+		{"slack user without role", "block_actions", "triage_false_positive", "U0EVE", "T0TEST", s.cfg.SlackSigningSecret, time.Now(), 0, nil, http.StatusOK, "not allowed"},
+		{"viewer can not triage", "block_actions", "triage_false_positive", "U0JOHN", "T0TEST", s.cfg.SlackSigningSecret, time.Now(), 0, nil, http.StatusOK, "not allowed"},
+		{"triager of other repository", "block_actions", "triage_false_positive", "U0MARY", "T0TEST", s.cfg.SlackSigningSecret, time.Now(), 0, nil, http.StatusOK, "not allowed"},
+		{"other workspace", "block_actions", "triage_assign", "U0JANE", "T0OTHER", s.cfg.SlackSigningSecret, time.Now(), 0, nil, http.StatusForbidden, ""},
+		{"wrong signing secret", "block_actions", "triage_assign", "U0JANE", "T0TEST", "other secret", time.Now(), 0, nil, http.StatusUnauthorized, ""},
+		{"replayed request", "block_actions", "triage_assign", "U0JANE", "T0TEST", s.cfg.SlackSigningSecret, time.Now().Add(-10 * time.Minute), 0, nil, http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// arrange
+			s.responses = nil
+
+			var change domain.StatusChange
+			mockFindingService := mocks.NewMockFindingService(s.ctrl)
+			mockFindingService.
+				EXPECT().
+				UpdateStatus(444, "a85af84d:src/config.go:aws-access-token:12", gomock.Any()).
+				DoAndReturn(func(repoId int, fingerprint string, c domain.StatusChange) error {
+					change = c
+					return tt.updateErr
+				}).
+				Times(tt.updateTimes)
+
+			handler := NewSlackHandler(s.cfg, s.sugaredLogger, mockFindingService, s.policy)
+
+			router := gin.New()
+			router.POST("/api/v1/slack/interactions", handler.Interactions)
+
+			// act
+			recorder := s.serve(router, s.payload(tt.interactionType, tt.actionId, tt.userId, tt.teamId), tt.secret, tt.at)
+			handler.triages.Wait()
+
+			// assert
+			assert.Equal(s.T(), tt.wantCode, recorder.Code, recorder.Body.String())
+
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			switch tt.wantResponse {
+			case "":
+				assert.Empty(s.T(), s.responses)
+			case "replace":
+				assert.Equal(s.T(), domain.StatusChange{Status: domain.FindingStatusAcknowledged, ChangedBy: "slack:U0JANE", Assignee: "slack:U0JANE"}, change)
+				if assert.Len(s.T(), s.responses, 1) {
+					assert.Equal(s.T(), true, s.responses[0]["replace_original"])
+
+					blocks := s.responses[0]["attachments"].([]interface{})[0].(map[string]interface{})["blocks"].([]interface{})
+					if assert.Len(s.T(), blocks, 3) {
+						triaged := blocks[1].(map[string]interface{})
+						assert.Equal(s.T(), "context", triaged["type"])
+						assert.Equal(s.T(), ":white_check_mark: Finding assigned to <@U0JANE>", triaged["elements"].([]interface{})[0].(map[string]interface{})["text"])
+						assert.Equal(s.T(), "actions", blocks[2].(map[string]interface{})["type"], "buttons of other findings are kept")
+					}
+				}
+			default:
+				if assert.Len(s.T(), s.responses, 1) {
+					assert.Equal(s.T(), "ephemeral", s.responses[0]["response_type"])
+					assert.Contains(s.T(), s.responses[0]["text"], tt.wantResponse)
+				}
+			}
+		})
+	}
+}
+
+func (s *SlackHandlerTestSuite) TestHandler_InteractionsRespondBeforeTriage() {
+
+	// arrange
+	s.responses = nil
+	release := make(chan struct{})
+
+	mockFindingService := mocks.NewMockFindingService(s.ctrl)
+	mockFindingService.
+		EXPECT().
+		UpdateStatus(444, "a85af84d:src/config.go:aws-access-token:12", gomock.Any()).
+		DoAndReturn(func(repoId int, fingerprint string, c domain.StatusChange) error {
+			<-release
+			return nil
+		})
+
+	handler := NewSlackHandler(s.cfg, s.sugaredLogger, mockFindingService, s.policy)
+
+	router := gin.New()
+	router.POST("/api/v1/slack/interactions", handler.Interactions)
+
+	// act
+	recorder := s.serve(router, s.payload("block_actions", "triage_assign", "U0JANE", "T0TEST"), s.cfg.SlackSigningSecret, time.Now())
+
+	// assert
+	assert.Equal(s.T(), http.StatusOK, recorder.Code, "Slack is answered while the finding is still being triaged")
+
+	close(release)
+	handler.triages.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if assert.Len(s.T(), s.responses, 1) {
+		assert.Equal(s.T(), true, s.responses[0]["replace_original"])
+	}
+}
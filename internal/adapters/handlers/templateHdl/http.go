@@ -0,0 +1,89 @@
+package templateHdl
+
+import (
+	stdErrors "errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+	"net/http"
+	"secrets-operator/config"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/core/ports"
+	"secrets-operator/internal/errors"
+)
+
+type httpHandler struct {
+	cfg             *config.Config
+	l               *zap.SugaredLogger
+	validate        *validator.Validate
+	templateService ports.TemplateService
+}
+
+func NewTemplateHandler(cfg *config.Config, l *zap.SugaredLogger, templateService ports.TemplateService) *httpHandler {
+
+	return &httpHandler{
+		cfg:             cfg,
+		l:               l,
+		validate:        validator.New(),
+		templateService: templateService,
+	}
+}
+
+func (handler *httpHandler) List(c *gin.Context) {
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": handler.templateService.Names(),
+	})
+}
+
+// Preview renders a loaded template or a draft, so templates can be checked before they are deployed
+func (handler *httpHandler) Preview(c *gin.Context) {
+
+	request := domain.TemplatePreview{}
+
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		handler.l.Errorln("could not bind template preview request.", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Cannot extract payload from request",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	err = handler.validate.Struct(request)
+	if err != nil {
+		handler.l.Errorln("template preview validation failed.", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Validation failed.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	rendered, err := handler.templateService.Preview(request)
+	if err != nil {
+		switch {
+		case err == errors.ErrTemplateNotFound:
+			c.JSON(http.StatusNotFound, gin.H{
+				"message": "Notification template not found",
+				"error":   err.Error(),
+			})
+		case stdErrors.Is(err, errors.ErrInvalidTemplate):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"message": "Notification template can not be rendered",
+				"error":   err.Error(),
+			})
+		default:
+			handler.l.Errorln(err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"message": "Could not preview notification template, something went wrong",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"rendered": rendered,
+	})
+}
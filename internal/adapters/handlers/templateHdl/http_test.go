@@ -0,0 +1,136 @@
+package templateHdl
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-json"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"net/http/httptest"
+	"secrets-operator/config"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/core/ports/mocks"
+	"secrets-operator/internal/errors"
+	"testing"
+)
+
+type TemplateHandlerTestSuite struct {
+	suite.Suite
+	sugaredLogger *zap.SugaredLogger
+	cfg           *config.Config
+	ctrl          *gomock.Controller
+}
+
+func TestSuiteTemplateHandler(t *testing.T) {
+	suite.Run(t, new(TemplateHandlerTestSuite))
+}
+
+func (s *TemplateHandlerTestSuite) SetupTest() {
+
+	var err error
+
+	s.sugaredLogger = zap.NewNop().Sugar()
+
+	// setup configs
+	s.cfg, err = config.LoadConfig("test")
+	if err != nil {
+		s.T().Fatalf("cannot load configuration variables. %v", err.Error())
+	}
+
+	// setup gomock controller
+	s.ctrl = gomock.NewController(s.T())
+	defer s.ctrl.Finish()
+}
+
+func (s *TemplateHandlerTestSuite) serve(router *gin.Engine, method, target string, body interface{}) *httptest.ResponseRecorder {
+
+	reqBodyBytes := new(bytes.Buffer)
+	if body != nil {
+		if err := json.NewEncoder(reqBodyBytes).Encode(body); err != nil {
+			s.T().Fatal("could not encode request body for testing.", err)
+		}
+	}
+
+	request := httptest.NewRequest(method, target, reqBodyBytes)
+	request.Header.Set("Content-Type", "application/json")
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+func (s *TemplateHandlerTestSuite) TestHttpHandler_PreviewTableDriven() {
+
+	tests := []struct {
+		name                  string
+		inputBody             interface{}
+		previewReturnErr      error
+		wantPreviewInvocation bool
+		wantStatusCode        int
+	}{
+		{"named template", map[string]interface{}{"name": "slack"}, nil, true, 200},
+		{"draft with report", map[string]interface{}{"source": "{{.RepoName}}", "report": map[string]interface{}{"repoName": "testing repo"}}, nil, true, 200},
+		{"neither name nor source", map[string]interface{}{"report": map[string]interface{}{"repoName": "testing repo"}}, nil, false, 400},
+		{"malformed body", "template", nil, false, 400},
+		{"unknown template", map[string]interface{}{"name": "missing"}, errors.ErrTemplateNotFound, true, 404},
+		{"invalid draft", map[string]interface{}{"source": "{{.Unknown}}"}, fmt.Errorf("%w: can't evaluate field Unknown", errors.ErrInvalidTemplate), true, 400},
+		{"service error", map[string]interface{}{"name": "slack"}, assert.AnError, true, 500},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// arrange
+			mockTemplateService := mocks.NewMockTemplateService(s.ctrl)
+
+			invoked := false
+			mockTemplateService.
+				EXPECT().
+				Preview(gomock.Any()).
+				DoAndReturn(func(preview domain.TemplatePreview) (string, error) {
+					invoked = true
+					return "rendered " + preview.Name, tt.previewReturnErr
+				}).
+				AnyTimes()
+
+			sut := NewTemplateHandler(s.cfg, s.sugaredLogger, mockTemplateService)
+
+			router := gin.New()
+			router.POST("/api/v1/templates/preview", sut.Preview)
+
+			// act
+			recorder := s.serve(router, "POST", "/api/v1/templates/preview", tt.inputBody)
+
+			// assert
+			assert.Equal(s.T(), tt.wantStatusCode, recorder.Code, recorder.Body.String())
+			assert.Equal(s.T(), tt.wantPreviewInvocation, invoked)
+			if tt.wantStatusCode == 400 && tt.previewReturnErr != nil {
+				assert.Contains(s.T(), recorder.Body.String(), "can't evaluate field Unknown", "template errors are shown to their authors")
+			}
+		})
+	}
+}
+
+func (s *TemplateHandlerTestSuite) TestHttpHandler_List() {
+
+	// arrange
+	mockTemplateService := mocks.NewMockTemplateService(s.ctrl)
+	mockTemplateService.EXPECT().Names().Return([]string{"payments", "slack", "teams"})
+
+	sut := NewTemplateHandler(s.cfg, s.sugaredLogger, mockTemplateService)
+
+	router := gin.New()
+	router.GET("/api/v1/templates", sut.List)
+
+	// act
+	recorder := s.serve(router, "GET", "/api/v1/templates", nil)
+
+	// assert
+	assert.Equal(s.T(), 200, recorder.Code)
+	assert.JSONEq(s.T(), `{"items":["payments","slack","teams"]}`, recorder.Body.String())
+}
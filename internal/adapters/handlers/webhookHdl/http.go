@@ -0,0 +1,163 @@
+package webhookHdl
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+	"net/http"
+	"secrets-operator/config"
+	"secrets-operator/internal/adapters/handlers/authHdl"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/core/ports"
+	"secrets-operator/internal/errors"
+)
+
+type httpHandler struct {
+	cfg            *config.Config
+	l              *zap.SugaredLogger
+	validate       *validator.Validate
+	webhookService ports.WebhookService
+}
+
+func NewWebhookHandler(cfg *config.Config, l *zap.SugaredLogger, webhookService ports.WebhookService) *httpHandler {
+
+	return &httpHandler{
+		cfg:            cfg,
+		l:              l,
+		validate:       validator.New(),
+		webhookService: webhookService,
+	}
+}
+
+// Create subscribes endpoint to events, the signing secret is included in response only once
+func (handler *httpHandler) Create(c *gin.Context) {
+
+	request := domain.WebhookSubscription{}
+
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		handler.l.Errorln("could not bind webhook subscription request.", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Cannot extract payload from request",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	err = handler.validate.Struct(request)
+	if err != nil {
+		handler.l.Errorln("webhook subscription validation failed.", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Validation failed.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	principal, _ := authHdl.PrincipalFrom(c)
+
+	subscription, err := handler.webhookService.Subscribe(request, principal.Name)
+	if err != nil {
+		handler.l.Errorln(err)
+		if err == errors.ErrInvalidWebhookSubscription {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"message": "Invalid webhook subscription",
+				"error":   err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not create webhook subscription, something went wrong",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":      "Created",
+		"secret":       subscription.Secret,
+		"subscription": subscription,
+	})
+}
+
+func (handler *httpHandler) List(c *gin.Context) {
+
+	subscriptions, err := handler.webhookService.ListSubscriptions()
+	if err != nil {
+		handler.l.Errorln(err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not get webhook subscriptions, something went wrong",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": subscriptions,
+	})
+}
+
+func (handler *httpHandler) Delete(c *gin.Context) {
+
+	id, ok := handler.subscriptionId(c)
+	if !ok {
+		return
+	}
+
+	err := handler.webhookService.Unsubscribe(id)
+	if err != nil {
+		switch err {
+		case errors.ErrWebhookSubscriptionNotFound:
+			c.JSON(http.StatusNotFound, gin.H{
+				"message": "Webhook subscription not found",
+				"error":   err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"message": "Could not delete webhook subscription, something went wrong",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Deleted",
+	})
+}
+
+// Deliveries returns delivery log of the subscription, newest first
+func (handler *httpHandler) Deliveries(c *gin.Context) {
+
+	id, ok := handler.subscriptionId(c)
+	if !ok {
+		return
+	}
+
+	deliveries, err := handler.webhookService.GetDeliveries(id)
+	if err != nil {
+		handler.l.Errorln(err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not get webhook deliveries, something went wrong",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": deliveries,
+	})
+}
+
+func (handler *httpHandler) subscriptionId(c *gin.Context) (string, bool) {
+
+	id := c.Param("id")
+
+	err := handler.validate.Var(id, "required,hexadecimal,len=24")
+	if err != nil {
+		handler.l.Errorln("validation failed for id parameter", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Could not process id parameter in request URI",
+			"error":   err.Error(),
+		})
+		return "", false
+	}
+
+	return id, true
+}
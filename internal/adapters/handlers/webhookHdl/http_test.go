@@ -0,0 +1,246 @@
+package webhookHdl
+
+import (
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-json"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"net/http/httptest"
+	"secrets-operator/config"
+	"secrets-operator/internal/adapters/handlers/authHdl"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/core/ports/mocks"
+	"secrets-operator/internal/errors"
+	"testing"
+)
+
+type WebhookHandlerTestSuite struct {
+	suite.Suite
+	sugaredLogger *zap.SugaredLogger
+	cfg           *config.Config
+	ctrl          *gomock.Controller
+}
+
+func TestSuiteWebhookHandler(t *testing.T) {
+	suite.Run(t, new(WebhookHandlerTestSuite))
+}
+
+func (s *WebhookHandlerTestSuite) SetupTest() {
+
+	var err error
+
+	s.sugaredLogger = zap.NewNop().Sugar()
+
+	// setup configs
+	s.cfg, err = config.LoadConfig("test")
+	if err != nil {
+		s.T().Fatalf("cannot load configuration variables. %v", err.Error())
+	}
+
+	// setup gomock controller
+	s.ctrl = gomock.NewController(s.T())
+	defer s.ctrl.Finish()
+}
+
+func (s *WebhookHandlerTestSuite) serve(router *gin.Engine, method, target string, body interface{}) *httptest.ResponseRecorder {
+
+	reqBodyBytes := new(bytes.Buffer)
+	if body != nil {
+		if err := json.NewEncoder(reqBodyBytes).Encode(body); err != nil {
+			s.T().Fatal("could not encode request body for testing.", err)
+		}
+	}
+
+	request := httptest.NewRequest(method, target, reqBodyBytes)
+	request.Header.Set("Content-Type", "application/json")
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+func (s *WebhookHandlerTestSuite) TestHttpHandler_CreateTableDriven() {
+
+	tests := []struct {
+		name                    string
+		inputBody               interface{}
+		subscribeReturnErr      error
+		wantSubscribeInvocation bool
+		wantStatusCode          int
+	}{
+		{"valid subscription", map[string]interface{}{"url": "https://hooks.example.com", "events": []string{"finding.new"}}, nil, true, 201},
+		{"subscription of repositories", map[string]interface{}{"url": "https://hooks.example.com", "events": []string{"report.received", "finding.status_changed"}, "repoIds": []int{444}}, nil, true, 201},
+		{"missing url", map[string]interface{}{"events": []string{"finding.new"}}, nil, false, 400},
+		{"malformed url", map[string]interface{}{"url": "hooks", "events": []string{"finding.new"}}, nil, false, 400},
+		{"missing events", map[string]interface{}{"url": "https://hooks.example.com"}, nil, false, 400},
+		{"unknown event", map[string]interface{}{"url": "https://hooks.example.com", "events": []string{"finding.deleted"}}, nil, false, 400},
+		{"service rejects subscription", map[string]interface{}{"url": "ftp://hooks.example.com", "events": []string{"finding.new"}}, errors.ErrInvalidWebhookSubscription, true, 400},
+		{"service error", map[string]interface{}{"url": "https://hooks.example.com", "events": []string{"finding.new"}}, errors.ErrCouldNotSaveWebhookSubscription, true, 500},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// arrange
+			mockWebhookService := mocks.NewMockWebhookService(s.ctrl)
+
+			invoked := false
+			mockWebhookService.
+				EXPECT().
+				Subscribe(gomock.Any(), "test admin").
+				DoAndReturn(func(subscription domain.WebhookSubscription, createdBy string) (domain.WebhookSubscription, error) {
+					invoked = true
+					subscription.ID = "a1"
+					subscription.Secret = "whsec_test"
+					return subscription, tt.subscribeReturnErr
+				}).
+				AnyTimes()
+
+			sut := NewWebhookHandler(s.cfg, s.sugaredLogger, mockWebhookService)
+
+			router := gin.New()
+			router.Use(authHdl.WithPrincipal(domain.Principal{Name: "test admin", Role: domain.RoleAdmin}))
+			router.POST("/api/v1/webhooks", sut.Create)
+
+			// act
+			recorder := s.serve(router, "POST", "/api/v1/webhooks", tt.inputBody)
+
+			// assert
+			assert.Equal(s.T(), tt.wantStatusCode, recorder.Code, recorder.Body.String())
+			assert.Equal(s.T(), tt.wantSubscribeInvocation, invoked)
+
+			if tt.wantStatusCode == 201 {
+				resp := struct {
+					Secret       string                     `json:"secret"`
+					Subscription domain.WebhookSubscription `json:"subscription"`
+				}{}
+				if err := json.NewDecoder(recorder.Body).Decode(&resp); err != nil {
+					s.T().Fatal("could not decode response body.", err)
+				}
+				assert.Equal(s.T(), "whsec_test", resp.Secret)
+				assert.Equal(s.T(), "a1", resp.Subscription.ID)
+			}
+		})
+	}
+}
+
+func (s *WebhookHandlerTestSuite) TestHttpHandler_List() {
+
+	// arrange
+	mockWebhookService := mocks.NewMockWebhookService(s.ctrl)
+	mockWebhookService.EXPECT().ListSubscriptions().Return([]domain.WebhookSubscription{{ID: "a1", URL: "https://hooks.example.com", Secret: "whsec_test"}}, nil)
+
+	sut := NewWebhookHandler(s.cfg, s.sugaredLogger, mockWebhookService)
+
+	router := gin.New()
+	router.GET("/api/v1/webhooks", sut.List)
+
+	// act
+	recorder := s.serve(router, "GET", "/api/v1/webhooks", nil)
+
+	// assert
+	assert.Equal(s.T(), 200, recorder.Code)
+	assert.Contains(s.T(), recorder.Body.String(), `"id":"a1"`)
+	assert.NotContains(s.T(), recorder.Body.String(), "whsec_test", "secrets are returned only on creation")
+}
+
+func (s *WebhookHandlerTestSuite) TestHttpHandler_DeleteTableDriven() {
+
+	tests := []struct {
+		name                      string
+		inputId                   string
+		unsubscribeReturnErr      error
+		wantUnsubscribeInvocation bool
+		wantStatusCode            int
+	}{
+		{"deleted", "0123456789abcdef01234567", nil, true, 200},
+		{"malformed id", "a1", nil, false, 400},
+		{"unknown subscription", "0123456789abcdef01234567", errors.ErrWebhookSubscriptionNotFound, true, 404},
+		{"service error", "0123456789abcdef01234567", errors.ErrCouldNotDeleteWebhookSubscription, true, 500},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// arrange
+			mockWebhookService := mocks.NewMockWebhookService(s.ctrl)
+
+			invoked := false
+			mockWebhookService.
+				EXPECT().
+				Unsubscribe(tt.inputId).
+				DoAndReturn(func(id string) error {
+					invoked = true
+					return tt.unsubscribeReturnErr
+				}).
+				AnyTimes()
+
+			sut := NewWebhookHandler(s.cfg, s.sugaredLogger, mockWebhookService)
+
+			router := gin.New()
+			router.DELETE("/api/v1/webhooks/:id", sut.Delete)
+
+			// act
+			recorder := s.serve(router, "DELETE", "/api/v1/webhooks/"+tt.inputId, nil)
+
+			// assert
+			assert.Equal(s.T(), tt.wantStatusCode, recorder.Code)
+			assert.Equal(s.T(), tt.wantUnsubscribeInvocation, invoked)
+		})
+	}
+}
+
+func (s *WebhookHandlerTestSuite) TestHttpHandler_DeliveriesTableDriven() {
+
+	tests := []struct {
+		name                        string
+		inputId                     string
+		getDeliveriesReturnErr      error
+		wantGetDeliveriesInvocation bool
+		wantStatusCode              int
+	}{
+		{"delivery log", "0123456789abcdef01234567", nil, true, 200},
+		{"malformed id", "a1", nil, false, 400},
+		{"service error", "0123456789abcdef01234567", errors.ErrCouldNotGetWebhookDeliveries, true, 500},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// arrange
+			mockWebhookService := mocks.NewMockWebhookService(s.ctrl)
+
+			invoked := false
+			mockWebhookService.
+				EXPECT().
+				GetDeliveries(tt.inputId).
+				DoAndReturn(func(id string) ([]domain.WebhookDelivery, error) {
+					invoked = true
+					return []domain.WebhookDelivery{{ID: "d1", SubscriptionID: id, Payload: []byte(`{"event":"finding.new"}`)}}, tt.getDeliveriesReturnErr
+				}).
+				AnyTimes()
+
+			sut := NewWebhookHandler(s.cfg, s.sugaredLogger, mockWebhookService)
+
+			router := gin.New()
+			router.GET("/api/v1/webhooks/:id/deliveries", sut.Deliveries)
+
+			// act
+			recorder := s.serve(router, "GET", "/api/v1/webhooks/"+tt.inputId+"/deliveries", nil)
+
+			// assert
+			assert.Equal(s.T(), tt.wantStatusCode, recorder.Code)
+			assert.Equal(s.T(), tt.wantGetDeliveriesInvocation, invoked)
+			if tt.wantStatusCode == 200 {
+				assert.Contains(s.T(), recorder.Body.String(), `"payload":{"event":"finding.new"}`, "payload is embedded as JSON")
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package identity
+
+import (
+	"encoding/json"
+	"fmt"
+	"go.uber.org/zap"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+	"io"
+	"net/http"
+	"os"
+	"secrets-operator/config"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// keySetTTL is how long fetched keys are trusted before the key set is fetched again
+	keySetTTL = time.Hour
+	// minRefreshInterval limits fetching on unknown key ids, otherwise forged tokens could hammer the identity provider
+	minRefreshInterval = time.Minute
+	maxKeySetSize      = 1 << 20
+)
+
+// allowedAlgorithms excludes symmetric algorithms, identity provider keys are public
+var allowedAlgorithms = map[string]bool{
+	string(jose.RS256): true, string(jose.RS384): true, string(jose.RS512): true,
+	string(jose.PS256): true, string(jose.PS384): true, string(jose.PS512): true,
+	string(jose.ES256): true, string(jose.ES384): true, string(jose.ES512): true,
+	string(jose.EdDSA): true,
+}
+
+type jwksVerifier struct {
+	cfg         *config.Config
+	l           *zap.SugaredLogger
+	client      *http.Client
+	mu          sync.Mutex
+	keySet      jose.JSONWebKeySet
+	fetchedAt   time.Time
+	attemptedAt time.Time
+}
+
+// NewJWKSVerifier creates verifier of identity provider tokens signed by keys of JSON web key set.
+// Key set is fetched from OIDC_JWKS_URL, or read from OIDC_JWKS_FILE for providers without discovery and for testing.
+func NewJWKSVerifier(cfg *config.Config, l *zap.SugaredLogger) (*jwksVerifier, error) {
+
+	if cfg.OIDCIssuer == "" {
+		return nil, fmt.Errorf("OIDC_ISSUER is required")
+	}
+
+	// without audience any token of the issuer would be accepted, including tokens minted for other clients
+	if cfg.OIDCAudience == "" {
+		return nil, fmt.Errorf("OIDC_AUDIENCE is required")
+	}
+
+	if cfg.OIDCJWKSURL == "" && cfg.OIDCJWKSFile == "" {
+		return nil, fmt.Errorf("one of OIDC_JWKS_URL or OIDC_JWKS_FILE is required")
+	}
+
+	return &jwksVerifier{
+		cfg:    cfg,
+		l:      l,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Verify checks signature, issuer, audience and expiry of the token.
+// errors.ErrInvalidToken is returned for rejected tokens, other errors mean the key set is not available.
+func (v *jwksVerifier) Verify(token string) (domain.Claims, error) {
+
+	parsed, err := jwt.ParseSigned(token)
+	if err != nil {
+		return domain.Claims{}, errors.ErrInvalidToken
+	}
+
+	if len(parsed.Headers) != 1 || !allowedAlgorithms[parsed.Headers[0].Algorithm] {
+		return domain.Claims{}, errors.ErrInvalidToken
+	}
+
+	keys, err := v.keys(parsed.Headers[0].KeyID)
+	if err != nil {
+		return domain.Claims{}, err
+	}
+
+	standard := jwt.Claims{}
+	custom := map[string]interface{}{}
+
+	verified := false
+	for _, key := range keys {
+		if err = parsed.Claims(key.Key, &standard, &custom); err == nil {
+			verified = true
+			break
+		}
+	}
+
+	if !verified {
+		v.l.Debugln("token signature could not be verified", err)
+		return domain.Claims{}, errors.ErrInvalidToken
+	}
+
+	if standard.Expiry == nil || standard.Subject == "" {
+		return domain.Claims{}, errors.ErrInvalidToken
+	}
+
+	expected := jwt.Expected{Issuer: v.cfg.OIDCIssuer, Audience: jwt.Audience{v.cfg.OIDCAudience}, Time: time.Now()}
+
+	if err = standard.ValidateWithLeeway(expected, jwt.DefaultLeeway); err != nil {
+		v.l.Debugln("token claims are not valid", err)
+		return domain.Claims{}, errors.ErrInvalidToken
+	}
+
+	return domain.Claims{
+		Subject: standard.Subject,
+		Name:    firstString(custom, "preferred_username", "email", "name", "sub"),
+		Roles:   claimValues(custom, v.cfg.OIDCRolesClaim),
+		Groups:  claimValues(custom, v.cfg.OIDCGroupsClaim),
+	}, nil
+}
+
+// keys returns signing keys with the key id, or every signing key if the token has no key id
+func (v *jwksVerifier) keys(kid string) ([]jose.JSONWebKey, error) {
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	now := time.Now()
+	keys := v.lookup(kid)
+
+	initial := v.fetchedAt.IsZero()
+	outdated := len(keys) == 0 || now.Sub(v.fetchedAt) > keySetTTL
+
+	if initial || (outdated && now.Sub(v.attemptedAt) > minRefreshInterval) {
+		v.attemptedAt = now
+		if err := v.refresh(); err != nil {
+			if initial {
+				return nil, err
+			}
+			// identity provider being down must not lock out users whose keys are known
+			v.l.Errorln("could not refresh key set, cached keys are used", err)
+		}
+		keys = v.lookup(kid)
+	}
+
+	return keys, nil
+}
+
+func (v *jwksVerifier) lookup(kid string) []jose.JSONWebKey {
+
+	var keys []jose.JSONWebKey
+	for _, key := range v.keySet.Keys {
+		if key.Use == "enc" || (kid != "" && key.KeyID != kid) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+func (v *jwksVerifier) refresh() error {
+
+	data, err := v.load()
+	if err != nil {
+		return err
+	}
+
+	keySet := jose.JSONWebKeySet{}
+	if err = json.Unmarshal(data, &keySet); err != nil {
+		return fmt.Errorf("could not decode key set: %w", err)
+	}
+
+	v.keySet = keySet
+	v.fetchedAt = time.Now()
+
+	return nil
+}
+
+func (v *jwksVerifier) load() ([]byte, error) {
+
+	if v.cfg.OIDCJWKSFile != "" {
+		return os.ReadFile(v.cfg.OIDCJWKSFile)
+	}
+
+	resp, err := v.client.Get(v.cfg.OIDCJWKSURL)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch key set: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not fetch key set: unexpected status %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, maxKeySetSize))
+}
+
+// claimValues reads string or list of strings claim, nested claims are addressed by dots, e.g. realm_access.roles
+func claimValues(claims map[string]interface{}, path string) []string {
+
+	if path == "" {
+		return nil
+	}
+
+	var value interface{} = claims
+	for _, name := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[name]
+	}
+
+	switch typed := value.(type) {
+	case string:
+		return []string{typed}
+	case []interface{}:
+		values := make([]string, 0, len(typed))
+		for _, item := range typed {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+func firstString(claims map[string]interface{}, names ...string) string {
+
+	for _, name := range names {
+		if s, ok := claims[name].(string); ok && s != "" {
+			return s
+		}
+	}
+
+	return ""
+}
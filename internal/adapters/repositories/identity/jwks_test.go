@@ -0,0 +1,245 @@
+package identity
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"secrets-operator/config"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type JWKSVerifierTestSuite struct {
+	suite.Suite
+	l         *zap.SugaredLogger
+	cfg       *config.Config
+	key       *rsa.PrivateKey
+	otherKey  *rsa.PrivateKey
+	keySetDir string
+}
+
+func TestSuiteJWKSVerifier(t *testing.T) {
+	suite.Run(t, new(JWKSVerifierTestSuite))
+}
+
+func (s *JWKSVerifierTestSuite) SetupSuite() {
+
+	var err error
+
+	s.key, err = rsa.GenerateKey(rand.Reader, 2048)
+	s.Require().NoError(err)
+
+	s.otherKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	s.Require().NoError(err)
+}
+
+func (s *JWKSVerifierTestSuite) SetupTest() {
+
+	s.l = zap.NewNop().Sugar()
+	s.keySetDir = s.T().TempDir()
+
+	// local key set instead of identity provider
+	s.cfg = &config.Config{
+		OIDCIssuer:      "https://idp.example.com",
+		OIDCAudience:    "secrets-operator",
+		OIDCJWKSFile:    filepath.Join(s.keySetDir, "jwks.json"),
+		OIDCRolesClaim:  "realm_access.roles",
+		OIDCGroupsClaim: "groups",
+	}
+
+	s.Require().NoError(os.WriteFile(s.cfg.OIDCJWKSFile, s.keySet(s.key, "k1"), 0600))
+}
+
+func (s *JWKSVerifierTestSuite) keySet(key *rsa.PrivateKey, kid string) []byte {
+
+	data, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{Key: &key.PublicKey, KeyID: kid, Algorithm: string(jose.RS256), Use: "sig"},
+	}})
+	s.Require().NoError(err)
+
+	return data
+}
+
+func (s *JWKSVerifierTestSuite) sign(algorithm jose.SignatureAlgorithm, key interface{}, kid string, claims jwt.Claims, custom map[string]interface{}) string {
+
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: algorithm, Key: jose.JSONWebKey{Key: key, KeyID: kid}},
+		(&jose.SignerOptions{}).WithType("JWT"),
+	)
+	s.Require().NoError(err)
+
+	token, err := jwt.Signed(signer).Claims(claims).Claims(custom).CompactSerialize()
+	s.Require().NoError(err)
+
+	return token
+}
+
+func (s *JWKSVerifierTestSuite) claims() jwt.Claims {
+	return jwt.Claims{
+		Issuer:   "https://idp.example.com",
+		Subject:  "u1",
+		Audience: jwt.Audience{"secrets-operator"},
+		Expiry:   jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		IssuedAt: jwt.NewNumericDate(time.Now()),
+	}
+}
+
+func (s *JWKSVerifierTestSuite) TestVerifyTableDriven() {
+
+	custom := map[string]interface{}{
+		"preferred_username": "jane",
+		"realm_access":       map[string]interface{}{"roles": []string{"security-champions"}},
+		"groups":             []string{"team-payments", "team-search"},
+	}
+
+	expired := s.claims()
+	expired.Expiry = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+
+	otherIssuer := s.claims()
+	otherIssuer.Issuer = "https://other.example.com"
+
+	otherAudience := s.claims()
+	otherAudience.Audience = jwt.Audience{"other-app"}
+
+	withoutExpiry := s.claims()
+	withoutExpiry.Expiry = nil
+
+	tests := []struct {
+		name       string
+		token      string
+		wantClaims domain.Claims
+		wantErr    error
+	}{
+		{
+			"valid token",
+			s.sign(jose.RS256, s.key, "k1", s.claims(), custom),
+			domain.Claims{Subject: "u1", Name: "jane", Roles: []string{"security-champions"}, Groups: []string{"team-payments", "team-search"}},
+			nil,
+		},
+		{
+			"token without key id is verified by every key",
+			s.sign(jose.RS256, s.key, "", s.claims(), nil),
+			domain.Claims{Subject: "u1", Name: "u1"},
+			nil,
+		},
+		{"expired token", s.sign(jose.RS256, s.key, "k1", expired, custom), domain.Claims{}, errors.ErrInvalidToken},
+		{"token of other issuer", s.sign(jose.RS256, s.key, "k1", otherIssuer, custom), domain.Claims{}, errors.ErrInvalidToken},
+		{"token of other audience", s.sign(jose.RS256, s.key, "k1", otherAudience, custom), domain.Claims{}, errors.ErrInvalidToken},
+		{"token without expiry", s.sign(jose.RS256, s.key, "k1", withoutExpiry, custom), domain.Claims{}, errors.ErrInvalidToken},
+		{"token signed by unknown key", s.sign(jose.RS256, s.otherKey, "k1", s.claims(), custom), domain.Claims{}, errors.ErrInvalidToken},
+		{"token of unknown key id", s.sign(jose.RS256, s.otherKey, "k2", s.claims(), custom), domain.Claims{}, errors.ErrInvalidToken},
+		{"symmetric algorithm", s.sign(jose.HS256, []byte("0123456789abcdef0123456789abcdef"), "k1", s.claims(), custom), domain.Claims{}, errors.ErrInvalidToken},
+		{"malformed token", "a.b.c", domain.Claims{}, errors.ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// arrange
+			sut, err := NewJWKSVerifier(s.cfg, s.l)
+			s.Require().NoError(err)
+
+			// act
+			claims, err := sut.Verify(tt.token)
+
+			// assert
+			assert.Equalf(s.T(), tt.wantErr, err, "assertion failed, wanted: %s, got: %s", tt.wantErr, err)
+			assert.Equal(s.T(), tt.wantClaims, claims)
+		})
+	}
+}
+
+func (s *JWKSVerifierTestSuite) TestNewJWKSVerifierRequiresIssuerAudienceAndKeySet() {
+
+	// arrange
+	withoutIssuer := *s.cfg
+	withoutIssuer.OIDCIssuer = ""
+
+	withoutAudience := *s.cfg
+	withoutAudience.OIDCAudience = ""
+
+	withoutKeySet := *s.cfg
+	withoutKeySet.OIDCJWKSFile = ""
+
+	// act
+	_, issuerErr := NewJWKSVerifier(&withoutIssuer, s.l)
+	_, keySetErr := NewJWKSVerifier(&withoutKeySet, s.l)
+	_, audienceErr := NewJWKSVerifier(&withoutAudience, s.l)
+
+	// assert
+	assert.Error(s.T(), issuerErr)
+	assert.EqualError(s.T(), audienceErr, "OIDC_AUDIENCE is required")
+	assert.Error(s.T(), keySetErr)
+}
+
+func (s *JWKSVerifierTestSuite) TestVerifyFetchesRotatedKeys() {
+
+	// arrange
+	var fetches int32
+	current := s.keySet(s.key, "k1")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		_, _ = w.Write(current)
+	}))
+	defer server.Close()
+
+	cfg := *s.cfg
+	cfg.OIDCJWKSFile = ""
+	cfg.OIDCJWKSURL = server.URL
+
+	sut, err := NewJWKSVerifier(&cfg, s.l)
+	s.Require().NoError(err)
+
+	_, err = sut.Verify(s.sign(jose.RS256, s.key, "k1", s.claims(), nil))
+	s.Require().NoError(err)
+
+	// act
+	current = s.keySet(s.otherKey, "k2")
+	rotated := s.sign(jose.RS256, s.otherKey, "k2", s.claims(), nil)
+
+	_, rateLimitedErr := sut.Verify(rotated)
+
+	sut.attemptedAt = time.Now().Add(-2 * minRefreshInterval)
+	_, refreshedErr := sut.Verify(rotated)
+
+	// assert
+	assert.Equal(s.T(), errors.ErrInvalidToken, rateLimitedErr, "unknown key ids must not refetch key set on every request")
+	assert.NoError(s.T(), refreshedErr)
+	assert.Equal(s.T(), int32(2), atomic.LoadInt32(&fetches))
+}
+
+func (s *JWKSVerifierTestSuite) TestVerifyKeySetUnavailable() {
+
+	// arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	cfg := *s.cfg
+	cfg.OIDCJWKSFile = ""
+	cfg.OIDCJWKSURL = server.URL
+
+	sut, err := NewJWKSVerifier(&cfg, s.l)
+	s.Require().NoError(err)
+
+	// act
+	_, err = sut.Verify(s.sign(jose.RS256, s.key, "k1", s.claims(), nil))
+
+	// assert
+	assert.Error(s.T(), err)
+	assert.NotEqual(s.T(), errors.ErrInvalidToken, err, "unavailable key set is not a client error")
+}
@@ -0,0 +1,331 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	htmlTemplate "html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"secrets-operator/config"
+	"secrets-operator/internal/core/domain"
+	"strings"
+	textTemplate "text/template"
+	"time"
+)
+
+const emailText = `Hard coded secrets were found in {{.RepoName}} ({{.RepoURL}}).
+{{.CommitAuthor}}'s commit included secret(ish) information. Please check.
+
+Pipeline: {{.PipelineURL}}
+Commit:   {{.CommitURL}}
+{{range .Findings}}
+- {{.RuleID}}: {{.Description}}
+  {{.File}}:{{.Line}}
+  {{.URL}}{{if .RemediationURL}}
+  How to remediate: {{.RemediationURL}}{{end}}
+{{end}}
+Secrets pushed to git history must be treated as leaked. Rotate them first, removing them from the code is not enough.
+`
+
+const emailHTML = `<html>
+<body>
+<h2>Hard coded secrets were found in <a href="{{.RepoURL}}">{{.RepoName}}</a></h2>
+<p>{{.CommitAuthor}}'s commit included secret(ish) information. Please check
+<a href="{{.PipelineURL}}">the pipeline</a> and <a href="{{.CommitURL}}">the commit</a>.</p>
+<table border="1" cellpadding="4" cellspacing="0">
+<tr><th>Rule</th><th>Description</th><th>Location</th><th>Remediation</th></tr>
+{{range .Findings}}<tr>
+<td>{{.RuleID}}</td>
+<td>{{.Description}}</td>
+<td><a href="{{.URL}}">{{.File}}:{{.Line}}</a></td>
+<td>{{if .RemediationURL}}<a href="{{.RemediationURL}}">How to remediate</a>{{end}}</td>
+</tr>
+{{end}}</table>
+<p>Secrets pushed to git history must be treated as leaked. Rotate them first, removing them from the code is not enough.</p>
+</body>
+</html>
+`
+
+var (
+	emailTextTemplate = textTemplate.Must(textTemplate.New("text").Parse(emailText))
+	emailHTMLTemplate = htmlTemplate.Must(htmlTemplate.New("html").Parse(emailHTML))
+)
+
+// emailData is rendered by email templates, secrets and matches are never included
+type emailData struct {
+	RepoName     string
+	RepoURL      string
+	CommitAuthor string
+	PipelineURL  string
+	CommitURL    string
+	Findings     []emailFinding
+}
+
+type emailFinding struct {
+	RuleID         string
+	Description    string
+	File           string
+	Line           int
+	URL            string
+	RemediationURL string
+}
+
+type emailNotifier struct {
+	cfg      *config.Config
+	l        *zap.SugaredLogger
+	settings config.EmailSettings
+}
+
+// NewEmailNotifier creates notifier mailing commit authors of findings, owners of the repository and CC recipients
+func NewEmailNotifier(cfg *config.Config, l *zap.SugaredLogger, settings config.EmailSettings) *emailNotifier {
+
+	return &emailNotifier{
+		cfg:      cfg,
+		l:        l,
+		settings: settings,
+	}
+}
+
+func (en emailNotifier) SendMessage(ctx context.Context, message domain.FindingsReport) error {
+
+	to, cc := en.recipients(message)
+	if len(to) == 0 {
+		en.l.Warnf("No email recipients for findings of repository %d", message.RepoID)
+		return nil
+	}
+
+	return en.send(ctx, message, to, cc)
+}
+
+// SendTo mails the message to address of the target only, e.g. a team mailing list given by a routing rule.
+// Emails are rendered by their own templates, template of the target is not used.
+func (en emailNotifier) SendTo(ctx context.Context, message domain.FindingsReport, target domain.NotificationTarget) error {
+
+	parsed, err := mail.ParseAddress(target.Destination)
+	if err != nil {
+		return err
+	}
+
+	return en.send(ctx, message, []string{parsed.Address}, nil)
+}
+
+func (en emailNotifier) send(ctx context.Context, message domain.FindingsReport, to []string, cc []string) error {
+
+	msg, err := en.compose(message, to, cc)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if en.cfg.SMTPUser != "" {
+		auth = smtp.PlainAuth("", en.cfg.SMTPUser, en.cfg.SMTPPass, en.cfg.SMTPHost)
+	}
+
+	return sendMail(ctx, en.cfg.SMTPHost, en.cfg.SMTPPort, auth, en.cfg.EmailFrom, append(to, cc...), msg)
+}
+
+// sendMail does what smtp.SendMail does on a connection which is closed once ctx is done, so a hanging server does
+// not keep the mail going after the notification gave up
+func sendMail(ctx context.Context, host string, port string, auth smtp.Auth, from string, to []string, msg []byte) error {
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.Close()
+		case <-done:
+		}
+	}()
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err = client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+
+	if auth != nil {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return errors.New("smtp server does not support AUTH")
+		}
+		if err = client.Auth(auth); err != nil {
+			return err
+		}
+	}
+
+	if err = client.Mail(from); err != nil {
+		return err
+	}
+	for _, address := range to {
+		if err = client.Rcpt(address); err != nil {
+			return err
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = writer.Write(msg); err != nil {
+		return err
+	}
+	if err = writer.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// recipients returns commit authors as recipients and owners with CC as copy recipients.
+// Owners become recipients if no finding has a valid author email.
+func (en emailNotifier) recipients(message domain.FindingsReport) ([]string, []string) {
+
+	seen := map[string]bool{}
+	collect := func(addresses ...string) []string {
+		var collected []string
+		for _, address := range addresses {
+			parsed, err := mail.ParseAddress(address)
+			if err != nil {
+				en.l.Debugln("skipping invalid email address", address)
+				continue
+			}
+			key := strings.ToLower(parsed.Address)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			collected = append(collected, parsed.Address)
+		}
+		return collected
+	}
+
+	var authors []string
+	for _, finding := range message.Findings {
+		authors = append(authors, finding.Email)
+	}
+
+	to := collect(authors...)
+	cc := collect(append(append([]string{}, en.settings.Owners[message.RepoID]...), en.settings.CC...)...)
+
+	if len(to) == 0 {
+		return cc, nil
+	}
+
+	return to, cc
+}
+
+func (en emailNotifier) compose(message domain.FindingsReport, to []string, cc []string) ([]byte, error) {
+
+	data := emailData{
+		RepoName:     message.RepoName,
+		RepoURL:      message.RepoURL,
+		CommitAuthor: message.CommitAuthor,
+		PipelineURL:  message.BuildPipelineURL(),
+		CommitURL:    message.BuildCommitURL(),
+	}
+
+	for _, finding := range message.Findings {
+		remediationURL, ok := en.settings.RemediationURLs[strings.ToLower(finding.RuleID)]
+		if !ok {
+			remediationURL = en.cfg.EmailRemediationURL
+		}
+
+		data.Findings = append(data.Findings, emailFinding{
+			RuleID:         finding.RuleID,
+			Description:    finding.Description,
+			File:           finding.File,
+			Line:           finding.StartLine,
+			URL:            message.BuildFindingURL(finding),
+			RemediationURL: remediationURL,
+		})
+	}
+
+	text := new(bytes.Buffer)
+	if err := emailTextTemplate.Execute(text, data); err != nil {
+		return nil, err
+	}
+
+	html := new(bytes.Buffer)
+	if err := emailHTMLTemplate.Execute(html, data); err != nil {
+		return nil, err
+	}
+
+	msg := new(bytes.Buffer)
+	body := multipart.NewWriter(msg)
+
+	headers := []string{
+		"From: " + (&mail.Address{Address: en.cfg.EmailFrom}).String(),
+		"To: " + joinAddresses(to),
+	}
+	if len(cc) > 0 {
+		headers = append(headers, "Cc: "+joinAddresses(cc))
+	}
+	headers = append(headers,
+		"Subject: "+mime.QEncoding.Encode("utf-8", fmt.Sprintf("Hard coded secrets found in %s", message.RepoName)),
+		"Date: "+time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: multipart/alternative; boundary="+body.Boundary(),
+	)
+	msg.WriteString(strings.Join(headers, "\r\n") + "\r\n\r\n")
+
+	for _, part := range []struct {
+		contentType string
+		content     []byte
+	}{
+		{"text/plain; charset=utf-8", text.Bytes()},
+		{"text/html; charset=utf-8", html.Bytes()},
+	} {
+		writer, err := body.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		encoder := quotedprintable.NewWriter(writer)
+		if _, err = encoder.Write(part.content); err != nil {
+			return nil, err
+		}
+		if err = encoder.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+
+	return msg.Bytes(), nil
+}
+
+func joinAddresses(addresses []string) string {
+
+	formatted := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		formatted = append(formatted, (&mail.Address{Address: address}).String())
+	}
+
+	return strings.Join(formatted, ", ")
+}
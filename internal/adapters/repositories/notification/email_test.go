@@ -0,0 +1,287 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"secrets-operator/config"
+	"secrets-operator/internal/core/domain"
+	"strings"
+	"testing"
+	"time"
+)
+
+type EmailNotifierTestSuite struct {
+	suite.Suite
+	l      *zap.SugaredLogger
+	report domain.FindingsReport
+}
+
+func TestSuiteEmailNotifier(t *testing.T) {
+	suite.Run(t, new(EmailNotifierTestSuite))
+}
+
+func (s *EmailNotifierTestSuite) SetupTest() {
+
+	s.l = zap.NewNop().Sugar()
+
+	finding := func(ruleId, email, file string, line int) domain.Finding {
+		return domain.Finding{
+			Description: "Found " + ruleId + " <in code>",
+			StartLine:   line,
+			Match:       "key = 'very secret value'",
+			Secret:      "very secret value",
+			File:        file,
+			Commit:      "a85af84d39a32da2c8eba1d88019079aeb0741b0",
+			Email:       email,
+			RuleID:      ruleId,
+			Fingerprint: ruleId,
+		}
+	}
+
+	s.report = domain.FindingsReport{
+		PipelineID:   2,
+		RepoName:     "testing repo",
+		RepoID:       444,
+		RepoURL:      "https://gitlab.com/testing-repo",
+		CommitAuthor: "test user",
+		CommitSHA:    "a85af84d39a32da2c8eba1d88019079aeb0741b0",
+		Timestamp:    time.Date(2022, 12, 3, 12, 48, 14, 0, time.UTC),
+		Findings: domain.Findings{
+			finding("aws-access-token", "author@example.com", "src/main.go", 12),
+			finding("generic-api-key", "Author@example.com", "config/app.yml", 3),
+			finding("private-key", "other@example.com", "deploy/key.pem", 1),
+		},
+	}
+}
+
+// smtpMessage is a message received by the SMTP stand-in
+type smtpMessage struct {
+	from string
+	to   []string
+	data []byte
+}
+
+// smtpServer starts local SMTP stand-in accepting a single session, rcptReply is the reply to RCPT commands
+func (s *EmailNotifierTestSuite) smtpServer(rcptReply string) (string, string, <-chan smtpMessage) {
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { _ = listener.Close() })
+
+	messages := make(chan smtpMessage, 1)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		tp := textproto.NewConn(conn)
+		_ = tp.PrintfLine("220 localhost ESMTP")
+
+		msg := smtpMessage{}
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+
+			command := strings.ToUpper(line)
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				_ = tp.PrintfLine("250 localhost")
+			case strings.HasPrefix(command, "MAIL FROM:"):
+				msg.from = strings.Trim(line[len("MAIL FROM:"):], "<> ")
+				_ = tp.PrintfLine("250 OK")
+			case strings.HasPrefix(command, "RCPT TO:"):
+				msg.to = append(msg.to, strings.Trim(line[len("RCPT TO:"):], "<> "))
+				_ = tp.PrintfLine(rcptReply)
+			case command == "DATA":
+				_ = tp.PrintfLine("354 go ahead")
+				msg.data, _ = tp.ReadDotBytes()
+				_ = tp.PrintfLine("250 OK")
+			case command == "QUIT":
+				_ = tp.PrintfLine("221 bye")
+				messages <- msg
+				return
+			default:
+				_ = tp.PrintfLine("250 OK")
+			}
+		}
+	}()
+
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	s.Require().NoError(err)
+
+	return host, port, messages
+}
+
+func (s *EmailNotifierTestSuite) config(host, port string) *config.Config {
+	return &config.Config{
+		SMTPHost:            host,
+		SMTPPort:            port,
+		EmailFrom:           "secrets-operator@example.com",
+		EmailRemediationURL: "https://wiki.example.com/leaked-secrets",
+	}
+}
+
+func (s *EmailNotifierTestSuite) TestEmailNotifier_SendMessage() {
+
+	// arrange
+	host, port, messages := s.smtpServer("250 OK")
+
+	settings := config.EmailSettings{
+		CC:              []string{"security@example.com"},
+		Owners:          map[int][]string{444: {"lead@example.com", "other@example.com"}, 555: {"stranger@example.com"}},
+		RemediationURLs: map[string]string{"aws-access-token": "https://wiki.example.com/rotate-aws-keys"},
+	}
+
+	sut := NewEmailNotifier(s.config(host, port), s.l, settings)
+
+	// act
+	err := sut.SendMessage(context.Background(), s.report)
+
+	// assert
+	s.Require().NoError(err)
+	received := <-messages
+
+	assert.Equal(s.T(), "secrets-operator@example.com", received.from)
+	assert.Equal(s.T(), []string{"author@example.com", "other@example.com", "lead@example.com", "security@example.com"}, received.to,
+		"authors are deduplicated case insensitively and owners of other repositories are not mailed")
+	assert.NotContains(s.T(), string(received.data), "very secret value", "secrets must never be mailed")
+
+	msg, err := mail.ReadMessage(bytes.NewReader(received.data))
+	s.Require().NoError(err)
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	s.Require().NoError(err)
+	assert.Equal(s.T(), "Hard coded secrets found in testing repo", subject)
+	assert.Equal(s.T(), "<author@example.com>, <other@example.com>", msg.Header.Get("To"))
+	assert.Equal(s.T(), "<lead@example.com>, <security@example.com>", msg.Header.Get("Cc"))
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	s.Require().NoError(err)
+	s.Require().Equal("multipart/alternative", mediaType)
+
+	parts := map[string]string{}
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		s.Require().NoError(err)
+		content, err := io.ReadAll(part)
+		s.Require().NoError(err)
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		parts[contentType] = string(content)
+	}
+
+	s.Require().Contains(parts, "text/plain")
+	s.Require().Contains(parts, "text/html")
+
+	text := parts["text/plain"]
+	assert.Contains(s.T(), text, "- aws-access-token: Found aws-access-token <in code>")
+	assert.Contains(s.T(), text, "src/main.go:12")
+	assert.Contains(s.T(), text, "https://gitlab.com/testing-repo/-/blob/a85af84d39a32da2c8eba1d88019079aeb0741b0/src/main.go#L12")
+	assert.Contains(s.T(), text, "How to remediate: https://wiki.example.com/rotate-aws-keys")
+	assert.Contains(s.T(), text, "How to remediate: https://wiki.example.com/leaked-secrets")
+	assert.Contains(s.T(), text, "https://gitlab.com/testing-repo/-/pipelines/2")
+
+	html := parts["text/html"]
+	assert.Contains(s.T(), html, "Found aws-access-token &lt;in code&gt;")
+	assert.Contains(s.T(), html, `<a href="https://gitlab.com/testing-repo/-/blob/a85af84d39a32da2c8eba1d88019079aeb0741b0/config/app.yml#L3">config/app.yml:3</a>`)
+	assert.Contains(s.T(), html, `<a href="https://wiki.example.com/rotate-aws-keys">How to remediate</a>`)
+}
+
+func (s *EmailNotifierTestSuite) TestEmailNotifier_SendMessageToOwnersWithoutAuthorEmails() {
+
+	// arrange
+	host, port, messages := s.smtpServer("250 OK")
+
+	for i := range s.report.Findings {
+		s.report.Findings[i].Email = "not an email"
+	}
+
+	sut := NewEmailNotifier(s.config(host, port), s.l, config.EmailSettings{Owners: map[int][]string{444: {"lead@example.com"}}})
+
+	// act
+	err := sut.SendMessage(context.Background(), s.report)
+
+	// assert
+	s.Require().NoError(err)
+	received := <-messages
+	assert.Equal(s.T(), []string{"lead@example.com"}, received.to)
+}
+
+func (s *EmailNotifierTestSuite) TestEmailNotifier_SendMessageWithoutRecipients() {
+
+	// arrange
+	for i := range s.report.Findings {
+		s.report.Findings[i].Email = ""
+	}
+
+	// nothing listens on the port, connecting would fail
+	sut := NewEmailNotifier(s.config("127.0.0.1", "1"), s.l, config.EmailSettings{})
+
+	// act
+	err := sut.SendMessage(context.Background(), s.report)
+
+	// assert
+	assert.NoError(s.T(), err)
+}
+
+func (s *EmailNotifierTestSuite) TestEmailNotifier_SendMessageRejected() {
+
+	// arrange
+	host, port, _ := s.smtpServer("550 mailbox unavailable")
+
+	sut := NewEmailNotifier(s.config(host, port), s.l, config.EmailSettings{})
+
+	// act
+	err := sut.SendMessage(context.Background(), s.report)
+
+	// assert
+	assert.Error(s.T(), err)
+}
+
+func (s *EmailNotifierTestSuite) TestEmailNotifier_SendToMailsOnlyGivenAddress() {
+
+	// arrange
+	host, port, messages := s.smtpServer("250 OK")
+
+	sut := NewEmailNotifier(s.config(host, port), s.l, config.EmailSettings{
+		Owners: map[int][]string{444: {"lead@example.com"}},
+		CC:     []string{"security@example.com"},
+	})
+
+	// act
+	err := sut.SendTo(context.Background(), s.report, domain.NotificationTarget{Type: domain.NotificationTargetEmail, Destination: "Payments Team <payments@example.com>"})
+
+	// assert
+	s.Require().NoError(err)
+	received := <-messages
+	assert.Equal(s.T(), []string{"payments@example.com"}, received.to, "authors, owners and CC recipients are not mailed")
+}
+
+func (s *EmailNotifierTestSuite) TestEmailNotifier_SendToInvalidAddress() {
+
+	// arrange
+	sut := NewEmailNotifier(s.config("127.0.0.1", "1"), s.l, config.EmailSettings{})
+
+	// act
+	err := sut.SendTo(context.Background(), s.report, domain.NotificationTarget{Type: domain.NotificationTargetEmail, Destination: "payments team"})
+
+	// assert
+	assert.Error(s.T(), err)
+}
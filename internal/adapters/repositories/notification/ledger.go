@@ -0,0 +1,93 @@
+package notification
+
+import (
+	"go.uber.org/zap"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/core/ports"
+	"time"
+)
+
+// ledger remembers findings announced to every channel and routed target, so each finding is announced to them once,
+// and queues findings of channels in digest modes
+type ledger struct {
+	l             *zap.SugaredLogger
+	announcements ports.AnnouncementRepository
+	digests       ports.DigestRepository
+	dailyHour     int
+}
+
+// NewLedger creates ledger queuing daily digests to be sent at dailyHour UTC
+func NewLedger(l *zap.SugaredLogger, announcements ports.AnnouncementRepository, digests ports.DigestRepository, dailyHour int) *ledger {
+
+	return &ledger{
+		l:             l,
+		announcements: announcements,
+		digests:       digests,
+		dailyHour:     dailyHour,
+	}
+}
+
+// unannounced narrows the report to findings not yet announced to the channel, false is returned if none are left
+func (lg ledger) unannounced(channel string, report domain.FindingsReport) (domain.FindingsReport, bool, error) {
+
+	fingerprints := make([]string, 0, len(report.Findings))
+	for _, finding := range report.Findings {
+		fingerprints = append(fingerprints, finding.Fingerprint)
+	}
+
+	announced, err := lg.announcements.GetAnnouncedFingerprints(channel, report.RepoID, fingerprints, "announcements")
+	if err != nil {
+		return domain.FindingsReport{}, false, err
+	}
+
+	if len(announced) == 0 {
+		return report, len(report.Findings) > 0, nil
+	}
+
+	skip := map[string]bool{}
+	for _, fingerprint := range announced {
+		skip[fingerprint] = true
+	}
+
+	findings := domain.Findings{}
+	for _, finding := range report.Findings {
+		if !skip[finding.Fingerprint] {
+			findings = append(findings, finding)
+		}
+	}
+
+	narrowed := report
+	narrowed.Findings = findings
+
+	return narrowed, len(findings) > 0, nil
+}
+
+// announce records findings of the report as announced to the channel
+func (lg ledger) announce(channel string, report domain.FindingsReport) error {
+
+	fingerprints := make([]string, 0, len(report.Findings))
+	for _, finding := range report.Findings {
+		fingerprints = append(fingerprints, finding.Fingerprint)
+	}
+
+	return lg.announcements.SaveAnnouncements(channel, report.RepoID, fingerprints, time.Now().UTC(), "announcements")
+}
+
+// queue stores findings of the report until the digest of the channel is due
+func (lg ledger) queue(rc recipient, report domain.FindingsReport) error {
+
+	now := time.Now().UTC()
+
+	entry := domain.DigestEntry{
+		ID:        domain.NewID(),
+		Channel:   rc.name,
+		Target:    rc.target,
+		Report:    report,
+		DueAt:     rc.mode.DigestDue(now, lg.dailyHour),
+		CreatedAt: now,
+	}
+
+	lg.l.Debugf("Notification for repository %d queued to %s digest of %s due at %s", report.RepoID, rc.mode, rc.name, entry.DueAt)
+
+	return lg.digests.SaveDigestEntry(entry, "digests")
+}
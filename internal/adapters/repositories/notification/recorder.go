@@ -0,0 +1,69 @@
+package notification
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"secrets-operator/config"
+	"secrets-operator/internal/core/domain"
+	"sync"
+)
+
+// recordingNotifier keeps sent messages in memory instead of delivering them anywhere.
+// It is meant for local development and tests, sent messages can be inspected with Messages.
+type recordingNotifier struct {
+	cfg      *config.Config
+	l        *zap.SugaredLogger
+	mu       sync.Mutex
+	messages []domain.FindingsReport
+	routed   map[string][]domain.FindingsReport
+}
+
+func NewRecordingNotifier(cfg *config.Config, l *zap.SugaredLogger) *recordingNotifier {
+
+	return &recordingNotifier{
+		cfg:    cfg,
+		l:      l,
+		routed: map[string][]domain.FindingsReport{},
+	}
+}
+
+func (rn *recordingNotifier) SendMessage(ctx context.Context, message domain.FindingsReport) error {
+
+	rn.mu.Lock()
+	defer rn.mu.Unlock()
+
+	rn.l.Infof("Recorded notification for repository %d, pipeline %d", message.RepoID, message.PipelineID)
+	rn.messages = append(rn.messages, message)
+
+	return nil
+}
+
+// Messages returns copy of all recorded messages, oldest first
+func (rn *recordingNotifier) Messages() []domain.FindingsReport {
+
+	rn.mu.Lock()
+	defer rn.mu.Unlock()
+
+	return append([]domain.FindingsReport(nil), rn.messages...)
+}
+
+// SendTo records the message under destination of the target instead of sending it there
+func (rn *recordingNotifier) SendTo(ctx context.Context, message domain.FindingsReport, target domain.NotificationTarget) error {
+
+	rn.mu.Lock()
+	defer rn.mu.Unlock()
+
+	rn.l.Infof("Recorded notification for repository %d, pipeline %d routed to %s", message.RepoID, message.PipelineID, target.Name())
+	rn.routed[target.Destination] = append(rn.routed[target.Destination], message)
+
+	return nil
+}
+
+// RoutedMessages returns copy of messages recorded under the destination, oldest first
+func (rn *recordingNotifier) RoutedMessages(destination string) []domain.FindingsReport {
+
+	rn.mu.Lock()
+	defer rn.mu.Unlock()
+
+	return append([]domain.FindingsReport(nil), rn.routed[destination]...)
+}
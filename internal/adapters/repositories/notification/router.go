@@ -0,0 +1,261 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/core/ports"
+	"secrets-operator/internal/errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Channel is a named notifier receiving findings matching its filter
+type Channel struct {
+	Name     string
+	Notifier ports.Notifier
+	Filter   domain.NotificationFilter
+	// Mode tells when the channel is notified, immediate when it is empty
+	Mode domain.NotificationMode
+}
+
+// router fans reports out to every channel and routed target concurrently,
+// a failing or hanging channel does not block the others
+type router struct {
+	l        *zap.SugaredLogger
+	channels []Channel
+	ledger   *ledger
+	routes   *routes
+	timeout  time.Duration
+}
+
+// NewRouter creates notifier delivering to the channels and to targets of routes, ledger and routes may be nil.
+// Without a ledger findings are not deduplicated and digest modes notify right away.
+// Timeout limits how long SendMessage waits for all of them, sends still running then are cancelled.
+func NewRouter(l *zap.SugaredLogger, timeout time.Duration, ledger *ledger, routes *routes, channels ...Channel) *router {
+
+	return &router{
+		l:        l,
+		channels: channels,
+		ledger:   ledger,
+		routes:   routes,
+		timeout:  timeout,
+	}
+}
+
+// recipient is a channel or routed target together with the part of the report it receives
+type recipient struct {
+	name string
+	mode domain.NotificationMode
+	// target is set for routed targets only
+	target *domain.NotificationTarget
+	report domain.FindingsReport
+	send   func(ctx context.Context, report domain.FindingsReport) error
+}
+
+// dispatch sends the message to a single channel or routed target
+type dispatch struct {
+	channel string
+	send    func(ctx context.Context) error
+}
+
+// delivery is the outcome of the dispatch at index, names of channels and routed targets may repeat
+type delivery struct {
+	index int
+	err   error
+}
+
+// SendMessage returns errors.ErrNotificationChannelsFailed naming channels that failed or did not finish in time
+func (r router) SendMessage(ctx context.Context, message domain.FindingsReport) error {
+
+	failed := r.sendToChannels(ctx, message, nil)
+	if len(failed) > 0 {
+		return fmt.Errorf("%w: %s", errors.ErrNotificationChannelsFailed, strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
+// SendToChannels delivers to the named channels, or to every channel if none are named, and returns sorted names
+// of channels that failed or did not finish in time, so retries skip channels which already received the message.
+// Routed targets are named "type:destination". Names of channels which are no longer configured are ignored.
+func (r router) SendToChannels(message domain.FindingsReport, channels []string) []string {
+
+	return r.sendToChannels(context.Background(), message, channels)
+}
+
+func (r router) sendToChannels(ctx context.Context, message domain.FindingsReport, channels []string) []string {
+
+	var recipients []recipient
+	var dispatches []dispatch
+
+	for _, channel := range r.channels {
+
+		if len(channels) > 0 && !containsString(channels, channel.Name) {
+			continue
+		}
+
+		filtered, ok := channel.Filter.Apply(message)
+		if !ok {
+			r.l.Debugf("Notification for repository %d filtered out of channel %s", message.RepoID, channel.Name)
+			continue
+		}
+
+		recipients = append(recipients, recipient{name: channel.Name, mode: channel.Mode, report: filtered, send: channel.Notifier.SendMessage})
+	}
+
+	if r.routes != nil {
+		routed, err := r.routes.recipients(message, channels)
+		if err != nil {
+			dispatches = append(dispatches, dispatch{channel: routesChannel, send: func(ctx context.Context) error {
+				return fmt.Errorf("could not load routing rules: %w", err)
+			}})
+		}
+		recipients = append(recipients, routed...)
+	}
+
+	for _, rc := range recipients {
+
+		if rc.mode == domain.NotificationSuppressed {
+			r.l.Debugf("Notification for repository %d suppressed for %s", message.RepoID, rc.name)
+			continue
+		}
+
+		dispatches = append(dispatches, r.dispatch(rc))
+	}
+
+	return r.deliver(ctx, dispatches)
+}
+
+// SendDigest delivers the digest to its channel or routed target right away, findings announced there meanwhile
+// are skipped. Digests of channels which are no longer configured are dropped.
+func (r router) SendDigest(digest domain.Digest) error {
+
+	rc := recipient{name: digest.Channel, mode: domain.NotificationImmediate, target: digest.Target, report: digest.Report}
+
+	if digest.Target == nil {
+		for _, channel := range r.channels {
+			if channel.Name == digest.Channel {
+				rc.send = channel.Notifier.SendMessage
+			}
+		}
+		if rc.send == nil {
+			r.l.Warnf("Digest of repository %d for channel %s is dropped, the channel is not configured", digest.Report.RepoID, digest.Channel)
+			return nil
+		}
+	} else {
+		if r.routes == nil {
+			return fmt.Errorf("%w: %s", errors.ErrNotificationChannelsFailed, digest.Channel)
+		}
+		rc.send = r.routes.sender(*digest.Target)
+	}
+
+	failed := r.deliver(context.Background(), []dispatch{r.dispatch(rc)})
+	if len(failed) > 0 {
+		return fmt.Errorf("%w: %s", errors.ErrNotificationChannelsFailed, strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
+// dispatch delivers findings of the recipient which were not announced to it yet, findings of recipients in digest
+// modes are queued instead
+func (r router) dispatch(rc recipient) dispatch {
+
+	return dispatch{channel: rc.name, send: func(ctx context.Context) error {
+
+		if r.ledger == nil {
+			return rc.send(ctx, rc.report)
+		}
+
+		report, ok, err := r.ledger.unannounced(rc.name, rc.report)
+		if err != nil {
+			return fmt.Errorf("could not check announced findings: %w", err)
+		}
+		if !ok {
+			r.l.Debugf("Findings of repository %d were already announced to %s", rc.report.RepoID, rc.name)
+			return nil
+		}
+
+		if rc.mode.IsDigest() {
+			return r.ledger.queue(rc, report)
+		}
+
+		if err = rc.send(ctx, report); err != nil {
+			return err
+		}
+
+		// the message is out, failing to record it must not send it again
+		if err = r.ledger.announce(rc.name, report); err != nil {
+			r.l.Errorf("Could not record findings announced to %s: %v", rc.name, err)
+		}
+
+		return nil
+	}}
+}
+
+// deliver runs the dispatches concurrently and returns sorted names of channels which failed or did not finish in time.
+// Sends still running at the timeout are cancelled, so they do not deliver besides the retry of their channel.
+func (r router) deliver(parent context.Context, dispatches []dispatch) []string {
+
+	ctx, cancel := context.WithTimeout(parent, r.timeout)
+	defer cancel()
+
+	deliveries := make(chan delivery, len(dispatches))
+	pending := map[int]bool{}
+
+	for i, d := range dispatches {
+
+		pending[i] = true
+
+		go func(i int, d dispatch) {
+			defer func() {
+				// a panicking channel must not take the server down
+				if recovered := recover(); recovered != nil {
+					deliveries <- delivery{index: i, err: fmt.Errorf("panic: %v", recovered)}
+				}
+			}()
+			deliveries <- delivery{index: i, err: d.send(ctx)}
+		}(i, d)
+	}
+
+	failed := map[string]bool{}
+
+	for len(pending) > 0 {
+		select {
+		case d := <-deliveries:
+			delete(pending, d.index)
+			if d.err != nil {
+				r.l.Errorf("Notification channel %s failed: %v", dispatches[d.index].channel, d.err)
+				failed[dispatches[d.index].channel] = true
+			}
+		case <-ctx.Done():
+			for i := range pending {
+				r.l.Errorf("Notification channel %s did not finish in %s", dispatches[i].channel, r.timeout)
+				failed[dispatches[i].channel] = true
+			}
+			pending = nil
+		}
+	}
+
+	var names []string
+	for channel := range failed {
+		names = append(names, channel)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func containsString(values []string, value string) bool {
+
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,429 @@
+package notification
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/core/ports/mocks"
+	"secrets-operator/internal/errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type RouterTestSuite struct {
+	suite.Suite
+	l      *zap.SugaredLogger
+	ctrl   *gomock.Controller
+	report domain.FindingsReport
+}
+
+func TestSuiteRouter(t *testing.T) {
+	suite.Run(t, new(RouterTestSuite))
+}
+
+func (s *RouterTestSuite) SetupTest() {
+
+	s.l = zap.NewNop().Sugar()
+
+	// setup gomock controller
+	s.ctrl = gomock.NewController(s.T())
+	defer s.ctrl.Finish()
+
+	s.report = domain.FindingsReport{
+		RepoID: 444,
+		Findings: domain.Findings{
+			{RuleID: "aws-access-token", Fingerprint: "f1"},
+			{RuleID: "generic-api-key", Fingerprint: "f2"},
+		},
+	}
+}
+
+// behaviour of a channel in tests
+type behaviour int
+
+const (
+	succeeds behaviour = iota
+	fails
+	hangs
+	panics
+)
+
+func (s *RouterTestSuite) TestRouter_SendMessageTableDriven() {
+
+	tests := []struct {
+		name          string
+		behaviours    map[string]behaviour
+		filters       map[string]domain.NotificationFilter
+		wantDelivered map[string][]string
+		wantErr       string
+	}{
+		{
+			"every channel is notified",
+			map[string]behaviour{"slack": succeeds, "teams": succeeds},
+			nil,
+			map[string][]string{"slack": {"f1", "f2"}, "teams": {"f1", "f2"}},
+			"",
+		},
+		{
+			"channels receive findings matching their filters",
+			map[string]behaviour{"slack": succeeds, "teams": succeeds, "email": succeeds},
+			map[string]domain.NotificationFilter{
+				"slack": {ExcludeRuleIDs: []string{"generic-api-key"}},
+				"teams": {RepoIDs: []int{555}},
+				"email": {RepoIDs: []int{444}, RuleIDs: []string{"generic-api-key"}},
+			},
+			map[string][]string{"slack": {"f1"}, "email": {"f2"}},
+			"",
+		},
+		{
+			"failing channel does not block the others",
+			map[string]behaviour{"slack": fails, "teams": succeeds},
+			nil,
+			map[string][]string{"teams": {"f1", "f2"}},
+			"notification channels failed: slack",
+		},
+		{
+			"hanging channel times out",
+			map[string]behaviour{"slack": hangs, "teams": succeeds},
+			nil,
+			map[string][]string{"teams": {"f1", "f2"}},
+			"notification channels failed: slack",
+		},
+		{
+			"panicking channel is isolated",
+			map[string]behaviour{"slack": panics, "teams": fails, "email": succeeds},
+			nil,
+			map[string][]string{"email": {"f1", "f2"}},
+			"notification channels failed: slack, teams",
+		},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// arrange
+			var mu sync.Mutex
+			delivered := map[string][]string{}
+			release := make(chan struct{})
+			defer close(release)
+
+			var channels []Channel
+			for name, b := range tt.behaviours {
+				name, b := name, b
+
+				mockNotifier := mocks.NewMockNotifier(s.ctrl)
+				mockNotifier.
+					EXPECT().
+					SendMessage(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, message domain.FindingsReport) error {
+						switch b {
+						case fails:
+							return assert.AnError
+						case hangs:
+							<-release
+							return nil
+						case panics:
+							panic("broken channel")
+						}
+
+						mu.Lock()
+						defer mu.Unlock()
+						for _, finding := range message.Findings {
+							delivered[name] = append(delivered[name], finding.Fingerprint)
+						}
+						return nil
+					}).
+					AnyTimes()
+
+				channels = append(channels, Channel{Name: name, Notifier: mockNotifier, Filter: tt.filters[name]})
+			}
+
+			sut := NewRouter(s.l, 100*time.Millisecond, nil, nil, channels...)
+
+			// act
+			err := sut.SendMessage(context.Background(), s.report)
+
+			// assert
+			if tt.wantErr == "" {
+				assert.NoError(s.T(), err)
+			} else {
+				assert.ErrorIs(s.T(), err, errors.ErrNotificationChannelsFailed)
+				assert.EqualError(s.T(), err, tt.wantErr)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			assert.Equal(s.T(), tt.wantDelivered, delivered)
+			assert.Len(s.T(), s.report.Findings, 2, "filters must not modify the original report")
+		})
+	}
+}
+
+func (s *RouterTestSuite) TestRouter_SendToChannels() {
+
+	// arrange
+	var mu sync.Mutex
+	delivered := map[string]int{}
+
+	var channels []Channel
+	for name, b := range map[string]behaviour{"slack": succeeds, "teams": fails, "email": succeeds} {
+		name, b := name, b
+
+		mockNotifier := mocks.NewMockNotifier(s.ctrl)
+		mockNotifier.
+			EXPECT().
+			SendMessage(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, message domain.FindingsReport) error {
+				mu.Lock()
+				defer mu.Unlock()
+				delivered[name]++
+				if b == fails {
+					return assert.AnError
+				}
+				return nil
+			}).
+			AnyTimes()
+
+		channels = append(channels, Channel{Name: name, Notifier: mockNotifier})
+	}
+
+	sut := NewRouter(s.l, 100*time.Millisecond, nil, nil, channels...)
+
+	// act
+	failedFirst := sut.SendToChannels(s.report, nil)
+	failedRetry := sut.SendToChannels(s.report, []string{"teams", "removed"})
+
+	// assert
+	assert.Equal(s.T(), []string{"teams"}, failedFirst)
+	assert.Equal(s.T(), []string{"teams"}, failedRetry)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(s.T(), map[string]int{"slack": 1, "teams": 2, "email": 1}, delivered, "retry skips channels which received the message")
+}
+
+func (s *RouterTestSuite) TestRouter_SendToChannelsSharingName() {
+
+	// arrange
+	quick := mocks.NewMockNotifier(s.ctrl)
+	quick.EXPECT().SendMessage(gomock.Any(), gomock.Any()).Return(nil)
+
+	slow := mocks.NewMockNotifier(s.ctrl)
+	slow.EXPECT().SendMessage(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, message domain.FindingsReport) error {
+		time.Sleep(20 * time.Millisecond)
+		return assert.AnError
+	})
+
+	sut := NewRouter(s.l, time.Second, nil, nil, Channel{Name: "slack", Notifier: quick}, Channel{Name: "slack", Notifier: slow})
+
+	// act
+	failed := sut.SendToChannels(s.report, nil)
+
+	// assert
+	assert.Equal(s.T(), []string{"slack"}, failed, "delivery of one does not hide failure of the other")
+}
+
+func (s *RouterTestSuite) TestRouter_SendToChannelsCancelsTimedOutSends() {
+
+	// arrange
+	cancelled := make(chan error, 1)
+
+	hanging := mocks.NewMockNotifier(s.ctrl)
+	hanging.EXPECT().SendMessage(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, message domain.FindingsReport) error {
+		<-ctx.Done()
+		cancelled <- ctx.Err()
+		return ctx.Err()
+	})
+
+	sut := NewRouter(s.l, 20*time.Millisecond, nil, nil, Channel{Name: "teams", Notifier: hanging})
+
+	// act
+	failed := sut.SendToChannels(s.report, nil)
+
+	// assert
+	assert.Equal(s.T(), []string{"teams"}, failed)
+	select {
+	case err := <-cancelled:
+		assert.ErrorIs(s.T(), err, context.DeadlineExceeded, "send is cancelled, so it cannot deliver besides the retry")
+	case <-time.After(time.Second):
+		s.T().Fatal("timed out send was not cancelled")
+	}
+}
+
+func (s *RouterTestSuite) TestRouter_SendToRoutedTargets() {
+
+	// arrange
+	rules := []domain.RoutingRule{
+		{ID: "a1", RepoIDs: []int{444}, RuleIDs: []string{"aws-access-token"}, Targets: []domain.NotificationTarget{
+			{Type: domain.NotificationTargetSlack, Destination: "C0123456"},
+			{Type: domain.NotificationTargetEmail, Destination: "payments@example.com"},
+		}},
+		{ID: "b2", RepoIDs: []int{555}, Targets: []domain.NotificationTarget{{Type: domain.NotificationTargetSlack, Destination: "C0999999"}}},
+		{ID: "c3", Targets: []domain.NotificationTarget{{Type: domain.NotificationTargetWebhook, Destination: "https://hooks.example.com"}}},
+	}
+
+	mockRoutingRuleRepository := mocks.NewMockRoutingRuleRepository(s.ctrl)
+	mockRoutingRuleRepository.EXPECT().GetRoutingRules("routingrules").Return(rules, nil).Times(2)
+
+	recorder := NewRecordingNotifier(nil, s.l)
+
+	mockNotifier := mocks.NewMockNotifier(s.ctrl)
+	mockNotifier.EXPECT().SendMessage(gomock.Any(), s.report).Return(nil)
+
+	routes := NewRoutes(s.l, mockRoutingRuleRepository, map[domain.NotificationTargetType]TargetNotifier{
+		domain.NotificationTargetSlack: recorder,
+		domain.NotificationTargetEmail: recorder,
+	})
+
+	sut := NewRouter(s.l, 100*time.Millisecond, nil, routes, Channel{Name: "teams", Notifier: mockNotifier})
+
+	// act
+	failedFirst := sut.SendToChannels(s.report, nil)
+	failedRetry := sut.SendToChannels(s.report, failedFirst)
+
+	// assert
+	assert.Equal(s.T(), []string{"webhook:https://hooks.example.com"}, failedFirst, "targets without a notifier fail")
+	assert.Equal(s.T(), []string{"webhook:https://hooks.example.com"}, failedRetry)
+
+	slack := recorder.RoutedMessages("C0123456")
+	if assert.Len(s.T(), slack, 1, "retry skips targets which received the message") {
+		assert.Equal(s.T(), domain.Findings{s.report.Findings[0]}, slack[0].Findings)
+	}
+	assert.Len(s.T(), recorder.RoutedMessages("payments@example.com"), 1)
+	assert.Empty(s.T(), recorder.RoutedMessages("C0999999"))
+}
+
+func (s *RouterTestSuite) TestRouter_RetriesRoutesWhenRulesCouldNotBeLoaded() {
+
+	// arrange
+	rules := []domain.RoutingRule{
+		{ID: "a1", Targets: []domain.NotificationTarget{{Type: domain.NotificationTargetSlack, Destination: "C0123456"}}},
+	}
+
+	mockRoutingRuleRepository := mocks.NewMockRoutingRuleRepository(s.ctrl)
+	gomock.InOrder(
+		mockRoutingRuleRepository.EXPECT().GetRoutingRules("routingrules").Return(nil, assert.AnError),
+		mockRoutingRuleRepository.EXPECT().GetRoutingRules("routingrules").Return(rules, nil),
+	)
+
+	recorder := NewRecordingNotifier(nil, s.l)
+
+	mockNotifier := mocks.NewMockNotifier(s.ctrl)
+	mockNotifier.EXPECT().SendMessage(gomock.Any(), s.report).Return(nil).Times(1)
+
+	routes := NewRoutes(s.l, mockRoutingRuleRepository, map[domain.NotificationTargetType]TargetNotifier{
+		domain.NotificationTargetSlack: recorder,
+	})
+
+	sut := NewRouter(s.l, 100*time.Millisecond, nil, routes, Channel{Name: "teams", Notifier: mockNotifier})
+
+	// act
+	failedFirst := sut.SendToChannels(s.report, nil)
+	failedRetry := sut.SendToChannels(s.report, failedFirst)
+	failedAgain := sut.SendToChannels(s.report, []string{"teams-old"})
+
+	// assert
+	assert.Equal(s.T(), []string{"routes"}, failedFirst)
+	assert.Empty(s.T(), failedRetry)
+	assert.Empty(s.T(), failedAgain, "rules are not loaded when only default channels are retried")
+	assert.Len(s.T(), recorder.RoutedMessages("C0123456"), 1)
+}
+
+func (s *RouterTestSuite) TestRouter_NotificationModes() {
+
+	// arrange
+	mockAnnouncementRepository := mocks.NewMockAnnouncementRepository(s.ctrl)
+	mockAnnouncementRepository.EXPECT().GetAnnouncedFingerprints("slack", 444, []string{"f1", "f2"}, "announcements").Return([]string{"f1"}, nil)
+	mockAnnouncementRepository.EXPECT().SaveAnnouncements("slack", 444, []string{"f2"}, gomock.Any(), "announcements").Return(nil)
+	mockAnnouncementRepository.EXPECT().GetAnnouncedFingerprints("teams", 444, []string{"f1", "f2"}, "announcements").Return([]string{}, nil)
+
+	var queued domain.DigestEntry
+	mockDigestRepository := mocks.NewMockDigestRepository(s.ctrl)
+	mockDigestRepository.EXPECT().SaveDigestEntry(gomock.Any(), "digests").DoAndReturn(func(entry domain.DigestEntry, collectionName string) error {
+		queued = entry
+		return nil
+	})
+
+	slack := mocks.NewMockNotifier(s.ctrl)
+	slack.EXPECT().SendMessage(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, message domain.FindingsReport) error {
+		assert.Equal(s.T(), domain.Findings{s.report.Findings[1]}, message.Findings, "announced findings are skipped")
+		return nil
+	})
+	teams := mocks.NewMockNotifier(s.ctrl)
+	email := mocks.NewMockNotifier(s.ctrl)
+
+	sut := NewRouter(s.l, 100*time.Millisecond, NewLedger(s.l, mockAnnouncementRepository, mockDigestRepository, 9), nil,
+		Channel{Name: "slack", Notifier: slack},
+		Channel{Name: "teams", Notifier: teams, Mode: domain.NotificationDaily},
+		Channel{Name: "email", Notifier: email, Mode: domain.NotificationSuppressed},
+	)
+
+	// act
+	failed := sut.SendToChannels(s.report, nil)
+
+	// assert
+	assert.Empty(s.T(), failed)
+	assert.Equal(s.T(), "teams", queued.Channel)
+	assert.Nil(s.T(), queued.Target)
+	assert.Equal(s.T(), s.report.Findings, queued.Report.Findings)
+	assert.Equal(s.T(), 9, queued.DueAt.Hour(), "daily digest is due at the configured hour")
+	assert.True(s.T(), queued.DueAt.After(queued.CreatedAt))
+}
+
+func (s *RouterTestSuite) TestRouter_FailsWhenAnnouncementsCouldNotBeChecked() {
+
+	// arrange
+	mockAnnouncementRepository := mocks.NewMockAnnouncementRepository(s.ctrl)
+	mockAnnouncementRepository.EXPECT().GetAnnouncedFingerprints("slack", 444, gomock.Any(), "announcements").Return(nil, assert.AnError)
+
+	sut := NewRouter(s.l, 100*time.Millisecond, NewLedger(s.l, mockAnnouncementRepository, nil, 9), nil,
+		Channel{Name: "slack", Notifier: mocks.NewMockNotifier(s.ctrl)},
+	)
+
+	// act
+	failed := sut.SendToChannels(s.report, nil)
+
+	// assert
+	assert.Equal(s.T(), []string{"slack"}, failed, "channel is retried instead of announcing findings twice")
+}
+
+func (s *RouterTestSuite) TestRouter_SendDigest() {
+
+	// arrange
+	target := domain.NotificationTarget{Type: domain.NotificationTargetSlack, Destination: "C0123456", Mode: domain.NotificationHourly}
+
+	mockAnnouncementRepository := mocks.NewMockAnnouncementRepository(s.ctrl)
+	mockAnnouncementRepository.EXPECT().GetAnnouncedFingerprints("teams", 444, []string{"f1", "f2"}, "announcements").Return([]string{"f2"}, nil)
+	mockAnnouncementRepository.EXPECT().SaveAnnouncements("teams", 444, []string{"f1"}, gomock.Any(), "announcements").Return(assert.AnError)
+	mockAnnouncementRepository.EXPECT().GetAnnouncedFingerprints(target.Name(), 444, []string{"f1", "f2"}, "announcements").Return(nil, nil)
+	mockAnnouncementRepository.EXPECT().SaveAnnouncements(target.Name(), 444, []string{"f1", "f2"}, gomock.Any(), "announcements").Return(nil)
+
+	teams := mocks.NewMockNotifier(s.ctrl)
+	teams.EXPECT().SendMessage(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, message domain.FindingsReport) error {
+		assert.Equal(s.T(), domain.Findings{s.report.Findings[0]}, message.Findings)
+		return nil
+	})
+
+	recorder := NewRecordingNotifier(nil, s.l)
+	routes := NewRoutes(s.l, nil, map[domain.NotificationTargetType]TargetNotifier{domain.NotificationTargetSlack: recorder})
+
+	sut := NewRouter(s.l, 100*time.Millisecond, NewLedger(s.l, mockAnnouncementRepository, nil, 9), routes,
+		Channel{Name: "teams", Notifier: teams, Mode: domain.NotificationDaily},
+	)
+
+	// act
+	errChannel := sut.SendDigest(domain.Digest{Channel: "teams", Report: s.report})
+	errTarget := sut.SendDigest(domain.Digest{Channel: target.Name(), Target: &target, Report: s.report})
+	errRemoved := sut.SendDigest(domain.Digest{Channel: "email", Report: s.report})
+
+	// assert
+	assert.NoError(s.T(), errChannel, "digest which was sent is not failed by the ledger")
+	assert.NoError(s.T(), errTarget)
+	assert.NoError(s.T(), errRemoved, "digests of removed channels are dropped")
+	assert.Len(s.T(), recorder.RoutedMessages("C0123456"), 1, "digests are sent right away regardless of the mode")
+}
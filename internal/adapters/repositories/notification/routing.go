@@ -0,0 +1,93 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/core/ports"
+	"strings"
+)
+
+// routesChannel names the failed channel when routing rules could not be loaded, retrying it evaluates all rules again
+const routesChannel = "routes"
+
+// TargetNotifier delivers to a target given by a routing rule, e.g. Slack channel or email address
+type TargetNotifier interface {
+	SendTo(ctx context.Context, message domain.FindingsReport, target domain.NotificationTarget) error
+}
+
+// routes evaluates stored routing rules for every report, routed targets are notified besides the default channels
+type routes struct {
+	l          *zap.SugaredLogger
+	repository ports.RoutingRuleRepository
+	notifiers  map[domain.NotificationTargetType]TargetNotifier
+}
+
+// NewRoutes creates routes delivering with notifiers by target type, targets of types without a notifier fail
+func NewRoutes(l *zap.SugaredLogger, repository ports.RoutingRuleRepository, notifiers map[domain.NotificationTargetType]TargetNotifier) *routes {
+
+	return &routes{
+		l:          l,
+		repository: repository,
+		notifiers:  notifiers,
+	}
+}
+
+// recipients returns recipient for every target the report is routed to and named in channels, or for every target
+// if channels are empty or name routesChannel
+func (rs routes) recipients(message domain.FindingsReport, channels []string) ([]recipient, error) {
+
+	if len(channels) > 0 && !containsString(channels, routesChannel) && !namesTarget(channels) {
+		return nil, nil
+	}
+
+	rules, err := rs.repository.GetRoutingRules("routingrules")
+	if err != nil {
+		return nil, err
+	}
+
+	var recipients []recipient
+
+	for _, routed := range domain.RoutingRules(rules).Route(message) {
+
+		target := routed.Target
+		name := target.Name()
+		if len(channels) > 0 && !containsString(channels, routesChannel) && !containsString(channels, name) {
+			continue
+		}
+
+		rs.l.Debugf("Notification for repository %d routed to %s", message.RepoID, name)
+
+		recipients = append(recipients, recipient{name: name, mode: target.Mode, target: &target, report: routed.Report, send: rs.sender(target)})
+	}
+
+	return recipients, nil
+}
+
+// sender delivers to the target with notifier of its type, targets of types without a notifier fail
+func (rs routes) sender(target domain.NotificationTarget) func(ctx context.Context, report domain.FindingsReport) error {
+
+	notifier, ok := rs.notifiers[target.Type]
+	if !ok {
+		return func(ctx context.Context, report domain.FindingsReport) error {
+			return fmt.Errorf("no notifier for %s targets is configured", target.Type)
+		}
+	}
+
+	return func(ctx context.Context, report domain.FindingsReport) error {
+		return notifier.SendTo(ctx, report, target)
+	}
+}
+
+// namesTarget tells whether any of the channels is a routed target, their names are "type:destination"
+func namesTarget(channels []string) bool {
+
+	for _, channel := range channels {
+		if strings.Contains(channel, ":") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,60 +1,121 @@
 package notification
 
 import (
+	"context"
 	"fmt"
 	"github.com/slack-go/slack"
 	"go.uber.org/zap"
 	"secrets-operator/config"
 	"secrets-operator/internal/core/domain"
+	"strings"
+	"time"
+)
+
+const (
+	// slackActionFindings limits findings with triage buttons, Slack allows 50 blocks per message
+	slackActionFindings = 10
+	// slackSectionLength is the longest text of a Slack section block
+	slackSectionLength = 3000
+	// slackButtonValueLength is the longest value of a Slack button
+	slackButtonValueLength = 2000
 )
 
 type slackNotifier struct {
-	cfg    *config.Config
-	l      *zap.SugaredLogger
-	client *slack.Client
+	cfg       *config.Config
+	l         *zap.SugaredLogger
+	client    *slack.Client
+	templates domain.NotificationTemplates
+	users     *slackUsers
 }
 
-func NewSlackNotifier(cfg *config.Config, l *zap.SugaredLogger) *slackNotifier {
+func NewSlackNotifier(cfg *config.Config, l *zap.SugaredLogger, templates domain.NotificationTemplates) *slackNotifier {
+
+	client := slack.New(cfg.SlackAuthToken, slack.OptionDebug(cfg.SlackDebugEnabled))
 
 	return &slackNotifier{
-		cfg:    cfg,
-		l:      l,
-		client: slack.New(cfg.SlackAuthToken, slack.OptionDebug(cfg.SlackDebugEnabled)),
+		cfg:       cfg,
+		l:         l,
+		client:    client,
+		templates: templates,
+		users:     newSlackUsers(time.Duration(cfg.SlackUserCacheMinutes)*time.Minute, client.GetUserByEmailContext),
 	}
 }
 
-func (sl slackNotifier) SendMessage(message domain.FindingsReport) error {
+// SendMessage posts the message to the Slack channel. With SlackDMAuthors commit authors get their findings in a
+// direct message instead, the channel only gets findings of authors who could not be messaged.
+func (sl slackNotifier) SendMessage(ctx context.Context, message domain.FindingsReport) error {
 
-	attachment := slack.Attachment{
-		Title: fmt.Sprintf("Found new hard coded secrets in %s 😐", message.RepoName),
-		Color: "#FF0000",
-		Text:  fmt.Sprintf("%s's commit included secret(ish) information. Please check", message.CommitAuthor),
-		Fields: []slack.AttachmentField{
-			{
-				Title: "Repository",
-				Value: message.RepoURL,
-			},
-			{
-				Title: "Pipeline",
-				Value: fmt.Sprintf("%s", message.BuildPipelineURL()),
-			},
-			{
-				Title: "Commit",
-				Value: fmt.Sprintf("%s", message.BuildCommitURL()),
-			},
-			{
-				Title: "How many findings found?",
-				Value: fmt.Sprintf("%d", len(message.Findings)),
-			},
-			{
-				Title: "Date",
-				Value: message.Timestamp.String(),
-			},
-		},
+	channel := domain.NotificationTarget{Type: domain.NotificationTargetSlack, Destination: sl.cfg.SlackChannelId}
+
+	if !sl.cfg.SlackDMAuthors {
+		return sl.SendTo(ctx, message, channel)
+	}
+
+	undelivered := sl.sendToAuthors(ctx, message)
+	if len(undelivered.Findings) == 0 {
+		return nil
+	}
+
+	return sl.SendTo(ctx, undelivered, channel)
+}
+
+// sendToAuthors messages every commit author found by email in Slack with their findings, the returned report keeps
+// findings which were not delivered
+func (sl slackNotifier) sendToAuthors(ctx context.Context, message domain.FindingsReport) domain.FindingsReport {
+
+	var emails []string
+	byEmail := map[string]domain.Findings{}
+	for _, finding := range message.Findings {
+		email := strings.ToLower(strings.TrimSpace(finding.Email))
+		if _, ok := byEmail[email]; !ok {
+			emails = append(emails, email)
+		}
+		byEmail[email] = append(byEmail[email], finding)
+	}
+
+	failed := map[string]bool{}
+	for _, email := range emails {
+		if err := sl.sendToAuthor(ctx, message, email, byEmail[email]); err != nil {
+			sl.l.Infof("Could not message author of %d findings in repository %d directly, they go to the channel: %v", len(byEmail[email]), message.RepoID, err)
+			failed[email] = true
+		}
+	}
+
+	undelivered := message
+	undelivered.Findings = domain.Findings{}
+	for _, finding := range message.Findings {
+		if failed[strings.ToLower(strings.TrimSpace(finding.Email))] {
+			undelivered.Findings = append(undelivered.Findings, finding)
+		}
+	}
+
+	return undelivered
+}
+
+func (sl slackNotifier) sendToAuthor(ctx context.Context, message domain.FindingsReport, email string, findings domain.Findings) error {
+
+	userId, err := sl.users.id(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	message.Findings = findings
+
+	// posting to a user ID delivers the message to the direct message channel of the app with the user
+	return sl.SendTo(ctx, message, domain.NotificationTarget{Type: domain.NotificationTargetSlack, Destination: userId, Template: domain.TemplateSlackDM})
+}
+
+// SendTo posts the message to Slack channel given by its ID, rendered by template of the target
+func (sl slackNotifier) SendTo(ctx context.Context, message domain.FindingsReport, target domain.NotificationTarget) error {
+
+	attachment, err := sl.attachment(message, target.Template)
+	if err != nil {
+		return err
 	}
 
-	_, _, err := sl.client.PostMessage(
-		sl.cfg.SlackChannelId,
+	_, _, err = sl.client.PostMessageContext(
+		ctx,
+		target.Destination,
 		slack.MsgOptionAttachments(attachment),
 	)
 	if err != nil {
@@ -63,3 +124,82 @@ func (sl slackNotifier) SendMessage(message domain.FindingsReport) error {
 
 	return nil
 }
+
+func (sl slackNotifier) attachment(message domain.FindingsReport, templateName string) (slack.Attachment, error) {
+
+	if templateName == "" {
+		templateName = domain.TemplateSlack
+	}
+
+	text, err := sl.templates.Render(templateName, message)
+	if err != nil {
+		return slack.Attachment{}, err
+	}
+
+	attachment := slack.Attachment{
+		Color:    "#FF0000",
+		Fallback: fmt.Sprintf("Found new hard coded secrets in %s", message.RepoName),
+	}
+
+	if !sl.cfg.SlackActionsEnabled {
+		attachment.Text = text
+		attachment.MarkdownIn = []string{"text"}
+		return attachment, nil
+	}
+
+	// text of attachments with blocks is not shown, so it goes to the first block
+	attachment.Blocks = slack.Blocks{BlockSet: append(
+		[]slack.Block{slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, truncate(text, slackSectionLength), false, false), nil, nil)},
+		triageBlocks(message)...,
+	)}
+
+	return attachment, nil
+}
+
+// triageBlocks describes findings of the message, each followed by buttons triaging it. Block IDs are "finding-<index>",
+// so the interactivity endpoint replaces buttons of the finding with the outcome.
+func triageBlocks(message domain.FindingsReport) []slack.Block {
+
+	var blocks []slack.Block
+
+	for i, finding := range message.Findings {
+
+		if i == slackActionFindings {
+			more := fmt.Sprintf("%d more findings can be triaged in the web UI", len(message.Findings)-slackActionFindings)
+			blocks = append(blocks, slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType, more, false, false)))
+			break
+		}
+
+		description := fmt.Sprintf("*%s* `%s:%d`", finding.RuleID, finding.File, finding.StartLine)
+		blocks = append(blocks, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, truncate(description, slackSectionLength), false, false), nil, nil))
+
+		value := domain.TriageValue(message.RepoID, finding.Fingerprint)
+		if len(value) > slackButtonValueLength {
+			continue
+		}
+
+		var buttons []slack.BlockElement
+		for _, action := range domain.TriageActions {
+			button := slack.NewButtonBlockElement(string(action), value, slack.NewTextBlockObject(slack.PlainTextType, action.Label(), false, false))
+			if action == domain.TriageAssign {
+				button = button.WithStyle(slack.StylePrimary)
+			}
+			buttons = append(buttons, button)
+		}
+
+		blocks = append(blocks, slack.NewActionBlock(fmt.Sprintf("finding-%d", i), buttons...))
+	}
+
+	return blocks
+}
+
+// truncate cuts text to at most max runes
+func truncate(text string, max int) string {
+
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+
+	return string(runes[:max-1]) + "…"
+}
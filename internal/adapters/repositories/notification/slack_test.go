@@ -1 +1,200 @@
 package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"secrets-operator/config"
+	"secrets-operator/internal/core/domain"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type SlackNotifierTestSuite struct {
+	suite.Suite
+	l      *zap.SugaredLogger
+	report domain.FindingsReport
+}
+
+func TestSuiteSlackNotifier(t *testing.T) {
+	suite.Run(t, new(SlackNotifierTestSuite))
+}
+
+func (s *SlackNotifierTestSuite) SetupTest() {
+
+	s.l = zap.NewNop().Sugar()
+
+	s.report = domain.FindingsReport{
+		PipelineID:   2,
+		RepoName:     "testing repo",
+		RepoID:       444,
+		RepoURL:      "https://gitlab.com/testing-repo",
+		CommitAuthor: "test user",
+		CommitSHA:    "a85af84d39a32da2c8eba1d88019079aeb0741b0",
+		Timestamp:    time.Date(2022, 12, 3, 12, 48, 14, 0, time.UTC),
+		Findings:     domain.Findings{{Fingerprint: "f1"}, {Fingerprint: "f2"}},
+	}
+}
+
+func (s *SlackNotifierTestSuite) TestSlackNotifier_AttachmentTableDriven() {
+
+	templates, err := domain.NewNotificationTemplates(map[string]string{
+		"slack":    "{{len .Findings}} {{plural (len .Findings) \"secret\" \"secrets\"}} in {{.RepoName}}",
+		"payments": "<{{pipelineURL .}}|{{.RepoName}}> needs payments team",
+	})
+	s.Require().NoError(err)
+
+	tests := []struct {
+		name     string
+		template string
+		wantText string
+		wantErr  bool
+	}{
+		{"default template of the channel", "", "2 secrets in testing repo", false},
+		{"template of the target", "payments", "<https://gitlab.com/testing-repo/-/pipelines/2|testing repo> needs payments team", false},
+		{"unknown template", "missing", "", true},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// arrange
+			sut := NewSlackNotifier(&config.Config{}, s.l, templates)
+
+			// act
+			attachment, err := sut.attachment(s.report, tt.template)
+
+			// assert
+			assert.Equal(s.T(), tt.wantErr, err != nil, err)
+			assert.Equal(s.T(), tt.wantText, attachment.Text)
+			if !tt.wantErr {
+				assert.Equal(s.T(), "Found new hard coded secrets in testing repo", attachment.Fallback)
+				assert.Equal(s.T(), []string{"text"}, attachment.MarkdownIn)
+			}
+		})
+	}
+}
+
+func (s *SlackNotifierTestSuite) TestSlackNotifier_AttachmentWithTriageButtons() {
+
+	// arrange
+	templates, err := domain.NewNotificationTemplates(nil)
+	s.Require().NoError(err)
+
+	for i := 3; i <= 12; i++ {
+		s.report.Findings = append(s.report.Findings, domain.Finding{Fingerprint: "f" + strconv.Itoa(i)})
+	}
+	s.report.Findings[0] = domain.Finding{RuleID: "aws-access-token", File: "src/config.go", StartLine: 12, Fingerprint: "a85af84d:src/config.go:aws-access-token:12"}
+
+	sut := NewSlackNotifier(&config.Config{SlackActionsEnabled: true}, s.l, templates)
+
+	// act
+	attachment, err := sut.attachment(s.report, "")
+
+	// assert
+	s.Require().NoError(err)
+	assert.Empty(s.T(), attachment.Text, "text is the first block")
+
+	blocks := attachment.Blocks.BlockSet
+	s.Require().Len(blocks, 1+2*10+1, "buttons of the first 10 findings and a note about the rest")
+
+	text, ok := blocks[0].(*slack.SectionBlock)
+	s.Require().True(ok)
+	assert.Contains(s.T(), text.Text.Text, "Found new hard coded secrets in testing repo")
+
+	description, ok := blocks[1].(*slack.SectionBlock)
+	s.Require().True(ok)
+	assert.Equal(s.T(), "*aws-access-token* `src/config.go:12`", description.Text.Text)
+
+	actions, ok := blocks[2].(*slack.ActionBlock)
+	s.Require().True(ok)
+	assert.Equal(s.T(), "finding-0", actions.BlockID)
+	s.Require().Len(actions.Elements.ElementSet, 3)
+
+	var actionIds []string
+	for _, element := range actions.Elements.ElementSet {
+		button := element.(*slack.ButtonBlockElement)
+		actionIds = append(actionIds, button.ActionID)
+		assert.Equal(s.T(), "444:a85af84d:src/config.go:aws-access-token:12", button.Value)
+	}
+	assert.Equal(s.T(), []string{"triage_false_positive", "triage_revoked", "triage_assign"}, actionIds)
+
+	more, ok := blocks[21].(*slack.ContextBlock)
+	s.Require().True(ok)
+	assert.Equal(s.T(), "2 more findings can be triaged in the web UI", more.ContextElements.Elements[0].(*slack.TextBlockObject).Text)
+}
+
+func (s *SlackNotifierTestSuite) TestSlackNotifier_SendMessageToAuthors() {
+
+	// arrange
+	templates, err := domain.NewNotificationTemplates(nil)
+	s.Require().NoError(err)
+
+	users := map[string]string{"jane@example.com": "U0JANE", "john@example.com": "U0JOHN"}
+
+	var lookups []string
+	posted := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/users.lookupByEmail":
+			lookups = append(lookups, r.Form.Get("email"))
+			if id, ok := users[r.Form.Get("email")]; ok {
+				_, _ = fmt.Fprintf(w, `{"ok": true, "user": {"id": %q}}`, id)
+				return
+			}
+			_, _ = w.Write([]byte(`{"ok": false, "error": "users_not_found"}`))
+		case "/chat.postMessage":
+			channel := r.Form.Get("channel")
+			if channel == "U0JOHN" {
+				_, _ = w.Write([]byte(`{"ok": false, "error": "cannot_dm_bot"}`))
+				return
+			}
+			var attachments []slack.Attachment
+			_ = json.Unmarshal([]byte(r.Form.Get("attachments")), &attachments)
+			posted[channel] = attachments[0].Text
+			_, _ = fmt.Fprintf(w, `{"ok": true, "channel": %q, "ts": "1"}`, channel)
+		}
+	}))
+	defer server.Close()
+
+	s.report.Findings = domain.Findings{
+		{RuleID: "aws-access-token", File: "src/config.go", StartLine: 12, Email: "Jane@example.com", Fingerprint: "f1"},
+		{RuleID: "generic-api-key", File: "src/client.go", StartLine: 3, Email: "outsider@example.com", Fingerprint: "f2"},
+		{RuleID: "slack-bot-token", File: "deploy/env", StartLine: 7, Email: "jane@example.com", Fingerprint: "f3"},
+		{RuleID: "github-pat", File: "ci.yml", StartLine: 1, Email: "john@example.com", Fingerprint: "f4"},
+		{RuleID: "private-key", File: "id_rsa", StartLine: 1, Fingerprint: "f5"},
+	}
+
+	sut := NewSlackNotifier(&config.Config{SlackChannelId: "C0123456", SlackDMAuthors: true, SlackUserCacheMinutes: 60}, s.l, templates)
+	sut.client = slack.New("token", slack.OptionAPIURL(server.URL+"/"))
+	sut.users = newSlackUsers(time.Hour, sut.client.GetUserByEmailContext)
+
+	// act
+	err = sut.SendMessage(context.Background(), s.report)
+	s.Require().NoError(err)
+	err = sut.SendMessage(context.Background(), s.report)
+
+	// assert
+	assert.NoError(s.T(), err)
+	assert.ElementsMatch(s.T(), []string{"jane@example.com", "outsider@example.com", "john@example.com"}, lookups, "emails are looked up once")
+	s.Require().Len(posted, 2)
+
+	dm := posted["U0JANE"]
+	assert.Contains(s.T(), dm, "Your commit to testing repo included hard coded secrets")
+	assert.Contains(s.T(), dm, "src/config.go:12> aws-access-token")
+	assert.Contains(s.T(), dm, "deploy/env:7> slack-bot-token")
+	assert.NotContains(s.T(), dm, "src/client.go")
+
+	channel := posted["C0123456"]
+	assert.Contains(s.T(), channel, "How many findings found?* 3", "authors who were not messaged, failed messages and findings without email")
+}
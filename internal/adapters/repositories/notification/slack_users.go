@@ -0,0 +1,79 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"github.com/slack-go/slack"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errSlackUserNotFound is returned for emails without a Slack user
+var errSlackUserNotFound = errors.New("no Slack user with the email")
+
+type slackUser struct {
+	// id is empty for emails without a Slack user
+	id      string
+	expires time.Time
+}
+
+// slackUsers caches Slack user IDs looked up by email, emails without a Slack user are cached too, so authors outside
+// the workspace are not looked up on every notification
+type slackUsers struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	users  map[string]slackUser
+	lookup func(ctx context.Context, email string) (*slack.User, error)
+	now    func() time.Time
+}
+
+func newSlackUsers(ttl time.Duration, lookup func(ctx context.Context, email string) (*slack.User, error)) *slackUsers {
+
+	return &slackUsers{
+		ttl:    ttl,
+		users:  map[string]slackUser{},
+		lookup: lookup,
+		now:    time.Now,
+	}
+}
+
+// id returns ID of the Slack user with the email, errSlackUserNotFound when there is none
+func (su *slackUsers) id(ctx context.Context, email string) (string, error) {
+
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return "", errSlackUserNotFound
+	}
+
+	su.mu.Lock()
+	cached, ok := su.users[email]
+	su.mu.Unlock()
+
+	if ok && su.now().Before(cached.expires) {
+		if cached.id == "" {
+			return "", errSlackUserNotFound
+		}
+		return cached.id, nil
+	}
+
+	user, err := su.lookup(ctx, email)
+
+	var slackErr slack.SlackErrorResponse
+	if errors.As(err, &slackErr) && slackErr.Err == "users_not_found" {
+		user, err = &slack.User{}, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	su.mu.Lock()
+	su.users[email] = slackUser{id: user.ID, expires: su.now().Add(su.ttl)}
+	su.mu.Unlock()
+
+	if user.ID == "" {
+		return "", errSlackUserNotFound
+	}
+
+	return user.ID, nil
+}
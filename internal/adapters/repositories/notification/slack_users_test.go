@@ -0,0 +1,73 @@
+package notification
+
+import (
+	"context"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSlackUsers_IdTableDriven(t *testing.T) {
+
+	tests := []struct {
+		name        string
+		email       string
+		lookupErr   error
+		wantId      string
+		wantErr     error
+		wantLookups int
+	}{
+		{"known user is cached", "Jane@Example.com ", nil, "U0JANE", nil, 1},
+		{"unknown user is cached", "outsider@example.com", slack.SlackErrorResponse{Err: "users_not_found"}, "", errSlackUserNotFound, 1},
+		{"failed lookup is retried", "jane@example.com", assert.AnError, "", assert.AnError, 2},
+		{"empty email is not looked up", "", nil, "", errSlackUserNotFound, 0},
+	}
+
+	for _, tt := range tests {
+
+		t.Run(tt.name, func(t *testing.T) {
+
+			// arrange
+			var lookups int
+			sut := newSlackUsers(time.Hour, func(ctx context.Context, email string) (*slack.User, error) {
+				lookups++
+				if tt.lookupErr != nil {
+					return nil, tt.lookupErr
+				}
+				return &slack.User{ID: "U0JANE"}, nil
+			})
+
+			// act
+			_, _ = sut.id(context.Background(), tt.email)
+			id, err := sut.id(context.Background(), tt.email)
+
+			// assert
+			assert.Equal(t, tt.wantId, id)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.wantLookups, lookups)
+		})
+	}
+}
+
+func TestSlackUsers_IdExpires(t *testing.T) {
+
+	// arrange
+	var lookups int
+	now := time.Date(2022, 12, 3, 12, 48, 14, 0, time.UTC)
+	sut := newSlackUsers(time.Hour, func(ctx context.Context, email string) (*slack.User, error) {
+		lookups++
+		return &slack.User{ID: "U0JANE"}, nil
+	})
+	sut.now = func() time.Time { return now }
+
+	// act
+	_, _ = sut.id(context.Background(), "jane@example.com")
+	now = now.Add(59 * time.Minute)
+	_, _ = sut.id(context.Background(), "jane@example.com")
+	now = now.Add(time.Minute)
+	_, _ = sut.id(context.Background(), "jane@example.com")
+
+	// assert
+	assert.Equal(t, 2, lookups)
+}
@@ -0,0 +1,114 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"secrets-operator/config"
+	"secrets-operator/internal/core/domain"
+	"time"
+)
+
+type teamsNotifier struct {
+	cfg       *config.Config
+	l         *zap.SugaredLogger
+	client    *http.Client
+	templates domain.NotificationTemplates
+}
+
+func NewTeamsNotifier(cfg *config.Config, l *zap.SugaredLogger, templates domain.NotificationTemplates) *teamsNotifier {
+
+	return &teamsNotifier{
+		cfg:       cfg,
+		l:         l,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		templates: templates,
+	}
+}
+
+// teamsMessage is the payload of Teams incoming webhooks carrying an Adaptive Card,
+// see https://learn.microsoft.com/en-us/microsoftteams/platform/webhooks-and-connectors/how-to/connectors-using
+type teamsMessage struct {
+	Type        string            `json:"type"`
+	Attachments []teamsAttachment `json:"attachments"`
+}
+
+type teamsAttachment struct {
+	ContentType string       `json:"contentType"`
+	Content     adaptiveCard `json:"content"`
+}
+
+type adaptiveCard struct {
+	Schema  string                   `json:"$schema"`
+	Type    string                   `json:"type"`
+	Version string                   `json:"version"`
+	Body    []map[string]interface{} `json:"body"`
+	Actions []map[string]interface{} `json:"actions"`
+}
+
+func (tn teamsNotifier) SendMessage(ctx context.Context, message domain.FindingsReport) error {
+
+	return tn.SendTo(ctx, message, domain.NotificationTarget{Type: domain.NotificationTargetTeams, Destination: tn.cfg.TeamsWebhookURL})
+}
+
+// SendTo posts the message to Teams incoming webhook URL, rendered by template of the target
+func (tn teamsNotifier) SendTo(ctx context.Context, message domain.FindingsReport, target domain.NotificationTarget) error {
+
+	templateName := target.Template
+	if templateName == "" {
+		templateName = domain.TemplateTeams
+	}
+
+	text, err := tn.templates.Render(templateName, message)
+	if err != nil {
+		return err
+	}
+
+	card := adaptiveCard{
+		Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+		Type:    "AdaptiveCard",
+		Version: "1.4",
+		Body: []map[string]interface{}{
+			{
+				"type": "TextBlock",
+				"text": text,
+				"wrap": true,
+			},
+		},
+		Actions: []map[string]interface{}{
+			{"type": "Action.OpenUrl", "title": "Open pipeline", "url": message.BuildPipelineURL()},
+			{"type": "Action.OpenUrl", "title": "Open commit", "url": message.BuildCommitURL()},
+		},
+	}
+
+	payload, err := json.Marshal(teamsMessage{
+		Type:        "message",
+		Attachments: []teamsAttachment{{ContentType: "application/vnd.microsoft.card.adaptive", Content: card}},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.Destination, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := tn.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("teams webhook responded with status %d: %s", resp.StatusCode, body)
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"secrets-operator/config"
+	"secrets-operator/internal/core/domain"
+	"testing"
+	"time"
+)
+
+type TeamsNotifierTestSuite struct {
+	suite.Suite
+	l         *zap.SugaredLogger
+	report    domain.FindingsReport
+	templates domain.NotificationTemplates
+}
+
+func TestSuiteTeamsNotifier(t *testing.T) {
+	suite.Run(t, new(TeamsNotifierTestSuite))
+}
+
+func (s *TeamsNotifierTestSuite) SetupTest() {
+
+	s.l = zap.NewNop().Sugar()
+
+	var err error
+	s.templates, err = domain.NewNotificationTemplates(map[string]string{"payments": "{{.RepoName}} needs payments team"})
+	s.Require().NoError(err)
+
+	s.report = domain.FindingsReport{
+		PipelineID:   2,
+		RepoName:     "testing repo",
+		RepoID:       444,
+		RepoURL:      "https://gitlab.com/testing-repo/",
+		CommitAuthor: "test user",
+		CommitSHA:    "a85af84d39a32da2c8eba1d88019079aeb0741b0",
+		Timestamp:    time.Date(2022, 12, 3, 12, 48, 14, 0, time.UTC),
+		Findings:     domain.Findings{{Fingerprint: "f1"}, {Fingerprint: "f2"}},
+	}
+}
+
+func (s *TeamsNotifierTestSuite) TestTeamsNotifier_SendMessageTableDriven() {
+
+	tests := []struct {
+		name         string
+		responseCode int
+		wantErr      bool
+	}{
+		{"webhook accepts message", http.StatusOK, false},
+		{"workflow webhook accepts message", http.StatusAccepted, false},
+		{"webhook rejects message", http.StatusBadRequest, true},
+		{"webhook is down", http.StatusInternalServerError, true},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// arrange
+			var contentType string
+			var received map[string]interface{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				contentType = r.Header.Get("Content-Type")
+				body, _ := io.ReadAll(r.Body)
+				_ = json.Unmarshal(body, &received)
+				w.WriteHeader(tt.responseCode)
+			}))
+			defer server.Close()
+
+			sut := NewTeamsNotifier(&config.Config{TeamsWebhookURL: server.URL}, s.l, s.templates)
+
+			// act
+			err := sut.SendMessage(context.Background(), s.report)
+
+			// assert
+			assert.Equal(s.T(), tt.wantErr, err != nil, err)
+			assert.Equal(s.T(), "application/json", contentType)
+			assert.Equal(s.T(), "message", received["type"])
+		})
+	}
+}
+
+func (s *TeamsNotifierTestSuite) TestTeamsNotifier_SendMessageRendersAdaptiveCard() {
+
+	// arrange
+	message := teamsMessage{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.Require().NoError(json.NewDecoder(r.Body).Decode(&message))
+	}))
+	defer server.Close()
+
+	sut := NewTeamsNotifier(&config.Config{TeamsWebhookURL: server.URL}, s.l, s.templates)
+
+	// act
+	err := sut.SendMessage(context.Background(), s.report)
+
+	// assert
+	s.Require().NoError(err)
+	s.Require().Len(message.Attachments, 1)
+	assert.Equal(s.T(), "application/vnd.microsoft.card.adaptive", message.Attachments[0].ContentType)
+
+	card := message.Attachments[0].Content
+	assert.Equal(s.T(), "AdaptiveCard", card.Type)
+	s.Require().Len(card.Body, 1)
+	assert.Equal(s.T(), "TextBlock", card.Body[0]["type"])
+	assert.Equal(s.T(), "**Found new hard coded secrets in testing repo 😐**\n\n"+
+		"test user's commit included secret(ish) information. Please check\n\n"+
+		"- **Repository:** https://gitlab.com/testing-repo/\n"+
+		"- **Pipeline:** https://gitlab.com/testing-repo/-/pipelines/2\n"+
+		"- **Commit:** https://gitlab.com/testing-repo/-/commit/a85af84d39a32da2c8eba1d88019079aeb0741b0\n"+
+		"- **How many findings found?** 2\n"+
+		"- **Date:** 2022-12-03 12:48:14 +0000 UTC", card.Body[0]["text"])
+
+	s.Require().Len(card.Actions, 2)
+	assert.Equal(s.T(), "https://gitlab.com/testing-repo/-/pipelines/2", card.Actions[0]["url"])
+	assert.Equal(s.T(), "https://gitlab.com/testing-repo/-/commit/a85af84d39a32da2c8eba1d88019079aeb0741b0", card.Actions[1]["url"])
+}
@@ -0,0 +1,59 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"secrets-operator/config"
+	"secrets-operator/internal/core/domain"
+	"time"
+)
+
+// webhookNotifier posts reports as JSON to URLs given by routing rules, findings are redacted before they are stored,
+// so the report carries no more than the findings API does. Signed event subscriptions are handled by webhooksrv.
+type webhookNotifier struct {
+	cfg    *config.Config
+	l      *zap.SugaredLogger
+	client *http.Client
+}
+
+func NewWebhookNotifier(cfg *config.Config, l *zap.SugaredLogger) *webhookNotifier {
+
+	return &webhookNotifier{
+		cfg:    cfg,
+		l:      l,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// SendTo posts the report to URL of the target, any 2xx response means it was delivered
+func (wn webhookNotifier) SendTo(ctx context.Context, message domain.FindingsReport, target domain.NotificationTarget) error {
+
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.Destination, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := wn.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook responded with status %d: %s", resp.StatusCode, body)
+	}
+
+	return nil
+}
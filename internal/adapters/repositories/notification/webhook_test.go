@@ -0,0 +1,70 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"secrets-operator/config"
+	"secrets-operator/internal/core/domain"
+	"testing"
+)
+
+type WebhookNotifierTestSuite struct {
+	suite.Suite
+	l *zap.SugaredLogger
+}
+
+func TestSuiteWebhookNotifier(t *testing.T) {
+	suite.Run(t, new(WebhookNotifierTestSuite))
+}
+
+func (s *WebhookNotifierTestSuite) SetupTest() {
+	s.l = zap.NewNop().Sugar()
+}
+
+func (s *WebhookNotifierTestSuite) TestWebhookNotifier_SendToTableDriven() {
+
+	tests := []struct {
+		name         string
+		responseCode int
+		wantErr      bool
+	}{
+		{"webhook accepts report", http.StatusOK, false},
+		{"webhook accepts report asynchronously", http.StatusAccepted, false},
+		{"webhook rejects report", http.StatusBadRequest, true},
+		{"webhook is down", http.StatusInternalServerError, true},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// arrange
+			var contentType string
+			received := domain.FindingsReport{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				contentType = r.Header.Get("Content-Type")
+				_ = json.NewDecoder(r.Body).Decode(&received)
+				w.WriteHeader(tt.responseCode)
+			}))
+			defer server.Close()
+
+			sut := NewWebhookNotifier(&config.Config{}, s.l)
+
+			// act
+			err := sut.SendTo(context.Background(), domain.FindingsReport{RepoID: 444, Findings: domain.Findings{{Fingerprint: "f1"}}}, domain.NotificationTarget{Type: domain.NotificationTargetWebhook, Destination: server.URL + "/payments"})
+
+			// assert
+			assert.Equal(s.T(), tt.wantErr, err != nil, err)
+			assert.Equal(s.T(), "application/json", contentType)
+			assert.Equal(s.T(), 444, received.RepoID)
+			if assert.Len(s.T(), received.Findings, 1) {
+				assert.Equal(s.T(), "f1", received.Findings[0].Fingerprint)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/core/ports"
+	"secrets-operator/internal/errors"
+	"time"
+)
+
+// APIKeyRepositoryTestSuite describes behaviour shared by every ports.APIKeyRepository implementation
+type APIKeyRepositoryTestSuite struct {
+	suite.Suite
+	newRepository func() ports.APIKeyRepository
+	sut           ports.APIKeyRepository
+	createdAt     time.Time
+}
+
+func (s *APIKeyRepositoryTestSuite) SetupTest() {
+
+	s.sut = s.newRepository()
+
+	// mongo keeps milliseconds only, so test dates are rounded to seconds
+	s.createdAt = time.Date(2022, 12, 3, 12, 48, 14, 0, time.UTC)
+}
+
+func (s *APIKeyRepositoryTestSuite) apiKey(id string, createdAt time.Time) domain.APIKey {
+
+	return domain.APIKey{
+		ID:        id,
+		Name:      "test key " + id,
+		Scope:     domain.TokenScopeUpload,
+		RepoID:    444,
+		Prefix:    "so_" + id,
+		KeyHash:   id + "-hash",
+		CreatedBy: "test admin",
+		CreatedAt: createdAt,
+	}
+}
+
+func (s *APIKeyRepositoryTestSuite) TestSaveAndGetAPIKeyByHash() {
+
+	// arrange
+	assert.NoError(s.T(), s.sut.SaveAPIKey(s.apiKey("a1", s.createdAt), "apikeys"))
+
+	// act
+	apiKey, err := s.sut.GetAPIKeyByHash("a1-hash", "apikeys")
+
+	// assert
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "a1", apiKey.ID)
+	assert.Equal(s.T(), "test key a1", apiKey.Name)
+	assert.Equal(s.T(), domain.TokenScopeUpload, apiKey.Scope)
+	assert.Equal(s.T(), 444, apiKey.RepoID)
+	assert.Equal(s.T(), "a1-hash", apiKey.KeyHash)
+	assert.True(s.T(), s.createdAt.Equal(apiKey.CreatedAt))
+	assert.Nil(s.T(), apiKey.LastUsedAt)
+	assert.Nil(s.T(), apiKey.RevokedAt)
+
+	_, err = s.sut.GetAPIKeyByHash("unknown", "apikeys")
+	assert.Equal(s.T(), errors.ErrAPIKeyNotFound, err)
+}
+
+func (s *APIKeyRepositoryTestSuite) TestGetAPIKeys() {
+
+	// arrange
+	assert.NoError(s.T(), s.sut.SaveAPIKey(s.apiKey("b2", s.createdAt.Add(time.Hour)), "apikeys"))
+	assert.NoError(s.T(), s.sut.SaveAPIKey(s.apiKey("a1", s.createdAt), "apikeys"))
+
+	// act
+	apiKeys, err := s.sut.GetAPIKeys("apikeys")
+
+	// assert
+	assert.NoError(s.T(), err)
+	if assert.Len(s.T(), apiKeys, 2) {
+		assert.Equal(s.T(), "a1", apiKeys[0].ID, "keys are ordered by creation time")
+		assert.Equal(s.T(), "b2", apiKeys[1].ID)
+	}
+}
+
+func (s *APIKeyRepositoryTestSuite) TestRevokeAndTouchAPIKey() {
+
+	// arrange
+	assert.NoError(s.T(), s.sut.SaveAPIKey(s.apiKey("a1", s.createdAt), "apikeys"))
+	usedAt := s.createdAt.Add(time.Minute)
+	revokedAt := s.createdAt.Add(time.Hour)
+
+	// act
+	assert.NoError(s.T(), s.sut.TouchAPIKey("a1", usedAt, "apikeys"))
+	assert.NoError(s.T(), s.sut.RevokeAPIKey("a1", revokedAt, "apikeys"))
+
+	// assert
+	apiKey, err := s.sut.GetAPIKeyByHash("a1-hash", "apikeys")
+	assert.NoError(s.T(), err)
+	if assert.NotNil(s.T(), apiKey.LastUsedAt) {
+		assert.True(s.T(), usedAt.Equal(*apiKey.LastUsedAt))
+	}
+	if assert.NotNil(s.T(), apiKey.RevokedAt) {
+		assert.True(s.T(), revokedAt.Equal(*apiKey.RevokedAt))
+	}
+
+	assert.Equal(s.T(), errors.ErrAPIKeyNotFound, s.sut.TouchAPIKey("unknown", usedAt, "apikeys"))
+	assert.Equal(s.T(), errors.ErrAPIKeyNotFound, s.sut.RevokeAPIKey("unknown", revokedAt, "apikeys"))
+}
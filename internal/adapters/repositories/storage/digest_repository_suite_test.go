@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/core/ports"
+	"time"
+)
+
+// digestRepository keeps digest entries and announcements, every backend implements both
+type digestRepository interface {
+	ports.DigestRepository
+	ports.AnnouncementRepository
+}
+
+// DigestRepositoryTestSuite describes behaviour shared by every ports.DigestRepository and ports.AnnouncementRepository implementation
+type DigestRepositoryTestSuite struct {
+	suite.Suite
+	newRepository func() digestRepository
+	sut           digestRepository
+	now           time.Time
+}
+
+func (s *DigestRepositoryTestSuite) SetupTest() {
+
+	s.sut = s.newRepository()
+
+	// mongo keeps milliseconds only, so test dates are rounded to seconds
+	s.now = time.Date(2022, 12, 3, 12, 48, 14, 0, time.UTC)
+}
+
+func (s *DigestRepositoryTestSuite) entry(id string, createdAt time.Time, dueAt time.Time) domain.DigestEntry {
+
+	return domain.DigestEntry{
+		ID:        id,
+		Channel:   "slack:C0123456",
+		Target:    &domain.NotificationTarget{Type: domain.NotificationTargetSlack, Destination: "C0123456", Mode: domain.NotificationDaily},
+		Report:    domain.FindingsReport{RepoID: 444, Findings: domain.Findings{{RuleID: "aws-access-token", Fingerprint: "f1"}}},
+		DueAt:     dueAt,
+		CreatedAt: createdAt,
+	}
+}
+
+func (s *DigestRepositoryTestSuite) TestSaveGetAndDeleteDigestEntries() {
+
+	// arrange
+	assert.NoError(s.T(), s.sut.SaveDigestEntry(s.entry("b2", s.now.Add(time.Minute), s.now), "digests"))
+	assert.NoError(s.T(), s.sut.SaveDigestEntry(s.entry("a1", s.now, s.now), "digests"))
+	assert.NoError(s.T(), s.sut.SaveDigestEntry(s.entry("c3", s.now, s.now.Add(time.Hour)), "digests"))
+	dead := s.entry("d4", s.now, s.now)
+	dead.Dead = true
+	assert.NoError(s.T(), s.sut.SaveDigestEntry(dead, "digests"))
+
+	// act
+	due, err := s.sut.GetDueDigestEntries(s.now, 10, "digests")
+
+	// assert
+	assert.NoError(s.T(), err)
+	if assert.Len(s.T(), due, 2, "entries due later and dead ones are not returned") {
+		assert.Equal(s.T(), "a1", due[0].ID, "entries are ordered by creation time")
+		assert.Equal(s.T(), "b2", due[1].ID)
+		assert.Equal(s.T(), "slack:C0123456", due[0].Channel)
+		if assert.NotNil(s.T(), due[0].Target) {
+			assert.Equal(s.T(), domain.NotificationDaily, due[0].Target.Mode)
+		}
+		assert.Equal(s.T(), "f1", due[0].Report.Findings[0].Fingerprint)
+		assert.True(s.T(), s.now.Equal(due[0].DueAt))
+	}
+
+	limited, err := s.sut.GetDueDigestEntries(s.now.Add(time.Hour), 1, "digests")
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), limited, 1)
+
+	assert.NoError(s.T(), s.sut.DeleteDigestEntries([]string{"a1", "b2", "missing"}, "digests"))
+
+	left, err := s.sut.GetDueDigestEntries(s.now.Add(time.Hour), 10, "digests")
+	assert.NoError(s.T(), err)
+	if assert.Len(s.T(), left, 1) {
+		assert.Equal(s.T(), "c3", left[0].ID)
+	}
+}
+
+func (s *DigestRepositoryTestSuite) TestAnnouncements() {
+
+	// arrange
+	assert.NoError(s.T(), s.sut.SaveAnnouncements("slack", 444, []string{"f1", "f2"}, s.now, "announcements"))
+	assert.NoError(s.T(), s.sut.SaveAnnouncements("slack", 444, []string{"f2"}, s.now.Add(time.Hour), "announcements"), "announcing again is not an error")
+	assert.NoError(s.T(), s.sut.SaveAnnouncements("email", 555, []string{"f3"}, s.now, "announcements"))
+
+	// act
+	announced, err := s.sut.GetAnnouncedFingerprints("slack", 444, []string{"f1", "f2", "f3"}, "announcements")
+
+	// assert
+	assert.NoError(s.T(), err)
+	assert.ElementsMatch(s.T(), []string{"f1", "f2"}, announced)
+
+	otherRepository, err := s.sut.GetAnnouncedFingerprints("email", 444, []string{"f3"}, "announcements")
+	assert.NoError(s.T(), err)
+	assert.Empty(s.T(), otherRepository, "announcements are kept per channel and repository")
+
+	none, err := s.sut.GetAnnouncedFingerprints("slack", 444, nil, "announcements")
+	assert.NoError(s.T(), err)
+	assert.Empty(s.T(), none)
+}
@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"go.uber.org/zap"
+	"secrets-operator/config"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// memoryDB keeps everything in process memory, it is meant for local development and tests.
+// Values are copied on the way in and out, so callers can never modify stored data by accident.
+type memoryDB struct {
+	cfg          *config.Config
+	l            *zap.SugaredLogger
+	mu           sync.RWMutex
+	reports      map[string][]domain.FindingsReport
+	repositories map[string]map[int]domain.RepoFindings
+	apiKeys      map[string]map[string]domain.APIKey
+	webhooks     map[string]map[string]domain.WebhookSubscription
+	deliveries   map[string]map[string]domain.WebhookDelivery
+	outbox       map[string]map[string]domain.OutboxMessage
+	routingRules map[string]map[string]domain.RoutingRule
+	configs      map[string]map[int]domain.GitleaksConfigVersion
+	digests      map[string]map[string]domain.DigestEntry
+	announced    map[string]map[announcement]time.Time
+}
+
+func NewMemory(cfg *config.Config, l *zap.SugaredLogger) *memoryDB {
+
+	l.Infoln("Using in-memory storage, data will be lost on restart")
+	return &memoryDB{
+		cfg:          cfg,
+		l:            l,
+		reports:      map[string][]domain.FindingsReport{},
+		repositories: map[string]map[int]domain.RepoFindings{},
+		apiKeys:      map[string]map[string]domain.APIKey{},
+		webhooks:     map[string]map[string]domain.WebhookSubscription{},
+		deliveries:   map[string]map[string]domain.WebhookDelivery{},
+		outbox:       map[string]map[string]domain.OutboxMessage{},
+		routingRules: map[string]map[string]domain.RoutingRule{},
+		configs:      map[string]map[int]domain.GitleaksConfigVersion{},
+		digests:      map[string]map[string]domain.DigestEntry{},
+		announced:    map[string]map[announcement]time.Time{},
+	}
+}
+
+func (db *memoryDB) SaveFindingsReport(findingsReport domain.FindingsReport, collectionName string) error {
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	findingsReport.Findings = findingsReport.Findings.Clone()
+	db.reports[collectionName] = append(db.reports[collectionName], findingsReport)
+
+	return nil
+}
+
+func (db *memoryDB) GetRepoFindingsById(repoId int, collectionName string) (domain.RepoFindings, error) {
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	repoFindings, ok := db.repositories[collectionName][repoId]
+	if !ok {
+		return domain.RepoFindings{}, errors.ErrRepositoryNotFound
+	}
+
+	repoFindings.Findings = repoFindings.Findings.Clone()
+
+	return repoFindings, nil
+}
+
+// SaveAndUpdateRepoFindingsById merges findings of the report into repository findings and queues notification,
+// the lock makes it atomic
+func (db *memoryDB) SaveAndUpdateRepoFindingsById(findingsReport domain.FindingsReport, identity domain.FindingIdentity, repoId int, notify bool, collectionName string) (domain.UpsertResult, error) {
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	collection, ok := db.repositories[collectionName]
+	if !ok {
+		collection = map[int]domain.RepoFindings{}
+		db.repositories[collectionName] = collection
+	}
+
+	now := time.Now().UTC()
+
+	merged, result := collection[repoId].Merge(findingsReport, identity, now)
+	merged.RepoID = repoId
+
+	collection[repoId] = merged
+
+	if notify && len(result.New) > 0 {
+		db.saveOutboxMessage(domain.NewOutboxMessage(findingsReport, result.New, now), outboxCollectionName)
+	}
+
+	return result, nil
+}
+
+func (db *memoryDB) GetRepositoriesByName(repoName string, collectionName string) ([]map[string]string, error) {
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var repoIds []int
+	for repoId, repoFindings := range db.repositories[collectionName] {
+		if strings.Contains(strings.ToLower(repoFindings.RepoName), strings.ToLower(repoName)) {
+			repoIds = append(repoIds, repoId)
+		}
+	}
+	sort.Ints(repoIds)
+
+	var response []map[string]string
+
+	for _, repoId := range repoIds {
+		repoFindings := db.repositories[collectionName][repoId]
+		response = append(response, map[string]string{"name": repoFindings.RepoName, "id": strconv.Itoa(repoId)})
+	}
+
+	return response, nil
+}
+
+func (db *memoryDB) UpdateFindingStatus(repoId int, fingerprint string, change domain.StatusChange, collectionName string) error {
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	repoFindings, ok := db.repositories[collectionName][repoId]
+	if !ok {
+		return errors.ErrFindingNotFound
+	}
+
+	updated := false
+	for i := range repoFindings.Findings {
+		if repoFindings.Findings[i].Fingerprint != fingerprint {
+			continue
+		}
+
+		repoFindings.Findings[i].ApplyStatusChange(change)
+		updated = true
+	}
+
+	if !updated {
+		return errors.ErrFindingNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/errors"
+	"sort"
+	"time"
+)
+
+func (db *memoryDB) SaveAPIKey(apiKey domain.APIKey, collectionName string) error {
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	collection, ok := db.apiKeys[collectionName]
+	if !ok {
+		collection = map[string]domain.APIKey{}
+		db.apiKeys[collectionName] = collection
+	}
+
+	collection[apiKey.ID] = cloneAPIKey(apiKey)
+
+	return nil
+}
+
+func (db *memoryDB) GetAPIKeyByHash(keyHash string, collectionName string) (domain.APIKey, error) {
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	for _, apiKey := range db.apiKeys[collectionName] {
+		if apiKey.KeyHash == keyHash {
+			return cloneAPIKey(apiKey), nil
+		}
+	}
+
+	return domain.APIKey{}, errors.ErrAPIKeyNotFound
+}
+
+func (db *memoryDB) GetAPIKeys(collectionName string) ([]domain.APIKey, error) {
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	apiKeys := []domain.APIKey{}
+	for _, apiKey := range db.apiKeys[collectionName] {
+		apiKeys = append(apiKeys, cloneAPIKey(apiKey))
+	}
+
+	sort.Slice(apiKeys, func(i, j int) bool {
+		return apiKeys[i].CreatedAt.Before(apiKeys[j].CreatedAt)
+	})
+
+	return apiKeys, nil
+}
+
+func (db *memoryDB) RevokeAPIKey(id string, revokedAt time.Time, collectionName string) error {
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	apiKey, ok := db.apiKeys[collectionName][id]
+	if !ok {
+		return errors.ErrAPIKeyNotFound
+	}
+
+	apiKey.RevokedAt = &revokedAt
+	db.apiKeys[collectionName][id] = apiKey
+
+	return nil
+}
+
+func (db *memoryDB) TouchAPIKey(id string, usedAt time.Time, collectionName string) error {
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	apiKey, ok := db.apiKeys[collectionName][id]
+	if !ok {
+		return errors.ErrAPIKeyNotFound
+	}
+
+	apiKey.LastUsedAt = &usedAt
+	db.apiKeys[collectionName][id] = apiKey
+
+	return nil
+}
+
+// cloneAPIKey copies time pointers, so stored keys are not shared with callers
+func cloneAPIKey(apiKey domain.APIKey) domain.APIKey {
+
+	if apiKey.LastUsedAt != nil {
+		lastUsedAt := *apiKey.LastUsedAt
+		apiKey.LastUsedAt = &lastUsedAt
+	}
+	if apiKey.RevokedAt != nil {
+		revokedAt := *apiKey.RevokedAt
+		apiKey.RevokedAt = &revokedAt
+	}
+
+	return apiKey
+}
@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"secrets-operator/internal/core/domain"
+	"sort"
+	"time"
+)
+
+// announcement identifies a finding announced to a notification channel
+type announcement struct {
+	channel     string
+	repoId      int
+	fingerprint string
+}
+
+func (db *memoryDB) SaveDigestEntry(entry domain.DigestEntry, collectionName string) error {
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	collection, ok := db.digests[collectionName]
+	if !ok {
+		collection = map[string]domain.DigestEntry{}
+		db.digests[collectionName] = collection
+	}
+
+	collection[entry.ID] = cloneDigestEntry(entry)
+
+	return nil
+}
+
+func (db *memoryDB) GetDueDigestEntries(now time.Time, limit int, collectionName string) ([]domain.DigestEntry, error) {
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	entries := []domain.DigestEntry{}
+	for _, entry := range db.digests[collectionName] {
+		if !entry.Dead && !entry.DueAt.After(now) {
+			entries = append(entries, cloneDigestEntry(entry))
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].CreatedAt.Equal(entries[j].CreatedAt) {
+			return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+		}
+		return entries[i].ID < entries[j].ID
+	})
+
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	return entries, nil
+}
+
+func (db *memoryDB) DeleteDigestEntries(ids []string, collectionName string) error {
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, id := range ids {
+		delete(db.digests[collectionName], id)
+	}
+
+	return nil
+}
+
+func (db *memoryDB) GetAnnouncedFingerprints(channel string, repoId int, fingerprints []string, collectionName string) ([]string, error) {
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	announced := []string{}
+	for _, fingerprint := range fingerprints {
+		if _, ok := db.announced[collectionName][announcement{channel: channel, repoId: repoId, fingerprint: fingerprint}]; ok {
+			announced = append(announced, fingerprint)
+		}
+	}
+
+	return announced, nil
+}
+
+func (db *memoryDB) SaveAnnouncements(channel string, repoId int, fingerprints []string, announcedAt time.Time, collectionName string) error {
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	collection, ok := db.announced[collectionName]
+	if !ok {
+		collection = map[announcement]time.Time{}
+		db.announced[collectionName] = collection
+	}
+
+	for _, fingerprint := range fingerprints {
+		key := announcement{channel: channel, repoId: repoId, fingerprint: fingerprint}
+		if _, ok := collection[key]; !ok {
+			collection[key] = announcedAt
+		}
+	}
+
+	return nil
+}
+
+func cloneDigestEntry(entry domain.DigestEntry) domain.DigestEntry {
+
+	entry.Report.Findings = entry.Report.Findings.Clone()
+	if entry.Target != nil {
+		target := *entry.Target
+		entry.Target = &target
+	}
+
+	return entry
+}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/errors"
+	"sort"
+)
+
+func (db *memoryDB) SaveGitleaksConfigVersion(version domain.GitleaksConfigVersion, collectionName string) error {
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	collection, ok := db.configs[collectionName]
+	if !ok {
+		collection = map[int]domain.GitleaksConfigVersion{}
+		db.configs[collectionName] = collection
+	}
+
+	if _, ok := collection[version.Version]; ok {
+		return errors.ErrGitleaksConfigVersionExists
+	}
+
+	collection[version.Version] = cloneGitleaksConfigVersion(version)
+
+	return nil
+}
+
+func (db *memoryDB) GetGitleaksConfigVersion(version int, collectionName string) (domain.GitleaksConfigVersion, error) {
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	stored, ok := db.configs[collectionName][version]
+	if !ok {
+		return domain.GitleaksConfigVersion{}, errors.ErrGitleaksConfigVersionNotFound
+	}
+
+	return cloneGitleaksConfigVersion(stored), nil
+}
+
+func (db *memoryDB) GetLatestGitleaksConfigVersion(collectionName string) (domain.GitleaksConfigVersion, error) {
+
+	versions, err := db.GetGitleaksConfigVersions(collectionName)
+	if err != nil {
+		return domain.GitleaksConfigVersion{}, err
+	}
+
+	if len(versions) == 0 {
+		return domain.GitleaksConfigVersion{}, errors.ErrGitleaksConfigVersionNotFound
+	}
+
+	return versions[0], nil
+}
+
+func (db *memoryDB) GetGitleaksConfigVersions(collectionName string) ([]domain.GitleaksConfigVersion, error) {
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	versions := []domain.GitleaksConfigVersion{}
+	for _, version := range db.configs[collectionName] {
+		versions = append(versions, cloneGitleaksConfigVersion(version))
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Version > versions[j].Version
+	})
+
+	return versions, nil
+}
+
+func cloneGitleaksConfigVersion(version domain.GitleaksConfigVersion) domain.GitleaksConfigVersion {
+
+	version.Config.Allowlist = cloneGitleaksAllowlist(version.Config.Allowlist)
+
+	rules := make([]domain.GitleaksRule, 0, len(version.Config.Rules))
+	for _, rule := range version.Config.Rules {
+		rule.Keywords = append([]string(nil), rule.Keywords...)
+		rule.Tags = append([]string(nil), rule.Tags...)
+		rule.Allowlist = cloneGitleaksAllowlist(rule.Allowlist)
+		rules = append(rules, rule)
+	}
+	version.Config.Rules = rules
+
+	return version
+}
+
+func cloneGitleaksAllowlist(allowlist *domain.GitleaksAllowlist) *domain.GitleaksAllowlist {
+
+	if allowlist == nil {
+		return nil
+	}
+
+	clone := *allowlist
+	clone.Regexes = append([]string(nil), allowlist.Regexes...)
+	clone.Paths = append([]string(nil), allowlist.Paths...)
+	clone.Commits = append([]string(nil), allowlist.Commits...)
+	clone.StopWords = append([]string(nil), allowlist.StopWords...)
+
+	return &clone
+}
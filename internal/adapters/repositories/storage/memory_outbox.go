@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/errors"
+	"sort"
+	"time"
+)
+
+func (db *memoryDB) SaveOutboxMessage(message domain.OutboxMessage, collectionName string) error {
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.saveOutboxMessage(message, collectionName)
+
+	return nil
+}
+
+// saveOutboxMessage must be called with the lock held
+func (db *memoryDB) saveOutboxMessage(message domain.OutboxMessage, collectionName string) {
+
+	collection, ok := db.outbox[collectionName]
+	if !ok {
+		collection = map[string]domain.OutboxMessage{}
+		db.outbox[collectionName] = collection
+	}
+
+	collection[message.ID] = cloneOutboxMessage(message)
+}
+
+func (db *memoryDB) GetOutboxMessageById(id string, collectionName string) (domain.OutboxMessage, error) {
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	message, ok := db.outbox[collectionName][id]
+	if !ok {
+		return domain.OutboxMessage{}, errors.ErrOutboxMessageNotFound
+	}
+
+	return cloneOutboxMessage(message), nil
+}
+
+func (db *memoryDB) GetOutboxMessages(status domain.OutboxStatus, limit int, collectionName string) ([]domain.OutboxMessage, error) {
+
+	return db.findOutboxMessages(collectionName, limit, func(message domain.OutboxMessage) bool {
+		return message.Status == status
+	}, func(a, b domain.OutboxMessage) bool {
+		return a.CreatedAt.After(b.CreatedAt)
+	})
+}
+
+func (db *memoryDB) GetDueOutboxMessages(now time.Time, limit int, collectionName string) ([]domain.OutboxMessage, error) {
+
+	return db.findOutboxMessages(collectionName, limit, func(message domain.OutboxMessage) bool {
+		return message.Status == domain.OutboxPending && !message.NextAttemptAt.After(now)
+	}, func(a, b domain.OutboxMessage) bool {
+		return a.NextAttemptAt.Before(b.NextAttemptAt)
+	})
+}
+
+func (db *memoryDB) findOutboxMessages(collectionName string, limit int, match func(domain.OutboxMessage) bool, less func(a, b domain.OutboxMessage) bool) ([]domain.OutboxMessage, error) {
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	messages := []domain.OutboxMessage{}
+	for _, message := range db.outbox[collectionName] {
+		if match(message) {
+			messages = append(messages, cloneOutboxMessage(message))
+		}
+	}
+
+	sort.SliceStable(messages, func(i, j int) bool {
+		if less(messages[i], messages[j]) {
+			return true
+		}
+		if less(messages[j], messages[i]) {
+			return false
+		}
+		return messages[i].ID < messages[j].ID
+	})
+
+	if limit > 0 && len(messages) > limit {
+		messages = messages[:limit]
+	}
+
+	return messages, nil
+}
+
+func cloneOutboxMessage(message domain.OutboxMessage) domain.OutboxMessage {
+
+	message.Report.Findings = message.Report.Findings.Clone()
+	message.Channels = append([]string(nil), message.Channels...)
+	message.Attempts = append([]domain.OutboxAttempt(nil), message.Attempts...)
+
+	return message
+}
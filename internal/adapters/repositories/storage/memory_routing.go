@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/errors"
+	"sort"
+)
+
+func (db *memoryDB) SaveRoutingRule(rule domain.RoutingRule, collectionName string) error {
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	collection, ok := db.routingRules[collectionName]
+	if !ok {
+		collection = map[string]domain.RoutingRule{}
+		db.routingRules[collectionName] = collection
+	}
+
+	collection[rule.ID] = cloneRoutingRule(rule)
+
+	return nil
+}
+
+func (db *memoryDB) GetRoutingRuleById(id string, collectionName string) (domain.RoutingRule, error) {
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	rule, ok := db.routingRules[collectionName][id]
+	if !ok {
+		return domain.RoutingRule{}, errors.ErrRoutingRuleNotFound
+	}
+
+	return cloneRoutingRule(rule), nil
+}
+
+func (db *memoryDB) GetRoutingRules(collectionName string) ([]domain.RoutingRule, error) {
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	rules := []domain.RoutingRule{}
+	for _, rule := range db.routingRules[collectionName] {
+		rules = append(rules, cloneRoutingRule(rule))
+	}
+
+	sort.Slice(rules, func(i, j int) bool {
+		if !rules[i].CreatedAt.Equal(rules[j].CreatedAt) {
+			return rules[i].CreatedAt.Before(rules[j].CreatedAt)
+		}
+		return rules[i].ID < rules[j].ID
+	})
+
+	return rules, nil
+}
+
+func (db *memoryDB) DeleteRoutingRule(id string, collectionName string) error {
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.routingRules[collectionName][id]; !ok {
+		return errors.ErrRoutingRuleNotFound
+	}
+
+	delete(db.routingRules[collectionName], id)
+
+	return nil
+}
+
+func cloneRoutingRule(rule domain.RoutingRule) domain.RoutingRule {
+
+	rule.RepoIDs = append([]int(nil), rule.RepoIDs...)
+	rule.RuleIDs = append([]string(nil), rule.RuleIDs...)
+	rule.Tags = append([]string(nil), rule.Tags...)
+	rule.Targets = append([]domain.NotificationTarget(nil), rule.Targets...)
+
+	return rule
+}
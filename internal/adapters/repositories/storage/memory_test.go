@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"secrets-operator/config"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/core/ports"
+	"sync"
+	"testing"
+)
+
+func TestSuiteMemoryFindingsRepository(t *testing.T) {
+
+	s := new(FindingsRepositoryTestSuite)
+	s.newRepository = func() ports.FindingsRepository {
+		return NewMemory(&config.Config{}, zap.NewNop().Sugar())
+	}
+
+	suite.Run(t, s)
+}
+
+func TestSuiteMemoryAPIKeyRepository(t *testing.T) {
+
+	s := new(APIKeyRepositoryTestSuite)
+	s.newRepository = func() ports.APIKeyRepository {
+		return NewMemory(&config.Config{}, zap.NewNop().Sugar())
+	}
+
+	suite.Run(t, s)
+}
+
+func TestSuiteMemoryWebhookRepository(t *testing.T) {
+
+	s := new(WebhookRepositoryTestSuite)
+	s.newRepository = func() ports.WebhookRepository {
+		return NewMemory(&config.Config{}, zap.NewNop().Sugar())
+	}
+
+	suite.Run(t, s)
+}
+
+func TestSuiteMemoryOutboxRepository(t *testing.T) {
+
+	s := new(OutboxRepositoryTestSuite)
+	s.newRepository = func() outboxRepository {
+		return NewMemory(&config.Config{}, zap.NewNop().Sugar())
+	}
+
+	suite.Run(t, s)
+}
+
+func TestSuiteMemoryRoutingRuleRepository(t *testing.T) {
+
+	s := new(RoutingRuleRepositoryTestSuite)
+	s.newRepository = func() ports.RoutingRuleRepository {
+		return NewMemory(&config.Config{}, zap.NewNop().Sugar())
+	}
+
+	suite.Run(t, s)
+}
+
+func TestSuiteMemoryGitleaksConfigRepository(t *testing.T) {
+
+	s := new(GitleaksConfigRepositoryTestSuite)
+	s.newRepository = func() ports.GitleaksConfigRepository {
+		return NewMemory(&config.Config{}, zap.NewNop().Sugar())
+	}
+
+	suite.Run(t, s)
+}
+
+func TestSuiteMemoryDigestRepository(t *testing.T) {
+
+	s := new(DigestRepositoryTestSuite)
+	s.newRepository = func() digestRepository {
+		return NewMemory(&config.Config{}, zap.NewNop().Sugar())
+	}
+
+	suite.Run(t, s)
+}
+
+func TestMemoryDB_ConcurrentAccess(t *testing.T) {
+
+	db := NewMemory(&config.Config{}, zap.NewNop().Sugar())
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 50; i++ {
+		wg.Add(1)
+		go func(repoId int) {
+			defer wg.Done()
+
+			report := domain.FindingsReport{
+				RepoID:   repoId,
+				RepoName: "test",
+				Findings: domain.Findings{{Fingerprint: "shared"}},
+			}
+			assert.NoError(t, db.SaveFindingsReport(report, "findings"))
+			_, err := db.SaveAndUpdateRepoFindingsById(report, domain.FindingIdentityFingerprint, repoId, false, "repositories")
+			assert.NoError(t, err)
+			assert.NoError(t, db.UpdateFindingStatus(repoId, "shared", domain.StatusChange{Status: domain.FindingStatusRevoked}, "repositories"))
+			_, _ = db.GetRepositoriesByName("test", "repositories")
+		}(i)
+	}
+	wg.Wait()
+
+	repositories, err := db.GetRepositoriesByName("test", "repositories")
+	assert.NoError(t, err)
+	assert.Len(t, repositories, 50)
+}
+
+func TestMemoryDB_ReturnsCopies(t *testing.T) {
+
+	db := NewMemory(&config.Config{}, zap.NewNop().Sugar())
+
+	input := domain.FindingsReport{RepoID: 1, Findings: domain.Findings{{Fingerprint: "test", Tags: []string{"tag"}}}}
+	result, err := db.SaveAndUpdateRepoFindingsById(input, domain.FindingIdentityFingerprint, 1, false, "repositories")
+	assert.NoError(t, err)
+
+	result.New[0].Fingerprint = "modified"
+
+	input.Findings[0].Tags[0] = "modified"
+	returned, _ := db.GetRepoFindingsById(1, "repositories")
+	returned.Findings[0].Fingerprint = "modified"
+
+	stored, _ := db.GetRepoFindingsById(1, "repositories")
+	assert.Equal(t, "test", stored.Findings[0].Fingerprint)
+	assert.Equal(t, []string{"tag"}, stored.Findings[0].Tags)
+}
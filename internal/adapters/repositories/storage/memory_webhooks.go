@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/errors"
+	"sort"
+	"time"
+)
+
+func (db *memoryDB) SaveWebhookSubscription(subscription domain.WebhookSubscription, collectionName string) error {
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	collection, ok := db.webhooks[collectionName]
+	if !ok {
+		collection = map[string]domain.WebhookSubscription{}
+		db.webhooks[collectionName] = collection
+	}
+
+	collection[subscription.ID] = cloneWebhookSubscription(subscription)
+
+	return nil
+}
+
+func (db *memoryDB) GetWebhookSubscriptions(collectionName string) ([]domain.WebhookSubscription, error) {
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	subscriptions := []domain.WebhookSubscription{}
+	for _, subscription := range db.webhooks[collectionName] {
+		subscriptions = append(subscriptions, cloneWebhookSubscription(subscription))
+	}
+
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].CreatedAt.Before(subscriptions[j].CreatedAt)
+	})
+
+	return subscriptions, nil
+}
+
+func (db *memoryDB) DeleteWebhookSubscription(id string, collectionName string) error {
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.webhooks[collectionName][id]; !ok {
+		return errors.ErrWebhookSubscriptionNotFound
+	}
+
+	delete(db.webhooks[collectionName], id)
+
+	return nil
+}
+
+func (db *memoryDB) SaveWebhookDelivery(delivery domain.WebhookDelivery, collectionName string) error {
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	collection, ok := db.deliveries[collectionName]
+	if !ok {
+		collection = map[string]domain.WebhookDelivery{}
+		db.deliveries[collectionName] = collection
+	}
+
+	collection[delivery.ID] = cloneWebhookDelivery(delivery)
+
+	return nil
+}
+
+func (db *memoryDB) GetWebhookDeliveries(subscriptionId string, limit int, collectionName string) ([]domain.WebhookDelivery, error) {
+
+	return db.findWebhookDeliveries(collectionName, limit, func(delivery domain.WebhookDelivery) bool {
+		return delivery.SubscriptionID == subscriptionId
+	}, func(a, b domain.WebhookDelivery) bool {
+		return a.CreatedAt.After(b.CreatedAt)
+	})
+}
+
+func (db *memoryDB) GetDueWebhookDeliveries(now time.Time, limit int, collectionName string) ([]domain.WebhookDelivery, error) {
+
+	return db.findWebhookDeliveries(collectionName, limit, func(delivery domain.WebhookDelivery) bool {
+		return delivery.Status == domain.WebhookDeliveryPending && !delivery.NextAttemptAt.After(now)
+	}, func(a, b domain.WebhookDelivery) bool {
+		return a.NextAttemptAt.Before(b.NextAttemptAt)
+	})
+}
+
+func (db *memoryDB) findWebhookDeliveries(collectionName string, limit int, match func(domain.WebhookDelivery) bool, less func(a, b domain.WebhookDelivery) bool) ([]domain.WebhookDelivery, error) {
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	deliveries := []domain.WebhookDelivery{}
+	for _, delivery := range db.deliveries[collectionName] {
+		if match(delivery) {
+			deliveries = append(deliveries, cloneWebhookDelivery(delivery))
+		}
+	}
+
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		if less(deliveries[i], deliveries[j]) {
+			return true
+		}
+		if less(deliveries[j], deliveries[i]) {
+			return false
+		}
+		return deliveries[i].ID < deliveries[j].ID
+	})
+
+	if limit > 0 && len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+
+	return deliveries, nil
+}
+
+func cloneWebhookSubscription(subscription domain.WebhookSubscription) domain.WebhookSubscription {
+
+	subscription.Events = append([]domain.WebhookEvent(nil), subscription.Events...)
+	subscription.RepoIDs = append([]int(nil), subscription.RepoIDs...)
+
+	return subscription
+}
+
+func cloneWebhookDelivery(delivery domain.WebhookDelivery) domain.WebhookDelivery {
+
+	delivery.Payload = append([]byte(nil), delivery.Payload...)
+	delivery.Attempts = append([]domain.WebhookAttempt(nil), delivery.Attempts...)
+
+	return delivery
+}
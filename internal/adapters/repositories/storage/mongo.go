@@ -11,6 +11,7 @@ import (
 	"secrets-operator/internal/errors"
 	_ "secrets-operator/internal/errors"
 	"strconv"
+	"sync"
 	"time"
 )
 
@@ -18,6 +19,13 @@ type mongoDB struct {
 	cfg    *config.Config
 	l      *zap.SugaredLogger
 	client *mongo.Client
+	// indexed holds unique indexes which were already created, keyed by collection name and index key
+	indexed sync.Map
+}
+
+type uniqueIndex struct {
+	collection string
+	key        string
 }
 
 func NewMongoDb(cfg *config.Config, l *zap.SugaredLogger) *mongoDB {
@@ -67,7 +75,8 @@ func (db *mongoDB) GetRepoFindingsById(repoId int, collectionName string) (domai
 	repoFindings := domain.RepoFindings{}
 
 	filter := bson.D{{
-		"repoid", repoId,
+		Key:   "repoid",
+		Value: repoId,
 	}}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -88,53 +97,112 @@ func (db *mongoDB) GetRepoFindingsById(repoId int, collectionName string) (domai
 	return repoFindings, nil
 }
 
-// SaveAndUpdateRepoFindingsById TODO: should be optimized, unnecessary variable definitions should be moved
-func (db *mongoDB) SaveAndUpdateRepoFindingsById(repoFindings domain.RepoFindings, repoId int, collectionName string) error {
-
-	repoFindingsOld := domain.RepoFindings{}
-
-	// updating with update operators. Please check following link for more details
-	// https://www.mongodb.com/docs/manual/reference/operator/update/addToSet/#-each-modifier
-	repoFindingsUpdate := bson.D{{
-		"$addToSet",
-		bson.D{{
-			"findings",
-			bson.D{{
-				"$each",
-				repoFindings.Findings,
-			}},
-		}},
-	}}
+// maxMergeAttempts limits how many times merge is retried when repository document changes in between
+const maxMergeAttempts = 5
 
-	filter := bson.D{{
-		Key:   "repoid",
-		Value: repoId,
-	}}
+// repoFindingsDocument adds version used for optimistic concurrency control to repository findings
+type repoFindingsDocument struct {
+	domain.RepoFindings `bson:",inline"`
+	Version             int `bson:"version"`
+}
+
+// SaveAndUpdateRepoFindingsById merges findings of the report into repository findings.
+// Document is read, merged and replaced only if its version did not change meanwhile, otherwise merge is retried.
+// Notification is queued right after the merge, standalone servers have no transactions to make both writes atomic.
+func (db *mongoDB) SaveAndUpdateRepoFindingsById(findingsReport domain.FindingsReport, identity domain.FindingIdentity, repoId int, notify bool, collectionName string) (domain.UpsertResult, error) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	collection := db.client.Database(db.cfg.MongoDBName).Collection(collectionName)
 
-	err := collection.FindOne(ctx, filter).Decode(&repoFindingsOld)
-	if err != nil {
-		switch err {
-		// if this repository does not have any findings
-		case mongo.ErrNoDocuments:
-			_, insertErr := collection.InsertOne(ctx, repoFindings)
-			if insertErr != nil {
-				return err
+	if err := db.ensureRepoIdIndex(ctx, collection); err != nil {
+		return domain.UpsertResult{}, err
+	}
+
+	for attempt := 0; attempt < maxMergeAttempts; attempt++ {
+
+		existing := repoFindingsDocument{}
+		found := true
+
+		err := collection.FindOne(ctx, bson.D{{Key: "repoid", Value: repoId}}).Decode(&existing)
+		if err != nil {
+			if err != mongo.ErrNoDocuments {
+				return domain.UpsertResult{}, err
 			}
-		default:
-			return err
+			found = false
+		}
+
+		now := time.Now().UTC()
+
+		merged, result := existing.RepoFindings.Merge(findingsReport, identity, now)
+		merged.RepoID = repoId
+		document := repoFindingsDocument{RepoFindings: merged, Version: existing.Version + 1}
+
+		if !found {
+			_, err = collection.InsertOne(ctx, document)
+			if mongo.IsDuplicateKeyError(err) {
+				continue
+			}
+			if err != nil {
+				return domain.UpsertResult{}, err
+			}
+			return result, db.queueNotification(notify, findingsReport, result, now)
+		}
+
+		// documents stored before versioning was introduced have no version field
+		version := bson.E{Key: "version", Value: existing.Version}
+		if existing.Version == 0 {
+			version = bson.E{Key: "version", Value: bson.D{{Key: "$exists", Value: false}}}
+		}
+
+		replaced, err := collection.ReplaceOne(ctx, bson.D{{Key: "repoid", Value: repoId}, version}, document)
+		if err != nil {
+			return domain.UpsertResult{}, err
+		}
+		if replaced.MatchedCount == 1 {
+			return result, db.queueNotification(notify, findingsReport, result, now)
 		}
 	}
 
-	_, insertErr := collection.UpdateOne(ctx, filter, repoFindingsUpdate)
-	if insertErr != nil {
+	return domain.UpsertResult{}, errors.ErrConcurrentRepoFindingsUpdate
+}
+
+// queueNotification saves outbox message about new findings of the merged report
+func (db *mongoDB) queueNotification(notify bool, findingsReport domain.FindingsReport, result domain.UpsertResult, now time.Time) error {
+
+	if !notify || len(result.New) == 0 {
+		return nil
+	}
+
+	message := domain.NewOutboxMessage(findingsReport, result.New, now)
+
+	return db.replaceByID(message.ID, message, outboxCollectionName)
+}
+
+// ensureRepoIdIndex creates unique index on repoid once per collection, so concurrent inserts can not create two documents
+func (db *mongoDB) ensureRepoIdIndex(ctx context.Context, collection *mongo.Collection) error {
+
+	return db.ensureUniqueIndex(ctx, collection, "repoid")
+}
+
+// ensureUniqueIndex creates unique index on the key once per collection and key
+func (db *mongoDB) ensureUniqueIndex(ctx context.Context, collection *mongo.Collection, key string) error {
+
+	index := uniqueIndex{collection: collection.Name(), key: key}
+	if _, ok := db.indexed.Load(index); ok {
+		return nil
+	}
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: key, Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
 		return err
 	}
 
+	db.indexed.Store(index, true)
 	return nil
 }
 
@@ -171,3 +239,44 @@ func (db *mongoDB) GetRepositoriesByName(repoName string, collectionName string)
 
 	return response, nil
 }
+
+func (db *mongoDB) UpdateFindingStatus(repoId int, fingerprint string, change domain.StatusChange, collectionName string) error {
+
+	filter := bson.D{
+		{Key: "repoid", Value: repoId},
+		{Key: "findings.fingerprint", Value: fingerprint},
+	}
+
+	// array filters update every copy of the finding, older documents may contain duplicates
+	// https://www.mongodb.com/docs/manual/reference/operator/update/positional-filtered/
+	set := bson.D{{Key: "findings.$[f].status", Value: change.Status}}
+	if change.Assignee != "" {
+		set = append(set, bson.E{Key: "findings.$[f].assignee", Value: change.Assignee})
+	}
+
+	update := bson.D{
+		{Key: "$set", Value: set},
+		{Key: "$push", Value: bson.D{{Key: "findings.$[f].statushistory", Value: change}}},
+		{Key: "$inc", Value: bson.D{{Key: "version", Value: 1}}},
+	}
+
+	updateOptions := options.Update().SetArrayFilters(options.ArrayFilters{
+		Filters: []interface{}{bson.M{"f.fingerprint": fingerprint}},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := db.client.Database(db.cfg.MongoDBName).Collection(collectionName)
+
+	result, err := collection.UpdateOne(ctx, filter, update, updateOptions)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.ErrFindingNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/errors"
+	"time"
+)
+
+func (db *mongoDB) SaveAPIKey(apiKey domain.APIKey, collectionName string) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := db.client.Database(db.cfg.MongoDBName).Collection(collectionName)
+
+	_, err := collection.InsertOne(ctx, apiKey)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (db *mongoDB) GetAPIKeyByHash(keyHash string, collectionName string) (domain.APIKey, error) {
+
+	apiKey := domain.APIKey{}
+
+	filter := bson.D{{
+		Key:   "keyhash",
+		Value: keyHash,
+	}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := db.client.Database(db.cfg.MongoDBName).Collection(collectionName)
+
+	err := collection.FindOne(ctx, filter).Decode(&apiKey)
+	if err != nil {
+		switch err {
+		case mongo.ErrNoDocuments:
+			return domain.APIKey{}, errors.ErrAPIKeyNotFound
+		default:
+			return domain.APIKey{}, err
+		}
+	}
+
+	return apiKey, nil
+}
+
+func (db *mongoDB) GetAPIKeys(collectionName string) ([]domain.APIKey, error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := db.client.Database(db.cfg.MongoDBName).Collection(collectionName)
+
+	cursor, err := collection.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "createdat", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	apiKeys := []domain.APIKey{}
+	if err = cursor.All(ctx, &apiKeys); err != nil {
+		return nil, err
+	}
+
+	return apiKeys, nil
+}
+
+func (db *mongoDB) RevokeAPIKey(id string, revokedAt time.Time, collectionName string) error {
+	return db.setAPIKeyField(id, "revokedat", revokedAt, collectionName)
+}
+
+func (db *mongoDB) TouchAPIKey(id string, usedAt time.Time, collectionName string) error {
+	return db.setAPIKeyField(id, "lastusedat", usedAt, collectionName)
+}
+
+func (db *mongoDB) setAPIKeyField(id string, field string, value time.Time, collectionName string) error {
+
+	filter := bson.D{{Key: "id", Value: id}}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: field, Value: value}}}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := db.client.Database(db.cfg.MongoDBName).Collection(collectionName)
+
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
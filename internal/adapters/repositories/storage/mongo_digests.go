@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"secrets-operator/internal/core/domain"
+	"time"
+)
+
+func (db *mongoDB) SaveDigestEntry(entry domain.DigestEntry, collectionName string) error {
+
+	return db.replaceByID(entry.ID, entry, collectionName)
+}
+
+func (db *mongoDB) GetDueDigestEntries(now time.Time, limit int, collectionName string) ([]domain.DigestEntry, error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := db.client.Database(db.cfg.MongoDBName).Collection(collectionName)
+
+	cursor, err := collection.Find(ctx,
+		bson.D{
+			{Key: "dead", Value: bson.D{{Key: "$ne", Value: true}}},
+			{Key: "dueat", Value: bson.D{{Key: "$lte", Value: now}}},
+		},
+		options.Find().SetSort(bson.D{{Key: "createdat", Value: 1}, {Key: "id", Value: 1}}).SetLimit(int64(limit)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []domain.DigestEntry{}
+	if err = cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (db *mongoDB) DeleteDigestEntries(ids []string, collectionName string) error {
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := db.client.Database(db.cfg.MongoDBName).Collection(collectionName)
+
+	_, err := collection.DeleteMany(ctx, bson.D{{Key: "id", Value: bson.D{{Key: "$in", Value: ids}}}})
+
+	return err
+}
+
+func (db *mongoDB) GetAnnouncedFingerprints(channel string, repoId int, fingerprints []string, collectionName string) ([]string, error) {
+
+	announced := []string{}
+	if len(fingerprints) == 0 {
+		return announced, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := db.client.Database(db.cfg.MongoDBName).Collection(collectionName)
+
+	cursor, err := collection.Find(ctx, bson.D{
+		{Key: "channel", Value: channel},
+		{Key: "repoid", Value: repoId},
+		{Key: "fingerprint", Value: bson.D{{Key: "$in", Value: fingerprints}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var documents []struct {
+		Fingerprint string `bson:"fingerprint"`
+	}
+	if err = cursor.All(ctx, &documents); err != nil {
+		return nil, err
+	}
+
+	for _, document := range documents {
+		announced = append(announced, document.Fingerprint)
+	}
+
+	return announced, nil
+}
+
+func (db *mongoDB) SaveAnnouncements(channel string, repoId int, fingerprints []string, announcedAt time.Time, collectionName string) error {
+
+	if len(fingerprints) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := db.client.Database(db.cfg.MongoDBName).Collection(collectionName)
+
+	// time of the first announcement is kept
+	models := make([]mongo.WriteModel, 0, len(fingerprints))
+	for _, fingerprint := range fingerprints {
+		filter := bson.D{{Key: "channel", Value: channel}, {Key: "repoid", Value: repoId}, {Key: "fingerprint", Value: fingerprint}}
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(filter).
+			SetUpdate(bson.D{{Key: "$setOnInsert", Value: bson.D{{Key: "announcedat", Value: announcedAt}}}}).
+			SetUpsert(true))
+	}
+
+	_, err := collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+
+	return err
+}
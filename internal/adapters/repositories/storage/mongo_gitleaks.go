@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/errors"
+	"time"
+)
+
+// SaveGitleaksConfigVersion inserts the version, unique index on version keeps concurrent changes from taking the same number
+func (db *mongoDB) SaveGitleaksConfigVersion(version domain.GitleaksConfigVersion, collectionName string) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := db.client.Database(db.cfg.MongoDBName).Collection(collectionName)
+
+	if err := db.ensureUniqueIndex(ctx, collection, "version"); err != nil {
+		return err
+	}
+
+	_, err := collection.InsertOne(ctx, version)
+	if mongo.IsDuplicateKeyError(err) {
+		return errors.ErrGitleaksConfigVersionExists
+	}
+
+	return err
+}
+
+func (db *mongoDB) GetGitleaksConfigVersion(version int, collectionName string) (domain.GitleaksConfigVersion, error) {
+
+	return db.findGitleaksConfigVersion(bson.D{{Key: "version", Value: version}}, options.FindOne(), collectionName)
+}
+
+func (db *mongoDB) GetLatestGitleaksConfigVersion(collectionName string) (domain.GitleaksConfigVersion, error) {
+
+	return db.findGitleaksConfigVersion(bson.D{}, options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}}), collectionName)
+}
+
+func (db *mongoDB) GetGitleaksConfigVersions(collectionName string) ([]domain.GitleaksConfigVersion, error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := db.client.Database(db.cfg.MongoDBName).Collection(collectionName)
+
+	cursor, err := collection.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "version", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	versions := []domain.GitleaksConfigVersion{}
+	if err = cursor.All(ctx, &versions); err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+func (db *mongoDB) findGitleaksConfigVersion(filter bson.D, opts *options.FindOneOptions, collectionName string) (domain.GitleaksConfigVersion, error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := db.client.Database(db.cfg.MongoDBName).Collection(collectionName)
+
+	version := domain.GitleaksConfigVersion{}
+
+	err := collection.FindOne(ctx, filter, opts).Decode(&version)
+	if err == mongo.ErrNoDocuments {
+		return domain.GitleaksConfigVersion{}, errors.ErrGitleaksConfigVersionNotFound
+	}
+	if err != nil {
+		return domain.GitleaksConfigVersion{}, err
+	}
+
+	return version, nil
+}
@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/errors"
+	"time"
+)
+
+func (db *mongoDB) SaveOutboxMessage(message domain.OutboxMessage, collectionName string) error {
+
+	return db.replaceByID(message.ID, message, collectionName)
+}
+
+func (db *mongoDB) GetOutboxMessageById(id string, collectionName string) (domain.OutboxMessage, error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := db.client.Database(db.cfg.MongoDBName).Collection(collectionName)
+
+	message := domain.OutboxMessage{}
+
+	err := collection.FindOne(ctx, bson.D{{Key: "id", Value: id}}).Decode(&message)
+	if err == mongo.ErrNoDocuments {
+		return domain.OutboxMessage{}, errors.ErrOutboxMessageNotFound
+	}
+	if err != nil {
+		return domain.OutboxMessage{}, err
+	}
+
+	return message, nil
+}
+
+func (db *mongoDB) GetOutboxMessages(status domain.OutboxStatus, limit int, collectionName string) ([]domain.OutboxMessage, error) {
+
+	return db.findOutboxMessages(
+		bson.D{{Key: "status", Value: status}},
+		bson.D{{Key: "createdat", Value: -1}, {Key: "id", Value: 1}},
+		limit, collectionName,
+	)
+}
+
+func (db *mongoDB) GetDueOutboxMessages(now time.Time, limit int, collectionName string) ([]domain.OutboxMessage, error) {
+
+	return db.findOutboxMessages(
+		bson.D{
+			{Key: "status", Value: domain.OutboxPending},
+			{Key: "nextattemptat", Value: bson.D{{Key: "$lte", Value: now}}},
+		},
+		bson.D{{Key: "nextattemptat", Value: 1}, {Key: "id", Value: 1}},
+		limit, collectionName,
+	)
+}
+
+func (db *mongoDB) findOutboxMessages(filter bson.D, sort bson.D, limit int, collectionName string) ([]domain.OutboxMessage, error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := db.client.Database(db.cfg.MongoDBName).Collection(collectionName)
+
+	cursor, err := collection.Find(ctx, filter, options.Find().SetSort(sort).SetLimit(int64(limit)))
+	if err != nil {
+		return nil, err
+	}
+
+	messages := []domain.OutboxMessage{}
+	if err = cursor.All(ctx, &messages); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/errors"
+	"time"
+)
+
+func (db *mongoDB) SaveRoutingRule(rule domain.RoutingRule, collectionName string) error {
+
+	return db.replaceByID(rule.ID, rule, collectionName)
+}
+
+func (db *mongoDB) GetRoutingRuleById(id string, collectionName string) (domain.RoutingRule, error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := db.client.Database(db.cfg.MongoDBName).Collection(collectionName)
+
+	rule := domain.RoutingRule{}
+
+	err := collection.FindOne(ctx, bson.D{{Key: "id", Value: id}}).Decode(&rule)
+	if err == mongo.ErrNoDocuments {
+		return domain.RoutingRule{}, errors.ErrRoutingRuleNotFound
+	}
+	if err != nil {
+		return domain.RoutingRule{}, err
+	}
+
+	return rule, nil
+}
+
+func (db *mongoDB) GetRoutingRules(collectionName string) ([]domain.RoutingRule, error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := db.client.Database(db.cfg.MongoDBName).Collection(collectionName)
+
+	cursor, err := collection.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "createdat", Value: 1}, {Key: "id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	rules := []domain.RoutingRule{}
+	if err = cursor.All(ctx, &rules); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+func (db *mongoDB) DeleteRoutingRule(id string, collectionName string) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := db.client.Database(db.cfg.MongoDBName).Collection(collectionName)
+
+	result, err := collection.DeleteOne(ctx, bson.D{{Key: "id", Value: id}})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return errors.ErrRoutingRuleNotFound
+	}
+
+	return nil
+}
@@ -1 +1,173 @@
 package storage
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+	"os"
+	"secrets-operator/config"
+	"secrets-operator/internal/core/ports"
+	"testing"
+	"time"
+)
+
+// newMongoTestDB connects to its own database, which is dropped after the test
+func newMongoTestDB(t *testing.T, uri string) *mongoDB {
+
+	cfg := &config.Config{
+		MongoURI:    uri,
+		MongoDBName: fmt.Sprintf("secrets-operator-test-%d", time.Now().UnixNano()),
+	}
+
+	db := NewMongoDb(cfg, zap.NewNop().Sugar())
+	t.Cleanup(func() {
+		_ = db.client.Database(cfg.MongoDBName).Drop(context.Background())
+	})
+	return db
+}
+
+// TestSuiteMongoFindingsRepository runs only when MONGO_TEST_URI is set
+func TestSuiteMongoFindingsRepository(t *testing.T) {
+
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGO_TEST_URI is not set")
+	}
+
+	s := new(FindingsRepositoryTestSuite)
+	s.newRepository = func() ports.FindingsRepository {
+		return newMongoTestDB(t, uri)
+	}
+
+	suite.Run(t, s)
+}
+
+func TestSuiteMongoAPIKeyRepository(t *testing.T) {
+
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGO_TEST_URI is not set")
+	}
+
+	s := new(APIKeyRepositoryTestSuite)
+	s.newRepository = func() ports.APIKeyRepository {
+		return newMongoTestDB(t, uri)
+	}
+
+	suite.Run(t, s)
+}
+
+func TestSuiteMongoWebhookRepository(t *testing.T) {
+
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGO_TEST_URI is not set")
+	}
+
+	s := new(WebhookRepositoryTestSuite)
+	s.newRepository = func() ports.WebhookRepository {
+		return newMongoTestDB(t, uri)
+	}
+
+	suite.Run(t, s)
+}
+
+func TestSuiteMongoOutboxRepository(t *testing.T) {
+
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGO_TEST_URI is not set")
+	}
+
+	s := new(OutboxRepositoryTestSuite)
+	s.newRepository = func() outboxRepository {
+		return newMongoTestDB(t, uri)
+	}
+
+	suite.Run(t, s)
+}
+
+func TestSuiteMongoRoutingRuleRepository(t *testing.T) {
+
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGO_TEST_URI is not set")
+	}
+
+	s := new(RoutingRuleRepositoryTestSuite)
+	s.newRepository = func() ports.RoutingRuleRepository {
+		return newMongoTestDB(t, uri)
+	}
+
+	suite.Run(t, s)
+}
+
+func TestSuiteMongoGitleaksConfigRepository(t *testing.T) {
+
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGO_TEST_URI is not set")
+	}
+
+	s := new(GitleaksConfigRepositoryTestSuite)
+	s.newRepository = func() ports.GitleaksConfigRepository {
+		return newMongoTestDB(t, uri)
+	}
+
+	suite.Run(t, s)
+}
+
+func TestSuiteMongoDigestRepository(t *testing.T) {
+
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGO_TEST_URI is not set")
+	}
+
+	s := new(DigestRepositoryTestSuite)
+	s.newRepository = func() digestRepository {
+		return newMongoTestDB(t, uri)
+	}
+
+	suite.Run(t, s)
+}
+
+func TestMongoDB_EnsureUniqueIndexPerKey(t *testing.T) {
+
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGO_TEST_URI is not set")
+	}
+
+	// arrange
+	ctx := context.Background()
+	db := newMongoTestDB(t, uri)
+	collection := db.client.Database(db.cfg.MongoDBName).Collection("indexes")
+
+	// act
+	errRepo := db.ensureUniqueIndex(ctx, collection, "repoid")
+	errVersion := db.ensureUniqueIndex(ctx, collection, "version")
+
+	// assert
+	assert.NoError(t, errRepo)
+	assert.NoError(t, errVersion)
+
+	cursor, err := collection.Indexes().List(ctx)
+	if !assert.NoError(t, err) {
+		return
+	}
+	var indexes []bson.M
+	if !assert.NoError(t, cursor.All(ctx, &indexes)) {
+		return
+	}
+
+	names := []string{}
+	for _, index := range indexes {
+		names = append(names, index["name"].(string))
+	}
+	assert.Contains(t, names, "repoid_1")
+	assert.Contains(t, names, "version_1")
+}
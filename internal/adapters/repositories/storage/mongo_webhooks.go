@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/errors"
+	"time"
+)
+
+func (db *mongoDB) SaveWebhookSubscription(subscription domain.WebhookSubscription, collectionName string) error {
+
+	return db.replaceByID(subscription.ID, subscription, collectionName)
+}
+
+func (db *mongoDB) GetWebhookSubscriptions(collectionName string) ([]domain.WebhookSubscription, error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := db.client.Database(db.cfg.MongoDBName).Collection(collectionName)
+
+	cursor, err := collection.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "createdat", Value: 1}, {Key: "id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	subscriptions := []domain.WebhookSubscription{}
+	if err = cursor.All(ctx, &subscriptions); err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func (db *mongoDB) DeleteWebhookSubscription(id string, collectionName string) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := db.client.Database(db.cfg.MongoDBName).Collection(collectionName)
+
+	result, err := collection.DeleteOne(ctx, bson.D{{Key: "id", Value: id}})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return errors.ErrWebhookSubscriptionNotFound
+	}
+
+	return nil
+}
+
+func (db *mongoDB) SaveWebhookDelivery(delivery domain.WebhookDelivery, collectionName string) error {
+
+	return db.replaceByID(delivery.ID, delivery, collectionName)
+}
+
+func (db *mongoDB) GetWebhookDeliveries(subscriptionId string, limit int, collectionName string) ([]domain.WebhookDelivery, error) {
+
+	return db.findWebhookDeliveries(
+		bson.D{{Key: "subscriptionid", Value: subscriptionId}},
+		bson.D{{Key: "createdat", Value: -1}, {Key: "id", Value: 1}},
+		limit, collectionName,
+	)
+}
+
+func (db *mongoDB) GetDueWebhookDeliveries(now time.Time, limit int, collectionName string) ([]domain.WebhookDelivery, error) {
+
+	return db.findWebhookDeliveries(
+		bson.D{
+			{Key: "status", Value: domain.WebhookDeliveryPending},
+			{Key: "nextattemptat", Value: bson.D{{Key: "$lte", Value: now}}},
+		},
+		bson.D{{Key: "nextattemptat", Value: 1}, {Key: "id", Value: 1}},
+		limit, collectionName,
+	)
+}
+
+func (db *mongoDB) findWebhookDeliveries(filter bson.D, sort bson.D, limit int, collectionName string) ([]domain.WebhookDelivery, error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := db.client.Database(db.cfg.MongoDBName).Collection(collectionName)
+
+	cursor, err := collection.Find(ctx, filter, options.Find().SetSort(sort).SetLimit(int64(limit)))
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := []domain.WebhookDelivery{}
+	if err = cursor.All(ctx, &deliveries); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// replaceByID inserts the document or replaces the stored one with the same id field
+func (db *mongoDB) replaceByID(id string, document interface{}, collectionName string) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := db.client.Database(db.cfg.MongoDBName).Collection(collectionName)
+
+	_, err := collection.ReplaceOne(ctx, bson.D{{Key: "id", Value: id}}, document, options.Replace().SetUpsert(true))
+
+	return err
+}
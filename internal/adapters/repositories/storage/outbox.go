@@ -0,0 +1,4 @@
+package storage
+
+// outboxCollectionName is where SaveAndUpdateRepoFindingsById queues notifications about new findings
+const outboxCollectionName = "outbox"
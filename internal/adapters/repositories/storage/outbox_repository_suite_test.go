@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/core/ports"
+	"secrets-operator/internal/errors"
+	"time"
+)
+
+// outboxRepository is queued to by the findings repository, so both are tested together
+type outboxRepository interface {
+	ports.FindingsRepository
+	ports.OutboxRepository
+}
+
+// OutboxRepositoryTestSuite describes behaviour shared by every ports.OutboxRepository implementation
+type OutboxRepositoryTestSuite struct {
+	suite.Suite
+	newRepository func() outboxRepository
+	sut           outboxRepository
+	createdAt     time.Time
+}
+
+func (s *OutboxRepositoryTestSuite) SetupTest() {
+
+	s.sut = s.newRepository()
+
+	// mongo keeps milliseconds only, so test dates are rounded to seconds
+	s.createdAt = time.Date(2022, 12, 3, 12, 48, 14, 0, time.UTC)
+}
+
+func (s *OutboxRepositoryTestSuite) report(fingerprints ...string) domain.FindingsReport {
+
+	report := domain.FindingsReport{
+		ID:           "r1",
+		PipelineID:   7,
+		RepoID:       1,
+		RepoName:     "Testing Repo",
+		RepoURL:      "https://gitlab.com/testing-repo",
+		CommitAuthor: "test author",
+		CommitSHA:    "a85af84d39a32da2c8eba1d88019079aeb0741b0",
+		Timestamp:    s.createdAt,
+	}
+
+	for _, fingerprint := range fingerprints {
+		report.Findings = append(report.Findings, domain.Finding{
+			Description: "test",
+			Secret:      "REDACTED",
+			SecretHash:  fingerprint + "-hash",
+			File:        "test file",
+			Commit:      "a85af84d39a32da2c8eba1d88019079aeb0741b0",
+			Date:        s.createdAt,
+			Fingerprint: fingerprint,
+		})
+	}
+
+	return report
+}
+
+func (s *OutboxRepositoryTestSuite) message(id string, status domain.OutboxStatus, nextAttemptAt time.Time) domain.OutboxMessage {
+
+	return domain.OutboxMessage{
+		ID:            id,
+		ReportID:      "r-" + id,
+		RepoID:        1,
+		Report:        s.report("first"),
+		Status:        status,
+		NextAttemptAt: nextAttemptAt,
+		CreatedAt:     nextAttemptAt,
+	}
+}
+
+func (s *OutboxRepositoryTestSuite) TestSaveAndUpdateRepoFindingsById_QueuesNewFindings() {
+
+	// act
+	_, err := s.sut.SaveAndUpdateRepoFindingsById(s.report("first"), domain.FindingIdentityFingerprint, 1, true, "repositories")
+	assert.NoError(s.T(), err)
+
+	_, err = s.sut.SaveAndUpdateRepoFindingsById(s.report("first", "second"), domain.FindingIdentityFingerprint, 1, true, "repositories")
+	assert.NoError(s.T(), err)
+
+	_, err = s.sut.SaveAndUpdateRepoFindingsById(s.report("first", "second"), domain.FindingIdentityFingerprint, 1, true, "repositories")
+	assert.NoError(s.T(), err)
+
+	// assert
+	due, err := s.sut.GetDueOutboxMessages(time.Now().Add(time.Minute), 10, "outbox")
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), due, 2, "upload without new findings queues nothing")
+
+	notified := []string{}
+	for _, message := range due {
+		assert.Equal(s.T(), "r1", message.ReportID)
+		assert.Equal(s.T(), domain.OutboxPending, message.Status)
+		for _, finding := range message.Report.Findings {
+			notified = append(notified, finding.Fingerprint)
+		}
+	}
+	assert.ElementsMatch(s.T(), []string{"first", "second"}, notified, "only new findings are notified about")
+}
+
+func (s *OutboxRepositoryTestSuite) TestSaveAndUpdateRepoFindingsById_WithoutNotify() {
+
+	// act
+	_, err := s.sut.SaveAndUpdateRepoFindingsById(s.report("first"), domain.FindingIdentityFingerprint, 1, false, "repositories")
+	assert.NoError(s.T(), err)
+
+	// assert
+	due, err := s.sut.GetDueOutboxMessages(time.Now().Add(time.Minute), 10, "outbox")
+	assert.NoError(s.T(), err)
+	assert.Empty(s.T(), due)
+}
+
+func (s *OutboxRepositoryTestSuite) TestSaveAndGetOutboxMessages() {
+
+	// arrange
+	dead := s.message("b2", domain.OutboxDead, s.createdAt.Add(time.Hour))
+	dead.AttemptCount = 3
+	dead.Channels = []string{"slack"}
+	dead.Attempts = []domain.OutboxAttempt{{At: s.createdAt, FailedChannels: []string{"slack"}, Error: "timeout", DurationMs: 30}}
+
+	assert.NoError(s.T(), s.sut.SaveOutboxMessage(s.message("a1", domain.OutboxDead, s.createdAt), "outbox"))
+	assert.NoError(s.T(), s.sut.SaveOutboxMessage(dead, "outbox"))
+	assert.NoError(s.T(), s.sut.SaveOutboxMessage(s.message("c3", domain.OutboxDelivered, s.createdAt), "outbox"))
+
+	// act
+	messages, err := s.sut.GetOutboxMessages(domain.OutboxDead, 10, "outbox")
+
+	// assert
+	assert.NoError(s.T(), err)
+	if assert.Len(s.T(), messages, 2) {
+		assert.Equal(s.T(), "b2", messages[0].ID, "newest first")
+		assert.Equal(s.T(), []string{"slack"}, messages[0].Channels)
+		assert.Equal(s.T(), dead.Attempts, messages[0].Attempts)
+		assert.Equal(s.T(), "a1", messages[1].ID)
+	}
+
+	message, err := s.sut.GetOutboxMessageById("b2", "outbox")
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), 3, message.AttemptCount)
+	assert.Equal(s.T(), "r-b2", message.ReportID)
+	assert.Equal(s.T(), s.report("first").Findings, message.Report.Findings)
+
+	_, err = s.sut.GetOutboxMessageById("d4", "outbox")
+	assert.Equal(s.T(), errors.ErrOutboxMessageNotFound, err)
+}
+
+func (s *OutboxRepositoryTestSuite) TestGetDueOutboxMessages() {
+
+	// arrange
+	now := s.createdAt.Add(time.Hour)
+
+	assert.NoError(s.T(), s.sut.SaveOutboxMessage(s.message("late", domain.OutboxPending, now.Add(-time.Minute)), "outbox"))
+	assert.NoError(s.T(), s.sut.SaveOutboxMessage(s.message("early", domain.OutboxPending, now.Add(-time.Hour)), "outbox"))
+	assert.NoError(s.T(), s.sut.SaveOutboxMessage(s.message("future", domain.OutboxPending, now.Add(time.Minute)), "outbox"))
+	assert.NoError(s.T(), s.sut.SaveOutboxMessage(s.message("dead", domain.OutboxDead, now.Add(-time.Hour)), "outbox"))
+
+	// replacing message must not duplicate it
+	replayed := s.message("dead", domain.OutboxDead, now.Add(-time.Hour))
+	replayed.Replay(now)
+	assert.NoError(s.T(), s.sut.SaveOutboxMessage(replayed, "outbox"))
+
+	// act
+	due, err := s.sut.GetDueOutboxMessages(now, 10, "outbox")
+
+	// assert
+	assert.NoError(s.T(), err)
+	ids := []string{}
+	for _, message := range due {
+		ids = append(ids, message.ID)
+	}
+	assert.Equal(s.T(), []string{"early", "late", "dead"}, ids, "oldest due first")
+
+	limited, err := s.sut.GetDueOutboxMessages(now, 1, "outbox")
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), limited, 1)
+}
@@ -0,0 +1,333 @@
+package storage
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/core/ports"
+	"secrets-operator/internal/errors"
+	"time"
+)
+
+// FindingsRepositoryTestSuite describes behaviour shared by every ports.FindingsRepository implementation.
+// Adapter specific tests only provide a constructor returning an empty repository.
+type FindingsRepositoryTestSuite struct {
+	suite.Suite
+	newRepository func() ports.FindingsRepository
+	sut           ports.FindingsRepository
+	findingDate   time.Time
+}
+
+func (s *FindingsRepositoryTestSuite) SetupTest() {
+
+	s.sut = s.newRepository()
+
+	// mongo keeps milliseconds only, so test dates are rounded to seconds
+	s.findingDate = time.Date(2022, 12, 3, 12, 48, 14, 0, time.UTC)
+}
+
+// report returns findings report of repository 1, uploaded by the given pipeline
+func (s *FindingsRepositoryTestSuite) report(pipelineId int, fingerprints ...string) domain.FindingsReport {
+
+	report := domain.FindingsReport{
+		PipelineID:   pipelineId,
+		RepoID:       1,
+		RepoName:     "Testing Repo",
+		RepoURL:      "https://gitlab.com/testing-repo",
+		CommitAuthor: "test author",
+		CommitSHA:    "a85af84d39a32da2c8eba1d88019079aeb0741b0",
+		Timestamp:    s.findingDate,
+		Findings:     domain.Findings{},
+	}
+
+	for _, fingerprint := range fingerprints {
+		report.Findings = append(report.Findings, domain.Finding{
+			Description: "test",
+			StartLine:   1,
+			EndLine:     1,
+			StartColumn: 1,
+			EndColumn:   1,
+			Match:       "test match",
+			Secret:      "REDACTED",
+			SecretHash:  fingerprint + "-hash",
+			File:        "test file",
+			Commit:      "a85af84d39a32da2c8eba1d88019079aeb0741b0",
+			Entropy:     3.3822913,
+			Author:      "test author",
+			Email:       "test@mail.com",
+			Date:        s.findingDate,
+			Message:     "test message",
+			Tags:        []string{"test", "tags"},
+			RuleID:      "test ruleId",
+			Fingerprint: fingerprint,
+		})
+	}
+
+	return report
+}
+
+func fingerprintsOf(findings domain.Findings) []string {
+
+	fingerprints := []string{}
+	for _, finding := range findings {
+		fingerprints = append(fingerprints, finding.Fingerprint)
+	}
+
+	return fingerprints
+}
+
+func (s *FindingsRepositoryTestSuite) TestSaveFindingsReport() {
+
+	report := domain.FindingsReport{
+		PipelineID:   1,
+		RepoName:     "Testing Repo",
+		RepoID:       1,
+		RepoURL:      "https://gitlab.com/testing-repo",
+		CommitAuthor: "test author",
+		CommitSHA:    "a85af84d39a32da2c8eba1d88019079aeb0741b0",
+		Timestamp:    s.findingDate,
+		Findings:     s.report(7, "first").Findings,
+	}
+
+	assert.NoError(s.T(), s.sut.SaveFindingsReport(report, "findings"))
+	assert.NoError(s.T(), s.sut.SaveFindingsReport(report, "findings"), "same report can be uploaded twice")
+}
+
+func (s *FindingsRepositoryTestSuite) TestGetRepoFindingsById_UnknownRepository() {
+
+	_, err := s.sut.GetRepoFindingsById(404, "repositories")
+
+	assert.Equal(s.T(), errors.ErrRepositoryNotFound, err)
+}
+
+func (s *FindingsRepositoryTestSuite) TestSaveAndUpdateRepoFindingsById() {
+
+	// arrange & act
+	result, err := s.sut.SaveAndUpdateRepoFindingsById(s.report(7, "first", "second"), domain.FindingIdentityFingerprint, 1, false, "repositories")
+	assert.NoError(s.T(), err)
+
+	repoFindings, err := s.sut.GetRepoFindingsById(1, "repositories")
+
+	// assert
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{"first", "second"}, fingerprintsOf(result.New))
+	assert.Empty(s.T(), result.Known)
+
+	assert.Equal(s.T(), 1, repoFindings.RepoID)
+	assert.Equal(s.T(), "Testing Repo", repoFindings.RepoName)
+	assert.Equal(s.T(), "https://gitlab.com/testing-repo", repoFindings.RepoURL)
+	assert.Equal(s.T(), []string{"first", "second"}, fingerprintsOf(repoFindings.Findings))
+
+	finding := repoFindings.Findings[0]
+	assert.Equal(s.T(), result.New[0].ID, finding.ID)
+	assert.Len(s.T(), finding.ID, 24)
+	assert.True(s.T(), s.findingDate.Equal(finding.Date))
+	assert.False(s.T(), finding.FirstSeen.IsZero())
+	assert.True(s.T(), finding.FirstSeen.Equal(finding.LastSeen))
+	assert.Equal(s.T(), []string{"test", "tags"}, finding.Tags)
+	assert.Equal(s.T(), 3.3822913, finding.Entropy)
+	assert.Equal(s.T(), domain.FindingStatusOpen, finding.Status)
+	assert.Equal(s.T(), 1, finding.OccurrenceCount)
+	if assert.Len(s.T(), finding.Occurrences, 1) {
+		assert.Equal(s.T(), 7, finding.Occurrences[0].PipelineID)
+		assert.Equal(s.T(), "a85af84d39a32da2c8eba1d88019079aeb0741b0", finding.Occurrences[0].CommitSHA)
+	}
+}
+
+func (s *FindingsRepositoryTestSuite) TestSaveAndUpdateRepoFindingsById_DeduplicatesKnownFindings() {
+
+	// arrange
+	first, err := s.sut.SaveAndUpdateRepoFindingsById(s.report(7, "first", "second"), domain.FindingIdentityFingerprint, 1, false, "repositories")
+	assert.NoError(s.T(), err)
+
+	// re-run of the scan reports known finding with slightly different values
+	report := s.report(8, "second", "third")
+	report.RepoName = "Renamed Repo"
+	report.Findings[0].Date = s.findingDate.Add(time.Hour)
+	report.Findings[0].Entropy = 3.38229
+
+	// act
+	result, err := s.sut.SaveAndUpdateRepoFindingsById(report, domain.FindingIdentityFingerprint, 1, false, "repositories")
+
+	// assert
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{"third"}, fingerprintsOf(result.New))
+	assert.Equal(s.T(), []string{"second"}, fingerprintsOf(result.Known))
+
+	repoFindings, err := s.sut.GetRepoFindingsById(1, "repositories")
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "Renamed Repo", repoFindings.RepoName)
+	assert.Equal(s.T(), []string{"first", "second", "third"}, fingerprintsOf(repoFindings.Findings))
+
+	second := repoFindings.Findings[1]
+	assert.Equal(s.T(), first.New[1].ID, second.ID)
+	assert.Equal(s.T(), 2, second.OccurrenceCount)
+	assert.Len(s.T(), second.Occurrences, 2)
+}
+
+func (s *FindingsRepositoryTestSuite) TestSaveAndUpdateRepoFindingsById_SameReportTwice() {
+
+	// arrange
+	_, err := s.sut.SaveAndUpdateRepoFindingsById(s.report(7, "first", "first"), domain.FindingIdentityFingerprint, 1, false, "repositories")
+	assert.NoError(s.T(), err)
+
+	// act
+	result, err := s.sut.SaveAndUpdateRepoFindingsById(s.report(7, "first"), domain.FindingIdentityFingerprint, 1, false, "repositories")
+
+	// assert
+	assert.NoError(s.T(), err)
+	assert.Empty(s.T(), result.New)
+	assert.Equal(s.T(), []string{"first"}, fingerprintsOf(result.Known))
+
+	repoFindings, err := s.sut.GetRepoFindingsById(1, "repositories")
+	assert.NoError(s.T(), err)
+	if assert.Len(s.T(), repoFindings.Findings, 1) {
+		assert.Equal(s.T(), 1, repoFindings.Findings[0].OccurrenceCount)
+	}
+}
+
+func (s *FindingsRepositoryTestSuite) TestSaveAndUpdateRepoFindingsById_LocationIdentity() {
+
+	// arrange
+	_, err := s.sut.SaveAndUpdateRepoFindingsById(s.report(7, "first"), domain.FindingIdentityLocation, 1, false, "repositories")
+	assert.NoError(s.T(), err)
+
+	// same secret in the same file, but in a later commit and therefore with another fingerprint
+	report := s.report(8, "moved")
+	report.Findings[0].SecretHash = "first-hash"
+
+	// act
+	result, err := s.sut.SaveAndUpdateRepoFindingsById(report, domain.FindingIdentityLocation, 1, false, "repositories")
+
+	// assert
+	assert.NoError(s.T(), err)
+	assert.Empty(s.T(), result.New)
+	assert.Equal(s.T(), []string{"first"}, fingerprintsOf(result.Known))
+}
+
+func (s *FindingsRepositoryTestSuite) TestSaveAndUpdateRepoFindingsById_KeepsStatus() {
+
+	// arrange
+	_, err := s.sut.SaveAndUpdateRepoFindingsById(s.report(7, "first"), domain.FindingIdentityFingerprint, 1, false, "repositories")
+	assert.NoError(s.T(), err)
+
+	change := domain.StatusChange{Status: domain.FindingStatusFalsePositive, ChangedBy: "test user", ChangedAt: s.findingDate}
+	assert.NoError(s.T(), s.sut.UpdateFindingStatus(1, "first", change, "repositories"))
+
+	// act
+	_, err = s.sut.SaveAndUpdateRepoFindingsById(s.report(8, "first"), domain.FindingIdentityFingerprint, 1, false, "repositories")
+
+	// assert
+	assert.NoError(s.T(), err)
+
+	repoFindings, err := s.sut.GetRepoFindingsById(1, "repositories")
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), domain.FindingStatusFalsePositive, repoFindings.Findings[0].Status)
+	assert.Len(s.T(), repoFindings.Findings[0].StatusHistory, 1)
+}
+
+func (s *FindingsRepositoryTestSuite) TestGetRepositoriesByName() {
+
+	first := s.report(7, "first")
+	second := s.report(8, "second")
+	second.RepoID = 2
+	second.RepoName = "Another_Project"
+
+	_, err := s.sut.SaveAndUpdateRepoFindingsById(first, domain.FindingIdentityFingerprint, first.RepoID, false, "repositories")
+	assert.NoError(s.T(), err)
+	_, err = s.sut.SaveAndUpdateRepoFindingsById(second, domain.FindingIdentityFingerprint, second.RepoID, false, "repositories")
+	assert.NoError(s.T(), err)
+
+	tests := []struct {
+		name  string
+		input string
+		want  []map[string]string
+	}{
+		{"case insensitive substring", "testing", []map[string]string{{"id": "1", "name": "Testing Repo"}}},
+		{"matches every repository", "o", []map[string]string{{"id": "1", "name": "Testing Repo"}, {"id": "2", "name": "Another_Project"}}},
+		{"no repositories", "unknown", nil},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			repositories, err := s.sut.GetRepositoriesByName(tt.input, "repositories")
+
+			assert.NoError(s.T(), err)
+			assert.ElementsMatch(s.T(), tt.want, repositories)
+		})
+	}
+}
+
+func (s *FindingsRepositoryTestSuite) TestUpdateFindingStatus() {
+
+	// arrange
+	_, err := s.sut.SaveAndUpdateRepoFindingsById(s.report(7, "first", "second"), domain.FindingIdentityFingerprint, 1, false, "repositories")
+	assert.NoError(s.T(), err)
+
+	change := domain.StatusChange{
+		Status:    domain.FindingStatusRevoked,
+		ChangedBy: "test user",
+		ChangedAt: s.findingDate,
+		Comment:   "rotated",
+	}
+
+	// act
+	err = s.sut.UpdateFindingStatus(1, "second", change, "repositories")
+
+	// assert
+	assert.NoError(s.T(), err)
+
+	repoFindings, err := s.sut.GetRepoFindingsById(1, "repositories")
+	assert.NoError(s.T(), err)
+
+	for _, finding := range repoFindings.Findings {
+		switch finding.Fingerprint {
+		case "first":
+			assert.Equal(s.T(), domain.FindingStatusOpen, finding.Status)
+			assert.Empty(s.T(), finding.StatusHistory)
+		case "second":
+			assert.Equal(s.T(), domain.FindingStatusRevoked, finding.Status)
+			if assert.Len(s.T(), finding.StatusHistory, 1) {
+				assert.Equal(s.T(), "test user", finding.StatusHistory[0].ChangedBy)
+				assert.Equal(s.T(), "rotated", finding.StatusHistory[0].Comment)
+				assert.True(s.T(), s.findingDate.Equal(finding.StatusHistory[0].ChangedAt))
+			}
+		}
+	}
+}
+
+func (s *FindingsRepositoryTestSuite) TestUpdateFindingStatus_Assignee() {
+
+	// arrange
+	_, err := s.sut.SaveAndUpdateRepoFindingsById(s.report(7, "first"), domain.FindingIdentityFingerprint, 1, false, "repositories")
+	assert.NoError(s.T(), err)
+
+	assign := domain.StatusChange{Status: domain.FindingStatusAcknowledged, ChangedBy: "slack:jane", ChangedAt: s.findingDate, Assignee: "jane"}
+	revoke := domain.StatusChange{Status: domain.FindingStatusRevoked, ChangedBy: "test user", ChangedAt: s.findingDate}
+
+	// act
+	assert.NoError(s.T(), s.sut.UpdateFindingStatus(1, "first", assign, "repositories"))
+	assert.NoError(s.T(), s.sut.UpdateFindingStatus(1, "first", revoke, "repositories"))
+
+	// assert
+	repoFindings, err := s.sut.GetRepoFindingsById(1, "repositories")
+	assert.NoError(s.T(), err)
+	if assert.Len(s.T(), repoFindings.Findings, 1) {
+		assert.Equal(s.T(), domain.FindingStatusRevoked, repoFindings.Findings[0].Status)
+		assert.Equal(s.T(), "jane", repoFindings.Findings[0].Assignee, "change without assignee keeps the assignee")
+		assert.Len(s.T(), repoFindings.Findings[0].StatusHistory, 2)
+	}
+}
+
+func (s *FindingsRepositoryTestSuite) TestUpdateFindingStatus_UnknownFinding() {
+
+	_, err := s.sut.SaveAndUpdateRepoFindingsById(s.report(7, "first"), domain.FindingIdentityFingerprint, 1, false, "repositories")
+	assert.NoError(s.T(), err)
+
+	change := domain.StatusChange{Status: domain.FindingStatusRevoked, ChangedBy: "test user"}
+
+	assert.Equal(s.T(), errors.ErrFindingNotFound, s.sut.UpdateFindingStatus(1, "unknown", change, "repositories"))
+	assert.Equal(s.T(), errors.ErrFindingNotFound, s.sut.UpdateFindingStatus(404, "first", change, "repositories"))
+}
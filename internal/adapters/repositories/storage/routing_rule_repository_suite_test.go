@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/core/ports"
+	"secrets-operator/internal/errors"
+	"time"
+)
+
+// RoutingRuleRepositoryTestSuite describes behaviour shared by every ports.RoutingRuleRepository implementation
+type RoutingRuleRepositoryTestSuite struct {
+	suite.Suite
+	newRepository func() ports.RoutingRuleRepository
+	sut           ports.RoutingRuleRepository
+	createdAt     time.Time
+}
+
+func (s *RoutingRuleRepositoryTestSuite) SetupTest() {
+
+	s.sut = s.newRepository()
+
+	// mongo keeps milliseconds only, so test dates are rounded to seconds
+	s.createdAt = time.Date(2022, 12, 3, 12, 48, 14, 0, time.UTC)
+}
+
+func (s *RoutingRuleRepositoryTestSuite) rule(id string, createdAt time.Time) domain.RoutingRule {
+
+	return domain.RoutingRule{
+		ID:           id,
+		Description:  "test rule " + id,
+		RepoIDs:      []int{444},
+		RepoNameGlob: "payments-*",
+		GroupPrefix:  "platform/payments",
+		RuleIDs:      []string{"aws-access-token"},
+		Tags:         []string{"key"},
+		Targets: []domain.NotificationTarget{
+			{Type: domain.NotificationTargetSlack, Destination: "C0123456"},
+			{Type: domain.NotificationTargetEmail, Destination: "payments@example.com"},
+		},
+		CreatedBy: "test admin",
+		CreatedAt: createdAt,
+	}
+}
+
+func (s *RoutingRuleRepositoryTestSuite) TestSaveGetAndDeleteRoutingRules() {
+
+	// arrange
+	assert.NoError(s.T(), s.sut.SaveRoutingRule(s.rule("b2", s.createdAt.Add(time.Hour)), "routingrules"))
+	assert.NoError(s.T(), s.sut.SaveRoutingRule(s.rule("a1", s.createdAt), "routingrules"))
+
+	// act
+	rules, err := s.sut.GetRoutingRules("routingrules")
+
+	// assert
+	assert.NoError(s.T(), err)
+	if assert.Len(s.T(), rules, 2) {
+		assert.Equal(s.T(), "a1", rules[0].ID, "rules are ordered by creation time")
+		assert.Equal(s.T(), []int{444}, rules[0].RepoIDs)
+		assert.Equal(s.T(), "payments-*", rules[0].RepoNameGlob)
+		assert.Equal(s.T(), "platform/payments", rules[0].GroupPrefix)
+		assert.Equal(s.T(), []string{"aws-access-token"}, rules[0].RuleIDs)
+		assert.Equal(s.T(), []string{"key"}, rules[0].Tags)
+		assert.Equal(s.T(), s.rule("a1", s.createdAt).Targets, rules[0].Targets)
+		assert.Equal(s.T(), "test admin", rules[0].CreatedBy)
+		assert.True(s.T(), s.createdAt.Equal(rules[0].CreatedAt))
+		assert.Equal(s.T(), "b2", rules[1].ID)
+	}
+
+	assert.NoError(s.T(), s.sut.DeleteRoutingRule("a1", "routingrules"))
+	assert.Equal(s.T(), errors.ErrRoutingRuleNotFound, s.sut.DeleteRoutingRule("a1", "routingrules"))
+
+	rules, err = s.sut.GetRoutingRules("routingrules")
+	assert.NoError(s.T(), err)
+	if assert.Len(s.T(), rules, 1) {
+		assert.Equal(s.T(), "b2", rules[0].ID)
+	}
+}
+
+func (s *RoutingRuleRepositoryTestSuite) TestSaveRoutingRule_ReplacesExistingRule() {
+
+	// arrange
+	rule := s.rule("a1", s.createdAt)
+	assert.NoError(s.T(), s.sut.SaveRoutingRule(rule, "routingrules"))
+
+	rule.Description = "updated"
+	rule.Targets = []domain.NotificationTarget{{Type: domain.NotificationTargetWebhook, Destination: "https://hooks.example.com/a1"}}
+
+	// act
+	err := s.sut.SaveRoutingRule(rule, "routingrules")
+
+	// assert
+	assert.NoError(s.T(), err)
+
+	saved, err := s.sut.GetRoutingRuleById("a1", "routingrules")
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "updated", saved.Description)
+	assert.Equal(s.T(), rule.Targets, saved.Targets)
+
+	rules, err := s.sut.GetRoutingRules("routingrules")
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), rules, 1)
+}
+
+func (s *RoutingRuleRepositoryTestSuite) TestGetRoutingRules_ReturnsEmptyListWithoutRules() {
+
+	// act
+	rules, err := s.sut.GetRoutingRules("routingrules")
+
+	// assert
+	assert.NoError(s.T(), err)
+	assert.NotNil(s.T(), rules)
+	assert.Empty(s.T(), rules)
+}
+
+func (s *RoutingRuleRepositoryTestSuite) TestGetRoutingRuleById_ReturnsNotFound() {
+
+	// act
+	_, err := s.sut.GetRoutingRuleById("missing", "routingrules")
+
+	// assert
+	assert.Equal(s.T(), errors.ErrRoutingRuleNotFound, err)
+}
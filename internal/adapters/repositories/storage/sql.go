@@ -0,0 +1,368 @@
+package storage
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+	"go.uber.org/zap"
+	"secrets-operator/config"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	driverPostgres = "postgres"
+	driverSQLite   = "sqlite3"
+)
+
+// sqlDB stores findings in PostgreSQL or SQLite.
+// Tables are fixed by migrations, so collection names passed by the service layer are ignored.
+// Finding documents are stored as JSON, only the fields used for lookups have their own columns.
+type sqlDB struct {
+	cfg    *config.Config
+	l      *zap.SugaredLogger
+	conn   *sql.DB
+	driver string
+}
+
+func NewPostgres(cfg *config.Config, l *zap.SugaredLogger) (*sqlDB, error) {
+	return newSQLDB(cfg, l, driverPostgres, cfg.PostgresDSN)
+}
+
+// NewSQLite opens embedded database at cfg.SQLitePath, ":memory:" can be used for throwaway databases
+func NewSQLite(cfg *config.Config, l *zap.SugaredLogger) (*sqlDB, error) {
+	return newSQLDB(cfg, l, driverSQLite, cfg.SQLitePath+"?_foreign_keys=on&_busy_timeout=5000")
+}
+
+func newSQLDB(cfg *config.Config, l *zap.SugaredLogger, driver string, dsn string) (*sqlDB, error) {
+
+	conn, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// sqlite allows a single writer only, and every connection to ":memory:" is a separate database
+	if driver == driverSQLite {
+		conn.SetMaxOpenConns(1)
+	}
+
+	if err = conn.Ping(); err != nil {
+		return nil, err
+	}
+
+	db := &sqlDB{
+		cfg:    cfg,
+		l:      l,
+		conn:   conn,
+		driver: driver,
+	}
+
+	if err = db.migrate(); err != nil {
+		return nil, err
+	}
+
+	l.Infoln("Connected to SQL database using driver", driver)
+	return db, nil
+}
+
+func (db *sqlDB) SaveFindingsReport(findingsReport domain.FindingsReport, collectionName string) error {
+
+	document, err := json.Marshal(findingsReport)
+	if err != nil {
+		return err
+	}
+
+	id := findingsReport.ID
+	if id == "" {
+		id = newDocumentID()
+	}
+
+	_, err = db.conn.Exec(
+		db.rebind(`INSERT INTO findings_reports (id, repo_id, pipeline_id, commit_sha, document) VALUES (?, ?, ?, ?, ?)`),
+		id, findingsReport.RepoID, findingsReport.PipelineID, findingsReport.CommitSHA, string(document),
+	)
+
+	return err
+}
+
+func (db *sqlDB) GetRepoFindingsById(repoId int, collectionName string) (domain.RepoFindings, error) {
+
+	repoFindings := domain.RepoFindings{RepoID: repoId}
+
+	err := db.conn.QueryRow(
+		db.rebind(`SELECT repo_name, repo_url FROM repositories WHERE repo_id = ?`), repoId,
+	).Scan(&repoFindings.RepoName, &repoFindings.RepoURL)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return domain.RepoFindings{}, errors.ErrRepositoryNotFound
+		default:
+			return domain.RepoFindings{}, err
+		}
+	}
+
+	repoFindings.Findings, err = db.readFindings(db.conn, repoId)
+	if err != nil {
+		return domain.RepoFindings{}, err
+	}
+
+	return repoFindings, nil
+}
+
+// SaveAndUpdateRepoFindingsById merges findings of the report into repository findings and queues notification in the same transaction.
+// Upserting the repository row first locks it until commit, which serializes concurrent uploads to the same repository.
+func (db *sqlDB) SaveAndUpdateRepoFindingsById(findingsReport domain.FindingsReport, identity domain.FindingIdentity, repoId int, notify bool, collectionName string) (domain.UpsertResult, error) {
+
+	var result domain.UpsertResult
+
+	err := db.inTx(func(tx *sql.Tx) error {
+
+		_, err := tx.Exec(
+			db.rebind(`INSERT INTO repositories (repo_id, repo_name, repo_url) VALUES (?, ?, ?)
+				ON CONFLICT (repo_id) DO UPDATE SET repo_name = excluded.repo_name, repo_url = excluded.repo_url`),
+			repoId, findingsReport.RepoName, findingsReport.RepoURL,
+		)
+		if err != nil {
+			return err
+		}
+
+		findings, err := db.readFindings(tx, repoId)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+
+		var merged domain.RepoFindings
+		merged, result = domain.RepoFindings{Findings: findings}.Merge(findingsReport, identity, now)
+
+		if err = db.replaceFindings(tx, repoId, merged.Findings); err != nil {
+			return err
+		}
+
+		if notify && len(result.New) > 0 {
+			return db.saveOutboxMessage(tx, domain.NewOutboxMessage(findingsReport, result.New, now))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return domain.UpsertResult{}, err
+	}
+
+	return result, nil
+}
+
+func (db *sqlDB) GetRepositoriesByName(repoName string, collectionName string) ([]map[string]string, error) {
+
+	// mongo adapter matches case insensitive substring, LIKE wildcards in input must be taken literally
+	pattern := "%" + escapeLike(strings.ToLower(repoName)) + "%"
+
+	rows, err := db.conn.Query(
+		db.rebind(`SELECT repo_id, repo_name FROM repositories WHERE LOWER(repo_name) LIKE ? ESCAPE '\' ORDER BY repo_id`), pattern,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var response []map[string]string
+
+	for rows.Next() {
+		var id int
+		var name string
+		if err = rows.Scan(&id, &name); err != nil {
+			return nil, err
+		}
+		response = append(response, map[string]string{"name": name, "id": strconv.Itoa(id)})
+	}
+
+	return response, rows.Err()
+}
+
+func (db *sqlDB) UpdateFindingStatus(repoId int, fingerprint string, change domain.StatusChange, collectionName string) error {
+
+	return db.inTx(func(tx *sql.Tx) error {
+
+		// lock repository row, so concurrent upload can not overwrite the change with stale findings
+		_, err := tx.Exec(db.rebind(`UPDATE repositories SET repo_id = repo_id WHERE repo_id = ?`), repoId)
+		if err != nil {
+			return err
+		}
+
+		rows, err := tx.Query(
+			db.rebind(`SELECT position, document FROM repository_findings WHERE repo_id = ? AND fingerprint = ?`),
+			repoId, fingerprint,
+		)
+		if err != nil {
+			return err
+		}
+
+		documents := map[int]string{}
+		for rows.Next() {
+			var position int
+			var document string
+			if err = rows.Scan(&position, &document); err != nil {
+				rows.Close()
+				return err
+			}
+			documents[position] = document
+		}
+		rows.Close()
+
+		if err = rows.Err(); err != nil {
+			return err
+		}
+
+		if len(documents) == 0 {
+			return errors.ErrFindingNotFound
+		}
+
+		for position, document := range documents {
+			finding := domain.Finding{}
+			if err = json.Unmarshal([]byte(document), &finding); err != nil {
+				return err
+			}
+
+			finding.ApplyStatusChange(change)
+
+			updated, err := json.Marshal(finding)
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.Exec(
+				db.rebind(`UPDATE repository_findings SET document = ?, document_hash = ? WHERE repo_id = ? AND position = ?`),
+				string(updated), hashDocument(updated), repoId, position,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// queryer is implemented by both *sql.DB and *sql.Tx
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// execer is implemented by both *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+func (db *sqlDB) readFindings(q queryer, repoId int) (domain.Findings, error) {
+
+	rows, err := q.Query(
+		db.rebind(`SELECT document FROM repository_findings WHERE repo_id = ? ORDER BY position`), repoId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	findings := domain.Findings{}
+
+	for rows.Next() {
+		var document string
+		if err = rows.Scan(&document); err != nil {
+			return nil, err
+		}
+
+		finding := domain.Finding{}
+		if err = json.Unmarshal([]byte(document), &finding); err != nil {
+			return nil, err
+		}
+		findings = append(findings, finding)
+	}
+
+	return findings, rows.Err()
+}
+
+func (db *sqlDB) replaceFindings(tx *sql.Tx, repoId int, findings domain.Findings) error {
+
+	_, err := tx.Exec(db.rebind(`DELETE FROM repository_findings WHERE repo_id = ?`), repoId)
+	if err != nil {
+		return err
+	}
+
+	for position, finding := range findings {
+		document, err := json.Marshal(finding)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(
+			db.rebind(`INSERT INTO repository_findings (repo_id, position, finding_id, fingerprint, document_hash, document)
+				VALUES (?, ?, ?, ?, ?, ?)`),
+			repoId, position+1, finding.ID, finding.Fingerprint, hashDocument(document), string(document),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// inTx runs fn in a transaction, which is committed if fn succeeds and rolled back otherwise
+func (db *sqlDB) inTx(fn func(tx *sql.Tx) error) error {
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err = fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// rebind converts "?" placeholders to "$n" placeholders required by PostgreSQL
+func (db *sqlDB) rebind(query string) string {
+
+	if db.driver != driverPostgres {
+		return query
+	}
+
+	var builder strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			builder.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		builder.WriteRune(r)
+	}
+
+	return builder.String()
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+func hashDocument(document []byte) string {
+	sum := sha256.Sum256(document)
+	return hex.EncodeToString(sum[:])
+}
+
+func newDocumentID() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/errors"
+	"time"
+)
+
+// api keys are stored as JSON documents like findings, key hash has its own column because documents never contain it
+
+func (db *sqlDB) SaveAPIKey(apiKey domain.APIKey, collectionName string) error {
+
+	document, err := json.Marshal(apiKey)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.conn.Exec(
+		db.rebind(`INSERT INTO api_keys (id, key_hash, created_at, document) VALUES (?, ?, ?, ?)`),
+		apiKey.ID, apiKey.KeyHash, apiKey.CreatedAt, string(document),
+	)
+
+	return err
+}
+
+func (db *sqlDB) GetAPIKeyByHash(keyHash string, collectionName string) (domain.APIKey, error) {
+
+	var document string
+
+	err := db.conn.QueryRow(db.rebind(`SELECT document FROM api_keys WHERE key_hash = ?`), keyHash).Scan(&document)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return domain.APIKey{}, errors.ErrAPIKeyNotFound
+		default:
+			return domain.APIKey{}, err
+		}
+	}
+
+	return decodeAPIKey(document, keyHash)
+}
+
+func (db *sqlDB) GetAPIKeys(collectionName string) ([]domain.APIKey, error) {
+
+	rows, err := db.conn.Query(`SELECT key_hash, document FROM api_keys ORDER BY created_at, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	apiKeys := []domain.APIKey{}
+
+	for rows.Next() {
+		var keyHash, document string
+		if err = rows.Scan(&keyHash, &document); err != nil {
+			return nil, err
+		}
+
+		apiKey, err := decodeAPIKey(document, keyHash)
+		if err != nil {
+			return nil, err
+		}
+		apiKeys = append(apiKeys, apiKey)
+	}
+
+	return apiKeys, rows.Err()
+}
+
+func (db *sqlDB) RevokeAPIKey(id string, revokedAt time.Time, collectionName string) error {
+
+	return db.updateAPIKey(id, func(apiKey *domain.APIKey) {
+		apiKey.RevokedAt = &revokedAt
+	})
+}
+
+func (db *sqlDB) TouchAPIKey(id string, usedAt time.Time, collectionName string) error {
+
+	return db.updateAPIKey(id, func(apiKey *domain.APIKey) {
+		apiKey.LastUsedAt = &usedAt
+	})
+}
+
+func (db *sqlDB) updateAPIKey(id string, update func(apiKey *domain.APIKey)) error {
+
+	return db.inTx(func(tx *sql.Tx) error {
+
+		var keyHash, document string
+
+		err := tx.QueryRow(db.rebind(`SELECT key_hash, document FROM api_keys WHERE id = ?`), id).Scan(&keyHash, &document)
+		if err != nil {
+			switch err {
+			case sql.ErrNoRows:
+				return errors.ErrAPIKeyNotFound
+			default:
+				return err
+			}
+		}
+
+		apiKey, err := decodeAPIKey(document, keyHash)
+		if err != nil {
+			return err
+		}
+
+		update(&apiKey)
+
+		updated, err := json.Marshal(apiKey)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(db.rebind(`UPDATE api_keys SET document = ? WHERE id = ?`), string(updated), id)
+		return err
+	})
+}
+
+func decodeAPIKey(document string, keyHash string) (domain.APIKey, error) {
+
+	apiKey := domain.APIKey{}
+	if err := json.Unmarshal([]byte(document), &apiKey); err != nil {
+		return domain.APIKey{}, err
+	}
+	apiKey.KeyHash = keyHash
+
+	return apiKey, nil
+}
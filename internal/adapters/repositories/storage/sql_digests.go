@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"secrets-operator/internal/core/domain"
+	"strings"
+	"time"
+)
+
+// digest entries are stored as JSON documents, announcements as plain rows keyed by channel, repository and fingerprint
+
+// inBatchSize keeps IN lists below parameter limits of SQLite
+const inBatchSize = 500
+
+func (db *sqlDB) SaveDigestEntry(entry domain.DigestEntry, collectionName string) error {
+
+	document, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.conn.Exec(
+		db.rebind(`INSERT INTO digest_entries (id, due_at, created_at, dead, document) VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET due_at = excluded.due_at, dead = excluded.dead, document = excluded.document`),
+		entry.ID, entry.DueAt.UnixMilli(), entry.CreatedAt.UnixMilli(), entry.Dead, string(document),
+	)
+
+	return err
+}
+
+func (db *sqlDB) GetDueDigestEntries(now time.Time, limit int, collectionName string) ([]domain.DigestEntry, error) {
+
+	rows, err := db.conn.Query(
+		db.rebind(`SELECT document FROM digest_entries WHERE dead = ? AND due_at <= ? ORDER BY created_at, id LIMIT ?`),
+		false, now.UnixMilli(), limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []domain.DigestEntry{}
+
+	for rows.Next() {
+		var document string
+		if err = rows.Scan(&document); err != nil {
+			return nil, err
+		}
+
+		entry := domain.DigestEntry{}
+		if err = json.Unmarshal([]byte(document), &entry); err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func (db *sqlDB) DeleteDigestEntries(ids []string, collectionName string) error {
+
+	return db.inTx(func(tx *sql.Tx) error {
+		for _, batch := range batches(ids) {
+			_, err := tx.Exec(db.rebind(`DELETE FROM digest_entries WHERE id IN (`+placeholders(len(batch))+`)`), toArgs(batch)...)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (db *sqlDB) GetAnnouncedFingerprints(channel string, repoId int, fingerprints []string, collectionName string) ([]string, error) {
+
+	announced := []string{}
+
+	for _, batch := range batches(fingerprints) {
+
+		args := append([]interface{}{channel, repoId}, toArgs(batch)...)
+		rows, err := db.conn.Query(
+			db.rebind(`SELECT fingerprint FROM announcements WHERE channel = ? AND repo_id = ? AND fingerprint IN (`+placeholders(len(batch))+`)`),
+			args...,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		for rows.Next() {
+			var fingerprint string
+			if err = rows.Scan(&fingerprint); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			announced = append(announced, fingerprint)
+		}
+
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return announced, nil
+}
+
+func (db *sqlDB) SaveAnnouncements(channel string, repoId int, fingerprints []string, announcedAt time.Time, collectionName string) error {
+
+	return db.inTx(func(tx *sql.Tx) error {
+		for _, fingerprint := range fingerprints {
+			_, err := tx.Exec(
+				db.rebind(`INSERT INTO announcements (channel, repo_id, fingerprint, announced_at) VALUES (?, ?, ?, ?)
+					ON CONFLICT (channel, repo_id, fingerprint) DO NOTHING`),
+				channel, repoId, fingerprint, announcedAt.UnixMilli(),
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// batches splits values into slices of at most inBatchSize
+func batches(values []string) [][]string {
+
+	var result [][]string
+	for len(values) > inBatchSize {
+		result = append(result, values[:inBatchSize])
+		values = values[inBatchSize:]
+	}
+	if len(values) > 0 {
+		result = append(result, values)
+	}
+
+	return result
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+func toArgs(values []string) []interface{} {
+
+	args := make([]interface{}, len(values))
+	for i, value := range values {
+		args[i] = value
+	}
+
+	return args
+}
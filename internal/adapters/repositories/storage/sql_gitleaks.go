@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"encoding/json"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/errors"
+)
+
+// gitleaks config versions are stored as JSON documents, the version number is the primary key
+
+func (db *sqlDB) SaveGitleaksConfigVersion(version domain.GitleaksConfigVersion, collectionName string) error {
+
+	document, err := json.Marshal(version)
+	if err != nil {
+		return err
+	}
+
+	result, err := db.conn.Exec(
+		db.rebind(`INSERT INTO gitleaks_config_versions (version, created_at, document) VALUES (?, ?, ?)
+			ON CONFLICT (version) DO NOTHING`),
+		version.Version, version.CreatedAt.UnixMilli(), string(document),
+	)
+	if err != nil {
+		return err
+	}
+
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if inserted == 0 {
+		return errors.ErrGitleaksConfigVersionExists
+	}
+
+	return nil
+}
+
+func (db *sqlDB) GetGitleaksConfigVersion(version int, collectionName string) (domain.GitleaksConfigVersion, error) {
+
+	versions, err := db.queryGitleaksConfigVersions(`SELECT document FROM gitleaks_config_versions WHERE version = ?`, version)
+	if err != nil {
+		return domain.GitleaksConfigVersion{}, err
+	}
+
+	if len(versions) == 0 {
+		return domain.GitleaksConfigVersion{}, errors.ErrGitleaksConfigVersionNotFound
+	}
+
+	return versions[0], nil
+}
+
+func (db *sqlDB) GetLatestGitleaksConfigVersion(collectionName string) (domain.GitleaksConfigVersion, error) {
+
+	versions, err := db.queryGitleaksConfigVersions(`SELECT document FROM gitleaks_config_versions ORDER BY version DESC LIMIT 1`)
+	if err != nil {
+		return domain.GitleaksConfigVersion{}, err
+	}
+
+	if len(versions) == 0 {
+		return domain.GitleaksConfigVersion{}, errors.ErrGitleaksConfigVersionNotFound
+	}
+
+	return versions[0], nil
+}
+
+func (db *sqlDB) GetGitleaksConfigVersions(collectionName string) ([]domain.GitleaksConfigVersion, error) {
+
+	return db.queryGitleaksConfigVersions(`SELECT document FROM gitleaks_config_versions ORDER BY version DESC`)
+}
+
+func (db *sqlDB) queryGitleaksConfigVersions(query string, args ...interface{}) ([]domain.GitleaksConfigVersion, error) {
+
+	rows, err := db.conn.Query(db.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := []domain.GitleaksConfigVersion{}
+
+	for rows.Next() {
+		var document string
+		if err = rows.Scan(&document); err != nil {
+			return nil, err
+		}
+
+		version := domain.GitleaksConfigVersion{}
+		if err = json.Unmarshal([]byte(document), &version); err != nil {
+			return nil, err
+		}
+
+		versions = append(versions, version)
+	}
+
+	return versions, rows.Err()
+}
@@ -0,0 +1,184 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// migration is a versioned set of schema changes, applied in a single transaction.
+// Statements must be valid for both PostgreSQL and SQLite, and already applied migrations must never be edited.
+type migration struct {
+	version    int
+	statements []string
+}
+
+var migrations = []migration{
+	{
+		version: 1,
+		statements: []string{
+			`CREATE TABLE findings_reports (
+				id          TEXT PRIMARY KEY,
+				repo_id     BIGINT NOT NULL,
+				pipeline_id BIGINT NOT NULL,
+				commit_sha  TEXT NOT NULL,
+				document    TEXT NOT NULL
+			)`,
+			`CREATE INDEX findings_reports_repo_id_idx ON findings_reports (repo_id)`,
+			`CREATE TABLE repositories (
+				repo_id   BIGINT PRIMARY KEY,
+				repo_name TEXT NOT NULL,
+				repo_url  TEXT NOT NULL
+			)`,
+			`CREATE TABLE repository_findings (
+				repo_id       BIGINT NOT NULL REFERENCES repositories (repo_id),
+				position      BIGINT NOT NULL,
+				fingerprint   TEXT NOT NULL,
+				document_hash TEXT NOT NULL,
+				document      TEXT NOT NULL,
+				PRIMARY KEY (repo_id, position),
+				UNIQUE (repo_id, document_hash)
+			)`,
+			`CREATE INDEX repository_findings_fingerprint_idx ON repository_findings (repo_id, fingerprint)`,
+		},
+	},
+	{
+		version: 2,
+		statements: []string{
+			`ALTER TABLE repository_findings ADD COLUMN finding_id TEXT NOT NULL DEFAULT ''`,
+			`CREATE INDEX repository_findings_finding_id_idx ON repository_findings (finding_id)`,
+		},
+	},
+	{
+		version: 3,
+		statements: []string{
+			`CREATE TABLE api_keys (
+				id         TEXT PRIMARY KEY,
+				key_hash   TEXT NOT NULL UNIQUE,
+				created_at TIMESTAMP NOT NULL,
+				document   TEXT NOT NULL
+			)`,
+		},
+	},
+	{
+		version: 4,
+		statements: []string{
+			`CREATE TABLE webhook_subscriptions (
+				id         TEXT PRIMARY KEY,
+				secret     TEXT NOT NULL,
+				created_at TIMESTAMP NOT NULL,
+				document   TEXT NOT NULL
+			)`,
+			`CREATE TABLE webhook_deliveries (
+				id              TEXT PRIMARY KEY,
+				subscription_id TEXT NOT NULL,
+				status          TEXT NOT NULL,
+				next_attempt_at BIGINT NOT NULL,
+				created_at      BIGINT NOT NULL,
+				document        TEXT NOT NULL
+			)`,
+			`CREATE INDEX webhook_deliveries_due_idx ON webhook_deliveries (status, next_attempt_at)`,
+			`CREATE INDEX webhook_deliveries_subscription_idx ON webhook_deliveries (subscription_id, created_at)`,
+		},
+	},
+	{
+		version: 5,
+		statements: []string{
+			`CREATE TABLE outbox (
+				id              TEXT PRIMARY KEY,
+				status          TEXT NOT NULL,
+				next_attempt_at BIGINT NOT NULL,
+				created_at      BIGINT NOT NULL,
+				document        TEXT NOT NULL
+			)`,
+			`CREATE INDEX outbox_due_idx ON outbox (status, next_attempt_at)`,
+			`CREATE INDEX outbox_status_created_idx ON outbox (status, created_at)`,
+		},
+	},
+	{
+		version: 6,
+		statements: []string{
+			`CREATE TABLE routing_rules (
+				id         TEXT PRIMARY KEY,
+				created_at BIGINT NOT NULL,
+				document   TEXT NOT NULL
+			)`,
+		},
+	},
+	{
+		version: 7,
+		statements: []string{
+			`CREATE TABLE digest_entries (
+				id         TEXT PRIMARY KEY,
+				due_at     BIGINT NOT NULL,
+				created_at BIGINT NOT NULL,
+				document   TEXT NOT NULL
+			)`,
+			`CREATE INDEX digest_entries_due_idx ON digest_entries (due_at)`,
+			`CREATE TABLE announcements (
+				channel      TEXT NOT NULL,
+				repo_id      INTEGER NOT NULL,
+				fingerprint  TEXT NOT NULL,
+				announced_at BIGINT NOT NULL,
+				PRIMARY KEY (channel, repo_id, fingerprint)
+			)`,
+		},
+	},
+	{
+		version: 8,
+		statements: []string{
+			`CREATE TABLE gitleaks_config_versions (
+				version    INTEGER PRIMARY KEY,
+				created_at BIGINT NOT NULL,
+				document   TEXT NOT NULL
+			)`,
+		},
+	},
+	{
+		version: 9,
+		statements: []string{
+			`ALTER TABLE digest_entries ADD COLUMN dead BOOLEAN NOT NULL DEFAULT FALSE`,
+		},
+	},
+}
+
+// migrate applies all migrations newer than the current schema version
+func (db *sqlDB) migrate() error {
+
+	_, err := db.conn.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return err
+	}
+
+	var current int
+	err = db.conn.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+
+		err = db.inTx(func(tx *sql.Tx) error {
+			for _, statement := range m.statements {
+				if _, err := tx.Exec(statement); err != nil {
+					return err
+				}
+			}
+
+			_, err := tx.Exec(db.rebind(`INSERT INTO schema_migrations (version) VALUES (?)`), m.version)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("migration %d failed: %w", m.version, err)
+		}
+
+		db.l.Infoln("Applied schema migration", m.version)
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"encoding/json"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/errors"
+	"time"
+)
+
+// outbox messages are stored as JSON documents in the outbox table, regardless of the collection name.
+// Times are stored as unix milliseconds, so they compare the same way in PostgreSQL and SQLite.
+
+func (db *sqlDB) SaveOutboxMessage(message domain.OutboxMessage, collectionName string) error {
+
+	return db.saveOutboxMessage(db.conn, message)
+}
+
+func (db *sqlDB) saveOutboxMessage(e execer, message domain.OutboxMessage) error {
+
+	document, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.Exec(
+		db.rebind(`INSERT INTO outbox (id, status, next_attempt_at, created_at, document) VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET status = excluded.status, next_attempt_at = excluded.next_attempt_at, document = excluded.document`),
+		message.ID, string(message.Status), message.NextAttemptAt.UnixMilli(), message.CreatedAt.UnixMilli(), string(document),
+	)
+
+	return err
+}
+
+func (db *sqlDB) GetOutboxMessageById(id string, collectionName string) (domain.OutboxMessage, error) {
+
+	messages, err := db.queryOutboxMessages(`SELECT document FROM outbox WHERE id = ?`, id)
+	if err != nil {
+		return domain.OutboxMessage{}, err
+	}
+
+	if len(messages) == 0 {
+		return domain.OutboxMessage{}, errors.ErrOutboxMessageNotFound
+	}
+
+	return messages[0], nil
+}
+
+func (db *sqlDB) GetOutboxMessages(status domain.OutboxStatus, limit int, collectionName string) ([]domain.OutboxMessage, error) {
+
+	return db.queryOutboxMessages(
+		`SELECT document FROM outbox WHERE status = ? ORDER BY created_at DESC, id LIMIT ?`,
+		string(status), limit,
+	)
+}
+
+func (db *sqlDB) GetDueOutboxMessages(now time.Time, limit int, collectionName string) ([]domain.OutboxMessage, error) {
+
+	return db.queryOutboxMessages(
+		`SELECT document FROM outbox WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at, id LIMIT ?`,
+		string(domain.OutboxPending), now.UnixMilli(), limit,
+	)
+}
+
+func (db *sqlDB) queryOutboxMessages(query string, args ...interface{}) ([]domain.OutboxMessage, error) {
+
+	rows, err := db.conn.Query(db.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := []domain.OutboxMessage{}
+
+	for rows.Next() {
+		var document string
+		if err = rows.Scan(&document); err != nil {
+			return nil, err
+		}
+
+		message := domain.OutboxMessage{}
+		if err = json.Unmarshal([]byte(document), &message); err != nil {
+			return nil, err
+		}
+
+		messages = append(messages, message)
+	}
+
+	return messages, rows.Err()
+}
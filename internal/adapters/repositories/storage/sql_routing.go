@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"encoding/json"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/errors"
+)
+
+// routing rules are stored as JSON documents, they are always read all at once
+
+func (db *sqlDB) SaveRoutingRule(rule domain.RoutingRule, collectionName string) error {
+
+	document, err := json.Marshal(rule)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.conn.Exec(
+		db.rebind(`INSERT INTO routing_rules (id, created_at, document) VALUES (?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET document = excluded.document`),
+		rule.ID, rule.CreatedAt.UnixMilli(), string(document),
+	)
+
+	return err
+}
+
+func (db *sqlDB) GetRoutingRuleById(id string, collectionName string) (domain.RoutingRule, error) {
+
+	rules, err := db.queryRoutingRules(`SELECT document FROM routing_rules WHERE id = ?`, id)
+	if err != nil {
+		return domain.RoutingRule{}, err
+	}
+
+	if len(rules) == 0 {
+		return domain.RoutingRule{}, errors.ErrRoutingRuleNotFound
+	}
+
+	return rules[0], nil
+}
+
+func (db *sqlDB) GetRoutingRules(collectionName string) ([]domain.RoutingRule, error) {
+
+	return db.queryRoutingRules(`SELECT document FROM routing_rules ORDER BY created_at, id`)
+}
+
+func (db *sqlDB) DeleteRoutingRule(id string, collectionName string) error {
+
+	result, err := db.conn.Exec(db.rebind(`DELETE FROM routing_rules WHERE id = ?`), id)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if deleted == 0 {
+		return errors.ErrRoutingRuleNotFound
+	}
+
+	return nil
+}
+
+func (db *sqlDB) queryRoutingRules(query string, args ...interface{}) ([]domain.RoutingRule, error) {
+
+	rows, err := db.conn.Query(db.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []domain.RoutingRule{}
+
+	for rows.Next() {
+		var document string
+		if err = rows.Scan(&document); err != nil {
+			return nil, err
+		}
+
+		rule := domain.RoutingRule{}
+		if err = json.Unmarshal([]byte(document), &rule); err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
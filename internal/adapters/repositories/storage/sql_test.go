@@ -0,0 +1,240 @@
+package storage
+
+import (
+	"database/sql"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"os"
+	"secrets-operator/config"
+	"secrets-operator/internal/core/ports"
+	"testing"
+)
+
+func newSQLiteTestDB(t *testing.T) *sqlDB {
+
+	db, err := NewSQLite(&config.Config{SQLitePath: ":memory:"}, zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatal("could not open sqlite database.", err)
+	}
+	return db
+}
+
+// newPostgresTestDB recreates public schema of the database before connecting
+func newPostgresTestDB(t *testing.T, dsn string) *sqlDB {
+
+	conn, err := sql.Open(driverPostgres, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if _, err = conn.Exec(`DROP SCHEMA public CASCADE; CREATE SCHEMA public`); err != nil {
+		t.Fatal("could not reset database.", err)
+	}
+
+	db, err := NewPostgres(&config.Config{PostgresDSN: dsn}, zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatal("could not connect to postgres.", err)
+	}
+	return db
+}
+
+func TestSuiteSQLiteFindingsRepository(t *testing.T) {
+
+	s := new(FindingsRepositoryTestSuite)
+	s.newRepository = func() ports.FindingsRepository {
+		return newSQLiteTestDB(t)
+	}
+
+	suite.Run(t, s)
+}
+
+func TestSuiteSQLiteAPIKeyRepository(t *testing.T) {
+
+	s := new(APIKeyRepositoryTestSuite)
+	s.newRepository = func() ports.APIKeyRepository {
+		return newSQLiteTestDB(t)
+	}
+
+	suite.Run(t, s)
+}
+
+func TestSuiteSQLiteWebhookRepository(t *testing.T) {
+
+	s := new(WebhookRepositoryTestSuite)
+	s.newRepository = func() ports.WebhookRepository {
+		return newSQLiteTestDB(t)
+	}
+
+	suite.Run(t, s)
+}
+
+func TestSuiteSQLiteOutboxRepository(t *testing.T) {
+
+	s := new(OutboxRepositoryTestSuite)
+	s.newRepository = func() outboxRepository {
+		return newSQLiteTestDB(t)
+	}
+
+	suite.Run(t, s)
+}
+
+func TestSuiteSQLiteRoutingRuleRepository(t *testing.T) {
+
+	s := new(RoutingRuleRepositoryTestSuite)
+	s.newRepository = func() ports.RoutingRuleRepository {
+		return newSQLiteTestDB(t)
+	}
+
+	suite.Run(t, s)
+}
+
+func TestSuiteSQLiteGitleaksConfigRepository(t *testing.T) {
+
+	s := new(GitleaksConfigRepositoryTestSuite)
+	s.newRepository = func() ports.GitleaksConfigRepository {
+		return newSQLiteTestDB(t)
+	}
+
+	suite.Run(t, s)
+}
+
+func TestSuiteSQLiteDigestRepository(t *testing.T) {
+
+	s := new(DigestRepositoryTestSuite)
+	s.newRepository = func() digestRepository {
+		return newSQLiteTestDB(t)
+	}
+
+	suite.Run(t, s)
+}
+
+// TestSuitePostgresFindingsRepository runs only when POSTGRES_TEST_DSN points to a throwaway database,
+// its public schema is recreated before every test.
+func TestSuitePostgresFindingsRepository(t *testing.T) {
+
+	dsn := os.Getenv("POSTGRES_TEST_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_TEST_DSN is not set")
+	}
+
+	s := new(FindingsRepositoryTestSuite)
+	s.newRepository = func() ports.FindingsRepository {
+		return newPostgresTestDB(t, dsn)
+	}
+
+	suite.Run(t, s)
+}
+
+func TestSuitePostgresAPIKeyRepository(t *testing.T) {
+
+	dsn := os.Getenv("POSTGRES_TEST_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_TEST_DSN is not set")
+	}
+
+	s := new(APIKeyRepositoryTestSuite)
+	s.newRepository = func() ports.APIKeyRepository {
+		return newPostgresTestDB(t, dsn)
+	}
+
+	suite.Run(t, s)
+}
+
+func TestSuitePostgresWebhookRepository(t *testing.T) {
+
+	dsn := os.Getenv("POSTGRES_TEST_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_TEST_DSN is not set")
+	}
+
+	s := new(WebhookRepositoryTestSuite)
+	s.newRepository = func() ports.WebhookRepository {
+		return newPostgresTestDB(t, dsn)
+	}
+
+	suite.Run(t, s)
+}
+
+func TestSuitePostgresOutboxRepository(t *testing.T) {
+
+	dsn := os.Getenv("POSTGRES_TEST_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_TEST_DSN is not set")
+	}
+
+	s := new(OutboxRepositoryTestSuite)
+	s.newRepository = func() outboxRepository {
+		return newPostgresTestDB(t, dsn)
+	}
+
+	suite.Run(t, s)
+}
+
+func TestSuitePostgresRoutingRuleRepository(t *testing.T) {
+
+	dsn := os.Getenv("POSTGRES_TEST_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_TEST_DSN is not set")
+	}
+
+	s := new(RoutingRuleRepositoryTestSuite)
+	s.newRepository = func() ports.RoutingRuleRepository {
+		return newPostgresTestDB(t, dsn)
+	}
+
+	suite.Run(t, s)
+}
+
+func TestSuitePostgresGitleaksConfigRepository(t *testing.T) {
+
+	dsn := os.Getenv("POSTGRES_TEST_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_TEST_DSN is not set")
+	}
+
+	s := new(GitleaksConfigRepositoryTestSuite)
+	s.newRepository = func() ports.GitleaksConfigRepository {
+		return newPostgresTestDB(t, dsn)
+	}
+
+	suite.Run(t, s)
+}
+
+func TestSuitePostgresDigestRepository(t *testing.T) {
+
+	dsn := os.Getenv("POSTGRES_TEST_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_TEST_DSN is not set")
+	}
+
+	s := new(DigestRepositoryTestSuite)
+	s.newRepository = func() digestRepository {
+		return newPostgresTestDB(t, dsn)
+	}
+
+	suite.Run(t, s)
+}
+
+func TestSQLiteMigrationsAreIdempotent(t *testing.T) {
+
+	path := t.TempDir() + "/secrets-operator.db"
+	cfg := &config.Config{SQLitePath: path}
+
+	for i := 0; i < 2; i++ {
+		db, err := NewSQLite(cfg, zap.NewNop().Sugar())
+		if err != nil {
+			t.Fatalf("could not open database #%d: %v", i, err)
+		}
+
+		var version int
+		if err = db.conn.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&version); err != nil {
+			t.Fatal(err)
+		}
+		if version != migrations[len(migrations)-1].version {
+			t.Fatalf("unexpected schema version %d", version)
+		}
+
+		_ = db.conn.Close()
+	}
+}
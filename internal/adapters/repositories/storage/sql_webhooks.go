@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"encoding/json"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/errors"
+	"time"
+)
+
+// webhook subscriptions and deliveries are stored as JSON documents, secrets have their own column because documents never contain them.
+// Delivery times are stored as unix milliseconds, so they compare the same way in PostgreSQL and SQLite.
+
+func (db *sqlDB) SaveWebhookSubscription(subscription domain.WebhookSubscription, collectionName string) error {
+
+	document, err := json.Marshal(subscription)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.conn.Exec(
+		db.rebind(`INSERT INTO webhook_subscriptions (id, secret, created_at, document) VALUES (?, ?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET secret = excluded.secret, document = excluded.document`),
+		subscription.ID, subscription.Secret, subscription.CreatedAt, string(document),
+	)
+
+	return err
+}
+
+func (db *sqlDB) GetWebhookSubscriptions(collectionName string) ([]domain.WebhookSubscription, error) {
+
+	rows, err := db.conn.Query(`SELECT secret, document FROM webhook_subscriptions ORDER BY created_at, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subscriptions := []domain.WebhookSubscription{}
+
+	for rows.Next() {
+		var secret, document string
+		if err = rows.Scan(&secret, &document); err != nil {
+			return nil, err
+		}
+
+		subscription := domain.WebhookSubscription{}
+		if err = json.Unmarshal([]byte(document), &subscription); err != nil {
+			return nil, err
+		}
+		subscription.Secret = secret
+
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	return subscriptions, rows.Err()
+}
+
+func (db *sqlDB) DeleteWebhookSubscription(id string, collectionName string) error {
+
+	result, err := db.conn.Exec(db.rebind(`DELETE FROM webhook_subscriptions WHERE id = ?`), id)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if deleted == 0 {
+		return errors.ErrWebhookSubscriptionNotFound
+	}
+
+	return nil
+}
+
+func (db *sqlDB) SaveWebhookDelivery(delivery domain.WebhookDelivery, collectionName string) error {
+
+	document, err := json.Marshal(delivery)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.conn.Exec(
+		db.rebind(`INSERT INTO webhook_deliveries (id, subscription_id, status, next_attempt_at, created_at, document) VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET status = excluded.status, next_attempt_at = excluded.next_attempt_at, document = excluded.document`),
+		delivery.ID, delivery.SubscriptionID, string(delivery.Status), delivery.NextAttemptAt.UnixMilli(), delivery.CreatedAt.UnixMilli(), string(document),
+	)
+
+	return err
+}
+
+func (db *sqlDB) GetWebhookDeliveries(subscriptionId string, limit int, collectionName string) ([]domain.WebhookDelivery, error) {
+
+	return db.queryWebhookDeliveries(
+		`SELECT document FROM webhook_deliveries WHERE subscription_id = ? ORDER BY created_at DESC, id LIMIT ?`,
+		subscriptionId, limit,
+	)
+}
+
+func (db *sqlDB) GetDueWebhookDeliveries(now time.Time, limit int, collectionName string) ([]domain.WebhookDelivery, error) {
+
+	return db.queryWebhookDeliveries(
+		`SELECT document FROM webhook_deliveries WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at, id LIMIT ?`,
+		string(domain.WebhookDeliveryPending), now.UnixMilli(), limit,
+	)
+}
+
+func (db *sqlDB) queryWebhookDeliveries(query string, args ...interface{}) ([]domain.WebhookDelivery, error) {
+
+	rows, err := db.conn.Query(db.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []domain.WebhookDelivery{}
+
+	for rows.Next() {
+		var document string
+		if err = rows.Scan(&document); err != nil {
+			return nil, err
+		}
+
+		delivery := domain.WebhookDelivery{}
+		if err = json.Unmarshal([]byte(document), &delivery); err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/core/ports"
+	"secrets-operator/internal/errors"
+	"time"
+)
+
+// WebhookRepositoryTestSuite describes behaviour shared by every ports.WebhookRepository implementation
+type WebhookRepositoryTestSuite struct {
+	suite.Suite
+	newRepository func() ports.WebhookRepository
+	sut           ports.WebhookRepository
+	createdAt     time.Time
+}
+
+func (s *WebhookRepositoryTestSuite) SetupTest() {
+
+	s.sut = s.newRepository()
+
+	// mongo keeps milliseconds only, so test dates are rounded to seconds
+	s.createdAt = time.Date(2022, 12, 3, 12, 48, 14, 0, time.UTC)
+}
+
+func (s *WebhookRepositoryTestSuite) subscription(id string, createdAt time.Time) domain.WebhookSubscription {
+
+	return domain.WebhookSubscription{
+		ID:          id,
+		URL:         "https://hooks.example.com/" + id,
+		Events:      []domain.WebhookEvent{domain.WebhookEventFindingNew, domain.WebhookEventFindingStatusChanged},
+		RepoIDs:     []int{444},
+		Description: "test subscription " + id,
+		Secret:      "whsec_" + id,
+		CreatedBy:   "test admin",
+		CreatedAt:   createdAt,
+	}
+}
+
+func (s *WebhookRepositoryTestSuite) delivery(id string, subscriptionId string, status domain.WebhookDeliveryStatus, nextAttemptAt time.Time) domain.WebhookDelivery {
+
+	return domain.WebhookDelivery{
+		ID:             id,
+		SubscriptionID: subscriptionId,
+		Event:          domain.WebhookEventFindingNew,
+		Payload:        json.RawMessage(`{"id":"` + id + `","event":"finding.new"}`),
+		Status:         status,
+		NextAttemptAt:  nextAttemptAt,
+		CreatedAt:      nextAttemptAt,
+	}
+}
+
+func (s *WebhookRepositoryTestSuite) TestSaveGetAndDeleteWebhookSubscriptions() {
+
+	// arrange
+	assert.NoError(s.T(), s.sut.SaveWebhookSubscription(s.subscription("b2", s.createdAt.Add(time.Hour)), "webhooks"))
+	assert.NoError(s.T(), s.sut.SaveWebhookSubscription(s.subscription("a1", s.createdAt), "webhooks"))
+
+	// act
+	subscriptions, err := s.sut.GetWebhookSubscriptions("webhooks")
+
+	// assert
+	assert.NoError(s.T(), err)
+	if assert.Len(s.T(), subscriptions, 2) {
+		assert.Equal(s.T(), "a1", subscriptions[0].ID, "subscriptions are ordered by creation time")
+		assert.Equal(s.T(), "https://hooks.example.com/a1", subscriptions[0].URL)
+		assert.Equal(s.T(), []domain.WebhookEvent{domain.WebhookEventFindingNew, domain.WebhookEventFindingStatusChanged}, subscriptions[0].Events)
+		assert.Equal(s.T(), []int{444}, subscriptions[0].RepoIDs)
+		assert.Equal(s.T(), "whsec_a1", subscriptions[0].Secret, "secret is stored even though it is never serialized to clients")
+		assert.True(s.T(), s.createdAt.Equal(subscriptions[0].CreatedAt))
+		assert.Equal(s.T(), "b2", subscriptions[1].ID)
+	}
+
+	assert.NoError(s.T(), s.sut.DeleteWebhookSubscription("a1", "webhooks"))
+	assert.Equal(s.T(), errors.ErrWebhookSubscriptionNotFound, s.sut.DeleteWebhookSubscription("a1", "webhooks"))
+
+	subscriptions, err = s.sut.GetWebhookSubscriptions("webhooks")
+	assert.NoError(s.T(), err)
+	if assert.Len(s.T(), subscriptions, 1) {
+		assert.Equal(s.T(), "b2", subscriptions[0].ID)
+	}
+}
+
+func (s *WebhookRepositoryTestSuite) TestSaveAndGetWebhookDeliveries() {
+
+	// arrange
+	assert.NoError(s.T(), s.sut.SaveWebhookDelivery(s.delivery("d1", "a1", domain.WebhookDeliveryPending, s.createdAt), "webhookdeliveries"))
+	assert.NoError(s.T(), s.sut.SaveWebhookDelivery(s.delivery("d2", "a1", domain.WebhookDeliveryPending, s.createdAt.Add(time.Minute)), "webhookdeliveries"))
+	assert.NoError(s.T(), s.sut.SaveWebhookDelivery(s.delivery("d3", "b2", domain.WebhookDeliveryPending, s.createdAt), "webhookdeliveries"))
+
+	updated := s.delivery("d1", "a1", domain.WebhookDeliveryPending, s.createdAt)
+	updated.RecordAttempt(domain.WebhookAttempt{At: s.createdAt, StatusCode: 500, Error: "status 500", DurationMs: 12},
+		domain.RetryPolicy{MaxAttempts: 1})
+
+	// act
+	assert.NoError(s.T(), s.sut.SaveWebhookDelivery(updated, "webhookdeliveries"))
+	deliveries, err := s.sut.GetWebhookDeliveries("a1", 10, "webhookdeliveries")
+
+	// assert
+	assert.NoError(s.T(), err)
+	if assert.Len(s.T(), deliveries, 2) {
+		assert.Equal(s.T(), "d2", deliveries[0].ID, "deliveries are ordered newest first")
+		assert.Equal(s.T(), "d1", deliveries[1].ID)
+		assert.Equal(s.T(), domain.WebhookDeliveryFailed, deliveries[1].Status)
+		assert.Equal(s.T(), 1, deliveries[1].AttemptCount)
+		if assert.Len(s.T(), deliveries[1].Attempts, 1) {
+			assert.Equal(s.T(), 500, deliveries[1].Attempts[0].StatusCode)
+			assert.Equal(s.T(), "status 500", deliveries[1].Attempts[0].Error)
+		}
+		assert.JSONEq(s.T(), `{"id":"d1","event":"finding.new"}`, string(deliveries[1].Payload))
+	}
+
+	limited, err := s.sut.GetWebhookDeliveries("a1", 1, "webhookdeliveries")
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), limited, 1)
+}
+
+func (s *WebhookRepositoryTestSuite) TestGetDueWebhookDeliveries() {
+
+	// arrange
+	assert.NoError(s.T(), s.sut.SaveWebhookDelivery(s.delivery("later", "a1", domain.WebhookDeliveryPending, s.createdAt.Add(time.Hour)), "webhookdeliveries"))
+	assert.NoError(s.T(), s.sut.SaveWebhookDelivery(s.delivery("second", "a1", domain.WebhookDeliveryPending, s.createdAt), "webhookdeliveries"))
+	assert.NoError(s.T(), s.sut.SaveWebhookDelivery(s.delivery("first", "b2", domain.WebhookDeliveryPending, s.createdAt.Add(-time.Minute)), "webhookdeliveries"))
+	assert.NoError(s.T(), s.sut.SaveWebhookDelivery(s.delivery("delivered", "a1", domain.WebhookDeliveryDelivered, s.createdAt), "webhookdeliveries"))
+	assert.NoError(s.T(), s.sut.SaveWebhookDelivery(s.delivery("failed", "a1", domain.WebhookDeliveryFailed, s.createdAt), "webhookdeliveries"))
+
+	// act
+	deliveries, err := s.sut.GetDueWebhookDeliveries(s.createdAt, 10, "webhookdeliveries")
+
+	// assert
+	assert.NoError(s.T(), err)
+	ids := []string{}
+	for _, delivery := range deliveries {
+		ids = append(ids, delivery.ID)
+	}
+	assert.Equal(s.T(), []string{"first", "second"}, ids, "only pending deliveries due by now are returned, oldest first")
+}
@@ -0,0 +1,72 @@
+package webhook
+
+import (
+	"bytes"
+	"fmt"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"secrets-operator/config"
+	"secrets-operator/internal/core/domain"
+	"strconv"
+	"time"
+)
+
+const (
+	HeaderEvent     = "X-Secrets-Operator-Event"
+	HeaderDelivery  = "X-Secrets-Operator-Delivery"
+	HeaderTimestamp = "X-Secrets-Operator-Timestamp"
+	HeaderSignature = "X-Secrets-Operator-Signature"
+)
+
+type httpSender struct {
+	cfg    *config.Config
+	l      *zap.SugaredLogger
+	client *http.Client
+}
+
+func NewHTTPSender(cfg *config.Config, l *zap.SugaredLogger) *httpSender {
+
+	return &httpSender{
+		cfg: cfg,
+		l:   l,
+		client: &http.Client{
+			Timeout: time.Duration(cfg.WebhookTimeoutSeconds) * time.Second,
+			// a redirect would resend the signed payload to an endpoint nobody subscribed
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Send posts payload of the delivery signed with secret of the subscription, responses other than 2xx are errors
+func (hs httpSender) Send(subscription domain.WebhookSubscription, delivery domain.WebhookDelivery) (int, error) {
+
+	request, err := http.NewRequest(http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "secrets-operator-webhooks")
+	request.Header.Set(HeaderEvent, string(delivery.Event))
+	request.Header.Set(HeaderDelivery, delivery.ID)
+	request.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(HeaderSignature, domain.SignWebhookPayload(subscription.Secret, timestamp, delivery.Payload))
+
+	resp, err := hs.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return resp.StatusCode, fmt.Errorf("webhook endpoint responded with status %d: %s", resp.StatusCode, body)
+	}
+
+	return resp.StatusCode, nil
+}
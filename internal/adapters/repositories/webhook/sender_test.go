@@ -0,0 +1,115 @@
+package webhook
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"secrets-operator/config"
+	"secrets-operator/internal/core/domain"
+	"strconv"
+	"testing"
+)
+
+type HTTPSenderTestSuite struct {
+	suite.Suite
+	l            *zap.SugaredLogger
+	cfg          *config.Config
+	subscription domain.WebhookSubscription
+	delivery     domain.WebhookDelivery
+}
+
+func TestSuiteHTTPSender(t *testing.T) {
+	suite.Run(t, new(HTTPSenderTestSuite))
+}
+
+func (s *HTTPSenderTestSuite) SetupTest() {
+
+	s.l = zap.NewNop().Sugar()
+	s.cfg = &config.Config{WebhookTimeoutSeconds: 5}
+
+	s.subscription = domain.WebhookSubscription{ID: "a1", Secret: "whsec_test"}
+	s.delivery = domain.WebhookDelivery{
+		ID:             "d1",
+		SubscriptionID: "a1",
+		Event:          domain.WebhookEventFindingNew,
+		Payload:        json.RawMessage(`{"id":"e1","event":"finding.new","repoId":444}`),
+	}
+}
+
+func (s *HTTPSenderTestSuite) TestHTTPSender_SendTableDriven() {
+
+	tests := []struct {
+		name           string
+		responseCode   int
+		wantStatusCode int
+		wantErr        bool
+	}{
+		{"endpoint accepts delivery", http.StatusOK, 200, false},
+		{"endpoint accepts delivery without content", http.StatusNoContent, 204, false},
+		{"endpoint redirects", http.StatusFound, 302, true},
+		{"endpoint rejects delivery", http.StatusBadRequest, 400, true},
+		{"endpoint is down", http.StatusServiceUnavailable, 503, true},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// arrange
+			var request *http.Request
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				request = r
+				body, _ = io.ReadAll(r.Body)
+				if tt.responseCode == http.StatusFound {
+					w.Header().Set("Location", "https://elsewhere.example.com")
+				}
+				w.WriteHeader(tt.responseCode)
+			}))
+			defer server.Close()
+
+			s.subscription.URL = server.URL
+			sut := NewHTTPSender(s.cfg, s.l)
+
+			// act
+			statusCode, err := sut.Send(s.subscription, s.delivery)
+
+			// assert
+			assert.Equal(s.T(), tt.wantStatusCode, statusCode)
+			assert.Equal(s.T(), tt.wantErr, err != nil, err)
+
+			s.Require().NotNil(request)
+			assert.Equal(s.T(), http.MethodPost, request.Method)
+			assert.Equal(s.T(), "application/json", request.Header.Get("Content-Type"))
+			assert.Equal(s.T(), "finding.new", request.Header.Get(HeaderEvent))
+			assert.Equal(s.T(), "d1", request.Header.Get(HeaderDelivery))
+			assert.JSONEq(s.T(), string(s.delivery.Payload), string(body))
+
+			timestamp, err := strconv.ParseInt(request.Header.Get(HeaderTimestamp), 10, 64)
+			s.Require().NoError(err)
+			assert.Equal(s.T(), domain.SignWebhookPayload("whsec_test", timestamp, body), request.Header.Get(HeaderSignature),
+				"receivers can verify the signature from raw body and timestamp header")
+		})
+	}
+}
+
+func (s *HTTPSenderTestSuite) TestHTTPSender_SendUnreachable() {
+
+	// arrange
+	server := httptest.NewServer(http.NotFoundHandler())
+	s.subscription.URL = server.URL
+	server.Close()
+
+	sut := NewHTTPSender(s.cfg, s.l)
+
+	// act
+	statusCode, err := sut.Send(s.subscription, s.delivery)
+
+	// assert
+	assert.Equal(s.T(), 0, statusCode)
+	assert.Error(s.T(), err)
+}
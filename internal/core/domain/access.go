@@ -0,0 +1,87 @@
+package domain
+
+import "sort"
+
+// Claims are verified claims of an identity provider token
+type Claims struct {
+	Subject string
+	Name    string
+	Roles   []string
+	Groups  []string
+}
+
+// rolesByPrecedence lists roles granted to users, a user matching several of them gets the first one
+var rolesByPrecedence = []Role{RoleAdmin, RoleTriager, RoleViewer}
+
+// AccessPolicy maps role and group claims of identity provider users to permissions.
+// Values of both claims are matched, so either can be used in the policy.
+type AccessPolicy struct {
+	// Roles lists claim values granting each role
+	Roles map[Role][]string
+	// AllRepos lists claim values allowed to see every repository
+	AllRepos []string
+	// Repos lists claim values allowed to see each repository
+	Repos map[int][]string
+	// SlackUsers grants roles and groups to Slack users by their IDs, Slack tells no claims of its users
+	SlackUsers map[string]Claims
+}
+
+// Principal resolves the user to a principal, false is returned if no role is granted.
+// Admins see every repository, other users only repositories granted to one of their claim values.
+func (policy AccessPolicy) Principal(claims Claims) (Principal, bool) {
+
+	values := map[string]bool{}
+	for _, value := range append(append([]string{}, claims.Roles...), claims.Groups...) {
+		values[value] = true
+	}
+
+	matches := func(granted []string) bool {
+		for _, value := range granted {
+			if values[value] {
+				return true
+			}
+		}
+		return false
+	}
+
+	principal := Principal{Subject: claims.Subject, Name: claims.Name}
+
+	for _, role := range rolesByPrecedence {
+		if matches(policy.Roles[role]) {
+			principal.Role = role
+			break
+		}
+	}
+
+	if principal.Role == "" {
+		return Principal{}, false
+	}
+
+	if principal.Role == RoleAdmin || matches(policy.AllRepos) {
+		return principal, true
+	}
+
+	principal.RepoIDs = []int{}
+	for repoId, granted := range policy.Repos {
+		if matches(granted) {
+			principal.RepoIDs = append(principal.RepoIDs, repoId)
+		}
+	}
+	sort.Ints(principal.RepoIDs)
+
+	return principal, true
+}
+
+// SlackPrincipal resolves the Slack user to a principal by roles and groups granted to its ID, false is returned if the
+// user is not listed or no role is granted. The subject is the Slack user ID, names of Slack users can be changed.
+func (policy AccessPolicy) SlackPrincipal(userID string) (Principal, bool) {
+
+	claims, ok := policy.SlackUsers[userID]
+	if !ok || userID == "" {
+		return Principal{}, false
+	}
+
+	claims.Subject = "slack:" + userID
+
+	return policy.Principal(claims)
+}
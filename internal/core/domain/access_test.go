@@ -0,0 +1,176 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type AccessTestSuite struct {
+	suite.Suite
+	policy AccessPolicy
+}
+
+func TestSuiteAccess(t *testing.T) {
+	suite.Run(t, new(AccessTestSuite))
+}
+
+func (s *AccessTestSuite) SetupTest() {
+	s.policy = AccessPolicy{
+		Roles: map[Role][]string{
+			RoleViewer:  {"developers"},
+			RoleTriager: {"security-champions"},
+			RoleAdmin:   {"secops"},
+		},
+		AllRepos: []string{"auditors"},
+		Repos: map[int][]string{
+			444: {"team-payments"},
+			555: {"team-payments", "team-search"},
+			666: {"team-search"},
+		},
+		SlackUsers: map[string]Claims{
+			"U0JANE": {Roles: []string{"security-champions"}, Groups: []string{"team-payments"}},
+			"U0JOHN": {Groups: []string{"team-payments"}},
+		},
+	}
+}
+
+func (s *AccessTestSuite) TestAccessPolicy_PrincipalTableDriven() {
+
+	tests := []struct {
+		name          string
+		claims        Claims
+		wantOk        bool
+		wantRole      Role
+		wantRepoIDs   []int
+		wantAllRepos  bool
+		wantReadable  []int
+		wantForbidden []int
+	}{
+		{
+			"viewer sees repositories of its groups",
+			Claims{Subject: "u1", Roles: []string{"developers"}, Groups: []string{"team-payments"}},
+			true, RoleViewer, []int{444, 555}, false, []int{444, 555}, []int{666},
+		},
+		{
+			"roles are matched in groups claim too",
+			Claims{Subject: "u1", Groups: []string{"developers", "team-search"}},
+			true, RoleViewer, []int{555, 666}, false, []int{555, 666}, []int{444},
+		},
+		{
+			"viewer without repository groups sees nothing",
+			Claims{Subject: "u1", Roles: []string{"developers"}},
+			true, RoleViewer, []int{}, false, nil, []int{444, 555, 666},
+		},
+		{
+			"most privileged role wins",
+			Claims{Subject: "u1", Roles: []string{"developers", "security-champions"}, Groups: []string{"team-search"}},
+			true, RoleTriager, []int{555, 666}, false, []int{555, 666}, []int{444},
+		},
+		{
+			"all repositories group",
+			Claims{Subject: "u1", Roles: []string{"developers"}, Groups: []string{"auditors"}},
+			true, RoleViewer, nil, true, []int{444, 555, 666, 777}, nil,
+		},
+		{
+			"admin sees every repository",
+			Claims{Subject: "u1", Roles: []string{"secops"}},
+			true, RoleAdmin, nil, true, []int{444, 777}, nil,
+		},
+		{
+			"user without role is rejected",
+			Claims{Subject: "u1", Groups: []string{"team-payments"}},
+			false, "", nil, false, nil, nil,
+		},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// act
+			principal, ok := s.policy.Principal(tt.claims)
+
+			// assert
+			assert.Equal(s.T(), tt.wantOk, ok)
+			assert.Equal(s.T(), tt.wantRole, principal.Role)
+			if tt.wantAllRepos {
+				assert.Nil(s.T(), principal.RepoIDs)
+			} else {
+				assert.Equal(s.T(), tt.wantRepoIDs, principal.RepoIDs)
+			}
+			for _, repoId := range tt.wantReadable {
+				assert.Truef(s.T(), principal.CanRead(repoId), "repository %d should be readable", repoId)
+			}
+			for _, repoId := range tt.wantForbidden {
+				assert.Falsef(s.T(), principal.CanRead(repoId), "repository %d should not be readable", repoId)
+			}
+		})
+	}
+}
+
+func (s *AccessTestSuite) TestAccessPolicy_SlackPrincipalTableDriven() {
+
+	tests := []struct {
+		name        string
+		userId      string
+		wantOk      bool
+		wantSubject string
+		wantRole    Role
+		wantRepoIDs []int
+	}{
+		{"slack user is granted listed roles and groups", "U0JANE", true, "slack:U0JANE", RoleTriager, []int{444, 555}},
+		{"slack user without role", "U0JOHN", false, "", "", nil},
+		{"unlisted slack user", "U0EVE", false, "", "", nil},
+		{"missing slack user", "", false, "", "", nil},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// act
+			principal, ok := s.policy.SlackPrincipal(tt.userId)
+
+			// assert
+			assert.Equal(s.T(), tt.wantOk, ok)
+			assert.Equal(s.T(), tt.wantSubject, principal.Subject)
+			assert.Equal(s.T(), tt.wantSubject, principal.Actor())
+			assert.Equal(s.T(), tt.wantRole, principal.Role)
+			assert.Equal(s.T(), tt.wantRepoIDs, principal.RepoIDs)
+		})
+	}
+}
+
+func (s *AccessTestSuite) TestPrincipal_PermissionsTableDriven() {
+
+	tests := []struct {
+		name          string
+		principal     Principal
+		repoId        int
+		wantCanRead   bool
+		wantCanUpload bool
+		wantCanTriage bool
+	}{
+		{"admin", Principal{Role: RoleAdmin}, 444, true, true, true},
+		{"uploader of the repository", APIKey{Scope: TokenScopeUpload, RepoID: 444}.Principal(), 444, true, true, false},
+		{"uploader of other repository", APIKey{Scope: TokenScopeUpload, RepoID: 555}.Principal(), 444, false, false, false},
+		{"read key of every repository", APIKey{Scope: TokenScopeRead}.Principal(), 444, true, false, false},
+		{"read key of other repository", APIKey{Scope: TokenScopeRead, RepoID: 555}.Principal(), 444, false, false, false},
+		{"triager of the repository", Principal{Role: RoleTriager, RepoIDs: []int{444}}, 444, true, false, true},
+		{"triager of other repository", Principal{Role: RoleTriager, RepoIDs: []int{555}}, 444, false, false, false},
+		{"viewer", Principal{Role: RoleViewer, RepoIDs: []int{444}}, 444, true, false, false},
+		{"principal without role", Principal{}, 444, false, false, false},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// act & assert
+			assert.Equal(s.T(), tt.wantCanRead, tt.principal.CanRead(tt.repoId))
+			assert.Equal(s.T(), tt.wantCanUpload, tt.principal.CanUpload(tt.repoId))
+			assert.Equal(s.T(), tt.wantCanTriage, tt.principal.CanTriage(tt.repoId))
+		})
+	}
+}
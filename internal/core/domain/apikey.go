@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// APIKeyPrefix makes secrets operator tokens recognizable, e.g. by gitleaks rules
+const APIKeyPrefix = "so_"
+
+type TokenScope string
+
+const (
+	// TokenScopeUpload allows uploading reports of a single repository and reading its findings as baseline
+	TokenScopeUpload TokenScope = "upload"
+	// TokenScopeRead allows reading findings, of a single repository if RepoID is set
+	TokenScopeRead TokenScope = "read"
+	// TokenScopeAdmin allows everything, including triage and token management
+	TokenScopeAdmin TokenScope = "admin"
+)
+
+// APIKey is an issued token, only hash of the token is stored
+type APIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name" validate:"required,ascii,max=200"`
+	Scope      TokenScope `json:"scope" validate:"required,oneof=upload read admin"`
+	RepoID     int        `json:"repoId,omitempty" validate:"required_if=Scope upload,min=0"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	CreatedBy  string     `json:"createdBy"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
+
+// NewAPIKeyToken returns random token and its hash to be stored
+func NewAPIKeyToken() (token string, keyHash string) {
+
+	b := make([]byte, 24)
+	_, _ = rand.Read(b)
+
+	token = APIKeyPrefix + hex.EncodeToString(b)
+
+	return token, HashAPIKeyToken(token)
+}
+
+// HashAPIKeyToken returns SHA-256 of the token, tokens are random enough to not need a salt
+func HashAPIKeyToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (k APIKey) IsRevoked() bool {
+	return k.RevokedAt != nil
+}
+
+// Principal returns caller authenticated by the key
+func (k APIKey) Principal() Principal {
+
+	principal := Principal{KeyID: k.ID, Name: k.Name}
+
+	switch k.Scope {
+	case TokenScopeUpload:
+		principal.Role = RoleUploader
+		principal.RepoIDs = []int{k.RepoID}
+	case TokenScopeRead:
+		principal.Role = RoleViewer
+		if k.RepoID != 0 {
+			principal.RepoIDs = []int{k.RepoID}
+		}
+	case TokenScopeAdmin:
+		principal.Role = RoleAdmin
+	}
+
+	return principal
+}
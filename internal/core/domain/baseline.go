@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// BaselineFinding is a finding as gitleaks reports it, gitleaks --baseline-path skips findings equal to one of them
+type BaselineFinding struct {
+	Description string    `json:"Description"`
+	StartLine   int       `json:"StartLine"`
+	EndLine     int       `json:"EndLine"`
+	StartColumn int       `json:"StartColumn"`
+	EndColumn   int       `json:"EndColumn"`
+	Match       string    `json:"Match"`
+	Secret      string    `json:"Secret"`
+	File        string    `json:"File"`
+	SymlinkFile string    `json:"SymlinkFile"`
+	Commit      string    `json:"Commit"`
+	Entropy     float64   `json:"Entropy"`
+	Author      string    `json:"Author"`
+	Email       string    `json:"Email"`
+	Date        time.Time `json:"Date"`
+	Message     string    `json:"Message"`
+	Tags        []string  `json:"Tags"`
+	RuleID      string    `json:"RuleID"`
+	Fingerprint string    `json:"Fingerprint"`
+}
+
+// Baseline returns the finding as gitleaks --redact reports it. Secrets are stored redacted by the redaction policy,
+// whatever its mode the secret becomes RedactedPlaceholder, so the baseline matches redacted gitleaks findings.
+func (f Finding) Baseline() BaselineFinding {
+
+	match := f.Match
+	if f.Secret != "" && f.Secret != RedactedPlaceholder {
+		match = strings.ReplaceAll(match, f.Secret, RedactedPlaceholder)
+	}
+
+	tags := f.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return BaselineFinding{
+		Description: f.Description,
+		StartLine:   f.StartLine,
+		EndLine:     f.EndLine,
+		StartColumn: f.StartColumn,
+		EndColumn:   f.EndColumn,
+		Match:       match,
+		Secret:      RedactedPlaceholder,
+		File:        f.File,
+		Commit:      f.Commit,
+		Entropy:     f.Entropy,
+		Author:      f.Author,
+		Email:       f.Email,
+		Date:        f.Date,
+		Message:     f.Message,
+		Tags:        append([]string{}, tags...),
+		RuleID:      f.RuleID,
+		Fingerprint: f.Fingerprint,
+	}
+}
+
+// Baseline returns findings as a gitleaks baseline, see Finding.Baseline
+func (f Findings) Baseline() []BaselineFinding {
+
+	baseline := make([]BaselineFinding, 0, len(f))
+	for _, finding := range f {
+		baseline = append(baseline, finding.Baseline())
+	}
+
+	return baseline
+}
@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type BaselineTestSuite struct {
+	suite.Suite
+	finding Finding
+}
+
+func TestSuiteBaseline(t *testing.T) {
+	suite.Run(t, new(BaselineTestSuite))
+}
+
+func (s *BaselineTestSuite) SetupTest() {
+
+	s.finding = Finding{
+		ID:              "a1",
+		Description:     "AWS Access Token",
+		StartLine:       12,
+		EndLine:         12,
+		StartColumn:     5,
+		EndColumn:       40,
+		File:            "src/config.go",
+		Commit:          "a85af84d39a32da2c8eba1d88019079aeb0741b0",
+		Entropy:         3.6,
+		Author:          "test author",
+		Email:           "test@mail.com",
+		Date:            time.Date(2022, 12, 3, 12, 48, 14, 0, time.UTC),
+		Message:         "add config",
+		Tags:            []string{"key"},
+		RuleID:          "aws-access-token",
+		Fingerprint:     "a85af84d39a32da2c8eba1d88019079aeb0741b0:src/config.go:aws-access-token:12",
+		Status:          FindingStatusFalsePositive,
+		Assignee:        "jane",
+		OccurrenceCount: 3,
+	}
+}
+
+func (s *BaselineTestSuite) TestFinding_BaselineTableDriven() {
+
+	tests := []struct {
+		name      string
+		secret    string
+		match     string
+		wantMatch string
+	}{
+		{"masked secret", "REDACTED", "key = REDACTED", "key = REDACTED"},
+		{"partially redacted secret", "AKIA*****IVXG", "key = AKIA*****IVXG", "key = REDACTED"},
+		{"hashed secret", "hmac-sha256:0a1b", "key = hmac-sha256:0a1b", "key = REDACTED"},
+		{"no secret", "", "key", "key"},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// arrange
+			finding := s.finding
+			finding.Secret = tt.secret
+			finding.Match = tt.match
+
+			// act
+			baseline := finding.Baseline()
+
+			// assert
+			s.Equal(BaselineFinding{
+				Description: "AWS Access Token",
+				StartLine:   12,
+				EndLine:     12,
+				StartColumn: 5,
+				EndColumn:   40,
+				Match:       tt.wantMatch,
+				Secret:      RedactedPlaceholder,
+				File:        "src/config.go",
+				Commit:      "a85af84d39a32da2c8eba1d88019079aeb0741b0",
+				Entropy:     3.6,
+				Author:      "test author",
+				Email:       "test@mail.com",
+				Date:        time.Date(2022, 12, 3, 12, 48, 14, 0, time.UTC),
+				Message:     "add config",
+				Tags:        []string{"key"},
+				RuleID:      "aws-access-token",
+				Fingerprint: "a85af84d39a32da2c8eba1d88019079aeb0741b0:src/config.go:aws-access-token:12",
+			}, baseline)
+		})
+	}
+}
+
+func (s *BaselineTestSuite) TestFindings_BaselineIsNeverNull() {
+
+	s.NotNil(Findings(nil).Baseline())
+	s.Equal([]string{}, Findings{{}}.Baseline()[0].Tags)
+}
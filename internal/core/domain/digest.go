@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"time"
+)
+
+type NotificationMode string
+
+const (
+	// NotificationImmediate notifies about new findings right away, it is the default
+	NotificationImmediate NotificationMode = "immediate"
+	// NotificationHourly collects new findings into a digest sent at the start of the next hour
+	NotificationHourly NotificationMode = "hourly"
+	// NotificationDaily collects new findings into a digest sent once a day
+	NotificationDaily NotificationMode = "daily"
+	// NotificationSuppressed never notifies
+	NotificationSuppressed NotificationMode = "suppressed"
+)
+
+// Valid tells whether the mode is known, empty mode is immediate
+func (m NotificationMode) Valid() bool {
+
+	switch m {
+	case "", NotificationImmediate, NotificationHourly, NotificationDaily, NotificationSuppressed:
+		return true
+	}
+
+	return false
+}
+
+// IsDigest tells whether findings are collected into digests instead of being notified right away
+func (m NotificationMode) IsDigest() bool {
+	return m == NotificationHourly || m == NotificationDaily
+}
+
+// DigestDue returns when the digest collecting findings queued at now is sent, hourly digests at the start of the next
+// hour and daily digests at the next dailyHour in UTC. Other modes are due right away.
+func (m NotificationMode) DigestDue(now time.Time, dailyHour int) time.Time {
+
+	now = now.UTC()
+
+	switch m {
+	case NotificationHourly:
+		return now.Truncate(time.Hour).Add(time.Hour)
+	case NotificationDaily:
+		due := time.Date(now.Year(), now.Month(), now.Day(), dailyHour, 0, 0, 0, time.UTC)
+		if !due.After(now) {
+			due = due.AddDate(0, 0, 1)
+		}
+		return due
+	}
+
+	return now
+}
+
+// DigestEntry holds findings waiting for the digest of a notification channel or routed target
+type DigestEntry struct {
+	ID string `json:"id"`
+	// Channel names the notification channel or the routed target, e.g. "slack" or "slack:C0123456"
+	Channel string `json:"channel"`
+	// Target is set for routed targets only
+	Target    *NotificationTarget `json:"target,omitempty"`
+	Report    FindingsReport      `json:"report"`
+	DueAt     time.Time           `json:"dueAt"`
+	CreatedAt time.Time           `json:"createdAt"`
+	// AttemptCount and LastError record failed digests of the entry, see RecordFailure
+	AttemptCount int    `json:"attemptCount,omitempty"`
+	LastError    string `json:"lastError,omitempty"`
+	// Dead entries exhausted attempts of the retry policy, they are kept but never due again
+	Dead bool `json:"dead,omitempty"`
+}
+
+// RecordFailure postpones the entry by backoff of the policy after its digest failed at the given time, so entries
+// of failing targets do not hold back others. Once attempts of the policy are exhausted the entry is dead.
+func (de *DigestEntry) RecordFailure(err error, at time.Time, policy RetryPolicy) {
+
+	de.AttemptCount++
+	de.LastError = err.Error()
+
+	if de.AttemptCount >= policy.MaxAttempts {
+		de.Dead = true
+		return
+	}
+
+	de.DueAt = at.Add(policy.Backoff(de.AttemptCount))
+}
+
+// Digest is a single notification of a channel about a repository assembled from digest entries
+type Digest struct {
+	Channel string
+	Target  *NotificationTarget
+	// Report describes the latest entry and carries findings of all of them
+	Report   FindingsReport
+	EntryIDs []string
+}
+
+// Digests merges entries, expected oldest first, into a digest per channel and repository. Findings are deduplicated
+// by fingerprint and keep the order they were queued in, digests keep the order of their first entries.
+func Digests(entries []DigestEntry) []Digest {
+
+	type key struct {
+		channel string
+		repoId  int
+	}
+
+	var keys []key
+	digests := map[key]*Digest{}
+	seen := map[key]map[string]bool{}
+
+	for _, entry := range entries {
+
+		k := key{channel: entry.Channel, repoId: entry.Report.RepoID}
+
+		digest, ok := digests[k]
+		if !ok {
+			keys = append(keys, k)
+			digest = &Digest{Channel: entry.Channel, Report: FindingsReport{Findings: Findings{}}}
+			digests[k] = digest
+			seen[k] = map[string]bool{}
+		}
+
+		findings := digest.Report.Findings
+		digest.Report = entry.Report
+		digest.Target = entry.Target
+		digest.EntryIDs = append(digest.EntryIDs, entry.ID)
+
+		for _, finding := range entry.Report.Findings {
+			if seen[k][finding.Fingerprint] {
+				continue
+			}
+			seen[k][finding.Fingerprint] = true
+			findings = append(findings, finding)
+		}
+		digest.Report.Findings = findings
+	}
+
+	result := make([]Digest, 0, len(keys))
+	for _, k := range keys {
+		result = append(result, *digests[k])
+	}
+
+	return result
+}
@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type DigestTestSuite struct {
+	suite.Suite
+}
+
+func TestSuiteDigest(t *testing.T) {
+	suite.Run(t, new(DigestTestSuite))
+}
+
+func (s *DigestTestSuite) TestNotificationMode_DigestDueTableDriven() {
+
+	tests := []struct {
+		name string
+		mode NotificationMode
+		now  time.Time
+		want time.Time
+	}{
+		{"hourly at the start of next hour", NotificationHourly, time.Date(2022, 12, 3, 12, 48, 14, 0, time.UTC), time.Date(2022, 12, 3, 13, 0, 0, 0, time.UTC)},
+		{"hourly on the hour waits for the next one", NotificationHourly, time.Date(2022, 12, 3, 12, 0, 0, 0, time.UTC), time.Date(2022, 12, 3, 13, 0, 0, 0, time.UTC)},
+		{"daily later today", NotificationDaily, time.Date(2022, 12, 3, 7, 30, 0, 0, time.UTC), time.Date(2022, 12, 3, 9, 0, 0, 0, time.UTC)},
+		{"daily tomorrow", NotificationDaily, time.Date(2022, 12, 31, 9, 0, 0, 0, time.UTC), time.Date(2023, 1, 1, 9, 0, 0, 0, time.UTC)},
+		{"daily in UTC", NotificationDaily, time.Date(2022, 12, 3, 10, 0, 0, 0, time.FixedZone("CET", 3600)), time.Date(2022, 12, 4, 9, 0, 0, 0, time.UTC)},
+		{"immediate right away", NotificationImmediate, time.Date(2022, 12, 3, 12, 48, 14, 0, time.UTC), time.Date(2022, 12, 3, 12, 48, 14, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// act
+			got := tt.mode.DigestDue(tt.now, 9)
+
+			// assert
+			assert.Equal(s.T(), tt.want, got)
+		})
+	}
+}
+
+func (s *DigestTestSuite) TestNotificationMode_Valid() {
+
+	for _, mode := range []NotificationMode{"", NotificationImmediate, NotificationHourly, NotificationDaily, NotificationSuppressed} {
+		assert.True(s.T(), mode.Valid(), mode)
+	}
+	assert.False(s.T(), NotificationMode("weekly").Valid())
+	assert.False(s.T(), NotificationImmediate.IsDigest())
+	assert.True(s.T(), NotificationHourly.IsDigest())
+}
+
+func (s *DigestTestSuite) TestDigests() {
+
+	// arrange
+	target := &NotificationTarget{Type: NotificationTargetSlack, Destination: "C0123456"}
+	entries := []DigestEntry{
+		{ID: "a", Channel: target.Name(), Target: target, Report: FindingsReport{RepoID: 444, CommitSHA: "c1", Findings: Findings{{Fingerprint: "f1"}}}},
+		{ID: "b", Channel: "email", Report: FindingsReport{RepoID: 444, Findings: Findings{{Fingerprint: "f1"}}}},
+		{ID: "c", Channel: target.Name(), Target: target, Report: FindingsReport{RepoID: 444, CommitSHA: "c2", Findings: Findings{{Fingerprint: "f2"}, {Fingerprint: "f1"}}}},
+	}
+
+	// act
+	digests := Digests(entries)
+
+	// assert
+	if assert.Len(s.T(), digests, 2) {
+		assert.Equal(s.T(), target, digests[0].Target)
+		assert.Equal(s.T(), "c2", digests[0].Report.CommitSHA)
+		assert.Equal(s.T(), Findings{{Fingerprint: "f1"}, {Fingerprint: "f2"}}, digests[0].Report.Findings)
+		assert.Equal(s.T(), []string{"a", "c"}, digests[0].EntryIDs)
+		assert.Equal(s.T(), "email", digests[1].Channel)
+		assert.Nil(s.T(), digests[1].Target)
+	}
+	assert.Len(s.T(), entries[2].Report.Findings, 2, "entries must not be modified")
+}
+
+func (s *DigestTestSuite) TestDigestEntry_RecordFailure() {
+
+	// arrange
+	at := time.Date(2022, 12, 3, 12, 0, 0, 0, time.UTC)
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: 10 * time.Second, MaxDelay: time.Hour}
+	entry := DigestEntry{ID: "a1", DueAt: at}
+
+	// act & assert
+	entry.RecordFailure(errors.New("slack is down"), at, policy)
+	assert.Equal(s.T(), 1, entry.AttemptCount)
+	assert.Equal(s.T(), "slack is down", entry.LastError)
+	assert.Equal(s.T(), at.Add(10*time.Second), entry.DueAt)
+	assert.False(s.T(), entry.Dead)
+
+	entry.RecordFailure(errors.New("slack is down"), at.Add(10*time.Second), policy)
+	assert.Equal(s.T(), at.Add(30*time.Second), entry.DueAt)
+
+	entry.RecordFailure(errors.New("channel_not_found"), at.Add(30*time.Second), policy)
+	assert.True(s.T(), entry.Dead, "attempts are exhausted")
+	assert.Equal(s.T(), 3, entry.AttemptCount)
+	assert.Equal(s.T(), "channel_not_found", entry.LastError)
+}
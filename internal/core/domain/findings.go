@@ -3,10 +3,15 @@ package domain
 import (
 	"fmt"
 	_ "github.com/go-playground/validator/v10"
+	"net/url"
+	"strings"
 	"time"
 )
 
-type Findings []struct {
+// Finding is a single gitleaks finding together with the state secrets operator keeps about it
+type Finding struct {
+	// ID is assigned by secrets operator when finding is seen for the first time, it never changes afterwards
+	ID          string    `json:"ID,omitempty" validate:"omitempty,hexadecimal"`
 	Description string    `json:"Description" validate:"required,ascii,max=1000"`
 	StartLine   int       `json:"StartLine" validate:"required,number,min=0"`
 	EndLine     int       `json:"EndLine" validate:"required,number,min=0"`
@@ -14,6 +19,7 @@ type Findings []struct {
 	EndColumn   int       `json:"EndColumn" validate:"required,number,min=0"`
 	Match       string    `json:"Match" validate:"required"`
 	Secret      string    `json:"Secret" validate:"required"`
+	SecretHash  string    `json:"SecretHash,omitempty" validate:"omitempty,hexadecimal"`
 	File        string    `json:"File" validate:"required,ascii,max=200"`
 	Commit      string    `json:"Commit" validate:"required,ascii,len=40"`
 	Entropy     float64   `json:"Entropy" validate:"required,number,min=0,max=20"`
@@ -24,9 +30,26 @@ type Findings []struct {
 	Tags        []string  `json:"Tags" validate:"required"`
 	RuleID      string    `json:"RuleID" validate:"required,ascii,max=200"`
 	Fingerprint string    `json:"Fingerprint" validate:"required,ascii,max=1000"`
+	// Verified is set by scanners which confirmed the secret works, e.g. TruffleHog, it stays set once reported
+	Verified bool `json:"Verified,omitempty"`
+	// Status, StatusHistory and Assignee are managed by secrets operator, they are never part of gitleaks reports
+	Status        FindingStatus  `json:"Status,omitempty" validate:"omitempty,oneof=open acknowledged false_positive revoked resolved"`
+	StatusHistory []StatusChange `json:"StatusHistory,omitempty" validate:"omitempty,dive"`
+	Assignee      string         `json:"Assignee,omitempty" validate:"omitempty,max=200"`
+	// FirstSeen, LastSeen, OccurrenceCount, Occurrences and TrimmedPipelineID are maintained by RepoFindings.Merge
+	FirstSeen       time.Time    `json:"FirstSeen"`
+	LastSeen        time.Time    `json:"LastSeen"`
+	OccurrenceCount int          `json:"OccurrenceCount,omitempty"`
+	Occurrences     []Occurrence `json:"Occurrences,omitempty" validate:"omitempty,dive"`
+	// TrimmedPipelineID is the highest pipeline ID of occurrences dropped over MaxOccurrences, those pipelines are counted already
+	TrimmedPipelineID int `json:"TrimmedPipelineID,omitempty"`
 }
 
+type Findings []Finding
+
 type FindingsReport struct {
+	// ID is assigned by secrets operator when report is uploaded
+	ID           string    `json:"id,omitempty"`
 	PipelineID   int       `json:"pipelineId" validate:"required,number,min=0"`
 	RepoName     string    `json:"repoName" validate:"required,ascii,max=1000"`
 	RepoID       int       `json:"repoId" validate:"required,number,min=0"`
@@ -34,7 +57,9 @@ type FindingsReport struct {
 	CommitAuthor string    `json:"commitAuthor" validate:"required,ascii,max=200"`
 	CommitSHA    string    `json:"commitSHA" validate:"required,ascii,len=40"`
 	Timestamp    time.Time `json:"timestamp" validate:"required"`
-	Findings     `json:"findings" validate:"required,dive"`
+	// ConfigVersion is the version of the gitleaks config which produced the findings, 0 if the uploader did not tell
+	ConfigVersion int `json:"configVersion,omitempty" validate:"min=0"`
+	Findings      `json:"findings" validate:"required,dive"`
 }
 
 type RepoFindings struct {
@@ -44,14 +69,89 @@ type RepoFindings struct {
 	Findings `json:"findings" validate:"omitempty,dive"`
 }
 
+// FilterByStatus returns findings having one of given statuses, findings without status are considered open.
+// If no status given, all findings are returned.
+func (f Findings) FilterByStatus(statuses ...FindingStatus) Findings {
+
+	if len(statuses) == 0 {
+		return f
+	}
+
+	filtered := Findings{}
+	for _, finding := range f {
+		status := finding.Status
+		if status == "" {
+			status = FindingStatusOpen
+		}
+
+		for _, s := range statuses {
+			if status == s {
+				filtered = append(filtered, finding)
+				break
+			}
+		}
+	}
+
+	return filtered
+}
+
+// ExcludeStatus returns findings having none of given statuses, findings without status are considered open
+func (f Findings) ExcludeStatus(statuses ...FindingStatus) Findings {
+
+	kept := make([]FindingStatus, 0, len(findingStatuses))
+	for _, status := range findingStatuses {
+		excluded := false
+		for _, s := range statuses {
+			excluded = excluded || status == s
+		}
+		if !excluded {
+			kept = append(kept, status)
+		}
+	}
+
+	if len(kept) == len(findingStatuses) {
+		return f
+	}
+
+	return f.FilterByStatus(kept...)
+}
+
+// InheritCommit sets commit, author and date of findings which scanners without git history leave empty, e.g.
+// filesystem scans, to the commit the report was uploaded for
+func (fr *FindingsReport) InheritCommit() {
+
+	for i := range fr.Findings {
+		if fr.Findings[i].Commit == "" {
+			fr.Findings[i].Commit = fr.CommitSHA
+		}
+		if fr.Findings[i].Author == "" {
+			fr.Findings[i].Author = fr.CommitAuthor
+		}
+		if fr.Findings[i].Date.IsZero() {
+			fr.Findings[i].Date = fr.Timestamp
+		}
+	}
+}
+
 func (fr *FindingsReport) BuildCommitURL() string {
-	return fmt.Sprintf("%s/-/commit/%s", fr.RepoURL, fr.CommitSHA)
+	return fmt.Sprintf("%s/-/commit/%s", fr.baseURL(), fr.CommitSHA)
 }
 
 func (fr *FindingsReport) BuildUserURL() string {
-	return fmt.Sprintf("%s/%s", fr.RepoURL, fr.CommitAuthor)
+	return fmt.Sprintf("%s/%s", fr.baseURL(), fr.CommitAuthor)
 }
 
 func (fr *FindingsReport) BuildPipelineURL() string {
-	return fmt.Sprintf("%s/-/pipelines/%d", fr.RepoURL, fr.PipelineID)
+	return fmt.Sprintf("%s/-/pipelines/%d", fr.baseURL(), fr.PipelineID)
+}
+
+// BuildFindingURL links first line of the finding in the commit it was found in
+func (fr *FindingsReport) BuildFindingURL(finding Finding) string {
+	file := (&url.URL{Path: finding.File}).EscapedPath()
+	return fmt.Sprintf("%s/-/blob/%s/%s#L%d", fr.baseURL(), finding.Commit, strings.TrimPrefix(file, "/"), finding.StartLine)
+}
+
+// baseURL returns repository URL without trailing slash, CI variables and manual uploads are not consistent about it
+func (fr *FindingsReport) baseURL() string {
+	return strings.TrimSuffix(fr.RepoURL, "/")
 }
@@ -102,6 +102,45 @@ func (s *FindingsReportTestSuite) TestFindingsReport_BuildCommitURLTableDrivenSh
 	}
 }
 
+func (s *FindingsReportTestSuite) TestFindingsReport_BuildFindingURLTableDriven() {
+
+	tests := []struct {
+		name    string
+		repoUrl string
+		file    string
+		want    string
+	}{
+		{
+			"file in repository root",
+			"https://gitlab.com/testing-repo/",
+			"main.go",
+			"https://gitlab.com/testing-repo/-/blob/a85af84d39a32da2c8eba1d88019079aeb0741b0/main.go#L12",
+		},
+		{
+			"nested file with special characters is escaped",
+			"https://gitlab.com/testing-repo",
+			"src/my config#1.yml",
+			"https://gitlab.com/testing-repo/-/blob/a85af84d39a32da2c8eba1d88019079aeb0741b0/src/my%20config%231.yml#L12",
+		},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// arrange
+			s.fReport.RepoURL = tt.repoUrl
+			finding := Finding{File: tt.file, Commit: "a85af84d39a32da2c8eba1d88019079aeb0741b0", StartLine: 12}
+
+			// act
+			findingUrl := s.fReport.BuildFindingURL(finding)
+
+			// assert
+			s.Equal(tt.want, findingUrl, tt.name)
+		})
+	}
+}
+
 func (s *FindingsReportTestSuite) TestFindingsReport_BuildPipelineURLTableDrivenShouldPass() {
 
 	tests := []struct {
@@ -195,3 +234,24 @@ func (s *FindingsReportTestSuite) TestFindingsReport_BuildUserURLTableDrivenShou
 		})
 	}
 }
+
+func (s *FindingsReportTestSuite) TestFindingsReport_InheritCommit() {
+
+	// arrange
+	date := time.Date(2022, 12, 3, 12, 48, 14, 0, time.UTC)
+	s.fReport.Findings = Findings{
+		{Fingerprint: "filesystem"},
+		{Fingerprint: "git", Commit: "b85af84d39a32da2c8eba1d88019079aeb0741b0", Author: "git author", Date: date},
+	}
+
+	// act
+	s.fReport.InheritCommit()
+
+	// assert
+	s.Equal("a85af84d39a32da2c8eba1d88019079aeb0741b0", s.fReport.Findings[0].Commit)
+	s.Equal("test author", s.fReport.Findings[0].Author)
+	s.Equal(s.fReport.Timestamp, s.fReport.Findings[0].Date)
+	s.Equal("b85af84d39a32da2c8eba1d88019079aeb0741b0", s.fReport.Findings[1].Commit)
+	s.Equal("git author", s.fReport.Findings[1].Author)
+	s.Equal(date, s.fReport.Findings[1].Date)
+}
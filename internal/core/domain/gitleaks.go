@@ -0,0 +1,403 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GitleaksAllowlist skips findings matching any of its conditions, globally or for a single rule
+type GitleaksAllowlist struct {
+	Description string `json:"description,omitempty" validate:"omitempty,max=500"`
+	// RegexTarget is what regexes are matched against, the secret when it is empty
+	RegexTarget string   `json:"regexTarget,omitempty" validate:"omitempty,oneof=secret match line"`
+	Regexes     []string `json:"regexes,omitempty" validate:"omitempty,max=200,dive,required,max=2000"`
+	Paths       []string `json:"paths,omitempty" validate:"omitempty,max=200,dive,required,max=2000"`
+	Commits     []string `json:"commits,omitempty" validate:"omitempty,max=1000,dive,required,hexadecimal,max=40"`
+	StopWords   []string `json:"stopWords,omitempty" validate:"omitempty,max=1000,dive,required,max=200"`
+}
+
+// GitleaksRule is a [[rules]] entry of gitleaks config, ID is the RuleID of its findings
+type GitleaksRule struct {
+	ID          string `json:"id" validate:"required,max=200"`
+	Description string `json:"description,omitempty" validate:"omitempty,max=500"`
+	Regex       string `json:"regex,omitempty" validate:"omitempty,max=5000"`
+	// SecretGroup is the capture group of Regex holding the secret, the whole match when it is 0
+	SecretGroup int     `json:"secretGroup,omitempty" validate:"min=0,max=100"`
+	Entropy     float64 `json:"entropy,omitempty" validate:"min=0,max=8"`
+	// Path restricts the rule to files matching it, rules without Regex report the files themselves
+	Path      string             `json:"path,omitempty" validate:"omitempty,max=2000"`
+	Keywords  []string           `json:"keywords,omitempty" validate:"omitempty,max=100,dive,required,max=200"`
+	Tags      []string           `json:"tags,omitempty" validate:"omitempty,max=100,dive,required,max=200"`
+	Allowlist *GitleaksAllowlist `json:"allowlist,omitempty"`
+}
+
+// GitleaksConfig is the config pipelines pass to gitleaks detect --config
+type GitleaksConfig struct {
+	Title string `json:"title,omitempty" validate:"omitempty,max=200"`
+	// UseDefault extends the default config of gitleaks, rules with the same ID override default ones
+	UseDefault bool               `json:"useDefault"`
+	Allowlist  *GitleaksAllowlist `json:"allowlist,omitempty"`
+	Rules      []GitleaksRule     `json:"rules" validate:"max=1000,dive"`
+}
+
+// GitleaksConfigVersion is a stored config, every change of the config stores a new version.
+// Version 0 is DefaultGitleaksConfig, which is served until the first version is stored.
+type GitleaksConfigVersion struct {
+	Version int            `json:"version"`
+	Config  GitleaksConfig `json:"config"`
+	// Change describes what changed since the previous version, e.g. "rule aws-access-token updated"
+	Change    string    `json:"change"`
+	CreatedBy string    `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// DefaultGitleaksConfig extends the default gitleaks config without rules of its own
+func DefaultGitleaksConfig() GitleaksConfig {
+	return GitleaksConfig{UseDefault: true, Rules: []GitleaksRule{}}
+}
+
+// Rule returns the rule with the ID
+func (gc GitleaksConfig) Rule(id string) (GitleaksRule, bool) {
+
+	for _, rule := range gc.Rules {
+		if rule.ID == id {
+			return rule, true
+		}
+	}
+
+	return GitleaksRule{}, false
+}
+
+// Validate checks what struct tags can not and gitleaks would reject loading the config: regexes and paths must
+// compile, rule IDs must be unique and secret groups must exist in regexes
+func (gc GitleaksConfig) Validate() error {
+
+	if gc.Allowlist != nil {
+		if err := gc.Allowlist.validate(); err != nil {
+			return fmt.Errorf("allowlist: %v", err)
+		}
+	}
+
+	seen := map[string]bool{}
+
+	for _, rule := range gc.Rules {
+		if strings.TrimSpace(rule.ID) == "" {
+			return fmt.Errorf("rule without id")
+		}
+		if seen[rule.ID] {
+			return fmt.Errorf("rule %q: id is not unique", rule.ID)
+		}
+		seen[rule.ID] = true
+
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("rule %q: %v", rule.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (gr GitleaksRule) validate() error {
+
+	if gr.Regex == "" && gr.Path == "" {
+		return fmt.Errorf("regex or path is required")
+	}
+
+	if gr.Regex != "" {
+		regex, err := regexp.Compile(gr.Regex)
+		if err != nil {
+			return fmt.Errorf("regex: %v", err)
+		}
+		if gr.SecretGroup > regex.NumSubexp() {
+			return fmt.Errorf("secretGroup %d does not exist, regex has %d groups", gr.SecretGroup, regex.NumSubexp())
+		}
+	} else if gr.SecretGroup > 0 {
+		return fmt.Errorf("secretGroup needs a regex")
+	}
+
+	if gr.Path != "" {
+		if _, err := regexp.Compile(gr.Path); err != nil {
+			return fmt.Errorf("path: %v", err)
+		}
+	}
+
+	if gr.Allowlist != nil {
+		if err := gr.Allowlist.validate(); err != nil {
+			return fmt.Errorf("allowlist: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func (ga GitleaksAllowlist) validate() error {
+
+	switch ga.RegexTarget {
+	case "", "secret", "match", "line":
+	default:
+		return fmt.Errorf("unknown regexTarget %q, use secret, match or line", ga.RegexTarget)
+	}
+
+	for _, regex := range ga.Regexes {
+		if _, err := regexp.Compile(regex); err != nil {
+			return fmt.Errorf("regex %q: %v", regex, err)
+		}
+	}
+
+	for _, path := range ga.Paths {
+		if _, err := regexp.Compile(path); err != nil {
+			return fmt.Errorf("path %q: %v", path, err)
+		}
+	}
+
+	return nil
+}
+
+// Empty tells whether the allowlist has no conditions, so it can be left out
+func (ga GitleaksAllowlist) Empty() bool {
+	return len(ga.Regexes) == 0 && len(ga.Paths) == 0 && len(ga.Commits) == 0 && len(ga.StopWords) == 0
+}
+
+// TOML renders the config as gitleaks reads it, regexes and paths are literal strings like in hand written configs
+func (gc GitleaksConfig) TOML() string {
+
+	w := &tomlWriter{}
+
+	if gc.Title != "" {
+		w.line("title = %s", tomlString(gc.Title))
+		w.blank()
+	}
+
+	if gc.UseDefault {
+		w.line("[extend]")
+		w.line("useDefault = true")
+		w.blank()
+	}
+
+	if gc.Allowlist != nil && !gc.Allowlist.Empty() {
+		w.line("[allowlist]")
+		w.allowlist(*gc.Allowlist)
+		w.blank()
+	}
+
+	for _, rule := range gc.Rules {
+		w.rule(rule)
+		w.blank()
+	}
+
+	return w.String()
+}
+
+// TOML renders config of the version, the first line tells the version, so pipelines can pass it with their reports
+func (gcv GitleaksConfigVersion) TOML() string {
+	return fmt.Sprintf("# gitleaks config of secrets operator, version %d\n\n", gcv.Version) + gcv.Config.TOML()
+}
+
+type tomlWriter struct {
+	strings.Builder
+}
+
+func (w *tomlWriter) line(format string, args ...interface{}) {
+	w.WriteString(fmt.Sprintf(format, args...))
+	w.WriteByte('\n')
+}
+
+func (w *tomlWriter) blank() {
+	w.WriteByte('\n')
+}
+
+func (w *tomlWriter) rule(rule GitleaksRule) {
+
+	w.line("[[rules]]")
+	w.line("id = %s", tomlString(rule.ID))
+	if rule.Description != "" {
+		w.line("description = %s", tomlString(rule.Description))
+	}
+	if rule.Regex != "" {
+		w.line("regex = %s", tomlLiteral(rule.Regex))
+	}
+	if rule.SecretGroup > 0 {
+		w.line("secretGroup = %d", rule.SecretGroup)
+	}
+	if rule.Entropy > 0 {
+		w.line("entropy = %s", tomlFloat(rule.Entropy))
+	}
+	if rule.Path != "" {
+		w.line("path = %s", tomlLiteral(rule.Path))
+	}
+	w.array("keywords", rule.Keywords, tomlString)
+	w.array("tags", rule.Tags, tomlString)
+
+	if rule.Allowlist != nil && !rule.Allowlist.Empty() {
+		w.line("[rules.allowlist]")
+		w.allowlist(*rule.Allowlist)
+	}
+}
+
+func (w *tomlWriter) allowlist(allowlist GitleaksAllowlist) {
+
+	if allowlist.Description != "" {
+		w.line("description = %s", tomlString(allowlist.Description))
+	}
+	if allowlist.RegexTarget != "" {
+		w.line("regexTarget = %s", tomlString(allowlist.RegexTarget))
+	}
+	w.array("regexes", allowlist.Regexes, tomlLiteral)
+	w.array("paths", allowlist.Paths, tomlLiteral)
+	w.array("commits", allowlist.Commits, tomlString)
+	w.array("stopwords", allowlist.StopWords, tomlString)
+}
+
+// array writes values one per line, empty arrays are left out
+func (w *tomlWriter) array(key string, values []string, quote func(string) string) {
+
+	if len(values) == 0 {
+		return
+	}
+
+	w.line("%s = [", key)
+	for _, value := range values {
+		w.line("    %s,", quote(value))
+	}
+	w.line("]")
+}
+
+// tomlLiteral quotes regexes as literal strings, backslashes need no escaping there. Values literal strings can not
+// hold are quoted as basic strings.
+func tomlLiteral(value string) string {
+
+	if strings.Contains(value, "'''") || strings.HasPrefix(value, "'") || strings.HasSuffix(value, "'") ||
+		strings.ContainsAny(value, "\r\n") {
+		return tomlString(value)
+	}
+
+	return "'''" + value + "'''"
+}
+
+// tomlString quotes the value as basic string
+func tomlString(value string) string {
+
+	var b strings.Builder
+	b.WriteByte('"')
+
+	for _, r := range value {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				b.WriteString(fmt.Sprintf(`\u%04X`, r))
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+
+	b.WriteByte('"')
+	return b.String()
+}
+
+// tomlFloat keeps the decimal point, so whole numbers stay floats
+func tomlFloat(value float64) string {
+
+	formatted := strconv.FormatFloat(value, 'f', -1, 64)
+	if !strings.Contains(formatted, ".") {
+		formatted += ".0"
+	}
+
+	return formatted
+}
+
+// GitleaksRuleChange is a rule changed between two versions
+type GitleaksRuleChange struct {
+	Before GitleaksRule `json:"before"`
+	After  GitleaksRule `json:"after"`
+}
+
+// GitleaksConfigDiff tells what changed between two config versions, rules are compared by ID
+type GitleaksConfigDiff struct {
+	From    int                  `json:"from"`
+	To      int                  `json:"to"`
+	Added   []GitleaksRule       `json:"added"`
+	Removed []GitleaksRule       `json:"removed"`
+	Changed []GitleaksRuleChange `json:"changed"`
+	// Settings lists changed settings other than rules: "title", "useDefault" or "allowlist"
+	Settings []string `json:"settings"`
+}
+
+// DiffGitleaksConfigs compares configs of the versions, rules and settings are equal when they render the same TOML.
+// Added and changed rules keep order of the newer version, removed ones order of the older one.
+func DiffGitleaksConfigs(from GitleaksConfigVersion, to GitleaksConfigVersion) GitleaksConfigDiff {
+
+	diff := GitleaksConfigDiff{
+		From:     from.Version,
+		To:       to.Version,
+		Added:    []GitleaksRule{},
+		Removed:  []GitleaksRule{},
+		Changed:  []GitleaksRuleChange{},
+		Settings: []string{},
+	}
+
+	if from.Config.Title != to.Config.Title {
+		diff.Settings = append(diff.Settings, "title")
+	}
+	if from.Config.UseDefault != to.Config.UseDefault {
+		diff.Settings = append(diff.Settings, "useDefault")
+	}
+	if allowlistTOML(from.Config.Allowlist) != allowlistTOML(to.Config.Allowlist) {
+		diff.Settings = append(diff.Settings, "allowlist")
+	}
+
+	for _, rule := range to.Config.Rules {
+		before, ok := from.Config.Rule(rule.ID)
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, rule)
+		case ruleTOML(before) != ruleTOML(rule):
+			diff.Changed = append(diff.Changed, GitleaksRuleChange{Before: before, After: rule})
+		}
+	}
+
+	for _, rule := range from.Config.Rules {
+		if _, ok := to.Config.Rule(rule.ID); !ok {
+			diff.Removed = append(diff.Removed, rule)
+		}
+	}
+
+	return diff
+}
+
+func ruleTOML(rule GitleaksRule) string {
+
+	w := &tomlWriter{}
+	w.rule(rule)
+
+	return w.String()
+}
+
+func allowlistTOML(allowlist *GitleaksAllowlist) string {
+
+	if allowlist == nil {
+		return ""
+	}
+
+	w := &tomlWriter{}
+	w.allowlist(*allowlist)
+
+	return w.String()
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FindingIdentity defines which findings are considered the same leak
+type FindingIdentity string
+
+const (
+	// FindingIdentityFingerprint uses gitleaks fingerprint (commit, file, rule and line)
+	FindingIdentityFingerprint FindingIdentity = "fingerprint"
+	// FindingIdentityLocation uses rule, file and keyed hash of the secret, so the same secret found
+	// in later commits or on other lines of the same file is not reported again. Findings without hash, e.g. reports
+	// redacted by gitleaks, use rule, file and line, so at least later commits do not report them again.
+	FindingIdentityLocation FindingIdentity = "location"
+)
+
+func ParseFindingIdentity(value string) (FindingIdentity, error) {
+
+	identity := FindingIdentity(strings.ToLower(value))
+
+	switch identity {
+	case FindingIdentityFingerprint, FindingIdentityLocation:
+		return identity, nil
+	default:
+		return "", fmt.Errorf("unknown finding identity %q", value)
+	}
+}
+
+// Key returns identity key of the finding
+func (fi FindingIdentity) Key(f Finding) string {
+
+	if fi == FindingIdentityLocation {
+		if f.SecretHash != "" {
+			return strings.Join([]string{"location", f.RuleID, f.File, f.SecretHash}, "\x00")
+		}
+		return strings.Join([]string{"line", f.RuleID, f.File, strconv.Itoa(f.StartLine)}, "\x00")
+	}
+
+	return "fingerprint\x00" + f.Fingerprint
+}
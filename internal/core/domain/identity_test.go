@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type FindingIdentityTestSuite struct {
+	suite.Suite
+}
+
+func TestSuiteFindingIdentity(t *testing.T) {
+	suite.Run(t, new(FindingIdentityTestSuite))
+}
+
+func (s *FindingIdentityTestSuite) TestParseFindingIdentityTableDriven() {
+
+	tests := []struct {
+		name    string
+		input   string
+		want    FindingIdentity
+		wantErr bool
+	}{
+		{"fingerprint identity", "fingerprint", FindingIdentityFingerprint, false},
+		{"identity is case insensitive", "Location", FindingIdentityLocation, false},
+		{"unknown identity should fail", "commit", "", true},
+		{"empty identity should fail", "", "", true},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// act
+			identity, err := ParseFindingIdentity(tt.input)
+
+			// assert
+			s.Equal(tt.want, identity, tt.name)
+			s.Equal(tt.wantErr, err != nil, tt.name)
+		})
+	}
+}
+
+func (s *FindingIdentityTestSuite) TestFindingIdentity_Key() {
+
+	finding := Finding{Fingerprint: "commit:file:rule:1", RuleID: "rule", File: "file", SecretHash: "hash"}
+	moved := Finding{Fingerprint: "commit:file:rule:7", RuleID: "rule", File: "file", SecretHash: "hash"}
+	redacted := Finding{Fingerprint: "commit:file:rule:1", RuleID: "rule", File: "file", StartLine: 1, Secret: RedactedPlaceholder}
+	redactedLater := Finding{Fingerprint: "later:file:rule:1", RuleID: "rule", File: "file", StartLine: 1, Secret: RedactedPlaceholder}
+	redactedMoved := Finding{Fingerprint: "later:file:rule:7", RuleID: "rule", File: "file", StartLine: 7, Secret: RedactedPlaceholder}
+
+	s.NotEqual(FindingIdentityFingerprint.Key(finding), FindingIdentityFingerprint.Key(moved))
+	s.Equal(FindingIdentityLocation.Key(finding), FindingIdentityLocation.Key(moved))
+
+	// redacted findings have no hash, location identity uses their line
+	s.NotEqual(FindingIdentityFingerprint.Key(redacted), FindingIdentityFingerprint.Key(redactedLater))
+	s.Equal(FindingIdentityLocation.Key(redacted), FindingIdentityLocation.Key(redactedLater))
+	s.NotEqual(FindingIdentityLocation.Key(redacted), FindingIdentityLocation.Key(redactedMoved))
+}
@@ -0,0 +1,188 @@
+package domain
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// MaxOccurrences limits how many occurrences are kept per finding, OccurrenceCount keeps counting after that.
+// Pipeline IDs grow, so a pipeline not newer than the dropped ones is taken as counted already.
+const MaxOccurrences = 100
+
+// Occurrence records a pipeline run which reported the finding
+type Occurrence struct {
+	PipelineID int       `json:"pipelineId"`
+	CommitSHA  string    `json:"commitSHA"`
+	SeenAt     time.Time `json:"seenAt"`
+}
+
+// NewID returns random 12 bytes long hex encoded identifier
+func NewID() string {
+
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+// UpsertResult tells which findings of a report were seen for the first time and which were already known.
+// Findings repeated within the same report are merged into the first one and are not listed twice.
+type UpsertResult struct {
+	New   Findings `json:"new"`
+	Known Findings `json:"known"`
+}
+
+// Merge returns copy of repository findings with findings of the report merged into it.
+// Findings are matched by identity: known findings get a new occurrence, unknown ones are added as open findings
+// with a new ID. Reports re-uploaded by the same pipeline are not counted twice.
+func (rf RepoFindings) Merge(report FindingsReport, identity FindingIdentity, seenAt time.Time) (RepoFindings, UpsertResult) {
+
+	merged := RepoFindings{
+		RepoID:   report.RepoID,
+		RepoName: report.RepoName,
+		RepoURL:  report.RepoURL,
+		Findings: Findings{},
+	}
+
+	index := map[string]int{}
+
+	// existing findings are normalized as well, documents stored before IDs existed may contain duplicates
+	for _, finding := range rf.Findings {
+		key := identity.Key(finding)
+		if i, ok := index[key]; ok {
+			merged.Findings[i].OccurrenceCount += maxInt(finding.OccurrenceCount, 1)
+			continue
+		}
+
+		if finding.ID == "" {
+			finding.ID = NewID()
+		}
+		if finding.Status == "" {
+			finding.Status = FindingStatusOpen
+		}
+		finding.OccurrenceCount = maxInt(finding.OccurrenceCount, 1)
+		finding = finding.Clone()
+
+		index[key] = len(merged.Findings)
+		merged.Findings = append(merged.Findings, finding)
+	}
+
+	result := UpsertResult{New: Findings{}, Known: Findings{}}
+	newIndexes := []int{}
+	knownIndexes := []int{}
+	reported := map[int]bool{}
+
+	occurrence := Occurrence{
+		PipelineID: report.PipelineID,
+		CommitSHA:  report.CommitSHA,
+		SeenAt:     seenAt,
+	}
+
+	for _, finding := range report.Findings {
+		key := identity.Key(finding)
+		i, ok := index[key]
+		if !ok {
+			finding = finding.Clone()
+			finding.ID = NewID()
+			finding.Status = FindingStatusOpen
+			finding.StatusHistory = nil
+			finding.Assignee = ""
+			finding.FirstSeen = seenAt
+			finding.LastSeen = seenAt
+			finding.OccurrenceCount = 1
+			finding.Occurrences = []Occurrence{occurrence}
+
+			index[key] = len(merged.Findings)
+			reported[len(merged.Findings)] = true
+			newIndexes = append(newIndexes, len(merged.Findings))
+			merged.Findings = append(merged.Findings, finding)
+			continue
+		}
+
+		merged.Findings[i].addOccurrence(occurrence)
+		if finding.Verified {
+			merged.Findings[i].Verified = true
+		}
+		if !reported[i] {
+			reported[i] = true
+			knownIndexes = append(knownIndexes, i)
+		}
+	}
+
+	// results are collected at the end, so they contain final state of findings
+	for _, i := range newIndexes {
+		result.New = append(result.New, merged.Findings[i].Clone())
+	}
+	for _, i := range knownIndexes {
+		result.Known = append(result.Known, merged.Findings[i].Clone())
+	}
+
+	return merged, result
+}
+
+// Clone returns deep copy of the finding, slices are not shared with the original
+func (f Finding) Clone() Finding {
+
+	if f.Tags != nil {
+		f.Tags = append([]string{}, f.Tags...)
+	}
+	if f.StatusHistory != nil {
+		f.StatusHistory = append([]StatusChange{}, f.StatusHistory...)
+	}
+	if f.Occurrences != nil {
+		f.Occurrences = append([]Occurrence{}, f.Occurrences...)
+	}
+
+	return f
+}
+
+// Clone returns deep copy of findings
+func (f Findings) Clone() Findings {
+
+	if f == nil {
+		return nil
+	}
+
+	cloned := make(Findings, len(f))
+	for i := range f {
+		cloned[i] = f[i].Clone()
+	}
+
+	return cloned
+}
+
+func (f *Finding) addOccurrence(occurrence Occurrence) {
+
+	// dropped occurrences are not listed anymore, re-uploads of their pipelines are recognized by the watermark
+	if f.TrimmedPipelineID > 0 && occurrence.PipelineID <= f.TrimmedPipelineID {
+		return
+	}
+	for _, o := range f.Occurrences {
+		if o.PipelineID == occurrence.PipelineID && o.CommitSHA == occurrence.CommitSHA {
+			return
+		}
+	}
+
+	if f.FirstSeen.IsZero() {
+		f.FirstSeen = occurrence.SeenAt
+	}
+	f.LastSeen = occurrence.SeenAt
+	f.OccurrenceCount++
+	f.Occurrences = append(f.Occurrences, occurrence)
+
+	if len(f.Occurrences) > MaxOccurrences {
+		trimmed := len(f.Occurrences) - MaxOccurrences
+		for _, o := range f.Occurrences[:trimmed] {
+			f.TrimmedPipelineID = maxInt(f.TrimmedPipelineID, o.PipelineID)
+		}
+		f.Occurrences = f.Occurrences[trimmed:]
+	}
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,233 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type MergeTestSuite struct {
+	suite.Suite
+	seenAt time.Time
+	report FindingsReport
+}
+
+func TestSuiteMerge(t *testing.T) {
+	suite.Run(t, new(MergeTestSuite))
+}
+
+func (s *MergeTestSuite) SetupTest() {
+
+	s.seenAt = time.Date(2022, 12, 3, 12, 48, 14, 0, time.UTC)
+	s.report = FindingsReport{
+		PipelineID: 2,
+		RepoID:     1,
+		RepoName:   "renamed repo",
+		RepoURL:    "https://test.com",
+		CommitSHA:  "a85af84d39a32da2c8eba1d88019079aeb0741b0",
+		Findings: Findings{
+			{Fingerprint: "known", Tags: []string{"test"}},
+			{Fingerprint: "new", Tags: []string{"test"}},
+		},
+	}
+}
+
+func (s *MergeTestSuite) TestRepoFindings_MergeIntoEmptyRepository() {
+
+	merged, result := RepoFindings{}.Merge(s.report, FindingIdentityFingerprint, s.seenAt)
+
+	s.Equal(merged.Findings, result.New)
+	s.Empty(result.Known)
+	s.Equal(1, merged.RepoID)
+	s.Equal("renamed repo", merged.RepoName)
+	s.Len(merged.Findings, 2)
+
+	for _, finding := range merged.Findings {
+		s.Len(finding.ID, 24)
+		s.Equal(FindingStatusOpen, finding.Status)
+		s.Equal(s.seenAt, finding.FirstSeen)
+		s.Equal(s.seenAt, finding.LastSeen)
+		s.Equal(1, finding.OccurrenceCount)
+		s.Equal([]Occurrence{{PipelineID: 2, CommitSHA: s.report.CommitSHA, SeenAt: s.seenAt}}, finding.Occurrences)
+	}
+	s.NotEqual(merged.Findings[0].ID, merged.Findings[1].ID)
+}
+
+func (s *MergeTestSuite) TestRepoFindings_MergeKnownFinding() {
+
+	firstSeen := s.seenAt.Add(-time.Hour)
+	existing := RepoFindings{
+		RepoID:   1,
+		RepoName: "test repo",
+		Findings: Findings{
+			{
+				ID:              "a1",
+				Fingerprint:     "known",
+				Status:          FindingStatusAcknowledged,
+				FirstSeen:       firstSeen,
+				LastSeen:        firstSeen,
+				OccurrenceCount: 1,
+				Occurrences:     []Occurrence{{PipelineID: 1, SeenAt: firstSeen}},
+			},
+		},
+	}
+
+	merged, result := existing.Merge(s.report, FindingIdentityFingerprint, s.seenAt)
+
+	s.Require().Len(merged.Findings, 2)
+	s.Require().Len(result.Known, 1)
+	s.Require().Len(result.New, 1)
+	s.Equal("a1", result.Known[0].ID)
+	s.Equal("new", result.New[0].Fingerprint)
+	known := merged.Findings[0]
+	s.Equal("a1", known.ID)
+	s.Equal(FindingStatusAcknowledged, known.Status)
+	s.Equal(firstSeen, known.FirstSeen)
+	s.Equal(s.seenAt, known.LastSeen)
+	s.Equal(2, known.OccurrenceCount)
+	s.Len(known.Occurrences, 2)
+	s.Len(existing.Findings[0].Occurrences, 1, "existing findings must not be modified")
+
+	// same pipeline uploading again must not be counted twice
+	again, againResult := merged.Merge(s.report, FindingIdentityFingerprint, s.seenAt.Add(time.Minute))
+	s.Empty(againResult.New)
+	s.Len(againResult.Known, 2)
+	s.Equal(2, again.Findings[0].OccurrenceCount)
+	s.Equal(merged.Findings[1].ID, again.Findings[1].ID)
+}
+
+func (s *MergeTestSuite) TestRepoFindings_MergeRedactedReportByLocation() {
+
+	// reports of the pipeline script are redacted by gitleaks, findings have no secret hash
+	s.report.Findings = Findings{
+		{Fingerprint: "a85af84:src/main.go:test-rule:1", RuleID: "test-rule", File: "src/main.go", StartLine: 1, Secret: RedactedPlaceholder},
+	}
+	existing, _ := RepoFindings{}.Merge(s.report, FindingIdentityLocation, s.seenAt)
+
+	later := s.report
+	later.PipelineID = 3
+	later.Findings = Findings{
+		{Fingerprint: "b91bf95:src/main.go:test-rule:1", RuleID: "test-rule", File: "src/main.go", StartLine: 1, Secret: RedactedPlaceholder},
+	}
+
+	merged, result := existing.Merge(later, FindingIdentityLocation, s.seenAt.Add(time.Hour))
+
+	s.Empty(result.New, "the same leak found in a later commit is known")
+	s.Require().Len(result.Known, 1)
+	s.Require().Len(merged.Findings, 1)
+	s.Equal(2, merged.Findings[0].OccurrenceCount)
+}
+
+func (s *MergeTestSuite) TestRepoFindings_MergeKeepsVerified() {
+
+	existing, _ := RepoFindings{}.Merge(s.report, FindingIdentityFingerprint, s.seenAt)
+
+	s.report.Findings[0].Verified = true
+	verified, _ := existing.Merge(s.report, FindingIdentityFingerprint, s.seenAt.Add(time.Hour))
+
+	s.report.Findings[0].Verified = false
+	again, _ := verified.Merge(s.report, FindingIdentityFingerprint, s.seenAt.Add(2*time.Hour))
+
+	s.False(existing.Findings[0].Verified)
+	s.True(verified.Findings[0].Verified)
+	s.True(again.Findings[0].Verified, "verified secret stays verified when a scanner without verification reports it")
+	s.False(again.Findings[1].Verified)
+}
+
+func (s *MergeTestSuite) TestRepoFindings_MergeNormalizesLegacyDocuments() {
+
+	// documents stored before findings had IDs may contain the same finding multiple times
+	existing := RepoFindings{
+		Findings: Findings{
+			{Fingerprint: "legacy"},
+			{Fingerprint: "legacy"},
+		},
+	}
+
+	merged, _ := existing.Merge(FindingsReport{RepoID: 1}, FindingIdentityFingerprint, s.seenAt)
+
+	s.Require().Len(merged.Findings, 1)
+	s.NotEmpty(merged.Findings[0].ID)
+	s.Equal(FindingStatusOpen, merged.Findings[0].Status)
+	s.Equal(2, merged.Findings[0].OccurrenceCount)
+}
+
+func (s *MergeTestSuite) TestRepoFindings_MergeDuplicatesWithinReport() {
+
+	s.report.Findings = Findings{{Fingerprint: "twice"}, {Fingerprint: "twice"}}
+
+	merged, result := RepoFindings{}.Merge(s.report, FindingIdentityFingerprint, s.seenAt)
+
+	s.Len(merged.Findings, 1)
+	s.Len(result.New, 1)
+	s.Empty(result.Known)
+	s.Equal(1, merged.Findings[0].OccurrenceCount)
+}
+
+func (s *MergeTestSuite) TestRepoFindings_MergeByLocation() {
+
+	existing := RepoFindings{
+		Findings: Findings{
+			{ID: "a1", Fingerprint: "commit1:file:rule:1", RuleID: "rule", File: "file", SecretHash: "hash"},
+		},
+	}
+	s.report.Findings = Findings{
+		{Fingerprint: "commit2:file:rule:5", RuleID: "rule", File: "file", SecretHash: "hash"},
+		{Fingerprint: "commit2:file:rule:9", RuleID: "rule", File: "file", SecretHash: "other hash"},
+	}
+
+	tests := []struct {
+		name      string
+		identity  FindingIdentity
+		wantNew   int
+		wantKnown int
+	}{
+		{"fingerprint identity treats moved secret as new", FindingIdentityFingerprint, 2, 0},
+		{"location identity recognises moved secret", FindingIdentityLocation, 1, 1},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// act
+			_, result := existing.Merge(s.report, tt.identity, s.seenAt)
+
+			// assert
+			s.Len(result.New, tt.wantNew, tt.name)
+			s.Len(result.Known, tt.wantKnown, tt.name)
+		})
+	}
+}
+
+func (s *MergeTestSuite) TestFinding_OccurrencesAreLimited() {
+
+	finding := Finding{}
+	for i := 0; i < MaxOccurrences+10; i++ {
+		finding.addOccurrence(Occurrence{PipelineID: i, SeenAt: s.seenAt})
+	}
+
+	s.Equal(MaxOccurrences+10, finding.OccurrenceCount)
+	s.Len(finding.Occurrences, MaxOccurrences)
+	s.Equal(10, finding.Occurrences[0].PipelineID)
+}
+
+func (s *MergeTestSuite) TestFinding_TrimmedOccurrencesAreNotCountedAgain() {
+
+	// arrange
+	finding := Finding{}
+	for i := 1; i <= MaxOccurrences+10; i++ {
+		finding.addOccurrence(Occurrence{PipelineID: i, SeenAt: s.seenAt})
+	}
+
+	// act
+	finding.addOccurrence(Occurrence{PipelineID: 5, SeenAt: s.seenAt})
+	finding.addOccurrence(Occurrence{PipelineID: 10, SeenAt: s.seenAt})
+	finding.addOccurrence(Occurrence{PipelineID: MaxOccurrences + 11, SeenAt: s.seenAt})
+
+	// assert
+	s.Equal(11, finding.TrimmedPipelineID)
+	s.Equal(MaxOccurrences+11, finding.OccurrenceCount)
+	s.Len(finding.Occurrences, MaxOccurrences)
+	s.Equal(12, finding.Occurrences[0].PipelineID)
+}
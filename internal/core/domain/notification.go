@@ -0,0 +1,53 @@
+package domain
+
+// NotificationFilter limits findings delivered to a notification channel, empty lists match everything
+type NotificationFilter struct {
+	RepoIDs        []int
+	RuleIDs        []string
+	ExcludeRuleIDs []string
+}
+
+// Apply returns report with findings matching the filter, false is returned if nothing is left to notify about
+func (filter NotificationFilter) Apply(report FindingsReport) (FindingsReport, bool) {
+
+	if len(filter.RepoIDs) > 0 && !containsInt(filter.RepoIDs, report.RepoID) {
+		return FindingsReport{}, false
+	}
+
+	findings := make(Findings, 0, len(report.Findings))
+	for _, finding := range report.Findings {
+		if len(filter.RuleIDs) > 0 && !containsString(filter.RuleIDs, finding.RuleID) {
+			continue
+		}
+		if containsString(filter.ExcludeRuleIDs, finding.RuleID) {
+			continue
+		}
+		findings = append(findings, finding)
+	}
+
+	if len(findings) == 0 {
+		return FindingsReport{}, false
+	}
+
+	report.Findings = findings
+
+	return report, true
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
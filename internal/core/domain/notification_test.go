@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type NotificationFilterTestSuite struct {
+	suite.Suite
+}
+
+func TestSuiteNotificationFilter(t *testing.T) {
+	suite.Run(t, new(NotificationFilterTestSuite))
+}
+
+func (s *NotificationFilterTestSuite) TestNotificationFilter_ApplyTableDriven() {
+
+	report := FindingsReport{
+		RepoID: 444,
+		Findings: Findings{
+			{RuleID: "aws-access-token", Fingerprint: "f1"},
+			{RuleID: "generic-api-key", Fingerprint: "f2"},
+		},
+	}
+
+	tests := []struct {
+		name             string
+		filter           NotificationFilter
+		wantOk           bool
+		wantFingerprints []string
+	}{
+		{"empty filter matches everything", NotificationFilter{}, true, []string{"f1", "f2"}},
+		{"matching repository", NotificationFilter{RepoIDs: []int{444, 555}}, true, []string{"f1", "f2"}},
+		{"other repository", NotificationFilter{RepoIDs: []int{555}}, false, nil},
+		{"included rules", NotificationFilter{RuleIDs: []string{"aws-access-token"}}, true, []string{"f1"}},
+		{"excluded rules", NotificationFilter{ExcludeRuleIDs: []string{"aws-access-token"}}, true, []string{"f2"}},
+		{"exclusion wins over inclusion", NotificationFilter{RuleIDs: []string{"aws-access-token"}, ExcludeRuleIDs: []string{"aws-access-token"}}, false, nil},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// act
+			filtered, ok := tt.filter.Apply(report)
+
+			// assert
+			assert.Equal(s.T(), tt.wantOk, ok)
+
+			var fingerprints []string
+			for _, finding := range filtered.Findings {
+				fingerprints = append(fingerprints, finding.Fingerprint)
+			}
+			assert.Equal(s.T(), tt.wantFingerprints, fingerprints)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"time"
+)
+
+type OutboxStatus string
+
+const (
+	OutboxPending   OutboxStatus = "pending"
+	OutboxDelivered OutboxStatus = "delivered"
+	// OutboxDead messages exhausted their attempts and wait for an admin to replay them
+	OutboxDead OutboxStatus = "dead"
+)
+
+// OutboxMessage is a notification about new findings of an upload, stored together with the findings
+// and delivered to notification channels by a background worker.
+type OutboxMessage struct {
+	ID       string         `json:"id"`
+	ReportID string         `json:"reportId"`
+	RepoID   int            `json:"repoId"`
+	Report   FindingsReport `json:"report"`
+	// Channels still waiting for the message, empty until the first attempt means every channel
+	Channels      []string        `json:"channels,omitempty"`
+	Status        OutboxStatus    `json:"status"`
+	AttemptCount  int             `json:"attemptCount"`
+	Attempts      []OutboxAttempt `json:"attempts,omitempty"`
+	NextAttemptAt time.Time       `json:"nextAttemptAt"`
+	CreatedAt     time.Time       `json:"createdAt"`
+}
+
+// OutboxAttempt is a single delivery attempt, FailedChannels is empty if every channel received the message
+type OutboxAttempt struct {
+	At             time.Time `json:"at"`
+	FailedChannels []string  `json:"failedChannels,omitempty"`
+	Error          string    `json:"error,omitempty"`
+	DurationMs     int64     `json:"durationMs"`
+}
+
+// NewOutboxMessage queues notification about newFindings of the report, due immediately
+func NewOutboxMessage(findingsReport FindingsReport, newFindings Findings, now time.Time) OutboxMessage {
+
+	findingsReport.Findings = newFindings
+
+	return OutboxMessage{
+		ID:            NewID(),
+		ReportID:      findingsReport.ID,
+		RepoID:        findingsReport.RepoID,
+		Report:        findingsReport,
+		Status:        OutboxPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+}
+
+// RecordAttempt logs the attempt and moves message to delivered, to dead once attempts are exhausted,
+// or schedules the next attempt for the channels which failed.
+func (om *OutboxMessage) RecordAttempt(attempt OutboxAttempt, policy RetryPolicy) {
+
+	om.AttemptCount++
+	om.Attempts = append(om.Attempts, attempt)
+	if len(om.Attempts) > maxLoggedAttempts {
+		om.Attempts = om.Attempts[len(om.Attempts)-maxLoggedAttempts:]
+	}
+
+	if len(attempt.FailedChannels) > 0 {
+		om.Channels = attempt.FailedChannels
+	}
+
+	switch {
+	case attempt.Error == "":
+		om.Status = OutboxDelivered
+		om.Channels = nil
+	case om.AttemptCount >= policy.MaxAttempts:
+		om.Status = OutboxDead
+	default:
+		om.Status = OutboxPending
+		om.NextAttemptAt = attempt.At.Add(policy.Backoff(om.AttemptCount))
+	}
+}
+
+// Replay gives dead message a fresh set of attempts, starting now
+func (om *OutboxMessage) Replay(now time.Time) {
+
+	om.Status = OutboxPending
+	om.AttemptCount = 0
+	om.NextAttemptAt = now
+}
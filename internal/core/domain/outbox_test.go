@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type OutboxTestSuite struct {
+	suite.Suite
+	policy RetryPolicy
+	at     time.Time
+}
+
+func TestSuiteOutbox(t *testing.T) {
+	suite.Run(t, new(OutboxTestSuite))
+}
+
+func (s *OutboxTestSuite) SetupTest() {
+	s.policy = RetryPolicy{MaxAttempts: 3, BaseDelay: 10 * time.Second, MaxDelay: 25 * time.Second}
+	s.at = time.Date(2022, 12, 3, 12, 48, 14, 0, time.UTC)
+}
+
+func (s *OutboxTestSuite) TestNewOutboxMessage() {
+
+	// arrange
+	report := FindingsReport{ID: "r1", RepoID: 444, Findings: Findings{{Fingerprint: "f1"}, {Fingerprint: "f2"}}}
+
+	// act
+	message := NewOutboxMessage(report, Findings{{Fingerprint: "f2"}}, s.at)
+
+	// assert
+	assert.NotEmpty(s.T(), message.ID)
+	assert.Equal(s.T(), "r1", message.ReportID)
+	assert.Equal(s.T(), 444, message.RepoID)
+	assert.Equal(s.T(), Findings{{Fingerprint: "f2"}}, message.Report.Findings, "only new findings are notified about")
+	assert.Equal(s.T(), OutboxPending, message.Status)
+	assert.Equal(s.T(), s.at, message.NextAttemptAt, "due immediately")
+	assert.Len(s.T(), report.Findings, 2, "report must not be modified")
+}
+
+func (s *OutboxTestSuite) TestOutboxMessage_RecordAttempt() {
+
+	// arrange
+	message := OutboxMessage{Status: OutboxPending, NextAttemptAt: s.at}
+
+	// act & assert
+	message.RecordAttempt(OutboxAttempt{At: s.at, FailedChannels: []string{"slack", "teams"}, Error: "slack, teams"}, s.policy)
+	assert.Equal(s.T(), OutboxPending, message.Status)
+	assert.Equal(s.T(), []string{"slack", "teams"}, message.Channels)
+	assert.Equal(s.T(), s.at.Add(10*time.Second), message.NextAttemptAt)
+
+	message.RecordAttempt(OutboxAttempt{At: s.at.Add(10 * time.Second), FailedChannels: []string{"teams"}, Error: "teams"}, s.policy)
+	assert.Equal(s.T(), []string{"teams"}, message.Channels, "only failed channels are retried")
+	assert.Equal(s.T(), s.at.Add(30*time.Second), message.NextAttemptAt)
+
+	message.RecordAttempt(OutboxAttempt{At: s.at.Add(30 * time.Second), FailedChannels: []string{"teams"}, Error: "teams"}, s.policy)
+	assert.Equal(s.T(), OutboxDead, message.Status, "attempts are exhausted")
+	assert.Equal(s.T(), 3, message.AttemptCount)
+
+	message.Replay(s.at.Add(time.Hour))
+	assert.Equal(s.T(), OutboxPending, message.Status)
+	assert.Equal(s.T(), 0, message.AttemptCount)
+	assert.Equal(s.T(), s.at.Add(time.Hour), message.NextAttemptAt)
+	assert.Len(s.T(), message.Attempts, 3, "attempt log is kept")
+
+	message.RecordAttempt(OutboxAttempt{At: s.at.Add(time.Hour)}, s.policy)
+	assert.Equal(s.T(), OutboxDelivered, message.Status)
+	assert.Empty(s.T(), message.Channels)
+}
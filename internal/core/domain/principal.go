@@ -0,0 +1,79 @@
+package domain
+
+type Role string
+
+const (
+	// RoleUploader is granted to upload tokens, it uploads reports and reads them as baseline
+	RoleUploader Role = "uploader"
+	// RoleViewer reads findings of visible repositories
+	RoleViewer Role = "viewer"
+	// RoleTriager reads findings and changes their status
+	RoleTriager Role = "triager"
+	// RoleAdmin is allowed everything on every repository, including token management
+	RoleAdmin Role = "admin"
+)
+
+// Principal is the authenticated caller of the API, either an API key or an identity provider user
+type Principal struct {
+	KeyID   string
+	Subject string
+	Name    string
+	Role    Role
+	// RepoIDs limits repositories visible to the principal, nil means every repository
+	RepoIDs []int
+}
+
+// Actor identifies the principal in audit records, e.g. status history of findings. Names of identity provider users
+// are display names which are neither unique nor fixed, so the subject is recorded, and the ID of API keys.
+func (p Principal) Actor() string {
+
+	switch {
+	case p.Subject != "":
+		return p.Subject
+	case p.KeyID != "":
+		return p.KeyID
+	default:
+		return p.Name
+	}
+}
+
+func (p Principal) IsAdmin() bool {
+	return p.Role == RoleAdmin
+}
+
+// CanSee tells whether the repository is visible to the principal, regardless of the role
+func (p Principal) CanSee(repoId int) bool {
+
+	if p.RepoIDs == nil {
+		return true
+	}
+
+	for _, id := range p.RepoIDs {
+		if id == repoId {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CanRead tells whether findings of the repository can be read, upload tokens need them as gitleaks baseline
+func (p Principal) CanRead(repoId int) bool {
+
+	switch p.Role {
+	case RoleAdmin:
+		return true
+	case RoleUploader, RoleViewer, RoleTriager:
+		return p.CanSee(repoId)
+	default:
+		return false
+	}
+}
+
+func (p Principal) CanUpload(repoId int) bool {
+	return p.IsAdmin() || (p.Role == RoleUploader && p.CanSee(repoId))
+}
+
+func (p Principal) CanTriage(repoId int) bool {
+	return p.IsAdmin() || (p.Role == RoleTriager && p.CanSee(repoId))
+}
@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+type RedactionMode string
+
+const (
+	// RedactionModeMask replaces whole secret with RedactedPlaceholder
+	RedactionModeMask RedactionMode = "mask"
+	// RedactionModePartial keeps configured amount of leading and trailing characters of the secret
+	RedactionModePartial RedactionMode = "partial"
+	// RedactionModeHMAC replaces the secret with its keyed hash
+	RedactionModeHMAC RedactionMode = "hmac"
+)
+
+// RedactedPlaceholder is the value gitleaks itself writes when running with --redact.
+// Keeping the same value lets reports redacted by gitleaks and by us be used as a gitleaks baseline.
+const RedactedPlaceholder = "REDACTED"
+
+const (
+	partialMask = "*****"
+	hmacPrefix  = "hmac-sha256:"
+	// partialKeepDivisor caps prefix and suffix of partial mode to an eighth of the secret each, so at most a quarter
+	// of a short secret is kept
+	partialKeepDivisor = 8
+)
+
+// RedactionPolicy defines how Secret and Match fields of findings are stored.
+// Independently of the mode, a keyed hash of the raw secret is kept in SecretHash, so duplicates can still be matched.
+// Without a key no hash is kept, an unkeyed hash of short secrets could be brute-forced by anyone reading findings.
+// Reports redacted by gitleaks --redact, like those of the pipeline script, carry no secret and get no hash.
+type RedactionPolicy struct {
+	Mode       RedactionMode
+	KeepPrefix int
+	KeepSuffix int
+	Key        []byte
+}
+
+func NewRedactionPolicy(mode string, keepPrefix, keepSuffix int, key string) (RedactionPolicy, error) {
+
+	policy := RedactionPolicy{
+		Mode:       RedactionMode(strings.ToLower(mode)),
+		KeepPrefix: keepPrefix,
+		KeepSuffix: keepSuffix,
+		Key:        []byte(key),
+	}
+
+	switch policy.Mode {
+	case RedactionModeMask:
+	case RedactionModePartial:
+		if keepPrefix < 0 || keepSuffix < 0 {
+			return RedactionPolicy{}, fmt.Errorf("redaction prefix and suffix lengths must not be negative")
+		}
+	case RedactionModeHMAC:
+		if len(policy.Key) == 0 {
+			return RedactionPolicy{}, fmt.Errorf("redaction mode %q requires a key", policy.Mode)
+		}
+	default:
+		return RedactionPolicy{}, fmt.Errorf("unknown redaction mode %q", mode)
+	}
+
+	return policy, nil
+}
+
+// HashesSecrets tells whether SecretHash is kept, it needs a key
+func (rp RedactionPolicy) HashesSecrets() bool {
+	return len(rp.Key) > 0
+}
+
+// Hash returns hex encoded HMAC-SHA256 of the secret with the policy key
+func (rp RedactionPolicy) Hash(secret string) string {
+
+	mac := hmac.New(sha256.New, rp.Key)
+	mac.Write([]byte(secret))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Redact returns copy of findings with Secret and Match redacted and SecretHash set if the policy has a key.
+// Findings which were already redacted by gitleaks are returned as they are, there is nothing left to hash.
+func (rp RedactionPolicy) Redact(findings Findings) Findings {
+
+	redacted := make(Findings, len(findings))
+	copy(redacted, findings)
+
+	for i := range redacted {
+		secret := redacted[i].Secret
+		if secret == "" || secret == RedactedPlaceholder {
+			continue
+		}
+
+		hash := ""
+		if rp.HashesSecrets() {
+			hash = rp.Hash(secret)
+		}
+		replacement := rp.redactSecret(secret, hash)
+
+		redacted[i].SecretHash = hash
+		redacted[i].Secret = replacement
+
+		// match normally contains the secret, anything else can not be trusted to be free of it
+		if strings.Contains(redacted[i].Match, secret) {
+			redacted[i].Match = strings.ReplaceAll(redacted[i].Match, secret, replacement)
+		} else {
+			redacted[i].Match = replacement
+		}
+	}
+
+	return redacted
+}
+
+func (rp RedactionPolicy) redactSecret(secret, hash string) string {
+
+	switch rp.Mode {
+	case RedactionModePartial:
+		runes := []rune(secret)
+		keepPrefix := minInt(rp.KeepPrefix, len(runes)/partialKeepDivisor)
+		keepSuffix := minInt(rp.KeepSuffix, len(runes)/partialKeepDivisor)
+		if keepPrefix+keepSuffix == 0 {
+			return RedactedPlaceholder
+		}
+		return string(runes[:keepPrefix]) + partialMask + string(runes[len(runes)-keepSuffix:])
+	case RedactionModeHMAC:
+		return hmacPrefix + hash
+	default:
+		return RedactedPlaceholder
+	}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
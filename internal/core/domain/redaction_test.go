@@ -0,0 +1,170 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/suite"
+	"strings"
+	"testing"
+)
+
+type RedactionPolicyTestSuite struct {
+	suite.Suite
+	findings Findings
+}
+
+func TestSuiteRedactionPolicy(t *testing.T) {
+	suite.Run(t, new(RedactionPolicyTestSuite))
+}
+
+func (s *RedactionPolicyTestSuite) SetupTest() {
+
+	s.findings = Findings{
+		{
+			Match:       "password = 's3cr3t-v4lue'",
+			Secret:      "s3cr3t-v4lue",
+			RuleID:      "test ruleId",
+			Fingerprint: "test fingerprint",
+		},
+	}
+}
+
+func (s *RedactionPolicyTestSuite) TestNewRedactionPolicyTableDriven() {
+
+	tests := []struct {
+		name       string
+		mode       string
+		keepPrefix int
+		keepSuffix int
+		key        string
+		wantErr    bool
+	}{
+		{"mask mode without key should pass", "mask", 0, 0, "", false},
+		{"mode is case insensitive", "PARTIAL", 2, 2, "", false},
+		{"negative partial length should fail", "partial", -1, 2, "", true},
+		{"hmac mode without key should fail", "hmac", 0, 0, "", true},
+		{"hmac mode with key should pass", "hmac", 0, 0, "key", false},
+		{"unknown mode should fail", "rot13", 0, 0, "", true},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// act
+			_, err := NewRedactionPolicy(tt.mode, tt.keepPrefix, tt.keepSuffix, tt.key)
+
+			// assert
+			s.Equal(tt.wantErr, err != nil, tt.name)
+		})
+	}
+}
+
+func (s *RedactionPolicyTestSuite) TestRedactionPolicy_RedactTableDriven() {
+
+	key := []byte("test key")
+	hash := RedactionPolicy{Key: key}.Hash("s3cr3t-v4lue")
+
+	tests := []struct {
+		name       string
+		policy     RedactionPolicy
+		wantSecret string
+		wantMatch  string
+	}{
+		{
+			"mask mode replaces secret with gitleaks placeholder",
+			RedactionPolicy{Mode: RedactionModeMask, Key: key},
+			"REDACTED",
+			"password = 'REDACTED'",
+		},
+		{
+			"partial mode keeps at most an eighth of the secret on each side",
+			RedactionPolicy{Mode: RedactionModePartial, KeepPrefix: 4, KeepSuffix: 4, Key: key},
+			"s*****e",
+			"password = 's*****e'",
+		},
+		{
+			"partial mode keeps less than the cap",
+			RedactionPolicy{Mode: RedactionModePartial, KeepPrefix: 0, KeepSuffix: 1, Key: key},
+			"*****e",
+			"password = '*****e'",
+		},
+		{
+			"hmac mode replaces secret with keyed hash",
+			RedactionPolicy{Mode: RedactionModeHMAC, Key: key},
+			"hmac-sha256:" + hash,
+			"password = 'hmac-sha256:" + hash + "'",
+		},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// act
+			redacted := tt.policy.Redact(s.findings)
+
+			// assert
+			s.Equal(tt.wantSecret, redacted[0].Secret, tt.name)
+			s.Equal(tt.wantMatch, redacted[0].Match, tt.name)
+			s.Equal(hash, redacted[0].SecretHash, tt.name)
+			s.Equal("s3cr3t-v4lue", s.findings[0].Secret, "original findings must not be modified")
+		})
+	}
+}
+
+func (s *RedactionPolicyTestSuite) TestRedactionPolicy_RedactEdgeCases() {
+
+	policy := RedactionPolicy{Mode: RedactionModeMask, Key: []byte("test key")}
+
+	s.Run("already redacted findings are left as they are", func() {
+		findings := Findings{{Match: "password = 'REDACTED'", Secret: RedactedPlaceholder}}
+
+		redacted := policy.Redact(findings)
+
+		s.Equal(RedactedPlaceholder, redacted[0].Secret)
+		s.Equal("password = 'REDACTED'", redacted[0].Match)
+		s.Empty(redacted[0].SecretHash)
+	})
+
+	s.Run("partial mode keeps prefix and suffix of long secrets", func() {
+		findings := Findings{{Match: "AKIAIOSFODNN7EXAMPLEAKIAIOSFODNN7EXAMPLE", Secret: "AKIAIOSFODNN7EXAMPLEAKIAIOSFODNN7EXAMPLE"}}
+
+		redacted := RedactionPolicy{Mode: RedactionModePartial, KeepPrefix: 4, KeepSuffix: 4}.Redact(findings)
+
+		s.Equal("AKIA*****MPLE", redacted[0].Secret)
+	})
+
+	s.Run("partial mode masks short secrets completely", func() {
+		findings := Findings{{Match: "pin=1234567", Secret: "1234567"}}
+
+		redacted := RedactionPolicy{Mode: RedactionModePartial, KeepPrefix: 4, KeepSuffix: 4}.Redact(findings)
+
+		s.Equal(RedactedPlaceholder, redacted[0].Secret)
+		s.Equal("pin="+RedactedPlaceholder, redacted[0].Match)
+	})
+
+	s.Run("no hash is kept without a key", func() {
+		findings := Findings{{Match: "a=s3cr3t", Secret: "s3cr3t"}}
+
+		redacted := RedactionPolicy{Mode: RedactionModeMask}.Redact(findings)
+
+		s.Equal(RedactedPlaceholder, redacted[0].Secret)
+		s.Empty(redacted[0].SecretHash)
+	})
+
+	s.Run("match without the secret is replaced completely", func() {
+		findings := Findings{{Match: "something else", Secret: "s3cr3t-v4lue"}}
+
+		redacted := policy.Redact(findings)
+
+		s.Equal(RedactedPlaceholder, redacted[0].Match)
+	})
+
+	s.Run("same secret gives same hash", func() {
+		findings := Findings{{Match: "a=s3cr3t", Secret: "s3cr3t"}, {Match: "b=s3cr3t", Secret: "s3cr3t"}}
+
+		redacted := policy.Redact(findings)
+
+		s.Equal(redacted[0].SecretHash, redacted[1].SecretHash)
+		s.False(strings.Contains(redacted[0].SecretHash, "s3cr3t"))
+	})
+}
@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"time"
+)
+
+// RetryPolicy retries failed deliveries with exponential backoff, starting at BaseDelay and capped at MaxDelay
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// Backoff returns delay before the next attempt after given number of failed attempts
+func (p RetryPolicy) Backoff(failedAttempts int) time.Duration {
+
+	delay := p.BaseDelay
+	for i := 1; i < failedAttempts; i++ {
+		delay *= 2
+		if delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+
+	if delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+
+	return delay
+}
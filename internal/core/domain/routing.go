@@ -0,0 +1,169 @@
+package domain
+
+import (
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+type NotificationTargetType string
+
+const (
+	// NotificationTargetSlack posts to Slack channel given by its ID
+	NotificationTargetSlack NotificationTargetType = "slack"
+	// NotificationTargetTeams posts to Teams incoming webhook URL
+	NotificationTargetTeams NotificationTargetType = "teams"
+	// NotificationTargetEmail mails an address, usually a team mailing list
+	NotificationTargetEmail NotificationTargetType = "email"
+	// NotificationTargetWebhook posts the report as JSON to the URL
+	NotificationTargetWebhook NotificationTargetType = "webhook"
+)
+
+// NotificationTarget is a destination of routed notifications
+type NotificationTarget struct {
+	Type        NotificationTargetType `json:"type" validate:"required,oneof=slack teams email webhook"`
+	Destination string                 `json:"destination" validate:"required,max=2000"`
+	// Template renders messages of slack and teams targets, their default templates are used when it is empty
+	Template string `json:"template,omitempty" validate:"omitempty,max=100"`
+	// Mode tells when the target is notified, immediate when it is empty
+	Mode NotificationMode `json:"mode,omitempty" validate:"omitempty,oneof=immediate hourly daily suppressed"`
+}
+
+// Name identifies the target among notification channels, e.g. "slack:C0123456"
+func (nt NotificationTarget) Name() string {
+	return string(nt.Type) + ":" + nt.Destination
+}
+
+// RoutingRule sends findings of matching reports to its targets, every condition which is set must match.
+// Repository conditions select reports, RuleIDs and Tags select findings of the report.
+type RoutingRule struct {
+	ID          string `json:"id"`
+	Description string `json:"description,omitempty" validate:"omitempty,ascii,max=200"`
+	RepoIDs     []int  `json:"repoIds,omitempty" validate:"omitempty,dive,min=1"`
+	// RepoNameGlob matches repository name case insensitively, e.g. "payments-*"
+	RepoNameGlob string `json:"repoNameGlob,omitempty" validate:"omitempty,max=200"`
+	// GroupPrefix matches GitLab group path of the repository URL, e.g. "platform/payments" matches its subgroups too
+	GroupPrefix string               `json:"groupPrefix,omitempty" validate:"omitempty,max=500"`
+	RuleIDs     []string             `json:"ruleIds,omitempty" validate:"omitempty,dive,required,max=200"`
+	Tags        []string             `json:"tags,omitempty" validate:"omitempty,dive,required,max=200"`
+	Targets     []NotificationTarget `json:"targets" validate:"required,min=1,max=20,dive"`
+	CreatedBy   string               `json:"createdBy"`
+	CreatedAt   time.Time            `json:"createdAt"`
+}
+
+// matchesRepository tells whether the rule selects reports of the repository
+func (rr RoutingRule) matchesRepository(report FindingsReport) bool {
+
+	if len(rr.RepoIDs) > 0 && !containsInt(rr.RepoIDs, report.RepoID) {
+		return false
+	}
+
+	if rr.RepoNameGlob != "" {
+		matched, err := path.Match(strings.ToLower(rr.RepoNameGlob), strings.ToLower(report.RepoName))
+		if err != nil || !matched {
+			return false
+		}
+	}
+
+	if rr.GroupPrefix != "" {
+		prefix := strings.ToLower(strings.Trim(rr.GroupPrefix, "/"))
+		projectPath := strings.ToLower(ProjectPath(report.RepoURL))
+		if projectPath != prefix && !strings.HasPrefix(projectPath, prefix+"/") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchesFinding tells whether the rule selects the finding
+func (rr RoutingRule) matchesFinding(finding Finding) bool {
+
+	if len(rr.RuleIDs) > 0 && !containsString(rr.RuleIDs, finding.RuleID) {
+		return false
+	}
+
+	if len(rr.Tags) > 0 {
+		for _, tag := range finding.Tags {
+			if containsString(rr.Tags, tag) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+// ProjectPath returns path of the project in repository URL without leading slash and ".git" suffix,
+// e.g. "platform/payments/api" for "https://gitlab.com/platform/payments/api.git"
+func ProjectPath(repoURL string) string {
+
+	parsed, err := url.Parse(repoURL)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSuffix(strings.Trim(parsed.Path, "/"), ".git")
+}
+
+// RoutedReport is a report narrowed to findings routed to the target
+type RoutedReport struct {
+	Target NotificationTarget
+	Report FindingsReport
+}
+
+type RoutingRules []RoutingRule
+
+// Route returns report for every target of matching rules, findings routed to a target by several rules are merged,
+// so each target is notified once. Targets are returned in order of rules and findings keep order of the report.
+func (rules RoutingRules) Route(report FindingsReport) []RoutedReport {
+
+	var targets []NotificationTarget
+	selected := map[string][]bool{}
+
+	for _, rule := range rules {
+
+		if !rule.matchesRepository(report) {
+			continue
+		}
+
+		for _, target := range rule.Targets {
+
+			name := target.Name()
+			if _, ok := selected[name]; !ok {
+				targets = append(targets, target)
+				selected[name] = make([]bool, len(report.Findings))
+			}
+
+			for i, finding := range report.Findings {
+				if rule.matchesFinding(finding) {
+					selected[name][i] = true
+				}
+			}
+		}
+	}
+
+	var routed []RoutedReport
+
+	for _, target := range targets {
+
+		findings := Findings{}
+		for i, ok := range selected[target.Name()] {
+			if ok {
+				findings = append(findings, report.Findings[i])
+			}
+		}
+
+		if len(findings) == 0 {
+			continue
+		}
+
+		narrowed := report
+		narrowed.Findings = findings
+		routed = append(routed, RoutedReport{Target: target, Report: narrowed})
+	}
+
+	return routed
+}
@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type RoutingTestSuite struct {
+	suite.Suite
+	report FindingsReport
+	slack  NotificationTarget
+	email  NotificationTarget
+}
+
+func TestSuiteRouting(t *testing.T) {
+	suite.Run(t, new(RoutingTestSuite))
+}
+
+func (s *RoutingTestSuite) SetupTest() {
+
+	s.report = FindingsReport{
+		RepoID:   444,
+		RepoName: "Payments-API",
+		RepoURL:  "https://gitlab.com/platform/payments/api.git",
+		Findings: Findings{
+			{RuleID: "aws-access-token", Fingerprint: "f1", Tags: []string{"aws", "key"}},
+			{RuleID: "generic-api-key", Fingerprint: "f2", Tags: []string{"generic"}},
+		},
+	}
+	s.slack = NotificationTarget{Type: NotificationTargetSlack, Destination: "C0123"}
+	s.email = NotificationTarget{Type: NotificationTargetEmail, Destination: "payments@example.com"}
+}
+
+func (s *RoutingTestSuite) TestRoutingRules_RouteTableDriven() {
+
+	tests := []struct {
+		name  string
+		rules RoutingRules
+		want  map[string][]string
+	}{
+		{"rule without conditions routes everything", RoutingRules{{Targets: []NotificationTarget{s.slack}}}, map[string][]string{"slack:C0123": {"f1", "f2"}}},
+		{"repository id", RoutingRules{{RepoIDs: []int{444}, Targets: []NotificationTarget{s.slack}}}, map[string][]string{"slack:C0123": {"f1", "f2"}}},
+		{"other repository id", RoutingRules{{RepoIDs: []int{555}, Targets: []NotificationTarget{s.slack}}}, map[string][]string{}},
+		{"repository name glob ignores case", RoutingRules{{RepoNameGlob: "payments-*", Targets: []NotificationTarget{s.slack}}}, map[string][]string{"slack:C0123": {"f1", "f2"}}},
+		{"other repository name", RoutingRules{{RepoNameGlob: "billing-*", Targets: []NotificationTarget{s.slack}}}, map[string][]string{}},
+		{"group prefix", RoutingRules{{GroupPrefix: "platform", Targets: []NotificationTarget{s.slack}}}, map[string][]string{"slack:C0123": {"f1", "f2"}}},
+		{"subgroup prefix with slashes", RoutingRules{{GroupPrefix: "/platform/payments/", Targets: []NotificationTarget{s.slack}}}, map[string][]string{"slack:C0123": {"f1", "f2"}}},
+		{"prefix must end at group boundary", RoutingRules{{GroupPrefix: "platform/pay", Targets: []NotificationTarget{s.slack}}}, map[string][]string{}},
+		{"rule id narrows findings", RoutingRules{{RuleIDs: []string{"generic-api-key"}, Targets: []NotificationTarget{s.slack}}}, map[string][]string{"slack:C0123": {"f2"}}},
+		{"tag narrows findings", RoutingRules{{Tags: []string{"aws"}, Targets: []NotificationTarget{s.slack}}}, map[string][]string{"slack:C0123": {"f1"}}},
+		{"rule id and tag must both match", RoutingRules{{RuleIDs: []string{"generic-api-key"}, Tags: []string{"aws"}, Targets: []NotificationTarget{s.slack}}}, map[string][]string{}},
+		{
+			"rules sharing target are merged",
+			RoutingRules{
+				{Tags: []string{"generic"}, Targets: []NotificationTarget{s.slack}},
+				{RuleIDs: []string{"aws-access-token"}, Targets: []NotificationTarget{s.slack, s.email}},
+			},
+			map[string][]string{"slack:C0123": {"f1", "f2"}, "email:payments@example.com": {"f1"}},
+		},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// act
+			routed := tt.rules.Route(s.report)
+
+			// assert
+			got := map[string][]string{}
+			for _, r := range routed {
+				for _, finding := range r.Report.Findings {
+					got[r.Target.Name()] = append(got[r.Target.Name()], finding.Fingerprint)
+				}
+				assert.Equal(s.T(), s.report.RepoID, r.Report.RepoID)
+			}
+			assert.Equal(s.T(), tt.want, got)
+			assert.Len(s.T(), s.report.Findings, 2, "report must not be modified")
+		})
+	}
+}
+
+func (s *RoutingTestSuite) TestProjectPath() {
+
+	assert.Equal(s.T(), "platform/payments/api", ProjectPath("https://gitlab.com/platform/payments/api.git"))
+	assert.Equal(s.T(), "platform/payments/api", ProjectPath("https://gitlab.com/platform/payments/api/"))
+	assert.Equal(s.T(), "", ProjectPath("://broken"))
+}
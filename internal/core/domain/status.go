@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+type FindingStatus string
+
+const (
+	FindingStatusOpen          FindingStatus = "open"
+	FindingStatusAcknowledged  FindingStatus = "acknowledged"
+	FindingStatusFalsePositive FindingStatus = "false_positive"
+	FindingStatusRevoked       FindingStatus = "revoked"
+	FindingStatusResolved      FindingStatus = "resolved"
+)
+
+var findingStatuses = []FindingStatus{
+	FindingStatusOpen,
+	FindingStatusAcknowledged,
+	FindingStatusFalsePositive,
+	FindingStatusRevoked,
+	FindingStatusResolved,
+}
+
+// StatusChange is a single entry of finding status history
+type StatusChange struct {
+	Status FindingStatus `json:"status" validate:"required,oneof=open acknowledged false_positive revoked resolved"`
+	// ChangedBy is set by the server from the authenticated caller, like ChangedAt
+	ChangedBy string    `json:"changedBy"`
+	ChangedAt time.Time `json:"changedAt"`
+	Comment   string    `json:"comment,omitempty" validate:"omitempty,max=1000"`
+	// Assignee takes over the finding, assignee of the finding is kept when it is empty
+	Assignee string `json:"assignee,omitempty" validate:"omitempty,max=200"`
+}
+
+// ApplyStatusChange sets status of the finding and appends the change to its history
+func (f *Finding) ApplyStatusChange(change StatusChange) {
+
+	f.Status = change.Status
+	f.StatusHistory = append(f.StatusHistory, change)
+	if change.Assignee != "" {
+		f.Assignee = change.Assignee
+	}
+}
+
+func (fs FindingStatus) IsValid() bool {
+
+	for _, status := range findingStatuses {
+		if fs == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseFindingStatuses parses comma separated list of statuses, e.g. "open,acknowledged".
+// Second return value is the first invalid status, if any.
+func ParseFindingStatuses(value string) ([]FindingStatus, string) {
+
+	var statuses []FindingStatus
+
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		status := FindingStatus(strings.ToLower(part))
+		if !status.IsValid() {
+			return nil, part
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, ""
+}
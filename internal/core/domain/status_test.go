@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type FindingStatusTestSuite struct {
+	suite.Suite
+}
+
+func TestSuiteFindingStatus(t *testing.T) {
+	suite.Run(t, new(FindingStatusTestSuite))
+}
+
+func (s *FindingStatusTestSuite) TestParseFindingStatusesTableDriven() {
+
+	tests := []struct {
+		name        string
+		input       string
+		want        []FindingStatus
+		wantInvalid string
+	}{
+		{"empty value", "", nil, ""},
+		{"single status", "open", []FindingStatus{FindingStatusOpen}, ""},
+		{"multiple statuses with spaces and upper case", "Open, FALSE_POSITIVE", []FindingStatus{FindingStatusOpen, FindingStatusFalsePositive}, ""},
+		{"unknown status", "open,closed", nil, "closed"},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// act
+			statuses, invalid := ParseFindingStatuses(tt.input)
+
+			// assert
+			s.Equal(tt.want, statuses, tt.name)
+			s.Equal(tt.wantInvalid, invalid, tt.name)
+		})
+	}
+}
+
+func (s *FindingStatusTestSuite) TestFindings_FilterByStatus() {
+
+	findings := Findings{
+		{Fingerprint: "legacy"},
+		{Fingerprint: "acknowledged", Status: FindingStatusAcknowledged},
+		{Fingerprint: "resolved", Status: FindingStatusResolved},
+	}
+
+	s.Len(findings.FilterByStatus(), 3)
+	s.Len(findings.FilterByStatus(FindingStatusOpen), 1)
+	s.Equal("legacy", findings.FilterByStatus(FindingStatusOpen)[0].Fingerprint)
+	s.Len(findings.FilterByStatus(FindingStatusAcknowledged, FindingStatusResolved), 2)
+	s.Empty(findings.FilterByStatus(FindingStatusRevoked))
+}
+
+func (s *FindingStatusTestSuite) TestFindings_ExcludeStatus() {
+
+	findings := Findings{
+		{Fingerprint: "legacy"},
+		{Fingerprint: "false positive", Status: FindingStatusFalsePositive},
+		{Fingerprint: "resolved", Status: FindingStatusResolved},
+	}
+
+	s.Len(findings.ExcludeStatus(), 3)
+	s.Len(findings.ExcludeStatus(FindingStatusRevoked), 3)
+	s.Equal([]string{"legacy", "false positive"}, fingerprints(findings.ExcludeStatus(FindingStatusResolved)))
+	s.Equal([]string{"false positive"}, fingerprints(findings.ExcludeStatus(FindingStatusOpen, FindingStatusResolved)))
+}
+
+func fingerprints(findings Findings) []string {
+
+	result := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		result = append(result, finding.Fingerprint)
+	}
+
+	return result
+}
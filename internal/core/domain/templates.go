@@ -0,0 +1,222 @@
+package domain
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	// TemplateSlack renders messages of Slack channel and Slack targets without a template
+	TemplateSlack = "slack"
+	// TemplateTeams renders messages of Teams channel and Teams targets without a template
+	TemplateTeams = "teams"
+	// TemplateSlackDM renders direct messages to commit authors listing their findings
+	TemplateSlackDM = "slack-dm"
+)
+
+const defaultSlackTemplate = `*Found new hard coded secrets in {{.RepoName}} 😐*
+{{.CommitAuthor}}'s commit included secret(ish) information. Please check
+*Repository:* {{.RepoURL}}
+*Pipeline:* {{pipelineURL .}}
+*Commit:* {{commitURL .}}
+*How many findings found?* {{len .Findings}}
+*Date:* {{.Timestamp}}`
+
+const defaultSlackDMTemplate = `*Your commit to {{.RepoName}} included hard coded secrets 😐*
+Please remove them from the code and rotate them
+*Pipeline:* {{pipelineURL .}}
+{{- range .Findings}}
+• <{{findingURL $ .}}|{{.File}}:{{.StartLine}}> {{.RuleID}}
+{{- end}}`
+
+const defaultTeamsTemplate = `**Found new hard coded secrets in {{.RepoName}} 😐**
+
+{{.CommitAuthor}}'s commit included secret(ish) information. Please check
+
+- **Repository:** {{.RepoURL}}
+- **Pipeline:** {{pipelineURL .}}
+- **Commit:** {{commitURL .}}
+- **How many findings found?** {{len .Findings}}
+- **Date:** {{.Timestamp}}`
+
+var templateNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,99}$`)
+
+// templateFuncs are helpers available in every notification template
+var templateFuncs = template.FuncMap{
+	"pipelineURL": func(report FindingsReport) string { return report.BuildPipelineURL() },
+	"commitURL":   func(report FindingsReport) string { return report.BuildCommitURL() },
+	"findingURL":  func(report FindingsReport, finding Finding) string { return report.BuildFindingURL(finding) },
+	// redact keeps first 4 characters of the value, e.g. {{redact .Secret}} when gitleaks output is not redacted
+	"redact": func(value string) string {
+		runes := []rune(value)
+		if len(runes) <= 8 {
+			return RedactedPlaceholder
+		}
+		return string(runes[:4]) + partialMask
+	},
+	// plural picks the word by count, e.g. {{len .Findings}} {{plural (len .Findings) "secret" "secrets"}}
+	"plural": func(count int, singular, plural string) string {
+		if count == 1 {
+			return singular
+		}
+		return plural
+	},
+}
+
+// NotificationTemplates render messages of chat channels from reports, keyed by template name
+type NotificationTemplates struct {
+	templates map[string]*template.Template
+}
+
+// NewNotificationTemplates parses sources keyed by template name on top of the default "slack", "slack-dm" and "teams"
+// templates, every template is rendered with SampleFindingsReport so broken templates fail at startup instead of on
+// notification.
+func NewNotificationTemplates(sources map[string]string) (NotificationTemplates, error) {
+
+	all := map[string]string{
+		TemplateSlack:   defaultSlackTemplate,
+		TemplateTeams:   defaultTeamsTemplate,
+		TemplateSlackDM: defaultSlackDMTemplate,
+	}
+	for name, source := range sources {
+		all[name] = source
+	}
+
+	nt := NotificationTemplates{templates: map[string]*template.Template{}}
+
+	for name, source := range all {
+
+		if !templateNameRegex.MatchString(name) {
+			return NotificationTemplates{}, fmt.Errorf("invalid template name %q, use lowercase letters, digits, - and _", name)
+		}
+
+		parsed, err := parseTemplate(name, source)
+		if err != nil {
+			return NotificationTemplates{}, err
+		}
+
+		nt.templates[name] = parsed
+	}
+
+	return nt, nil
+}
+
+// Has tells whether template with the name exists
+func (nt NotificationTemplates) Has(name string) bool {
+
+	_, ok := nt.templates[name]
+	return ok
+}
+
+// Names returns sorted names of all templates
+func (nt NotificationTemplates) Names() []string {
+
+	names := make([]string, 0, len(nt.templates))
+	for name := range nt.templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Render renders the named template with the report
+func (nt NotificationTemplates) Render(name string, report FindingsReport) (string, error) {
+
+	parsed, ok := nt.templates[name]
+	if !ok {
+		return "", fmt.Errorf("unknown notification template %q", name)
+	}
+
+	return execute(parsed, report)
+}
+
+// Preview parses source like a template loaded at startup and renders it with the report
+func (nt NotificationTemplates) Preview(source string, report FindingsReport) (string, error) {
+
+	parsed, err := parseTemplate("preview", source)
+	if err != nil {
+		return "", err
+	}
+
+	return execute(parsed, report)
+}
+
+func parseTemplate(name, source string) (*template.Template, error) {
+
+	parsed, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(source)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = execute(parsed, SampleFindingsReport()); err != nil {
+		return nil, err
+	}
+
+	return parsed, nil
+}
+
+func execute(parsed *template.Template, report FindingsReport) (string, error) {
+
+	rendered := new(bytes.Buffer)
+	if err := parsed.Execute(rendered, report); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(rendered.String()), nil
+}
+
+// SampleFindingsReport is a report with every field set, templates are validated and previewed with it
+func SampleFindingsReport() FindingsReport {
+
+	date := time.Date(2022, 12, 3, 12, 48, 14, 0, time.UTC)
+
+	return FindingsReport{
+		ID:           "638b4a2e9f1c2d3e4f5a6b7c",
+		PipelineID:   2,
+		RepoName:     "payments-api",
+		RepoID:       444,
+		RepoURL:      "https://gitlab.com/platform/payments/payments-api",
+		CommitAuthor: "Jane Doe",
+		CommitSHA:    "a85af84d39a32da2c8eba1d88019079aeb0741b0",
+		Timestamp:    date,
+		Findings: Findings{
+			{
+				ID:          "638b4a2e9f1c2d3e4f5a6b7d",
+				Description: "AWS Access Key",
+				StartLine:   12,
+				EndLine:     12,
+				StartColumn: 9,
+				EndColumn:   29,
+				Match:       "aws_key = 'REDACTED'",
+				Secret:      RedactedPlaceholder,
+				File:        "src/config.go",
+				Commit:      "a85af84d39a32da2c8eba1d88019079aeb0741b0",
+				Entropy:     3.6,
+				Author:      "Jane Doe",
+				Email:       "jane.doe@example.com",
+				Date:        date,
+				Message:     "add payment provider client",
+				Tags:        []string{"key", "aws"},
+				RuleID:      "aws-access-token",
+				Fingerprint: "a85af84d39a32da2c8eba1d88019079aeb0741b0:src/config.go:aws-access-token:12",
+				Status:      FindingStatusOpen,
+				FirstSeen:   date,
+				LastSeen:    date,
+			},
+		},
+	}
+}
+
+// TemplatePreview asks to render the named template or the source, with the report or SampleFindingsReport
+type TemplatePreview struct {
+	Name   string `json:"name" validate:"required_without=Source,omitempty,max=100"`
+	Source string `json:"source" validate:"required_without=Name,omitempty,max=20000"`
+	// Report is rendered as it is, it is never stored or sent anywhere
+	Report *FindingsReport `json:"report,omitempty" validate:"-"`
+}
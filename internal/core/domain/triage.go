@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TriageAction is a button of a chat notification changing status of a finding
+type TriageAction string
+
+const (
+	TriageFalsePositive TriageAction = "triage_false_positive"
+	TriageRevoked       TriageAction = "triage_revoked"
+	// TriageAssign acknowledges the finding and assigns it to the user who clicked
+	TriageAssign TriageAction = "triage_assign"
+)
+
+// TriageActions lists actions in the order buttons are shown
+var TriageActions = []TriageAction{TriageFalsePositive, TriageRevoked, TriageAssign}
+
+// Label is text of the button
+func (ta TriageAction) Label() string {
+
+	switch ta {
+	case TriageFalsePositive:
+		return "Mark false positive"
+	case TriageRevoked:
+		return "Mark revoked"
+	case TriageAssign:
+		return "Assign to me"
+	}
+
+	return string(ta)
+}
+
+// TriageRequest asks to apply the action to the finding of the repository
+type TriageRequest struct {
+	Action      TriageAction
+	RepoID      int
+	Fingerprint string
+}
+
+// TriageValue identifies the finding in the button value, e.g. "444:<fingerprint>"
+func TriageValue(repoId int, fingerprint string) string {
+	return strconv.Itoa(repoId) + ":" + fingerprint
+}
+
+// ParseTriageRequest parses action of the button and its value made by TriageValue
+func ParseTriageRequest(action string, value string) (TriageRequest, error) {
+
+	ta := TriageAction(action)
+	if ta != TriageFalsePositive && ta != TriageRevoked && ta != TriageAssign {
+		return TriageRequest{}, fmt.Errorf("unknown triage action %q", action)
+	}
+
+	repoId, fingerprint, ok := strings.Cut(value, ":")
+	if !ok || fingerprint == "" {
+		return TriageRequest{}, fmt.Errorf("invalid triage value %q", value)
+	}
+
+	id, err := strconv.Atoi(repoId)
+	if err != nil || id < 1 {
+		return TriageRequest{}, fmt.Errorf("invalid repository id in triage value %q", value)
+	}
+
+	return TriageRequest{Action: ta, RepoID: id, Fingerprint: fingerprint}, nil
+}
+
+// StatusChange returns change made by the user, e.g. a Slack user name
+func (tr TriageRequest) StatusChange(user string) StatusChange {
+
+	change := StatusChange{ChangedBy: user}
+
+	switch tr.Action {
+	case TriageFalsePositive:
+		change.Status = FindingStatusFalsePositive
+	case TriageRevoked:
+		change.Status = FindingStatusRevoked
+	case TriageAssign:
+		change.Status = FindingStatusAcknowledged
+		change.Assignee = user
+	}
+
+	return change
+}
+
+// Outcome describes the applied action to readers of the message
+func (tr TriageRequest) Outcome(user string) string {
+
+	switch tr.Action {
+	case TriageFalsePositive:
+		return "marked false positive by " + user
+	case TriageRevoked:
+		return "marked revoked by " + user
+	case TriageAssign:
+		return "assigned to " + user
+	}
+
+	return string(tr.Action) + " by " + user
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type TriageTestSuite struct {
+	suite.Suite
+}
+
+func TestSuiteTriage(t *testing.T) {
+	suite.Run(t, new(TriageTestSuite))
+}
+
+func (s *TriageTestSuite) TestParseTriageRequestTableDriven() {
+
+	fingerprint := "a85af84d39a32da2c8eba1d88019079aeb0741b0:src/config.go:aws-access-token:12"
+
+	tests := []struct {
+		name    string
+		action  string
+		value   string
+		want    TriageRequest
+		wantErr bool
+	}{
+		{"fingerprint with colons", "triage_revoked", TriageValue(444, fingerprint), TriageRequest{Action: TriageRevoked, RepoID: 444, Fingerprint: fingerprint}, false},
+		{"assign", "triage_assign", "444:f1", TriageRequest{Action: TriageAssign, RepoID: 444, Fingerprint: "f1"}, false},
+		{"unknown action", "triage_delete", "444:f1", TriageRequest{}, true},
+		{"missing fingerprint", "triage_revoked", "444:", TriageRequest{}, true},
+		{"missing separator", "triage_revoked", "444", TriageRequest{}, true},
+		{"invalid repository", "triage_revoked", "payments:f1", TriageRequest{}, true},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// act
+			got, err := ParseTriageRequest(tt.action, tt.value)
+
+			// assert
+			if tt.wantErr {
+				assert.Error(s.T(), err)
+				return
+			}
+			assert.NoError(s.T(), err)
+			assert.Equal(s.T(), tt.want, got)
+		})
+	}
+}
+
+func (s *TriageTestSuite) TestTriageRequest_StatusChange() {
+
+	falsePositive := TriageRequest{Action: TriageFalsePositive}.StatusChange("jane")
+	assert.Equal(s.T(), StatusChange{Status: FindingStatusFalsePositive, ChangedBy: "jane"}, falsePositive)
+
+	assign := TriageRequest{Action: TriageAssign}.StatusChange("jane")
+	assert.Equal(s.T(), StatusChange{Status: FindingStatusAcknowledged, ChangedBy: "jane", Assignee: "jane"}, assign)
+}
@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Verdict tells pipeline what to do with the job after upload
+type Verdict string
+
+const (
+	VerdictPass Verdict = "pass"
+	VerdictWarn Verdict = "warn"
+	VerdictFail Verdict = "fail"
+)
+
+// severity orders verdicts, the most severe verdict of an upload wins
+var severity = map[Verdict]int{
+	VerdictPass: 0,
+	VerdictWarn: 1,
+	VerdictFail: 2,
+}
+
+func ParseVerdict(value string) (Verdict, error) {
+
+	verdict := Verdict(strings.ToLower(strings.TrimSpace(value)))
+	if _, ok := severity[verdict]; !ok {
+		return "", fmt.Errorf("unknown verdict %q", value)
+	}
+
+	return verdict, nil
+}
+
+// VerdictPolicy maps kinds of findings of an upload to verdicts
+type VerdictPolicy struct {
+	OnNew        Verdict
+	OnKnown      Verdict
+	OnSuppressed Verdict
+}
+
+func NewVerdictPolicy(onNew, onKnown, onSuppressed string) (VerdictPolicy, error) {
+
+	var policy VerdictPolicy
+	var err error
+
+	if policy.OnNew, err = ParseVerdict(onNew); err != nil {
+		return VerdictPolicy{}, err
+	}
+	if policy.OnKnown, err = ParseVerdict(onKnown); err != nil {
+		return VerdictPolicy{}, err
+	}
+	if policy.OnSuppressed, err = ParseVerdict(onSuppressed); err != nil {
+		return VerdictPolicy{}, err
+	}
+
+	return policy, nil
+}
+
+// VerdictPolicies holds default policy and policies of repositories overriding it
+type VerdictPolicies struct {
+	Default VerdictPolicy
+	Repos   map[int]VerdictPolicy
+}
+
+// For returns policy of the repository, or default policy if repository has none
+func (vp VerdictPolicies) For(repoId int) VerdictPolicy {
+
+	if policy, ok := vp.Repos[repoId]; ok {
+		return policy
+	}
+
+	return vp.Default
+}
+
+// UploadResult is the outcome of a findings report upload.
+// Known findings which were triaged to any status other than open are counted as suppressed.
+type UploadResult struct {
+	ReportID   string
+	New        Findings
+	Known      Findings
+	Suppressed Findings
+	Verdict    Verdict
+}
+
+func NewUploadResult(reportID string, upsert UpsertResult, policy VerdictPolicy) UploadResult {
+
+	result := UploadResult{
+		ReportID:   reportID,
+		New:        upsert.New,
+		Known:      Findings{},
+		Suppressed: Findings{},
+		Verdict:    VerdictPass,
+	}
+
+	if result.New == nil {
+		result.New = Findings{}
+	}
+
+	for _, finding := range upsert.Known {
+		if finding.Status == "" || finding.Status == FindingStatusOpen {
+			result.Known = append(result.Known, finding)
+		} else {
+			result.Suppressed = append(result.Suppressed, finding)
+		}
+	}
+
+	if len(result.New) > 0 {
+		result.Verdict = worst(result.Verdict, policy.OnNew)
+	}
+	if len(result.Known) > 0 {
+		result.Verdict = worst(result.Verdict, policy.OnKnown)
+	}
+	if len(result.Suppressed) > 0 {
+		result.Verdict = worst(result.Verdict, policy.OnSuppressed)
+	}
+
+	return result
+}
+
+// NewFingerprints returns fingerprints of findings seen for the first time
+func (ur UploadResult) NewFingerprints() []string {
+
+	fingerprints := []string{}
+	for _, finding := range ur.New {
+		fingerprints = append(fingerprints, finding.Fingerprint)
+	}
+
+	return fingerprints
+}
+
+func worst(a, b Verdict) Verdict {
+	if severity[b] > severity[a] {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type VerdictTestSuite struct {
+	suite.Suite
+	policy VerdictPolicy
+}
+
+func TestSuiteVerdict(t *testing.T) {
+	suite.Run(t, new(VerdictTestSuite))
+}
+
+func (s *VerdictTestSuite) SetupTest() {
+	s.policy = VerdictPolicy{OnNew: VerdictFail, OnKnown: VerdictWarn, OnSuppressed: VerdictPass}
+}
+
+func (s *VerdictTestSuite) TestNewVerdictPolicyTableDriven() {
+
+	tests := []struct {
+		name         string
+		onNew        string
+		onKnown      string
+		onSuppressed string
+		wantErr      bool
+	}{
+		{"valid verdicts should pass", "fail", "warn", "pass", false},
+		{"verdicts are case insensitive", "FAIL", "Warn", "pass", false},
+		{"unknown verdict should fail", "block", "warn", "pass", true},
+		{"empty verdict should fail", "fail", "", "pass", true},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// act
+			_, err := NewVerdictPolicy(tt.onNew, tt.onKnown, tt.onSuppressed)
+
+			// assert
+			s.Equal(tt.wantErr, err != nil, tt.name)
+		})
+	}
+}
+
+func (s *VerdictTestSuite) TestNewUploadResultTableDriven() {
+
+	open := Finding{Fingerprint: "open", Status: FindingStatusOpen}
+	legacy := Finding{Fingerprint: "legacy"}
+	acknowledged := Finding{Fingerprint: "acknowledged", Status: FindingStatusAcknowledged}
+
+	tests := []struct {
+		name           string
+		upsert         UpsertResult
+		policy         VerdictPolicy
+		wantKnown      int
+		wantSuppressed int
+		wantVerdict    Verdict
+	}{
+		{"nothing found passes", UpsertResult{}, s.policy, 0, 0, VerdictPass},
+		{"new finding takes the most severe verdict", UpsertResult{New: Findings{open}, Known: Findings{open}}, s.policy, 1, 0, VerdictFail},
+		{"findings without status are known", UpsertResult{Known: Findings{legacy}}, s.policy, 1, 0, VerdictWarn},
+		{"triaged findings are suppressed", UpsertResult{Known: Findings{acknowledged}}, s.policy, 0, 1, VerdictPass},
+		{
+			"suppressed findings may fail by policy",
+			UpsertResult{Known: Findings{acknowledged}},
+			VerdictPolicy{OnNew: VerdictFail, OnKnown: VerdictFail, OnSuppressed: VerdictWarn},
+			0, 1,
+			VerdictWarn,
+		},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// act
+			result := NewUploadResult("a1", tt.upsert, tt.policy)
+
+			// assert
+			s.Equal("a1", result.ReportID, tt.name)
+			s.Len(result.Known, tt.wantKnown, tt.name)
+			s.Len(result.Suppressed, tt.wantSuppressed, tt.name)
+			s.Equal(tt.wantVerdict, result.Verdict, tt.name)
+			s.NotNil(result.NewFingerprints(), tt.name)
+		})
+	}
+}
+
+func (s *VerdictTestSuite) TestVerdictPolicies_For() {
+
+	override := VerdictPolicy{OnNew: VerdictWarn, OnKnown: VerdictPass, OnSuppressed: VerdictPass}
+	policies := VerdictPolicies{Default: s.policy, Repos: map[int]VerdictPolicy{444: override}}
+
+	s.Equal(override, policies.For(444))
+	s.Equal(s.policy, policies.For(555))
+	s.Equal(s.policy, VerdictPolicies{Default: s.policy}.For(444))
+}
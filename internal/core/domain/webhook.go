@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type WebhookEvent string
+
+const (
+	// WebhookEventReportReceived is published for every uploaded report, payload carries the redacted report
+	WebhookEventReportReceived WebhookEvent = "report.received"
+	// WebhookEventFindingNew is published once per upload, payload carries repository with findings seen for the first time
+	WebhookEventFindingNew WebhookEvent = "finding.new"
+	// WebhookEventFindingStatusChanged is published on triage, payload carries repository with the changed finding
+	WebhookEventFindingStatusChanged WebhookEvent = "finding.status_changed"
+)
+
+// WebhookSecretPrefix makes webhook signing secrets recognizable
+const WebhookSecretPrefix = "whsec_"
+
+// WebhookSubscription is an endpoint receiving events, Secret signs the payloads and is returned only on creation
+type WebhookSubscription struct {
+	ID          string         `json:"id"`
+	URL         string         `json:"url" validate:"required,url,max=2000"`
+	Events      []WebhookEvent `json:"events" validate:"required,min=1,dive,oneof=report.received finding.new finding.status_changed"`
+	RepoIDs     []int          `json:"repoIds,omitempty" validate:"omitempty,dive,min=1"`
+	Description string         `json:"description,omitempty" validate:"omitempty,ascii,max=200"`
+	Secret      string         `json:"-"`
+	CreatedBy   string         `json:"createdBy"`
+	CreatedAt   time.Time      `json:"createdAt"`
+}
+
+// Matches tells whether the subscription receives event of the repository, subscriptions without repositories receive every repository
+func (ws WebhookSubscription) Matches(event WebhookEvent, repoId int) bool {
+
+	subscribed := false
+	for _, e := range ws.Events {
+		if e == event {
+			subscribed = true
+			break
+		}
+	}
+
+	return subscribed && (len(ws.RepoIDs) == 0 || containsInt(ws.RepoIDs, repoId))
+}
+
+// NewWebhookSecret returns random secret for signing payloads of a subscription
+func NewWebhookSecret() string {
+
+	b := make([]byte, 24)
+	_, _ = rand.Read(b)
+
+	return WebhookSecretPrefix + hex.EncodeToString(b)
+}
+
+// SignWebhookPayload returns "sha256=<hex>" HMAC-SHA256 of "<timestamp>.<body>" keyed by the secret.
+// Receivers recompute it over the raw body and the timestamp header, and reject old timestamps to prevent replays.
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = fmt.Fprintf(mac, "%d.", timestamp)
+	_, _ = mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookPayload is the body posted to subscribers, exactly one of Report and Repository is set
+type WebhookPayload struct {
+	ID         string          `json:"id"`
+	Event      WebhookEvent    `json:"event"`
+	RepoID     int             `json:"repoId"`
+	CreatedAt  time.Time       `json:"createdAt"`
+	Report     *FindingsReport `json:"report,omitempty"`
+	Repository *RepoFindings   `json:"repository,omitempty"`
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery is a payload queued for a subscription together with log of its delivery attempts
+type WebhookDelivery struct {
+	ID             string                `json:"id"`
+	SubscriptionID string                `json:"subscriptionId"`
+	Event          WebhookEvent          `json:"event"`
+	Payload        json.RawMessage       `json:"payload"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	AttemptCount   int                   `json:"attemptCount"`
+	Attempts       []WebhookAttempt      `json:"attempts,omitempty"`
+	NextAttemptAt  time.Time             `json:"nextAttemptAt"`
+	CreatedAt      time.Time             `json:"createdAt"`
+}
+
+// WebhookAttempt is a single delivery attempt, StatusCode is zero if the endpoint could not be reached
+type WebhookAttempt struct {
+	At         time.Time `json:"at"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"durationMs"`
+}
+
+// maxLoggedAttempts keeps delivery documents small, attempt count still counts every attempt
+const maxLoggedAttempts = 20
+
+// RecordAttempt logs the attempt and moves delivery to delivered, to failed once attempts are exhausted,
+// or schedules the next attempt.
+func (wd *WebhookDelivery) RecordAttempt(attempt WebhookAttempt, policy RetryPolicy) {
+
+	wd.AttemptCount++
+	wd.Attempts = append(wd.Attempts, attempt)
+	if len(wd.Attempts) > maxLoggedAttempts {
+		wd.Attempts = wd.Attempts[len(wd.Attempts)-maxLoggedAttempts:]
+	}
+
+	switch {
+	case attempt.Error == "":
+		wd.Status = WebhookDeliveryDelivered
+	case wd.AttemptCount >= policy.MaxAttempts:
+		wd.Status = WebhookDeliveryFailed
+	default:
+		wd.Status = WebhookDeliveryPending
+		wd.NextAttemptAt = attempt.At.Add(policy.Backoff(wd.AttemptCount))
+	}
+}
@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"strings"
+	"testing"
+	"time"
+)
+
+type WebhookTestSuite struct {
+	suite.Suite
+	policy RetryPolicy
+}
+
+func TestSuiteWebhook(t *testing.T) {
+	suite.Run(t, new(WebhookTestSuite))
+}
+
+func (s *WebhookTestSuite) SetupTest() {
+	s.policy = RetryPolicy{MaxAttempts: 3, BaseDelay: 10 * time.Second, MaxDelay: 25 * time.Second}
+}
+
+func (s *WebhookTestSuite) TestWebhookSubscription_MatchesTableDriven() {
+
+	tests := []struct {
+		name         string
+		subscription WebhookSubscription
+		event        WebhookEvent
+		repoId       int
+		want         bool
+	}{
+		{"subscribed event of any repository", WebhookSubscription{Events: []WebhookEvent{WebhookEventFindingNew}}, WebhookEventFindingNew, 444, true},
+		{"other event", WebhookSubscription{Events: []WebhookEvent{WebhookEventFindingNew}}, WebhookEventReportReceived, 444, false},
+		{"subscribed repository", WebhookSubscription{Events: []WebhookEvent{WebhookEventFindingNew}, RepoIDs: []int{444}}, WebhookEventFindingNew, 444, true},
+		{"other repository", WebhookSubscription{Events: []WebhookEvent{WebhookEventFindingNew}, RepoIDs: []int{555}}, WebhookEventFindingNew, 444, false},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// act
+			got := tt.subscription.Matches(tt.event, tt.repoId)
+
+			// assert
+			assert.Equal(s.T(), tt.want, got)
+		})
+	}
+}
+
+func (s *WebhookTestSuite) TestSignWebhookPayload() {
+
+	// arrange
+	body := []byte(`{"event":"finding.new"}`)
+	mac := hmac.New(sha256.New, []byte("whsec_test"))
+	mac.Write([]byte(`1670071694.{"event":"finding.new"}`))
+
+	// act
+	signature := SignWebhookPayload("whsec_test", 1670071694, body)
+
+	// assert
+	assert.Equal(s.T(), "sha256="+hex.EncodeToString(mac.Sum(nil)), signature)
+	assert.NotEqual(s.T(), signature, SignWebhookPayload("whsec_test", 1670071695, body), "timestamp is signed")
+	assert.NotEqual(s.T(), signature, SignWebhookPayload("whsec_other", 1670071694, body), "secret is the key")
+}
+
+func (s *WebhookTestSuite) TestNewWebhookSecret() {
+
+	// act
+	secret := NewWebhookSecret()
+
+	// assert
+	assert.True(s.T(), strings.HasPrefix(secret, WebhookSecretPrefix))
+	assert.Len(s.T(), secret, len(WebhookSecretPrefix)+48)
+	assert.NotEqual(s.T(), secret, NewWebhookSecret())
+}
+
+func (s *WebhookTestSuite) TestRetryPolicy_BackoffTableDriven() {
+
+	tests := []struct {
+		failedAttempts int
+		want           time.Duration
+	}{
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{3, 25 * time.Second},
+		{30, 25 * time.Second},
+	}
+
+	for _, tt := range tests {
+
+		// act
+		got := s.policy.Backoff(tt.failedAttempts)
+
+		// assert
+		assert.Equal(s.T(), tt.want, got, "after %d failed attempts", tt.failedAttempts)
+	}
+}
+
+func (s *WebhookTestSuite) TestWebhookDelivery_RecordAttempt() {
+
+	// arrange
+	at := time.Date(2022, 12, 3, 12, 48, 14, 0, time.UTC)
+	delivery := WebhookDelivery{Status: WebhookDeliveryPending, NextAttemptAt: at}
+
+	// act & assert
+	delivery.RecordAttempt(WebhookAttempt{At: at, StatusCode: 500, Error: "status 500"}, s.policy)
+	assert.Equal(s.T(), WebhookDeliveryPending, delivery.Status)
+	assert.Equal(s.T(), at.Add(10*time.Second), delivery.NextAttemptAt)
+
+	delivery.RecordAttempt(WebhookAttempt{At: at.Add(10 * time.Second), Error: "connection refused"}, s.policy)
+	assert.Equal(s.T(), WebhookDeliveryPending, delivery.Status)
+	assert.Equal(s.T(), at.Add(30*time.Second), delivery.NextAttemptAt)
+
+	delivery.RecordAttempt(WebhookAttempt{At: at.Add(30 * time.Second), StatusCode: 502, Error: "status 502"}, s.policy)
+	assert.Equal(s.T(), WebhookDeliveryFailed, delivery.Status, "attempts are exhausted")
+	assert.Equal(s.T(), 3, delivery.AttemptCount)
+	assert.Len(s.T(), delivery.Attempts, 3)
+
+	delivered := WebhookDelivery{Status: WebhookDeliveryPending}
+	delivered.RecordAttempt(WebhookAttempt{At: at, StatusCode: 204}, s.policy)
+	assert.Equal(s.T(), WebhookDeliveryDelivered, delivered.Status)
+}
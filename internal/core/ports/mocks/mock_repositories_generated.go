@@ -1,12 +1,14 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: secrets-operator/internal/core/ports (interfaces: FindingsRepository,Notifier)
+// Source: secrets-operator/internal/core/ports (interfaces: FindingsRepository,APIKeyRepository,WebhookRepository,OutboxRepository,RoutingRuleRepository,GitleaksConfigRepository,DigestRepository,AnnouncementRepository,TokenVerifier,Notifier,ChannelNotifier,DigestNotifier,WebhookSender)
 
 // Package mocks is a generated GoMock package.
 package mocks
 
 import (
+	context "context"
 	reflect "reflect"
 	domain "secrets-operator/internal/core/domain"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -65,17 +67,18 @@ func (mr *MockFindingsRepositoryMockRecorder) GetRepositoriesByName(arg0, arg1 i
 }
 
 // SaveAndUpdateRepoFindingsById mocks base method.
-func (m *MockFindingsRepository) SaveAndUpdateRepoFindingsById(arg0 domain.RepoFindings, arg1 int, arg2 string) error {
+func (m *MockFindingsRepository) SaveAndUpdateRepoFindingsById(arg0 domain.FindingsReport, arg1 domain.FindingIdentity, arg2 int, arg3 bool, arg4 string) (domain.UpsertResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveAndUpdateRepoFindingsById", arg0, arg1, arg2)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "SaveAndUpdateRepoFindingsById", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(domain.UpsertResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // SaveAndUpdateRepoFindingsById indicates an expected call of SaveAndUpdateRepoFindingsById.
-func (mr *MockFindingsRepositoryMockRecorder) SaveAndUpdateRepoFindingsById(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockFindingsRepositoryMockRecorder) SaveAndUpdateRepoFindingsById(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAndUpdateRepoFindingsById", reflect.TypeOf((*MockFindingsRepository)(nil).SaveAndUpdateRepoFindingsById), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAndUpdateRepoFindingsById", reflect.TypeOf((*MockFindingsRepository)(nil).SaveAndUpdateRepoFindingsById), arg0, arg1, arg2, arg3, arg4)
 }
 
 // SaveFindingsReport mocks base method.
@@ -92,6 +95,626 @@ func (mr *MockFindingsRepositoryMockRecorder) SaveFindingsReport(arg0, arg1 inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveFindingsReport", reflect.TypeOf((*MockFindingsRepository)(nil).SaveFindingsReport), arg0, arg1)
 }
 
+// UpdateFindingStatus mocks base method.
+func (m *MockFindingsRepository) UpdateFindingStatus(arg0 int, arg1 string, arg2 domain.StatusChange, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFindingStatus", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateFindingStatus indicates an expected call of UpdateFindingStatus.
+func (mr *MockFindingsRepositoryMockRecorder) UpdateFindingStatus(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFindingStatus", reflect.TypeOf((*MockFindingsRepository)(nil).UpdateFindingStatus), arg0, arg1, arg2, arg3)
+}
+
+// MockAPIKeyRepository is a mock of APIKeyRepository interface.
+type MockAPIKeyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAPIKeyRepositoryMockRecorder
+}
+
+// MockAPIKeyRepositoryMockRecorder is the mock recorder for MockAPIKeyRepository.
+type MockAPIKeyRepositoryMockRecorder struct {
+	mock *MockAPIKeyRepository
+}
+
+// NewMockAPIKeyRepository creates a new mock instance.
+func NewMockAPIKeyRepository(ctrl *gomock.Controller) *MockAPIKeyRepository {
+	mock := &MockAPIKeyRepository{ctrl: ctrl}
+	mock.recorder = &MockAPIKeyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAPIKeyRepository) EXPECT() *MockAPIKeyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetAPIKeyByHash mocks base method.
+func (m *MockAPIKeyRepository) GetAPIKeyByHash(arg0, arg1 string) (domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeyByHash", arg0, arg1)
+	ret0, _ := ret[0].(domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeyByHash indicates an expected call of GetAPIKeyByHash.
+func (mr *MockAPIKeyRepositoryMockRecorder) GetAPIKeyByHash(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeyByHash", reflect.TypeOf((*MockAPIKeyRepository)(nil).GetAPIKeyByHash), arg0, arg1)
+}
+
+// GetAPIKeys mocks base method.
+func (m *MockAPIKeyRepository) GetAPIKeys(arg0 string) ([]domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeys", arg0)
+	ret0, _ := ret[0].([]domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeys indicates an expected call of GetAPIKeys.
+func (mr *MockAPIKeyRepositoryMockRecorder) GetAPIKeys(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeys", reflect.TypeOf((*MockAPIKeyRepository)(nil).GetAPIKeys), arg0)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockAPIKeyRepository) RevokeAPIKey(arg0 string, arg1 time.Time, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockAPIKeyRepositoryMockRecorder) RevokeAPIKey(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockAPIKeyRepository)(nil).RevokeAPIKey), arg0, arg1, arg2)
+}
+
+// SaveAPIKey mocks base method.
+func (m *MockAPIKeyRepository) SaveAPIKey(arg0 domain.APIKey, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAPIKey indicates an expected call of SaveAPIKey.
+func (mr *MockAPIKeyRepositoryMockRecorder) SaveAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAPIKey", reflect.TypeOf((*MockAPIKeyRepository)(nil).SaveAPIKey), arg0, arg1)
+}
+
+// TouchAPIKey mocks base method.
+func (m *MockAPIKeyRepository) TouchAPIKey(arg0 string, arg1 time.Time, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchAPIKey", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchAPIKey indicates an expected call of TouchAPIKey.
+func (mr *MockAPIKeyRepositoryMockRecorder) TouchAPIKey(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchAPIKey", reflect.TypeOf((*MockAPIKeyRepository)(nil).TouchAPIKey), arg0, arg1, arg2)
+}
+
+// MockWebhookRepository is a mock of WebhookRepository interface.
+type MockWebhookRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookRepositoryMockRecorder
+}
+
+// MockWebhookRepositoryMockRecorder is the mock recorder for MockWebhookRepository.
+type MockWebhookRepositoryMockRecorder struct {
+	mock *MockWebhookRepository
+}
+
+// NewMockWebhookRepository creates a new mock instance.
+func NewMockWebhookRepository(ctrl *gomock.Controller) *MockWebhookRepository {
+	mock := &MockWebhookRepository{ctrl: ctrl}
+	mock.recorder = &MockWebhookRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookRepository) EXPECT() *MockWebhookRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteWebhookSubscription mocks base method.
+func (m *MockWebhookRepository) DeleteWebhookSubscription(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhookSubscription", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhookSubscription indicates an expected call of DeleteWebhookSubscription.
+func (mr *MockWebhookRepositoryMockRecorder) DeleteWebhookSubscription(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhookSubscription", reflect.TypeOf((*MockWebhookRepository)(nil).DeleteWebhookSubscription), arg0, arg1)
+}
+
+// GetDueWebhookDeliveries mocks base method.
+func (m *MockWebhookRepository) GetDueWebhookDeliveries(arg0 time.Time, arg1 int, arg2 string) ([]domain.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueWebhookDeliveries", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]domain.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueWebhookDeliveries indicates an expected call of GetDueWebhookDeliveries.
+func (mr *MockWebhookRepositoryMockRecorder) GetDueWebhookDeliveries(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueWebhookDeliveries", reflect.TypeOf((*MockWebhookRepository)(nil).GetDueWebhookDeliveries), arg0, arg1, arg2)
+}
+
+// GetWebhookDeliveries mocks base method.
+func (m *MockWebhookRepository) GetWebhookDeliveries(arg0 string, arg1 int, arg2 string) ([]domain.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDeliveries", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]domain.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDeliveries indicates an expected call of GetWebhookDeliveries.
+func (mr *MockWebhookRepositoryMockRecorder) GetWebhookDeliveries(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDeliveries", reflect.TypeOf((*MockWebhookRepository)(nil).GetWebhookDeliveries), arg0, arg1, arg2)
+}
+
+// GetWebhookSubscriptions mocks base method.
+func (m *MockWebhookRepository) GetWebhookSubscriptions(arg0 string) ([]domain.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookSubscriptions", arg0)
+	ret0, _ := ret[0].([]domain.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookSubscriptions indicates an expected call of GetWebhookSubscriptions.
+func (mr *MockWebhookRepositoryMockRecorder) GetWebhookSubscriptions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookSubscriptions", reflect.TypeOf((*MockWebhookRepository)(nil).GetWebhookSubscriptions), arg0)
+}
+
+// SaveWebhookDelivery mocks base method.
+func (m *MockWebhookRepository) SaveWebhookDelivery(arg0 domain.WebhookDelivery, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveWebhookDelivery", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveWebhookDelivery indicates an expected call of SaveWebhookDelivery.
+func (mr *MockWebhookRepositoryMockRecorder) SaveWebhookDelivery(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWebhookDelivery", reflect.TypeOf((*MockWebhookRepository)(nil).SaveWebhookDelivery), arg0, arg1)
+}
+
+// SaveWebhookSubscription mocks base method.
+func (m *MockWebhookRepository) SaveWebhookSubscription(arg0 domain.WebhookSubscription, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveWebhookSubscription", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveWebhookSubscription indicates an expected call of SaveWebhookSubscription.
+func (mr *MockWebhookRepositoryMockRecorder) SaveWebhookSubscription(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWebhookSubscription", reflect.TypeOf((*MockWebhookRepository)(nil).SaveWebhookSubscription), arg0, arg1)
+}
+
+// MockOutboxRepository is a mock of OutboxRepository interface.
+type MockOutboxRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxRepositoryMockRecorder
+}
+
+// MockOutboxRepositoryMockRecorder is the mock recorder for MockOutboxRepository.
+type MockOutboxRepositoryMockRecorder struct {
+	mock *MockOutboxRepository
+}
+
+// NewMockOutboxRepository creates a new mock instance.
+func NewMockOutboxRepository(ctrl *gomock.Controller) *MockOutboxRepository {
+	mock := &MockOutboxRepository{ctrl: ctrl}
+	mock.recorder = &MockOutboxRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxRepository) EXPECT() *MockOutboxRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetDueOutboxMessages mocks base method.
+func (m *MockOutboxRepository) GetDueOutboxMessages(arg0 time.Time, arg1 int, arg2 string) ([]domain.OutboxMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueOutboxMessages", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]domain.OutboxMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueOutboxMessages indicates an expected call of GetDueOutboxMessages.
+func (mr *MockOutboxRepositoryMockRecorder) GetDueOutboxMessages(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueOutboxMessages", reflect.TypeOf((*MockOutboxRepository)(nil).GetDueOutboxMessages), arg0, arg1, arg2)
+}
+
+// GetOutboxMessageById mocks base method.
+func (m *MockOutboxRepository) GetOutboxMessageById(arg0, arg1 string) (domain.OutboxMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOutboxMessageById", arg0, arg1)
+	ret0, _ := ret[0].(domain.OutboxMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOutboxMessageById indicates an expected call of GetOutboxMessageById.
+func (mr *MockOutboxRepositoryMockRecorder) GetOutboxMessageById(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOutboxMessageById", reflect.TypeOf((*MockOutboxRepository)(nil).GetOutboxMessageById), arg0, arg1)
+}
+
+// GetOutboxMessages mocks base method.
+func (m *MockOutboxRepository) GetOutboxMessages(arg0 domain.OutboxStatus, arg1 int, arg2 string) ([]domain.OutboxMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOutboxMessages", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]domain.OutboxMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOutboxMessages indicates an expected call of GetOutboxMessages.
+func (mr *MockOutboxRepositoryMockRecorder) GetOutboxMessages(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOutboxMessages", reflect.TypeOf((*MockOutboxRepository)(nil).GetOutboxMessages), arg0, arg1, arg2)
+}
+
+// SaveOutboxMessage mocks base method.
+func (m *MockOutboxRepository) SaveOutboxMessage(arg0 domain.OutboxMessage, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveOutboxMessage", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveOutboxMessage indicates an expected call of SaveOutboxMessage.
+func (mr *MockOutboxRepositoryMockRecorder) SaveOutboxMessage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOutboxMessage", reflect.TypeOf((*MockOutboxRepository)(nil).SaveOutboxMessage), arg0, arg1)
+}
+
+// MockRoutingRuleRepository is a mock of RoutingRuleRepository interface.
+type MockRoutingRuleRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRoutingRuleRepositoryMockRecorder
+}
+
+// MockRoutingRuleRepositoryMockRecorder is the mock recorder for MockRoutingRuleRepository.
+type MockRoutingRuleRepositoryMockRecorder struct {
+	mock *MockRoutingRuleRepository
+}
+
+// NewMockRoutingRuleRepository creates a new mock instance.
+func NewMockRoutingRuleRepository(ctrl *gomock.Controller) *MockRoutingRuleRepository {
+	mock := &MockRoutingRuleRepository{ctrl: ctrl}
+	mock.recorder = &MockRoutingRuleRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRoutingRuleRepository) EXPECT() *MockRoutingRuleRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteRoutingRule mocks base method.
+func (m *MockRoutingRuleRepository) DeleteRoutingRule(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRoutingRule", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRoutingRule indicates an expected call of DeleteRoutingRule.
+func (mr *MockRoutingRuleRepositoryMockRecorder) DeleteRoutingRule(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRoutingRule", reflect.TypeOf((*MockRoutingRuleRepository)(nil).DeleteRoutingRule), arg0, arg1)
+}
+
+// GetRoutingRuleById mocks base method.
+func (m *MockRoutingRuleRepository) GetRoutingRuleById(arg0, arg1 string) (domain.RoutingRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoutingRuleById", arg0, arg1)
+	ret0, _ := ret[0].(domain.RoutingRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoutingRuleById indicates an expected call of GetRoutingRuleById.
+func (mr *MockRoutingRuleRepositoryMockRecorder) GetRoutingRuleById(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoutingRuleById", reflect.TypeOf((*MockRoutingRuleRepository)(nil).GetRoutingRuleById), arg0, arg1)
+}
+
+// GetRoutingRules mocks base method.
+func (m *MockRoutingRuleRepository) GetRoutingRules(arg0 string) ([]domain.RoutingRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoutingRules", arg0)
+	ret0, _ := ret[0].([]domain.RoutingRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoutingRules indicates an expected call of GetRoutingRules.
+func (mr *MockRoutingRuleRepositoryMockRecorder) GetRoutingRules(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoutingRules", reflect.TypeOf((*MockRoutingRuleRepository)(nil).GetRoutingRules), arg0)
+}
+
+// SaveRoutingRule mocks base method.
+func (m *MockRoutingRuleRepository) SaveRoutingRule(arg0 domain.RoutingRule, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveRoutingRule", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveRoutingRule indicates an expected call of SaveRoutingRule.
+func (mr *MockRoutingRuleRepositoryMockRecorder) SaveRoutingRule(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRoutingRule", reflect.TypeOf((*MockRoutingRuleRepository)(nil).SaveRoutingRule), arg0, arg1)
+}
+
+// MockGitleaksConfigRepository is a mock of GitleaksConfigRepository interface.
+type MockGitleaksConfigRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockGitleaksConfigRepositoryMockRecorder
+}
+
+// MockGitleaksConfigRepositoryMockRecorder is the mock recorder for MockGitleaksConfigRepository.
+type MockGitleaksConfigRepositoryMockRecorder struct {
+	mock *MockGitleaksConfigRepository
+}
+
+// NewMockGitleaksConfigRepository creates a new mock instance.
+func NewMockGitleaksConfigRepository(ctrl *gomock.Controller) *MockGitleaksConfigRepository {
+	mock := &MockGitleaksConfigRepository{ctrl: ctrl}
+	mock.recorder = &MockGitleaksConfigRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGitleaksConfigRepository) EXPECT() *MockGitleaksConfigRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetGitleaksConfigVersion mocks base method.
+func (m *MockGitleaksConfigRepository) GetGitleaksConfigVersion(arg0 int, arg1 string) (domain.GitleaksConfigVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGitleaksConfigVersion", arg0, arg1)
+	ret0, _ := ret[0].(domain.GitleaksConfigVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGitleaksConfigVersion indicates an expected call of GetGitleaksConfigVersion.
+func (mr *MockGitleaksConfigRepositoryMockRecorder) GetGitleaksConfigVersion(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGitleaksConfigVersion", reflect.TypeOf((*MockGitleaksConfigRepository)(nil).GetGitleaksConfigVersion), arg0, arg1)
+}
+
+// GetGitleaksConfigVersions mocks base method.
+func (m *MockGitleaksConfigRepository) GetGitleaksConfigVersions(arg0 string) ([]domain.GitleaksConfigVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGitleaksConfigVersions", arg0)
+	ret0, _ := ret[0].([]domain.GitleaksConfigVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGitleaksConfigVersions indicates an expected call of GetGitleaksConfigVersions.
+func (mr *MockGitleaksConfigRepositoryMockRecorder) GetGitleaksConfigVersions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGitleaksConfigVersions", reflect.TypeOf((*MockGitleaksConfigRepository)(nil).GetGitleaksConfigVersions), arg0)
+}
+
+// GetLatestGitleaksConfigVersion mocks base method.
+func (m *MockGitleaksConfigRepository) GetLatestGitleaksConfigVersion(arg0 string) (domain.GitleaksConfigVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestGitleaksConfigVersion", arg0)
+	ret0, _ := ret[0].(domain.GitleaksConfigVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestGitleaksConfigVersion indicates an expected call of GetLatestGitleaksConfigVersion.
+func (mr *MockGitleaksConfigRepositoryMockRecorder) GetLatestGitleaksConfigVersion(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestGitleaksConfigVersion", reflect.TypeOf((*MockGitleaksConfigRepository)(nil).GetLatestGitleaksConfigVersion), arg0)
+}
+
+// SaveGitleaksConfigVersion mocks base method.
+func (m *MockGitleaksConfigRepository) SaveGitleaksConfigVersion(arg0 domain.GitleaksConfigVersion, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveGitleaksConfigVersion", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveGitleaksConfigVersion indicates an expected call of SaveGitleaksConfigVersion.
+func (mr *MockGitleaksConfigRepositoryMockRecorder) SaveGitleaksConfigVersion(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveGitleaksConfigVersion", reflect.TypeOf((*MockGitleaksConfigRepository)(nil).SaveGitleaksConfigVersion), arg0, arg1)
+}
+
+// MockDigestRepository is a mock of DigestRepository interface.
+type MockDigestRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockDigestRepositoryMockRecorder
+}
+
+// MockDigestRepositoryMockRecorder is the mock recorder for MockDigestRepository.
+type MockDigestRepositoryMockRecorder struct {
+	mock *MockDigestRepository
+}
+
+// NewMockDigestRepository creates a new mock instance.
+func NewMockDigestRepository(ctrl *gomock.Controller) *MockDigestRepository {
+	mock := &MockDigestRepository{ctrl: ctrl}
+	mock.recorder = &MockDigestRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDigestRepository) EXPECT() *MockDigestRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteDigestEntries mocks base method.
+func (m *MockDigestRepository) DeleteDigestEntries(arg0 []string, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDigestEntries", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDigestEntries indicates an expected call of DeleteDigestEntries.
+func (mr *MockDigestRepositoryMockRecorder) DeleteDigestEntries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDigestEntries", reflect.TypeOf((*MockDigestRepository)(nil).DeleteDigestEntries), arg0, arg1)
+}
+
+// GetDueDigestEntries mocks base method.
+func (m *MockDigestRepository) GetDueDigestEntries(arg0 time.Time, arg1 int, arg2 string) ([]domain.DigestEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueDigestEntries", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]domain.DigestEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueDigestEntries indicates an expected call of GetDueDigestEntries.
+func (mr *MockDigestRepositoryMockRecorder) GetDueDigestEntries(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueDigestEntries", reflect.TypeOf((*MockDigestRepository)(nil).GetDueDigestEntries), arg0, arg1, arg2)
+}
+
+// SaveDigestEntry mocks base method.
+func (m *MockDigestRepository) SaveDigestEntry(arg0 domain.DigestEntry, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveDigestEntry", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveDigestEntry indicates an expected call of SaveDigestEntry.
+func (mr *MockDigestRepositoryMockRecorder) SaveDigestEntry(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveDigestEntry", reflect.TypeOf((*MockDigestRepository)(nil).SaveDigestEntry), arg0, arg1)
+}
+
+// MockAnnouncementRepository is a mock of AnnouncementRepository interface.
+type MockAnnouncementRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAnnouncementRepositoryMockRecorder
+}
+
+// MockAnnouncementRepositoryMockRecorder is the mock recorder for MockAnnouncementRepository.
+type MockAnnouncementRepositoryMockRecorder struct {
+	mock *MockAnnouncementRepository
+}
+
+// NewMockAnnouncementRepository creates a new mock instance.
+func NewMockAnnouncementRepository(ctrl *gomock.Controller) *MockAnnouncementRepository {
+	mock := &MockAnnouncementRepository{ctrl: ctrl}
+	mock.recorder = &MockAnnouncementRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAnnouncementRepository) EXPECT() *MockAnnouncementRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetAnnouncedFingerprints mocks base method.
+func (m *MockAnnouncementRepository) GetAnnouncedFingerprints(arg0 string, arg1 int, arg2 []string, arg3 string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAnnouncedFingerprints", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAnnouncedFingerprints indicates an expected call of GetAnnouncedFingerprints.
+func (mr *MockAnnouncementRepositoryMockRecorder) GetAnnouncedFingerprints(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAnnouncedFingerprints", reflect.TypeOf((*MockAnnouncementRepository)(nil).GetAnnouncedFingerprints), arg0, arg1, arg2, arg3)
+}
+
+// SaveAnnouncements mocks base method.
+func (m *MockAnnouncementRepository) SaveAnnouncements(arg0 string, arg1 int, arg2 []string, arg3 time.Time, arg4 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAnnouncements", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAnnouncements indicates an expected call of SaveAnnouncements.
+func (mr *MockAnnouncementRepositoryMockRecorder) SaveAnnouncements(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAnnouncements", reflect.TypeOf((*MockAnnouncementRepository)(nil).SaveAnnouncements), arg0, arg1, arg2, arg3, arg4)
+}
+
+// MockTokenVerifier is a mock of TokenVerifier interface.
+type MockTokenVerifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenVerifierMockRecorder
+}
+
+// MockTokenVerifierMockRecorder is the mock recorder for MockTokenVerifier.
+type MockTokenVerifierMockRecorder struct {
+	mock *MockTokenVerifier
+}
+
+// NewMockTokenVerifier creates a new mock instance.
+func NewMockTokenVerifier(ctrl *gomock.Controller) *MockTokenVerifier {
+	mock := &MockTokenVerifier{ctrl: ctrl}
+	mock.recorder = &MockTokenVerifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenVerifier) EXPECT() *MockTokenVerifierMockRecorder {
+	return m.recorder
+}
+
+// Verify mocks base method.
+func (m *MockTokenVerifier) Verify(arg0 string) (domain.Claims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", arg0)
+	ret0, _ := ret[0].(domain.Claims)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockTokenVerifierMockRecorder) Verify(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockTokenVerifier)(nil).Verify), arg0)
+}
+
 // MockNotifier is a mock of Notifier interface.
 type MockNotifier struct {
 	ctrl     *gomock.Controller
@@ -116,15 +739,141 @@ func (m *MockNotifier) EXPECT() *MockNotifierMockRecorder {
 }
 
 // SendMessage mocks base method.
-func (m *MockNotifier) SendMessage(arg0 domain.FindingsReport) error {
+func (m *MockNotifier) SendMessage(arg0 context.Context, arg1 domain.FindingsReport) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendMessage", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMessage indicates an expected call of SendMessage.
+func (mr *MockNotifierMockRecorder) SendMessage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessage", reflect.TypeOf((*MockNotifier)(nil).SendMessage), arg0, arg1)
+}
+
+// MockChannelNotifier is a mock of ChannelNotifier interface.
+type MockChannelNotifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockChannelNotifierMockRecorder
+}
+
+// MockChannelNotifierMockRecorder is the mock recorder for MockChannelNotifier.
+type MockChannelNotifierMockRecorder struct {
+	mock *MockChannelNotifier
+}
+
+// NewMockChannelNotifier creates a new mock instance.
+func NewMockChannelNotifier(ctrl *gomock.Controller) *MockChannelNotifier {
+	mock := &MockChannelNotifier{ctrl: ctrl}
+	mock.recorder = &MockChannelNotifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockChannelNotifier) EXPECT() *MockChannelNotifierMockRecorder {
+	return m.recorder
+}
+
+// SendMessage mocks base method.
+func (m *MockChannelNotifier) SendMessage(arg0 context.Context, arg1 domain.FindingsReport) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SendMessage", arg0)
+	ret := m.ctrl.Call(m, "SendMessage", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SendMessage indicates an expected call of SendMessage.
-func (mr *MockNotifierMockRecorder) SendMessage(arg0 interface{}) *gomock.Call {
+func (mr *MockChannelNotifierMockRecorder) SendMessage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessage", reflect.TypeOf((*MockChannelNotifier)(nil).SendMessage), arg0, arg1)
+}
+
+// SendToChannels mocks base method.
+func (m *MockChannelNotifier) SendToChannels(arg0 domain.FindingsReport, arg1 []string) []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendToChannels", arg0, arg1)
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// SendToChannels indicates an expected call of SendToChannels.
+func (mr *MockChannelNotifierMockRecorder) SendToChannels(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendToChannels", reflect.TypeOf((*MockChannelNotifier)(nil).SendToChannels), arg0, arg1)
+}
+
+// MockDigestNotifier is a mock of DigestNotifier interface.
+type MockDigestNotifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockDigestNotifierMockRecorder
+}
+
+// MockDigestNotifierMockRecorder is the mock recorder for MockDigestNotifier.
+type MockDigestNotifierMockRecorder struct {
+	mock *MockDigestNotifier
+}
+
+// NewMockDigestNotifier creates a new mock instance.
+func NewMockDigestNotifier(ctrl *gomock.Controller) *MockDigestNotifier {
+	mock := &MockDigestNotifier{ctrl: ctrl}
+	mock.recorder = &MockDigestNotifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDigestNotifier) EXPECT() *MockDigestNotifierMockRecorder {
+	return m.recorder
+}
+
+// SendDigest mocks base method.
+func (m *MockDigestNotifier) SendDigest(arg0 domain.Digest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendDigest", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendDigest indicates an expected call of SendDigest.
+func (mr *MockDigestNotifierMockRecorder) SendDigest(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDigest", reflect.TypeOf((*MockDigestNotifier)(nil).SendDigest), arg0)
+}
+
+// MockWebhookSender is a mock of WebhookSender interface.
+type MockWebhookSender struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookSenderMockRecorder
+}
+
+// MockWebhookSenderMockRecorder is the mock recorder for MockWebhookSender.
+type MockWebhookSenderMockRecorder struct {
+	mock *MockWebhookSender
+}
+
+// NewMockWebhookSender creates a new mock instance.
+func NewMockWebhookSender(ctrl *gomock.Controller) *MockWebhookSender {
+	mock := &MockWebhookSender{ctrl: ctrl}
+	mock.recorder = &MockWebhookSenderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookSender) EXPECT() *MockWebhookSenderMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method.
+func (m *MockWebhookSender) Send(arg0 domain.WebhookSubscription, arg1 domain.WebhookDelivery) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockWebhookSenderMockRecorder) Send(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessage", reflect.TypeOf((*MockNotifier)(nil).SendMessage), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockWebhookSender)(nil).Send), arg0, arg1)
 }
@@ -1,17 +1,116 @@
-//go:generate mockgen -destination=mocks/mock_repositories_generated.go -package=mocks . FindingsRepository,Notifier
+//go:generate mockgen -destination=mocks/mock_repositories_generated.go -package=mocks . FindingsRepository,APIKeyRepository,WebhookRepository,OutboxRepository,RoutingRuleRepository,GitleaksConfigRepository,DigestRepository,AnnouncementRepository,TokenVerifier,Notifier,ChannelNotifier,DigestNotifier,WebhookSender
 package ports
 
 import (
+	"context"
 	"secrets-operator/internal/core/domain"
+	"time"
 )
 
 type FindingsRepository interface {
 	SaveFindingsReport(findingsReport domain.FindingsReport, collectionName string) error
 	GetRepoFindingsById(repoId int, collectionName string) (domain.RepoFindings, error)
-	SaveAndUpdateRepoFindingsById(repoFindings domain.RepoFindings, repoId int, collectionName string) error
+	// SaveAndUpdateRepoFindingsById atomically merges findings of the report into stored repository findings,
+	// if notify is set and there are new findings, notification about them is queued to "outbox" collection in the same step
+	SaveAndUpdateRepoFindingsById(findingsReport domain.FindingsReport, identity domain.FindingIdentity, repoId int, notify bool, collectionName string) (domain.UpsertResult, error)
 	GetRepositoriesByName(repoName string, collectionName string) ([]map[string]string, error)
+	UpdateFindingStatus(repoId int, fingerprint string, change domain.StatusChange, collectionName string) error
 }
 
+type APIKeyRepository interface {
+	SaveAPIKey(apiKey domain.APIKey, collectionName string) error
+	GetAPIKeyByHash(keyHash string, collectionName string) (domain.APIKey, error)
+	GetAPIKeys(collectionName string) ([]domain.APIKey, error)
+	RevokeAPIKey(id string, revokedAt time.Time, collectionName string) error
+	// TouchAPIKey records last use of the key
+	TouchAPIKey(id string, usedAt time.Time, collectionName string) error
+}
+
+type WebhookRepository interface {
+	SaveWebhookSubscription(subscription domain.WebhookSubscription, collectionName string) error
+	GetWebhookSubscriptions(collectionName string) ([]domain.WebhookSubscription, error)
+	DeleteWebhookSubscription(id string, collectionName string) error
+	// SaveWebhookDelivery inserts the delivery or replaces the stored one with the same ID
+	SaveWebhookDelivery(delivery domain.WebhookDelivery, collectionName string) error
+	// GetWebhookDeliveries returns deliveries of the subscription, newest first
+	GetWebhookDeliveries(subscriptionId string, limit int, collectionName string) ([]domain.WebhookDelivery, error)
+	// GetDueWebhookDeliveries returns pending deliveries whose next attempt is not after now, oldest first
+	GetDueWebhookDeliveries(now time.Time, limit int, collectionName string) ([]domain.WebhookDelivery, error)
+}
+
+// OutboxRepository stores notifications queued by SaveAndUpdateRepoFindingsById
+type OutboxRepository interface {
+	// SaveOutboxMessage inserts the message or replaces the stored one with the same ID
+	SaveOutboxMessage(message domain.OutboxMessage, collectionName string) error
+	GetOutboxMessageById(id string, collectionName string) (domain.OutboxMessage, error)
+	// GetOutboxMessages returns messages in the status, newest first
+	GetOutboxMessages(status domain.OutboxStatus, limit int, collectionName string) ([]domain.OutboxMessage, error)
+	// GetDueOutboxMessages returns pending messages whose next attempt is not after now, oldest first
+	GetDueOutboxMessages(now time.Time, limit int, collectionName string) ([]domain.OutboxMessage, error)
+}
+
+type RoutingRuleRepository interface {
+	// SaveRoutingRule inserts the rule or replaces the stored one with the same ID
+	SaveRoutingRule(rule domain.RoutingRule, collectionName string) error
+	GetRoutingRuleById(id string, collectionName string) (domain.RoutingRule, error)
+	// GetRoutingRules returns every rule, oldest first
+	GetRoutingRules(collectionName string) ([]domain.RoutingRule, error)
+	DeleteRoutingRule(id string, collectionName string) error
+}
+
+// GitleaksConfigRepository stores versions of gitleaks config, stored versions are never changed
+type GitleaksConfigRepository interface {
+	// SaveGitleaksConfigVersion inserts the version, errors.ErrGitleaksConfigVersionExists if its number is taken
+	SaveGitleaksConfigVersion(version domain.GitleaksConfigVersion, collectionName string) error
+	GetGitleaksConfigVersion(version int, collectionName string) (domain.GitleaksConfigVersion, error)
+	// GetLatestGitleaksConfigVersion returns errors.ErrGitleaksConfigVersionNotFound if no version is stored
+	GetLatestGitleaksConfigVersion(collectionName string) (domain.GitleaksConfigVersion, error)
+	// GetGitleaksConfigVersions returns every version, newest first
+	GetGitleaksConfigVersions(collectionName string) ([]domain.GitleaksConfigVersion, error)
+}
+
+// DigestRepository stores findings waiting for digests of notification channels
+type DigestRepository interface {
+	// SaveDigestEntry inserts the entry or replaces the stored one with the same ID
+	SaveDigestEntry(entry domain.DigestEntry, collectionName string) error
+	// GetDueDigestEntries returns entries whose digest is not due after now, oldest first, dead entries are left out
+	GetDueDigestEntries(now time.Time, limit int, collectionName string) ([]domain.DigestEntry, error)
+	// DeleteDigestEntries deletes entries with the IDs, missing ones are skipped
+	DeleteDigestEntries(ids []string, collectionName string) error
+}
+
+// AnnouncementRepository remembers findings announced to notification channels, so each is announced once
+type AnnouncementRepository interface {
+	// GetAnnouncedFingerprints returns those of the fingerprints which were announced to the channel about the repository
+	GetAnnouncedFingerprints(channel string, repoId int, fingerprints []string, collectionName string) ([]string, error)
+	// SaveAnnouncements records the fingerprints as announced to the channel, recording them again is not an error
+	SaveAnnouncements(channel string, repoId int, fingerprints []string, announcedAt time.Time, collectionName string) error
+}
+
+// TokenVerifier verifies tokens issued by an identity provider
+type TokenVerifier interface {
+	Verify(token string) (domain.Claims, error)
+}
+
+// Notifier delivers reports to a notification channel, it gives up once ctx is done, so a message which timed out is
+// not delivered behind the back of a retry
 type Notifier interface {
-	SendMessage(message domain.FindingsReport) error
+	SendMessage(ctx context.Context, message domain.FindingsReport) error
+}
+
+// ChannelNotifier delivers to named notification channels, so retries can skip channels which already received the message
+type ChannelNotifier interface {
+	Notifier
+	// SendToChannels delivers to the channels, to every channel if none are given, and returns names of channels which failed
+	SendToChannels(message domain.FindingsReport, channels []string) []string
+}
+
+// DigestNotifier delivers digests to the channel or routed target they were collected for
+type DigestNotifier interface {
+	SendDigest(digest domain.Digest) error
+}
+
+// WebhookSender posts signed payload of the delivery to endpoint of the subscription and returns HTTP status of the response
+type WebhookSender interface {
+	Send(subscription domain.WebhookSubscription, delivery domain.WebhookDelivery) (int, error)
 }
@@ -1,4 +1,4 @@
-//go:generate mockgen -destination=mocks/mock_services_generated.go -package=mocks . FindingService
+//go:generate mockgen -destination=mocks/mock_services_generated.go -package=mocks . FindingService,AuthService,WebhookService,EventPublisher,OutboxService,RoutingService,GitleaksConfigService,TemplateService
 package ports
 
 import (
@@ -6,8 +6,76 @@ import (
 )
 
 type FindingService interface {
-	Add(domain.FindingsReport) error
-	Notify(finding domain.FindingsReport) error
+	// Add stores the report, if notify is set notification about new findings is queued to the outbox
+	Add(findingsReport domain.FindingsReport, notify bool) (domain.UploadResult, error)
+	// GetById returns findings of the repository, errors.ErrRepositoryNotFound if nothing was uploaded for it yet
 	GetById(repoId int) (domain.RepoFindings, error)
 	GetByName(repoName string) ([]map[string]string, error)
+	UpdateStatus(repoId int, fingerprint string, change domain.StatusChange) error
+}
+
+type AuthService interface {
+	// Authenticate resolves API token of the request to its principal
+	Authenticate(token string) (domain.Principal, error)
+	// IssueKey creates new key from name, scope and repository of apiKey, the token is returned only once
+	IssueKey(apiKey domain.APIKey, createdBy string) (string, domain.APIKey, error)
+	ListKeys() ([]domain.APIKey, error)
+	RevokeKey(id string) error
+}
+
+// EventPublisher queues events for webhook subscribers
+type EventPublisher interface {
+	Publish(payload domain.WebhookPayload) error
+}
+
+type WebhookService interface {
+	EventPublisher
+	// Subscribe creates subscription with a new signing secret, the secret is returned only once
+	Subscribe(subscription domain.WebhookSubscription, createdBy string) (domain.WebhookSubscription, error)
+	ListSubscriptions() ([]domain.WebhookSubscription, error)
+	Unsubscribe(id string) error
+	// GetDeliveries returns delivery log of the subscription, newest first
+	GetDeliveries(subscriptionId string) ([]domain.WebhookDelivery, error)
+}
+
+// OutboxService lets admins inspect queued notifications and replay dead ones
+type OutboxService interface {
+	List(status domain.OutboxStatus) ([]domain.OutboxMessage, error)
+	Replay(id string) (domain.OutboxMessage, error)
+}
+
+// RoutingService manages rules routing notifications of repositories to their owners
+type RoutingService interface {
+	CreateRule(rule domain.RoutingRule, createdBy string) (domain.RoutingRule, error)
+	ListRules() ([]domain.RoutingRule, error)
+	// UpdateRule replaces conditions and targets of the rule, its author and creation time are kept
+	UpdateRule(id string, rule domain.RoutingRule) (domain.RoutingRule, error)
+	DeleteRule(id string) error
+}
+
+// GitleaksConfigService manages gitleaks config of pipelines, every change stores a new version of the whole config
+type GitleaksConfigService interface {
+	// Current returns the latest version, version 0 with domain.DefaultGitleaksConfig if none is stored
+	Current() (domain.GitleaksConfigVersion, error)
+	GetVersion(version int) (domain.GitleaksConfigVersion, error)
+	// ListVersions returns every stored version, newest first
+	ListVersions() ([]domain.GitleaksConfigVersion, error)
+	Diff(from int, to int) (domain.GitleaksConfigDiff, error)
+	Replace(config domain.GitleaksConfig, createdBy string) (domain.GitleaksConfigVersion, error)
+	CreateRule(rule domain.GitleaksRule, createdBy string) (domain.GitleaksConfigVersion, error)
+	// UpdateRule replaces the rule with the ID, the ID of the rule is kept
+	UpdateRule(id string, rule domain.GitleaksRule, createdBy string) (domain.GitleaksConfigVersion, error)
+	DeleteRule(id string, createdBy string) (domain.GitleaksConfigVersion, error)
+	// UpdateAllowlist replaces the global allowlist, an empty allowlist removes it
+	UpdateAllowlist(allowlist domain.GitleaksAllowlist, createdBy string) (domain.GitleaksConfigVersion, error)
+	// Rollback stores config of the version as a new version
+	Rollback(version int, createdBy string) (domain.GitleaksConfigVersion, error)
+	// Seed stores the config as the first version unless a version is stored already
+	Seed(config domain.GitleaksConfig, change string) error
+}
+
+// TemplateService previews notification templates loaded at startup and drafts of new ones
+type TemplateService interface {
+	Names() []string
+	Preview(preview domain.TemplatePreview) (string, error)
 }
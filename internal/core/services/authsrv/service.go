@@ -0,0 +1,170 @@
+package authsrv
+
+import (
+	"crypto/subtle"
+	"go.uber.org/zap"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/core/ports"
+	"secrets-operator/internal/errors"
+	"strings"
+	"time"
+)
+
+// lastUsedResolution limits how often last use of a key is written, pipelines may call the API many times a minute
+const lastUsedResolution = time.Minute
+
+type service struct {
+	l                *zap.SugaredLogger
+	apiKeyRepository ports.APIKeyRepository
+	tokenVerifier    ports.TokenVerifier
+	accessPolicy     domain.AccessPolicy
+	adminKeyHash     string
+}
+
+// NewAuthService creates service authenticating issued API keys and, if tokenVerifier is set, identity provider users.
+// adminKey is accepted as admin token without being stored, it is meant for issuing the first keys.
+func NewAuthService(l *zap.SugaredLogger, apiKeyRepository ports.APIKeyRepository, tokenVerifier ports.TokenVerifier, accessPolicy domain.AccessPolicy, adminKey string) *service {
+
+	srv := &service{
+		l:                l,
+		apiKeyRepository: apiKeyRepository,
+		tokenVerifier:    tokenVerifier,
+		accessPolicy:     accessPolicy,
+	}
+
+	if adminKey != "" {
+		srv.adminKeyHash = domain.HashAPIKeyToken(adminKey)
+	}
+
+	return srv
+}
+
+func (srv service) Authenticate(token string) (domain.Principal, error) {
+
+	if token == "" {
+		return domain.Principal{}, errors.ErrUnauthorized
+	}
+
+	keyHash := domain.HashAPIKeyToken(token)
+
+	if srv.adminKeyHash != "" && subtle.ConstantTimeCompare([]byte(keyHash), []byte(srv.adminKeyHash)) == 1 {
+		return domain.Principal{Name: "admin key", Role: domain.RoleAdmin}, nil
+	}
+
+	if srv.tokenVerifier != nil && isJWT(token) {
+		return srv.authenticateUser(token)
+	}
+
+	apiKey, err := srv.apiKeyRepository.GetAPIKeyByHash(keyHash, "apikeys")
+	if err != nil {
+		if err == errors.ErrAPIKeyNotFound {
+			return domain.Principal{}, errors.ErrUnauthorized
+		}
+		srv.l.Error(err)
+		return domain.Principal{}, errors.ErrCouldNotAuthenticate
+	}
+
+	if apiKey.IsRevoked() {
+		srv.l.Warnln("revoked api key used", apiKey.ID)
+		return domain.Principal{}, errors.ErrUnauthorized
+	}
+
+	now := time.Now().UTC()
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= lastUsedResolution {
+		// failing to record last use must not fail the request
+		if err = srv.apiKeyRepository.TouchAPIKey(apiKey.ID, now, "apikeys"); err != nil {
+			srv.l.Error(err)
+		}
+	}
+
+	return apiKey.Principal(), nil
+}
+
+// authenticateUser resolves identity provider token to principal by access policy
+func (srv service) authenticateUser(token string) (domain.Principal, error) {
+
+	claims, err := srv.tokenVerifier.Verify(token)
+	if err != nil {
+		if err == errors.ErrInvalidToken {
+			return domain.Principal{}, errors.ErrUnauthorized
+		}
+		srv.l.Error(err)
+		return domain.Principal{}, errors.ErrCouldNotAuthenticate
+	}
+
+	principal, ok := srv.accessPolicy.Principal(claims)
+	if !ok {
+		srv.l.Warnln("no role granted to user", claims.Subject)
+		return domain.Principal{}, errors.ErrUnauthorized
+	}
+
+	return principal, nil
+}
+
+// isJWT tells apart identity provider tokens, API keys never contain dots
+func isJWT(token string) bool {
+	return !strings.HasPrefix(token, domain.APIKeyPrefix) && strings.Count(token, ".") == 2
+}
+
+func (srv service) IssueKey(apiKey domain.APIKey, createdBy string) (string, domain.APIKey, error) {
+
+	switch apiKey.Scope {
+	case domain.TokenScopeUpload:
+		if apiKey.RepoID <= 0 {
+			srv.l.Errorln("upload key without repository")
+			return "", domain.APIKey{}, errors.ErrInvalidAPIKey
+		}
+	case domain.TokenScopeRead:
+	case domain.TokenScopeAdmin:
+		apiKey.RepoID = 0
+	default:
+		srv.l.Errorln("invalid api key scope", apiKey.Scope)
+		return "", domain.APIKey{}, errors.ErrInvalidAPIKey
+	}
+
+	token, keyHash := domain.NewAPIKeyToken()
+
+	issued := domain.APIKey{
+		ID:        domain.NewID(),
+		Name:      apiKey.Name,
+		Scope:     apiKey.Scope,
+		RepoID:    apiKey.RepoID,
+		Prefix:    token[:len(domain.APIKeyPrefix)+6],
+		KeyHash:   keyHash,
+		CreatedBy: createdBy,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	err := srv.apiKeyRepository.SaveAPIKey(issued, "apikeys")
+	if err != nil {
+		srv.l.Error(err)
+		return "", domain.APIKey{}, errors.ErrCouldNotIssueAPIKey
+	}
+
+	return token, issued, nil
+}
+
+func (srv service) ListKeys() ([]domain.APIKey, error) {
+
+	apiKeys, err := srv.apiKeyRepository.GetAPIKeys("apikeys")
+	if err != nil {
+		srv.l.Error(err)
+		return nil, errors.ErrCouldNotGetAPIKeys
+	}
+
+	return apiKeys, nil
+}
+
+func (srv service) RevokeKey(id string) error {
+
+	err := srv.apiKeyRepository.RevokeAPIKey(id, time.Now().UTC(), "apikeys")
+	if err != nil {
+		srv.l.Error(err)
+		if err == errors.ErrAPIKeyNotFound {
+			return err
+		}
+		return errors.ErrCouldNotRevokeAPIKey
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package digestsrv
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/core/ports"
+	"time"
+)
+
+// digestBatchSize limits entries read by a single SendDue call
+const digestBatchSize = 500
+
+type service struct {
+	l                *zap.SugaredLogger
+	digestRepository ports.DigestRepository
+	notifier         ports.DigestNotifier
+	retryPolicy      domain.RetryPolicy
+}
+
+// NewDigestService creates service assembling digests from queued findings once they are due, digests are sent by Run.
+// Failed digests are retried by the policy, entries of digests failing after its last attempt are dead.
+func NewDigestService(l *zap.SugaredLogger, digestRepository ports.DigestRepository, notifier ports.DigestNotifier, retryPolicy domain.RetryPolicy) *service {
+
+	return &service{
+		l:                l,
+		digestRepository: digestRepository,
+		notifier:         notifier,
+		retryPolicy:      retryPolicy,
+	}
+}
+
+// SendDue sends digests of due entries and returns how many entries were sent, entries of failed digests are
+// postponed by the retry policy
+func (srv service) SendDue() (int, error) {
+
+	now := time.Now().UTC()
+
+	entries, err := srv.digestRepository.GetDueDigestEntries(now, digestBatchSize, "digests")
+	if err != nil {
+		return 0, err
+	}
+
+	byID := make(map[string]domain.DigestEntry, len(entries))
+	for _, entry := range entries {
+		byID[entry.ID] = entry
+	}
+
+	sent := 0
+
+	for _, digest := range domain.Digests(entries) {
+
+		if err = srv.notifier.SendDigest(digest); err != nil {
+			srv.l.Errorf("Digest of repository %d for %s failed: %v", digest.Report.RepoID, digest.Channel, err)
+			if err = srv.postpone(digest, byID, err, now); err != nil {
+				return sent, err
+			}
+			continue
+		}
+
+		if err = srv.digestRepository.DeleteDigestEntries(digest.EntryIDs, "digests"); err != nil {
+			return sent, err
+		}
+
+		sent += len(digest.EntryIDs)
+	}
+
+	return sent, nil
+}
+
+// postpone records the failure on entries of the digest, so they are retried after backoff or are dead
+func (srv service) postpone(digest domain.Digest, entries map[string]domain.DigestEntry, failure error, now time.Time) error {
+
+	for _, id := range digest.EntryIDs {
+
+		entry := entries[id]
+		entry.RecordFailure(failure, now, srv.retryPolicy)
+		if entry.Dead {
+			srv.l.Errorf("Digest entry %s of repository %d for %s is dead after %d attempts", entry.ID, entry.Report.RepoID, entry.Channel, entry.AttemptCount)
+		}
+
+		if err := srv.digestRepository.SaveDigestEntry(entry, "digests"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Run sends due digests every interval until ctx is done, full batches are followed by the next one right away
+func (srv service) Run(ctx context.Context, interval time.Duration) {
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		sent, err := srv.SendDue()
+		if err != nil {
+			srv.l.Errorln("Could not send notification digests.", err)
+		}
+
+		if sent == digestBatchSize && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
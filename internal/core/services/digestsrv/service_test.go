@@ -0,0 +1,154 @@
+package digestsrv
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/core/ports/mocks"
+	"testing"
+	"time"
+)
+
+type DigestServiceTestSuite struct {
+	suite.Suite
+	l      *zap.SugaredLogger
+	ctrl   *gomock.Controller
+	policy domain.RetryPolicy
+}
+
+func TestSuiteDigestService(t *testing.T) {
+	suite.Run(t, new(DigestServiceTestSuite))
+}
+
+func (s *DigestServiceTestSuite) SetupTest() {
+
+	s.l = zap.NewNop().Sugar()
+	s.policy = domain.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour}
+
+	// setup gomock controller
+	s.ctrl = gomock.NewController(s.T())
+	defer s.ctrl.Finish()
+}
+
+func entry(id string, channel string, repoId int, fingerprints ...string) domain.DigestEntry {
+
+	report := domain.FindingsReport{RepoID: repoId, PipelineID: len(id), Findings: domain.Findings{}}
+	for _, fingerprint := range fingerprints {
+		report.Findings = append(report.Findings, domain.Finding{Fingerprint: fingerprint})
+	}
+
+	return domain.DigestEntry{ID: id, Channel: channel, Report: report}
+}
+
+func (s *DigestServiceTestSuite) TestService_SendDue() {
+
+	// arrange
+	entries := []domain.DigestEntry{
+		entry("a", "slack", 444, "f1", "f2"),
+		entry("b", "teams", 444, "f1"),
+		entry("cc", "slack", 444, "f2", "f3"),
+		entry("d", "slack", 555, "f4"),
+	}
+
+	mockDigestRepository := mocks.NewMockDigestRepository(s.ctrl)
+	mockDigestRepository.EXPECT().GetDueDigestEntries(gomock.Any(), digestBatchSize, "digests").Return(entries, nil)
+	mockDigestRepository.EXPECT().DeleteDigestEntries([]string{"a", "cc"}, "digests").Return(nil)
+	mockDigestRepository.EXPECT().DeleteDigestEntries([]string{"d"}, "digests").Return(nil)
+
+	var postponed domain.DigestEntry
+	mockDigestRepository.
+		EXPECT().
+		SaveDigestEntry(gomock.Any(), "digests").
+		DoAndReturn(func(entry domain.DigestEntry, collectionName string) error {
+			postponed = entry
+			return nil
+		})
+
+	var sent []domain.Digest
+	mockDigestNotifier := mocks.NewMockDigestNotifier(s.ctrl)
+	mockDigestNotifier.
+		EXPECT().
+		SendDigest(gomock.Any()).
+		DoAndReturn(func(digest domain.Digest) error {
+			sent = append(sent, digest)
+			if digest.Channel == "teams" {
+				return assert.AnError
+			}
+			return nil
+		}).
+		Times(3)
+
+	sut := NewDigestService(s.l, mockDigestRepository, mockDigestNotifier, s.policy)
+
+	// act
+	started := time.Now()
+	count, err := sut.SendDue()
+
+	// assert
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), 3, count, "entries of failed digest are kept")
+	assert.Equal(s.T(), "b", postponed.ID)
+	assert.Equal(s.T(), 1, postponed.AttemptCount)
+	assert.Equal(s.T(), assert.AnError.Error(), postponed.LastError)
+	assert.False(s.T(), postponed.Dead)
+	assert.False(s.T(), postponed.DueAt.Before(started.Add(time.Minute)), "failed entries are postponed by backoff")
+	if assert.Len(s.T(), sent, 3) {
+		assert.Equal(s.T(), "slack", sent[0].Channel)
+		assert.Equal(s.T(), 444, sent[0].Report.RepoID)
+		assert.Equal(s.T(), 2, sent[0].Report.PipelineID, "digest describes the latest entry")
+		assert.Equal(s.T(), domain.Findings{{Fingerprint: "f1"}, {Fingerprint: "f2"}, {Fingerprint: "f3"}}, sent[0].Report.Findings)
+		assert.Equal(s.T(), "teams", sent[1].Channel)
+		assert.Equal(s.T(), 555, sent[2].Report.RepoID)
+	}
+}
+
+func (s *DigestServiceTestSuite) TestService_SendDueFailsWhenEntriesCouldNotBeRead() {
+
+	// arrange
+	mockDigestRepository := mocks.NewMockDigestRepository(s.ctrl)
+	mockDigestRepository.EXPECT().GetDueDigestEntries(gomock.Any(), digestBatchSize, "digests").Return(nil, assert.AnError)
+
+	sut := NewDigestService(s.l, mockDigestRepository, mocks.NewMockDigestNotifier(s.ctrl), s.policy)
+
+	// act
+	count, err := sut.SendDue()
+
+	// assert
+	assert.ErrorIs(s.T(), err, assert.AnError)
+	assert.Zero(s.T(), count)
+}
+
+func (s *DigestServiceTestSuite) TestService_SendDueDeadLettersExhaustedEntries() {
+
+	// arrange
+	exhausted := entry("a", "slack", 444, "f1")
+	exhausted.AttemptCount = 2
+
+	mockDigestRepository := mocks.NewMockDigestRepository(s.ctrl)
+	mockDigestRepository.EXPECT().GetDueDigestEntries(gomock.Any(), digestBatchSize, "digests").Return([]domain.DigestEntry{exhausted}, nil)
+
+	var saved domain.DigestEntry
+	mockDigestRepository.
+		EXPECT().
+		SaveDigestEntry(gomock.Any(), "digests").
+		DoAndReturn(func(entry domain.DigestEntry, collectionName string) error {
+			saved = entry
+			return nil
+		})
+
+	mockDigestNotifier := mocks.NewMockDigestNotifier(s.ctrl)
+	mockDigestNotifier.EXPECT().SendDigest(gomock.Any()).Return(assert.AnError)
+
+	sut := NewDigestService(s.l, mockDigestRepository, mockDigestNotifier, s.policy)
+
+	// act
+	count, err := sut.SendDue()
+
+	// assert
+	assert.NoError(s.T(), err)
+	assert.Zero(s.T(), count)
+	assert.Equal(s.T(), 3, saved.AttemptCount)
+	assert.True(s.T(), saved.Dead, "entries are dead once attempts are exhausted")
+}
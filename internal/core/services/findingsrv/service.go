@@ -5,56 +5,68 @@ import (
 	"secrets-operator/internal/core/domain"
 	"secrets-operator/internal/core/ports"
 	"secrets-operator/internal/errors"
+	"time"
 )
 
 type service struct {
 	l                  *zap.SugaredLogger
 	findingsRepository ports.FindingsRepository
-	notifier           ports.Notifier
+	publisher          ports.EventPublisher
+	redaction          domain.RedactionPolicy
+	identity           domain.FindingIdentity
+	policies           domain.VerdictPolicies
 }
 
-func NewFindingService(l *zap.SugaredLogger, findingsRepository ports.FindingsRepository, notifier ports.Notifier) *service {
+// NewFindingService creates the service, publisher may be nil if webhook events are not published
+func NewFindingService(l *zap.SugaredLogger, findingsRepository ports.FindingsRepository, publisher ports.EventPublisher, redaction domain.RedactionPolicy, identity domain.FindingIdentity, policies domain.VerdictPolicies) *service {
 
 	return &service{
 		l:                  l,
 		findingsRepository: findingsRepository,
-		notifier:           notifier,
+		publisher:          publisher,
+		redaction:          redaction,
+		identity:           identity,
+		policies:           policies,
 	}
 }
 
-func (srv service) Add(findingsReport domain.FindingsReport) error {
+// Add stores the report and merges its findings into repository findings, if notify is set notification about findings
+// seen for the first time is queued in the same step. Known leaks were already reported.
+// Returned result tells which findings are genuinely new and the verdict of the repository policy, so pipelines can decide whether to fail.
+func (srv service) Add(findingsReport domain.FindingsReport, notify bool) (domain.UploadResult, error) {
+
+	// raw secrets must never reach the storage
+	findingsReport.Findings = srv.redaction.Redact(findingsReport.Findings)
+	findingsReport.ID = domain.NewID()
 
 	err := srv.findingsRepository.SaveFindingsReport(findingsReport, "findings")
 	if err != nil {
 		srv.l.Error(err)
-		return errors.ErrCouldNotSaveFindingsReport
-	}
-
-	repositoryFindings := domain.RepoFindings{
-		Findings: findingsReport.Findings,
-		RepoID:   findingsReport.RepoID,
-		RepoName: findingsReport.RepoName,
-		RepoURL:  findingsReport.RepoURL,
+		return domain.UploadResult{}, errors.ErrCouldNotSaveFindingsReport
 	}
 
-	err = srv.findingsRepository.SaveAndUpdateRepoFindingsById(repositoryFindings, findingsReport.RepoID, "repositories")
+	upsert, err := srv.findingsRepository.SaveAndUpdateRepoFindingsById(findingsReport, srv.identity, findingsReport.RepoID, notify, "repositories")
 	if err != nil {
 		srv.l.Error(err)
-		return errors.ErrCouldNotSaveAndUpdateRepoFindingsById
+		return domain.UploadResult{}, errors.ErrCouldNotSaveAndUpdateRepoFindingsById
 	}
 
-	return nil
-}
-
-func (srv service) Notify(finding domain.FindingsReport) error {
-
-	err := srv.notifier.SendMessage(finding)
-	if err != nil {
-		srv.l.Errorln(err)
-		return errors.ErrCouldNotNotify
+	srv.publish(domain.WebhookPayload{Event: domain.WebhookEventReportReceived, RepoID: findingsReport.RepoID, Report: &findingsReport})
+
+	if len(upsert.New) > 0 {
+		srv.publish(domain.WebhookPayload{
+			Event:  domain.WebhookEventFindingNew,
+			RepoID: findingsReport.RepoID,
+			Repository: &domain.RepoFindings{
+				RepoID:   findingsReport.RepoID,
+				RepoName: findingsReport.RepoName,
+				RepoURL:  findingsReport.RepoURL,
+				Findings: upsert.New,
+			},
+		})
 	}
 
-	return nil
+	return domain.NewUploadResult(findingsReport.ID, upsert, srv.policies.For(findingsReport.RepoID)), nil
 }
 
 func (srv service) GetById(repoId int) (domain.RepoFindings, error) {
@@ -62,6 +74,9 @@ func (srv service) GetById(repoId int) (domain.RepoFindings, error) {
 	repositoryFindings, err := srv.findingsRepository.GetRepoFindingsById(repoId, "repositories")
 	if err != nil {
 		srv.l.Error(err)
+		if err == errors.ErrRepositoryNotFound {
+			return domain.RepoFindings{}, err
+		}
 		return domain.RepoFindings{}, errors.ErrCouldNotGetRepoFindingsById
 	}
 
@@ -83,3 +98,62 @@ func (srv service) GetByName(repoName string) ([]map[string]string, error) {
 
 	return repositories, nil
 }
+
+func (srv service) UpdateStatus(repoId int, fingerprint string, change domain.StatusChange) error {
+
+	if !change.Status.IsValid() {
+		srv.l.Errorln("invalid finding status", change.Status)
+		return errors.ErrInvalidFindingStatus
+	}
+
+	if change.ChangedAt.IsZero() {
+		change.ChangedAt = time.Now().UTC()
+	}
+
+	err := srv.findingsRepository.UpdateFindingStatus(repoId, fingerprint, change, "repositories")
+	if err != nil {
+		srv.l.Error(err)
+		if err == errors.ErrFindingNotFound {
+			return err
+		}
+		return errors.ErrCouldNotUpdateFindingStatus
+	}
+
+	if srv.publisher != nil {
+		srv.publishStatusChange(repoId, fingerprint)
+	}
+
+	return nil
+}
+
+// publishStatusChange publishes repository findings narrowed down to the changed finding
+func (srv service) publishStatusChange(repoId int, fingerprint string) {
+
+	repoFindings, err := srv.findingsRepository.GetRepoFindingsById(repoId, "repositories")
+	if err != nil {
+		srv.l.Errorln("could not read changed finding for webhook event.", err)
+		return
+	}
+
+	changed := domain.Findings{}
+	for _, finding := range repoFindings.Findings {
+		if finding.Fingerprint == fingerprint {
+			changed = append(changed, finding)
+		}
+	}
+	repoFindings.Findings = changed
+
+	srv.publish(domain.WebhookPayload{Event: domain.WebhookEventFindingStatusChanged, RepoID: repoId, Repository: &repoFindings})
+}
+
+// publish queues webhook event, failing to do so must not fail the request which caused it
+func (srv service) publish(payload domain.WebhookPayload) {
+
+	if srv.publisher == nil {
+		return
+	}
+
+	if err := srv.publisher.Publish(payload); err != nil {
+		srv.l.Errorln("could not publish webhook event", payload.Event, err)
+	}
+}
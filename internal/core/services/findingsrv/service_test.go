@@ -14,8 +14,10 @@ import (
 
 type FindingsServiceTestSuite struct {
 	suite.Suite
-	l    *zap.SugaredLogger
-	ctrl *gomock.Controller
+	l         *zap.SugaredLogger
+	ctrl      *gomock.Controller
+	redaction domain.RedactionPolicy
+	policies  domain.VerdictPolicies
 }
 
 func TestSuiteFindingService(t *testing.T) {
@@ -31,6 +33,14 @@ func (s *FindingsServiceTestSuite) SetupTest() {
 
 	s.l = sugaredLogger
 
+	s.redaction = domain.RedactionPolicy{Mode: domain.RedactionModeMask, Key: []byte("test key")}
+	s.policies = domain.VerdictPolicies{
+		Default: domain.VerdictPolicy{OnNew: domain.VerdictFail, OnKnown: domain.VerdictWarn, OnSuppressed: domain.VerdictPass},
+		Repos: map[int]domain.VerdictPolicy{
+			2: {OnNew: domain.VerdictWarn, OnKnown: domain.VerdictPass, OnSuppressed: domain.VerdictPass},
+		},
+	}
+
 	// setup gomock controller
 	s.ctrl = gomock.NewController(s.T())
 	defer s.ctrl.Finish()
@@ -200,7 +210,6 @@ func (s *FindingsServiceTestSuite) TestService_AddTableDriven() {
 
 			// arrange
 			mockFindingRepository := mocks.NewMockFindingsRepository(s.ctrl)
-			mockNotifier := mocks.NewMockNotifier(s.ctrl)
 
 			mockFindingRepository.
 				EXPECT().
@@ -210,124 +219,16 @@ func (s *FindingsServiceTestSuite) TestService_AddTableDriven() {
 
 			mockFindingRepository.
 				EXPECT().
-				SaveAndUpdateRepoFindingsById(gomock.Any(), gomock.Any(), gomock.Any()).
-				Return(tt.saveAndUpdateRepoFindingsByIdReturnValue).
+				SaveAndUpdateRepoFindingsById(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(domain.UpsertResult{}, tt.saveAndUpdateRepoFindingsByIdReturnValue).
 				AnyTimes()
 
 			mockFindingRepository.EXPECT().SaveFindingsReport(tt.input, "test_collection")
 
-			sut := NewFindingService(s.l, mockFindingRepository, mockNotifier)
+			sut := NewFindingService(s.l, mockFindingRepository, nil, s.redaction, domain.FindingIdentityFingerprint, s.policies)
 
 			// act
-			err := sut.Add(tt.input)
-
-			// assert
-			assert.Equalf(s.T(), tt.want, err, "assertion failed, wanted: %s, got: %s", tt.want, err)
-		})
-	}
-}
-
-func (s *FindingsServiceTestSuite) TestService_NotifyTableDriven() {
-
-	tests := []struct {
-		name                   string
-		input                  domain.FindingsReport
-		sendMessageReturnValue error
-		want                   error
-	}{
-		{
-			"test #1",
-			domain.FindingsReport{
-				PipelineID:   1,
-				RepoName:     "test repo",
-				RepoID:       1,
-				RepoURL:      "https://test.com",
-				CommitAuthor: "test author",
-				CommitSHA:    "a85af84d39a32da2c8eba1d88019079aeb0741b0",
-				Timestamp:    time.Now(),
-				Findings: domain.Findings{
-					{
-						Description: "test",
-						StartLine:   1,
-						EndLine:     1,
-						StartColumn: 1,
-						EndColumn:   1,
-						Match:       "test match",
-						Secret:      "test secret",
-						File:        "test file",
-						Commit:      "a85af84d39a32da2c8eba1d88019079aeb0741b0",
-						Entropy:     3.3822913,
-						Author:      "test author",
-						Email:       "test@mail.com",
-						Date:        time.Now(),
-						Message:     "test message",
-						Tags:        []string{"test", "tags"},
-						RuleID:      "test ruleId",
-						Fingerprint: "test fingerprint",
-					},
-				},
-			},
-			nil,
-			nil,
-		},
-		{
-			"test #2",
-			domain.FindingsReport{
-				PipelineID:   1,
-				RepoName:     "test repo",
-				RepoID:       1,
-				RepoURL:      "https://test.com",
-				CommitAuthor: "test author",
-				CommitSHA:    "a85af84d39a32da2c8eba1d88019079aeb0741b0",
-				Timestamp:    time.Now(),
-				Findings: domain.Findings{
-					{
-						Description: "test",
-						StartLine:   1,
-						EndLine:     1,
-						StartColumn: 1,
-						EndColumn:   1,
-						Match:       "test match",
-						Secret:      "test secret",
-						File:        "test file",
-						Commit:      "a85af84d39a32da2c8eba1d88019079aeb0741b0",
-						Entropy:     3.3822913,
-						Author:      "test author",
-						Email:       "test@mail.com",
-						Date:        time.Now(),
-						Message:     "test message",
-						Tags:        []string{"test", "tags"},
-						RuleID:      "test ruleId",
-						Fingerprint: "test fingerprint",
-					},
-				},
-			},
-			assert.AnError,
-			errors.ErrCouldNotNotify,
-		},
-	}
-
-	for _, tt := range tests {
-
-		s.Run(tt.name, func() {
-
-			// because gomock does not support expectation overrides, mock expectations done here, in every loop iteration.
-			// when gomock will support expectation overrides, it can be easily moved to SetupTest method of TestSuiteFindingService.
-
-			// arrange
-			mockFindingRepository := mocks.NewMockFindingsRepository(s.ctrl)
-			mockNotifier := mocks.NewMockNotifier(s.ctrl)
-
-			mockNotifier.
-				EXPECT().
-				SendMessage(gomock.Any()).
-				Return(tt.sendMessageReturnValue).
-				AnyTimes()
-
-			sut := NewFindingService(s.l, mockFindingRepository, mockNotifier)
-
-			// act
-			err := sut.Notify(tt.input)
+			_, err := sut.Add(tt.input, false)
 
 			// assert
 			assert.Equalf(s.T(), tt.want, err, "assertion failed, wanted: %s, got: %s", tt.want, err)
@@ -369,6 +270,14 @@ func (s *FindingsServiceTestSuite) TestService_GetByIdTableDriven() {
 			domain.RepoFindings{},
 			errors.ErrCouldNotGetRepoFindingsById,
 		},
+		{
+			"repository not found",
+			444,
+			domain.RepoFindings{},
+			errors.ErrRepositoryNotFound,
+			domain.RepoFindings{},
+			errors.ErrRepositoryNotFound,
+		},
 	}
 
 	for _, tt := range tests {
@@ -380,7 +289,6 @@ func (s *FindingsServiceTestSuite) TestService_GetByIdTableDriven() {
 
 			// arrange
 			mockFindingRepository := mocks.NewMockFindingsRepository(s.ctrl)
-			mockNotifier := mocks.NewMockNotifier(s.ctrl)
 
 			mockFindingRepository.
 				EXPECT().
@@ -388,7 +296,7 @@ func (s *FindingsServiceTestSuite) TestService_GetByIdTableDriven() {
 				Return(tt.getRepoFindingsByIdReturnValues, tt.getRepoFindingsByIdReturnErr).
 				AnyTimes()
 
-			sut := NewFindingService(s.l, mockFindingRepository, mockNotifier)
+			sut := NewFindingService(s.l, mockFindingRepository, nil, s.redaction, domain.FindingIdentityFingerprint, s.policies)
 
 			// act
 			findings, err := sut.GetById(tt.input)
@@ -445,7 +353,6 @@ func (s *FindingsServiceTestSuite) TestService_GetByNameTableDriven() {
 
 			// arrange
 			mockFindingRepository := mocks.NewMockFindingsRepository(s.ctrl)
-			mockNotifier := mocks.NewMockNotifier(s.ctrl)
 
 			mockFindingRepository.
 				EXPECT().
@@ -453,7 +360,7 @@ func (s *FindingsServiceTestSuite) TestService_GetByNameTableDriven() {
 				Return(tt.getRepositoriesByNameReturnValues, tt.getRepositoriesByNameReturnErr).
 				AnyTimes()
 
-			sut := NewFindingService(s.l, mockFindingRepository, mockNotifier)
+			sut := NewFindingService(s.l, mockFindingRepository, nil, s.redaction, domain.FindingIdentityFingerprint, s.policies)
 
 			// act
 			findings, err := sut.GetByName(tt.input)
@@ -464,3 +371,290 @@ func (s *FindingsServiceTestSuite) TestService_GetByNameTableDriven() {
 		})
 	}
 }
+
+func (s *FindingsServiceTestSuite) TestService_AddRedactsSecretsBeforeSaving() {
+
+	// arrange
+	mockFindingRepository := mocks.NewMockFindingsRepository(s.ctrl)
+
+	input := domain.FindingsReport{
+		PipelineID: 1,
+		RepoID:     1,
+		Findings: domain.Findings{
+			{
+				Match:       "password = 'test secret'",
+				Secret:      "test secret",
+				Fingerprint: "test fingerprint",
+			},
+		},
+	}
+
+	var savedReport domain.FindingsReport
+	var mergedReport domain.FindingsReport
+
+	mockFindingRepository.
+		EXPECT().
+		SaveFindingsReport(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(findingsReport domain.FindingsReport, collectionName string) error {
+			savedReport = findingsReport
+			return nil
+		})
+
+	mockFindingRepository.
+		EXPECT().
+		SaveAndUpdateRepoFindingsById(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(findingsReport domain.FindingsReport, identity domain.FindingIdentity, repoId int, notify bool, collectionName string) (domain.UpsertResult, error) {
+			mergedReport = findingsReport
+			return domain.UpsertResult{}, nil
+		})
+
+	sut := NewFindingService(s.l, mockFindingRepository, nil, s.redaction, domain.FindingIdentityFingerprint, s.policies)
+
+	// act
+	_, err := sut.Add(input, false)
+
+	// assert
+	assert.NoError(s.T(), err)
+	for _, findings := range []domain.Findings{savedReport.Findings, mergedReport.Findings} {
+		assert.Equal(s.T(), domain.RedactedPlaceholder, findings[0].Secret)
+		assert.Equal(s.T(), "password = 'REDACTED'", findings[0].Match)
+		assert.Equal(s.T(), s.redaction.Hash("test secret"), findings[0].SecretHash)
+	}
+	assert.Equal(s.T(), "test secret", input.Findings[0].Secret, "caller's report must not be modified")
+}
+
+func (s *FindingsServiceTestSuite) TestService_UpdateStatusTableDriven() {
+
+	tests := []struct {
+		name                              string
+		input                             domain.StatusChange
+		updateFindingStatusReturnErr      error
+		wantUpdateFindingStatusInvocation bool
+		wantErr                           error
+	}{
+		{
+			"valid status change",
+			domain.StatusChange{Status: domain.FindingStatusRevoked, ChangedBy: "test user"},
+			nil,
+			true,
+			nil,
+		},
+		{
+			"invalid status",
+			domain.StatusChange{Status: "closed", ChangedBy: "test user"},
+			nil,
+			false,
+			errors.ErrInvalidFindingStatus,
+		},
+		{
+			"unknown finding",
+			domain.StatusChange{Status: domain.FindingStatusResolved, ChangedBy: "test user"},
+			errors.ErrFindingNotFound,
+			true,
+			errors.ErrFindingNotFound,
+		},
+		{
+			"repository error",
+			domain.StatusChange{Status: domain.FindingStatusResolved, ChangedBy: "test user"},
+			assert.AnError,
+			true,
+			errors.ErrCouldNotUpdateFindingStatus,
+		},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// arrange
+			mockFindingRepository := mocks.NewMockFindingsRepository(s.ctrl)
+
+			invoked := false
+			mockFindingRepository.
+				EXPECT().
+				UpdateFindingStatus(1, "test fingerprint", gomock.Any(), "repositories").
+				DoAndReturn(func(repoId int, fingerprint string, change domain.StatusChange, collectionName string) error {
+					invoked = true
+					assert.False(s.T(), change.ChangedAt.IsZero(), "time of change must be set")
+					return tt.updateFindingStatusReturnErr
+				}).
+				AnyTimes()
+
+			sut := NewFindingService(s.l, mockFindingRepository, nil, s.redaction, domain.FindingIdentityFingerprint, s.policies)
+
+			// act
+			err := sut.UpdateStatus(1, "test fingerprint", tt.input)
+
+			// assert
+			assert.Equalf(s.T(), tt.wantErr, err, "assertion failed, wanted: %s, got: %s", tt.wantErr, err)
+			assert.Equal(s.T(), tt.wantUpdateFindingStatusInvocation, invoked)
+		})
+	}
+}
+
+func (s *FindingsServiceTestSuite) TestService_AddReturnsUploadResultTableDriven() {
+
+	newFinding := domain.Finding{ID: "b2", Fingerprint: "new", Status: domain.FindingStatusOpen}
+	knownFinding := domain.Finding{ID: "a1", Fingerprint: "known", Status: domain.FindingStatusOpen}
+	suppressedFinding := domain.Finding{ID: "c3", Fingerprint: "suppressed", Status: domain.FindingStatusFalsePositive}
+
+	tests := []struct {
+		name           string
+		repoId         int
+		upsertResult   domain.UpsertResult
+		wantNew        int
+		wantKnown      int
+		wantSuppressed int
+		wantVerdict    domain.Verdict
+	}{
+		{
+			"new finding fails by default policy",
+			1,
+			domain.UpsertResult{New: domain.Findings{newFinding}, Known: domain.Findings{knownFinding, suppressedFinding}},
+			1, 1, 1,
+			domain.VerdictFail,
+		},
+		{
+			"known finding warns by default policy",
+			1,
+			domain.UpsertResult{Known: domain.Findings{knownFinding}},
+			0, 1, 0,
+			domain.VerdictWarn,
+		},
+		{
+			"suppressed findings only pass",
+			1,
+			domain.UpsertResult{Known: domain.Findings{suppressedFinding}},
+			0, 0, 1,
+			domain.VerdictPass,
+		},
+		{
+			"repository policy overrides default policy",
+			2,
+			domain.UpsertResult{New: domain.Findings{newFinding}},
+			1, 0, 0,
+			domain.VerdictWarn,
+		},
+		{
+			"empty report passes",
+			1,
+			domain.UpsertResult{},
+			0, 0, 0,
+			domain.VerdictPass,
+		},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// arrange
+			mockFindingRepository := mocks.NewMockFindingsRepository(s.ctrl)
+
+			var savedReport domain.FindingsReport
+			mockFindingRepository.
+				EXPECT().
+				SaveFindingsReport(gomock.Any(), "findings").
+				DoAndReturn(func(findingsReport domain.FindingsReport, collectionName string) error {
+					savedReport = findingsReport
+					return nil
+				})
+			mockFindingRepository.
+				EXPECT().
+				SaveAndUpdateRepoFindingsById(gomock.Any(), domain.FindingIdentityLocation, tt.repoId, true, "repositories").
+				Return(tt.upsertResult, nil)
+
+			sut := NewFindingService(s.l, mockFindingRepository, nil, s.redaction, domain.FindingIdentityLocation, s.policies)
+
+			// act
+			result, err := sut.Add(domain.FindingsReport{PipelineID: 2, RepoID: tt.repoId}, true)
+
+			// assert
+			assert.NoError(s.T(), err)
+			assert.Len(s.T(), result.ReportID, 24)
+			assert.Equal(s.T(), savedReport.ID, result.ReportID)
+			assert.Len(s.T(), result.New, tt.wantNew)
+			assert.Len(s.T(), result.Known, tt.wantKnown)
+			assert.Len(s.T(), result.Suppressed, tt.wantSuppressed)
+			assert.Equal(s.T(), tt.wantVerdict, result.Verdict)
+		})
+	}
+}
+
+func (s *FindingsServiceTestSuite) TestService_AddPublishesWebhookEvents() {
+
+	// arrange
+	mockFindingRepository := mocks.NewMockFindingsRepository(s.ctrl)
+	mockPublisher := mocks.NewMockEventPublisher(s.ctrl)
+
+	newFinding := domain.Finding{ID: "b2", Fingerprint: "new", Secret: "*****"}
+	mockFindingRepository.EXPECT().SaveFindingsReport(gomock.Any(), "findings").Return(nil)
+	mockFindingRepository.
+		EXPECT().
+		SaveAndUpdateRepoFindingsById(gomock.Any(), gomock.Any(), 444, false, "repositories").
+		Return(domain.UpsertResult{New: domain.Findings{newFinding}}, nil)
+
+	var published []domain.WebhookPayload
+	mockPublisher.
+		EXPECT().
+		Publish(gomock.Any()).
+		DoAndReturn(func(payload domain.WebhookPayload) error {
+			published = append(published, payload)
+			return assert.AnError
+		}).
+		Times(2)
+
+	sut := NewFindingService(s.l, mockFindingRepository, mockPublisher, s.redaction, domain.FindingIdentityFingerprint, s.policies)
+
+	// act
+	_, err := sut.Add(domain.FindingsReport{RepoID: 444, RepoName: "testing repo", Findings: domain.Findings{{Fingerprint: "new", Secret: "very secret value"}}}, false)
+
+	// assert
+	assert.NoError(s.T(), err, "failing to publish must not fail the upload")
+	s.Require().Len(published, 2)
+
+	assert.Equal(s.T(), domain.WebhookEventReportReceived, published[0].Event)
+	assert.Equal(s.T(), 444, published[0].RepoID)
+	s.Require().NotNil(published[0].Report)
+	assert.NotEqual(s.T(), "very secret value", published[0].Report.Findings[0].Secret, "published report is redacted")
+
+	assert.Equal(s.T(), domain.WebhookEventFindingNew, published[1].Event)
+	s.Require().NotNil(published[1].Repository)
+	assert.Equal(s.T(), "testing repo", published[1].Repository.RepoName)
+	assert.Equal(s.T(), domain.Findings{newFinding}, published[1].Repository.Findings)
+}
+
+func (s *FindingsServiceTestSuite) TestService_UpdateStatusPublishesWebhookEvent() {
+
+	// arrange
+	mockFindingRepository := mocks.NewMockFindingsRepository(s.ctrl)
+	mockPublisher := mocks.NewMockEventPublisher(s.ctrl)
+
+	changed := domain.Finding{ID: "a1", Fingerprint: "test fingerprint", Status: domain.FindingStatusRevoked}
+	mockFindingRepository.EXPECT().UpdateFindingStatus(444, "test fingerprint", gomock.Any(), "repositories").Return(nil)
+	mockFindingRepository.
+		EXPECT().
+		GetRepoFindingsById(444, "repositories").
+		Return(domain.RepoFindings{RepoID: 444, RepoName: "testing repo", Findings: domain.Findings{{ID: "b2", Fingerprint: "other"}, changed}}, nil)
+
+	var published domain.WebhookPayload
+	mockPublisher.
+		EXPECT().
+		Publish(gomock.Any()).
+		DoAndReturn(func(payload domain.WebhookPayload) error {
+			published = payload
+			return nil
+		})
+
+	sut := NewFindingService(s.l, mockFindingRepository, mockPublisher, s.redaction, domain.FindingIdentityFingerprint, s.policies)
+
+	// act
+	err := sut.UpdateStatus(444, "test fingerprint", domain.StatusChange{Status: domain.FindingStatusRevoked, ChangedBy: "test user"})
+
+	// assert
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), domain.WebhookEventFindingStatusChanged, published.Event)
+	assert.Equal(s.T(), 444, published.RepoID)
+	s.Require().NotNil(published.Repository)
+	assert.Equal(s.T(), domain.Findings{changed}, published.Repository.Findings, "only the changed finding is published")
+}
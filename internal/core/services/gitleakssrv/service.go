@@ -0,0 +1,251 @@
+package gitleakssrv
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/core/ports"
+	"secrets-operator/internal/errors"
+	"time"
+)
+
+// maxChangeAttempts limits retries of changes losing the race for the next version number
+const maxChangeAttempts = 5
+
+type service struct {
+	l                        *zap.SugaredLogger
+	gitleaksConfigRepository ports.GitleaksConfigRepository
+}
+
+// NewGitleaksConfigService creates service managing gitleaks config of pipelines. Changes never modify stored
+// versions, each of them stores the whole changed config as the next version.
+func NewGitleaksConfigService(l *zap.SugaredLogger, gitleaksConfigRepository ports.GitleaksConfigRepository) *service {
+
+	return &service{
+		l:                        l,
+		gitleaksConfigRepository: gitleaksConfigRepository,
+	}
+}
+
+func (srv service) Current() (domain.GitleaksConfigVersion, error) {
+
+	version, err := srv.gitleaksConfigRepository.GetLatestGitleaksConfigVersion("gitleaksconfigs")
+	if err == errors.ErrGitleaksConfigVersionNotFound {
+		return domain.GitleaksConfigVersion{Config: domain.DefaultGitleaksConfig()}, nil
+	}
+	if err != nil {
+		srv.l.Error(err)
+		return domain.GitleaksConfigVersion{}, errors.ErrCouldNotGetGitleaksConfig
+	}
+
+	return version, nil
+}
+
+// GetVersion returns the stored version, version 0 is always domain.DefaultGitleaksConfig
+func (srv service) GetVersion(version int) (domain.GitleaksConfigVersion, error) {
+
+	if version == 0 {
+		return domain.GitleaksConfigVersion{Config: domain.DefaultGitleaksConfig()}, nil
+	}
+
+	stored, err := srv.gitleaksConfigRepository.GetGitleaksConfigVersion(version, "gitleaksconfigs")
+	if err != nil {
+		srv.l.Error(err)
+		if err == errors.ErrGitleaksConfigVersionNotFound {
+			return domain.GitleaksConfigVersion{}, err
+		}
+		return domain.GitleaksConfigVersion{}, errors.ErrCouldNotGetGitleaksConfig
+	}
+
+	return stored, nil
+}
+
+func (srv service) ListVersions() ([]domain.GitleaksConfigVersion, error) {
+
+	versions, err := srv.gitleaksConfigRepository.GetGitleaksConfigVersions("gitleaksconfigs")
+	if err != nil {
+		srv.l.Error(err)
+		return nil, errors.ErrCouldNotGetGitleaksConfig
+	}
+
+	return versions, nil
+}
+
+func (srv service) Diff(from int, to int) (domain.GitleaksConfigDiff, error) {
+
+	fromVersion, err := srv.GetVersion(from)
+	if err != nil {
+		return domain.GitleaksConfigDiff{}, err
+	}
+
+	toVersion, err := srv.GetVersion(to)
+	if err != nil {
+		return domain.GitleaksConfigDiff{}, err
+	}
+
+	return domain.DiffGitleaksConfigs(fromVersion, toVersion), nil
+}
+
+func (srv service) Replace(config domain.GitleaksConfig, createdBy string) (domain.GitleaksConfigVersion, error) {
+
+	return srv.change(createdBy, "config replaced", func(domain.GitleaksConfig) (domain.GitleaksConfig, error) {
+		return config, nil
+	})
+}
+
+func (srv service) CreateRule(rule domain.GitleaksRule, createdBy string) (domain.GitleaksConfigVersion, error) {
+
+	return srv.change(createdBy, fmt.Sprintf("rule %s created", rule.ID), func(config domain.GitleaksConfig) (domain.GitleaksConfig, error) {
+		if _, ok := config.Rule(rule.ID); ok {
+			return domain.GitleaksConfig{}, errors.ErrGitleaksRuleExists
+		}
+		config.Rules = append(append([]domain.GitleaksRule{}, config.Rules...), rule)
+		return config, nil
+	})
+}
+
+func (srv service) UpdateRule(id string, rule domain.GitleaksRule, createdBy string) (domain.GitleaksConfigVersion, error) {
+
+	rule.ID = id
+
+	return srv.change(createdBy, fmt.Sprintf("rule %s updated", id), func(config domain.GitleaksConfig) (domain.GitleaksConfig, error) {
+		if _, ok := config.Rule(id); !ok {
+			return domain.GitleaksConfig{}, errors.ErrGitleaksRuleNotFound
+		}
+		rules := make([]domain.GitleaksRule, 0, len(config.Rules))
+		for _, existing := range config.Rules {
+			if existing.ID == id {
+				existing = rule
+			}
+			rules = append(rules, existing)
+		}
+		config.Rules = rules
+		return config, nil
+	})
+}
+
+func (srv service) DeleteRule(id string, createdBy string) (domain.GitleaksConfigVersion, error) {
+
+	return srv.change(createdBy, fmt.Sprintf("rule %s deleted", id), func(config domain.GitleaksConfig) (domain.GitleaksConfig, error) {
+		if _, ok := config.Rule(id); !ok {
+			return domain.GitleaksConfig{}, errors.ErrGitleaksRuleNotFound
+		}
+		rules := make([]domain.GitleaksRule, 0, len(config.Rules))
+		for _, existing := range config.Rules {
+			if existing.ID != id {
+				rules = append(rules, existing)
+			}
+		}
+		config.Rules = rules
+		return config, nil
+	})
+}
+
+func (srv service) UpdateAllowlist(allowlist domain.GitleaksAllowlist, createdBy string) (domain.GitleaksConfigVersion, error) {
+
+	return srv.change(createdBy, "allowlist updated", func(config domain.GitleaksConfig) (domain.GitleaksConfig, error) {
+		config.Allowlist = &allowlist
+		if allowlist.Empty() {
+			config.Allowlist = nil
+		}
+		return config, nil
+	})
+}
+
+func (srv service) Rollback(version int, createdBy string) (domain.GitleaksConfigVersion, error) {
+
+	target, err := srv.GetVersion(version)
+	if err != nil {
+		return domain.GitleaksConfigVersion{}, err
+	}
+
+	return srv.change(createdBy, fmt.Sprintf("rolled back to version %d", version), func(domain.GitleaksConfig) (domain.GitleaksConfig, error) {
+		return target.Config, nil
+	})
+}
+
+// Seed stores the config as version 1, so the config file read at every start is stored only once
+func (srv service) Seed(config domain.GitleaksConfig, change string) error {
+
+	current, err := srv.Current()
+	if err != nil {
+		return err
+	}
+	if current.Version > 0 {
+		return nil
+	}
+
+	if err = config.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", errors.ErrInvalidGitleaksConfig, err)
+	}
+	if config.Rules == nil {
+		config.Rules = []domain.GitleaksRule{}
+	}
+
+	version := domain.GitleaksConfigVersion{
+		Version:   1,
+		Config:    config,
+		Change:    change,
+		CreatedBy: "secrets operator",
+		CreatedAt: time.Now().UTC(),
+	}
+
+	err = srv.gitleaksConfigRepository.SaveGitleaksConfigVersion(version, "gitleaksconfigs")
+	// another replica seeded meanwhile
+	if err == errors.ErrGitleaksConfigVersionExists {
+		return nil
+	}
+	if err != nil {
+		srv.l.Error(err)
+		return errors.ErrCouldNotSaveGitleaksConfig
+	}
+
+	return nil
+}
+
+// change applies the change to the current config and stores the result as the next version. The change is applied
+// again to the new current config when another change stored the next version meanwhile.
+func (srv service) change(createdBy string, description string, apply func(domain.GitleaksConfig) (domain.GitleaksConfig, error)) (domain.GitleaksConfigVersion, error) {
+
+	for attempt := 0; attempt < maxChangeAttempts; attempt++ {
+
+		current, err := srv.Current()
+		if err != nil {
+			return domain.GitleaksConfigVersion{}, err
+		}
+
+		config, err := apply(current.Config)
+		if err != nil {
+			return domain.GitleaksConfigVersion{}, err
+		}
+		if config.Rules == nil {
+			config.Rules = []domain.GitleaksRule{}
+		}
+
+		if err = config.Validate(); err != nil {
+			srv.l.Debugln("invalid gitleaks config.", err)
+			return domain.GitleaksConfigVersion{}, fmt.Errorf("%w: %v", errors.ErrInvalidGitleaksConfig, err)
+		}
+
+		version := domain.GitleaksConfigVersion{
+			Version:   current.Version + 1,
+			Config:    config,
+			Change:    description,
+			CreatedBy: createdBy,
+			CreatedAt: time.Now().UTC(),
+		}
+
+		err = srv.gitleaksConfigRepository.SaveGitleaksConfigVersion(version, "gitleaksconfigs")
+		if err == errors.ErrGitleaksConfigVersionExists {
+			continue
+		}
+		if err != nil {
+			srv.l.Error(err)
+			return domain.GitleaksConfigVersion{}, errors.ErrCouldNotSaveGitleaksConfig
+		}
+
+		return version, nil
+	}
+
+	return domain.GitleaksConfigVersion{}, errors.ErrConcurrentGitleaksConfigUpdate
+}
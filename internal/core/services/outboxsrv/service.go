@@ -0,0 +1,141 @@
+package outboxsrv
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/core/ports"
+	"secrets-operator/internal/errors"
+	"strings"
+	"time"
+)
+
+const (
+	// deliveryBatchSize limits messages attempted by a single DeliverDue call
+	deliveryBatchSize = 50
+	// listSize limits messages returned by List
+	listSize = 100
+)
+
+type service struct {
+	l                *zap.SugaredLogger
+	outboxRepository ports.OutboxRepository
+	notifier         ports.ChannelNotifier
+	retryPolicy      domain.RetryPolicy
+}
+
+// NewOutboxService creates service delivering notifications queued with uploaded findings, deliveries are made by Run
+func NewOutboxService(l *zap.SugaredLogger, outboxRepository ports.OutboxRepository, notifier ports.ChannelNotifier, retryPolicy domain.RetryPolicy) *service {
+
+	return &service{
+		l:                l,
+		outboxRepository: outboxRepository,
+		notifier:         notifier,
+		retryPolicy:      retryPolicy,
+	}
+}
+
+func (srv service) List(status domain.OutboxStatus) ([]domain.OutboxMessage, error) {
+
+	messages, err := srv.outboxRepository.GetOutboxMessages(status, listSize, "outbox")
+	if err != nil {
+		srv.l.Error(err)
+		return nil, errors.ErrCouldNotGetOutboxMessages
+	}
+
+	return messages, nil
+}
+
+// Replay queues dead message again, it is delivered to channels which did not receive it with a fresh set of attempts
+func (srv service) Replay(id string) (domain.OutboxMessage, error) {
+
+	message, err := srv.outboxRepository.GetOutboxMessageById(id, "outbox")
+	if err != nil {
+		srv.l.Error(err)
+		if err == errors.ErrOutboxMessageNotFound {
+			return domain.OutboxMessage{}, err
+		}
+		return domain.OutboxMessage{}, errors.ErrCouldNotReplayOutboxMessage
+	}
+
+	if message.Status != domain.OutboxDead {
+		srv.l.Errorln("outbox message is not dead", id, message.Status)
+		return domain.OutboxMessage{}, errors.ErrOutboxMessageNotDead
+	}
+
+	message.Replay(time.Now().UTC())
+
+	if err = srv.outboxRepository.SaveOutboxMessage(message, "outbox"); err != nil {
+		srv.l.Error(err)
+		return domain.OutboxMessage{}, errors.ErrCouldNotReplayOutboxMessage
+	}
+
+	return message, nil
+}
+
+// DeliverDue attempts a batch of due messages and returns how many were attempted
+func (srv service) DeliverDue() (int, error) {
+
+	messages, err := srv.outboxRepository.GetDueOutboxMessages(time.Now().UTC(), deliveryBatchSize, "outbox")
+	if err != nil {
+		return 0, err
+	}
+
+	for _, message := range messages {
+
+		message.RecordAttempt(srv.send(message), srv.retryPolicy)
+
+		if message.Status == domain.OutboxDead {
+			srv.l.Warnf("Notification %s about report %s is dead after %d attempts, channels %s did not receive it",
+				message.ID, message.ReportID, message.AttemptCount, strings.Join(message.Channels, ", "))
+		}
+
+		if err = srv.outboxRepository.SaveOutboxMessage(message, "outbox"); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(messages), nil
+}
+
+func (srv service) send(message domain.OutboxMessage) domain.OutboxAttempt {
+
+	started := time.Now()
+	failed := srv.notifier.SendToChannels(message.Report, message.Channels)
+
+	attempt := domain.OutboxAttempt{
+		At:             time.Now().UTC(),
+		FailedChannels: failed,
+		DurationMs:     time.Since(started).Milliseconds(),
+	}
+	if len(failed) > 0 {
+		attempt.Error = fmt.Sprintf("%v: %s", errors.ErrNotificationChannelsFailed, strings.Join(failed, ", "))
+	}
+
+	return attempt
+}
+
+// Run delivers due messages every interval until ctx is done, full batches are followed by the next one right away
+func (srv service) Run(ctx context.Context, interval time.Duration) {
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		attempted, err := srv.DeliverDue()
+		if err != nil {
+			srv.l.Errorln("Could not deliver notifications.", err)
+		}
+
+		if attempted == deliveryBatchSize && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
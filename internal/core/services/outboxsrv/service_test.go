@@ -0,0 +1,199 @@
+package outboxsrv
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/core/ports/mocks"
+	"secrets-operator/internal/errors"
+	"testing"
+	"time"
+)
+
+type OutboxServiceTestSuite struct {
+	suite.Suite
+	l      *zap.SugaredLogger
+	ctrl   *gomock.Controller
+	policy domain.RetryPolicy
+	report domain.FindingsReport
+}
+
+func TestSuiteOutboxService(t *testing.T) {
+	suite.Run(t, new(OutboxServiceTestSuite))
+}
+
+func (s *OutboxServiceTestSuite) SetupTest() {
+
+	s.l = zap.NewNop().Sugar()
+
+	s.policy = domain.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour}
+	s.report = domain.FindingsReport{ID: "r1", RepoID: 444, Findings: domain.Findings{{RuleID: "aws-access-token", Fingerprint: "f1"}}}
+
+	// setup gomock controller
+	s.ctrl = gomock.NewController(s.T())
+	defer s.ctrl.Finish()
+}
+
+func (s *OutboxServiceTestSuite) TestService_DeliverDueTableDriven() {
+
+	tests := []struct {
+		name             string
+		attemptCount     int
+		channels         []string
+		failedChannels   []string
+		wantStatus       domain.OutboxStatus
+		wantChannels     []string
+		wantAttemptCount int
+		wantRetryIn      time.Duration
+	}{
+		{"delivered to every channel", 0, nil, nil, domain.OutboxDelivered, nil, 1, 0},
+		{"failed channels are retried after base delay", 0, nil, []string{"slack"}, domain.OutboxPending, []string{"slack"}, 1, time.Minute},
+		{"retry targets only failed channels", 1, []string{"slack", "teams"}, []string{"teams"}, domain.OutboxPending, []string{"teams"}, 2, 2 * time.Minute},
+		{"retry delivered to remaining channels", 1, []string{"teams"}, nil, domain.OutboxDelivered, nil, 2, 0},
+		{"last attempt dead letters the message", 2, []string{"teams"}, []string{"teams"}, domain.OutboxDead, []string{"teams"}, 3, 0},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// arrange
+			due := domain.OutboxMessage{
+				ID:           "m1",
+				ReportID:     "r1",
+				RepoID:       444,
+				Report:       s.report,
+				Channels:     tt.channels,
+				Status:       domain.OutboxPending,
+				AttemptCount: tt.attemptCount,
+			}
+
+			mockOutboxRepository := mocks.NewMockOutboxRepository(s.ctrl)
+			mockOutboxRepository.EXPECT().GetDueOutboxMessages(gomock.Any(), deliveryBatchSize, "outbox").Return([]domain.OutboxMessage{due}, nil)
+
+			var saved domain.OutboxMessage
+			mockOutboxRepository.
+				EXPECT().
+				SaveOutboxMessage(gomock.Any(), "outbox").
+				DoAndReturn(func(message domain.OutboxMessage, collectionName string) error {
+					saved = message
+					return nil
+				})
+
+			mockChannelNotifier := mocks.NewMockChannelNotifier(s.ctrl)
+			mockChannelNotifier.EXPECT().SendToChannels(s.report, tt.channels).Return(tt.failedChannels)
+
+			sut := NewOutboxService(s.l, mockOutboxRepository, mockChannelNotifier, s.policy)
+
+			// act
+			attempted, err := sut.DeliverDue()
+
+			// assert
+			s.Require().NoError(err)
+			assert.Equal(s.T(), 1, attempted)
+			assert.Equal(s.T(), tt.wantStatus, saved.Status)
+			assert.Equal(s.T(), tt.wantChannels, saved.Channels)
+			assert.Equal(s.T(), tt.wantAttemptCount, saved.AttemptCount)
+			s.Require().Len(saved.Attempts, 1)
+
+			attempt := saved.Attempts[0]
+			assert.Equal(s.T(), tt.failedChannels, attempt.FailedChannels)
+			assert.Equal(s.T(), tt.wantStatus == domain.OutboxDelivered, attempt.Error == "")
+			if tt.wantRetryIn > 0 {
+				assert.Equal(s.T(), attempt.At.Add(tt.wantRetryIn), saved.NextAttemptAt)
+			}
+		})
+	}
+}
+
+func (s *OutboxServiceTestSuite) TestService_DeliverDueWithoutDueMessages() {
+
+	// arrange
+	mockOutboxRepository := mocks.NewMockOutboxRepository(s.ctrl)
+	mockOutboxRepository.EXPECT().GetDueOutboxMessages(gomock.Any(), deliveryBatchSize, "outbox").Return([]domain.OutboxMessage{}, nil)
+
+	sut := NewOutboxService(s.l, mockOutboxRepository, mocks.NewMockChannelNotifier(s.ctrl), s.policy)
+
+	// act
+	attempted, err := sut.DeliverDue()
+
+	// assert
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), 0, attempted)
+}
+
+func (s *OutboxServiceTestSuite) TestService_List() {
+
+	// arrange
+	mockOutboxRepository := mocks.NewMockOutboxRepository(s.ctrl)
+	mockOutboxRepository.EXPECT().GetOutboxMessages(domain.OutboxDead, listSize, "outbox").Return([]domain.OutboxMessage{{ID: "m1"}}, nil)
+	mockOutboxRepository.EXPECT().GetOutboxMessages(domain.OutboxPending, listSize, "outbox").Return(nil, assert.AnError)
+
+	sut := NewOutboxService(s.l, mockOutboxRepository, mocks.NewMockChannelNotifier(s.ctrl), s.policy)
+
+	// act
+	dead, deadErr := sut.List(domain.OutboxDead)
+	_, pendingErr := sut.List(domain.OutboxPending)
+
+	// assert
+	assert.NoError(s.T(), deadErr)
+	assert.Len(s.T(), dead, 1)
+	assert.Equal(s.T(), errors.ErrCouldNotGetOutboxMessages, pendingErr)
+}
+
+func (s *OutboxServiceTestSuite) TestService_ReplayTableDriven() {
+
+	tests := []struct {
+		name     string
+		stored   domain.OutboxMessage
+		getErr   error
+		saveErr  error
+		wantSave bool
+		wantErr  error
+	}{
+		{"dead message is queued again", domain.OutboxMessage{ID: "m1", Status: domain.OutboxDead, AttemptCount: 3, Channels: []string{"teams"}}, nil, nil, true, nil},
+		{"unknown message", domain.OutboxMessage{}, errors.ErrOutboxMessageNotFound, nil, false, errors.ErrOutboxMessageNotFound},
+		{"pending message", domain.OutboxMessage{ID: "m1", Status: domain.OutboxPending}, nil, nil, false, errors.ErrOutboxMessageNotDead},
+		{"delivered message", domain.OutboxMessage{ID: "m1", Status: domain.OutboxDelivered}, nil, nil, false, errors.ErrOutboxMessageNotDead},
+		{"repository error", domain.OutboxMessage{}, assert.AnError, nil, false, errors.ErrCouldNotReplayOutboxMessage},
+		{"save error", domain.OutboxMessage{ID: "m1", Status: domain.OutboxDead, AttemptCount: 3}, nil, assert.AnError, true, errors.ErrCouldNotReplayOutboxMessage},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// arrange
+			mockOutboxRepository := mocks.NewMockOutboxRepository(s.ctrl)
+			mockOutboxRepository.EXPECT().GetOutboxMessageById("m1", "outbox").Return(tt.stored, tt.getErr)
+
+			var saved domain.OutboxMessage
+			if tt.wantSave {
+				mockOutboxRepository.
+					EXPECT().
+					SaveOutboxMessage(gomock.Any(), "outbox").
+					DoAndReturn(func(message domain.OutboxMessage, collectionName string) error {
+						saved = message
+						return tt.saveErr
+					})
+			}
+
+			sut := NewOutboxService(s.l, mockOutboxRepository, mocks.NewMockChannelNotifier(s.ctrl), s.policy)
+
+			// act
+			replayed, err := sut.Replay("m1")
+
+			// assert
+			assert.Equal(s.T(), tt.wantErr, err)
+			if tt.wantErr == nil {
+				assert.Equal(s.T(), saved, replayed)
+				assert.Equal(s.T(), domain.OutboxPending, saved.Status)
+				assert.Equal(s.T(), 0, saved.AttemptCount)
+				assert.Equal(s.T(), []string{"teams"}, saved.Channels, "channels which received the message are not notified again")
+				assert.WithinDuration(s.T(), time.Now(), saved.NextAttemptAt, time.Minute)
+			}
+		})
+	}
+}
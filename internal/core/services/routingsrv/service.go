@@ -0,0 +1,161 @@
+package routingsrv
+
+import (
+	"go.uber.org/zap"
+	"net/mail"
+	"net/url"
+	"path"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/core/ports"
+	"secrets-operator/internal/errors"
+	"time"
+)
+
+type service struct {
+	l                     *zap.SugaredLogger
+	routingRuleRepository ports.RoutingRuleRepository
+	templates             domain.NotificationTemplates
+}
+
+// NewRoutingService creates service managing routing rules, they are evaluated by the notification router.
+// Templates of targets must be among the templates.
+func NewRoutingService(l *zap.SugaredLogger, routingRuleRepository ports.RoutingRuleRepository, templates domain.NotificationTemplates) *service {
+
+	return &service{
+		l:                     l,
+		routingRuleRepository: routingRuleRepository,
+		templates:             templates,
+	}
+}
+
+func (srv service) CreateRule(rule domain.RoutingRule, createdBy string) (domain.RoutingRule, error) {
+
+	if err := srv.validate(rule); err != nil {
+		return domain.RoutingRule{}, err
+	}
+
+	rule.ID = domain.NewID()
+	rule.CreatedBy = createdBy
+	rule.CreatedAt = time.Now().UTC()
+
+	err := srv.routingRuleRepository.SaveRoutingRule(rule, "routingrules")
+	if err != nil {
+		srv.l.Error(err)
+		return domain.RoutingRule{}, errors.ErrCouldNotSaveRoutingRule
+	}
+
+	return rule, nil
+}
+
+func (srv service) ListRules() ([]domain.RoutingRule, error) {
+
+	rules, err := srv.routingRuleRepository.GetRoutingRules("routingrules")
+	if err != nil {
+		srv.l.Error(err)
+		return nil, errors.ErrCouldNotGetRoutingRules
+	}
+
+	return rules, nil
+}
+
+func (srv service) UpdateRule(id string, rule domain.RoutingRule) (domain.RoutingRule, error) {
+
+	if err := srv.validate(rule); err != nil {
+		return domain.RoutingRule{}, err
+	}
+
+	existing, err := srv.routingRuleRepository.GetRoutingRuleById(id, "routingrules")
+	if err != nil {
+		srv.l.Error(err)
+		if err == errors.ErrRoutingRuleNotFound {
+			return domain.RoutingRule{}, err
+		}
+		return domain.RoutingRule{}, errors.ErrCouldNotSaveRoutingRule
+	}
+
+	rule.ID = existing.ID
+	rule.CreatedBy = existing.CreatedBy
+	rule.CreatedAt = existing.CreatedAt
+
+	err = srv.routingRuleRepository.SaveRoutingRule(rule, "routingrules")
+	if err != nil {
+		srv.l.Error(err)
+		return domain.RoutingRule{}, errors.ErrCouldNotSaveRoutingRule
+	}
+
+	return rule, nil
+}
+
+func (srv service) DeleteRule(id string) error {
+
+	err := srv.routingRuleRepository.DeleteRoutingRule(id, "routingrules")
+	if err != nil {
+		srv.l.Error(err)
+		if err == errors.ErrRoutingRuleNotFound {
+			return err
+		}
+		return errors.ErrCouldNotDeleteRoutingRule
+	}
+
+	return nil
+}
+
+// validate checks what struct tags can not, the glob syntax, destinations, templates and modes of targets
+func (srv service) validate(rule domain.RoutingRule) error {
+
+	if rule.RepoNameGlob != "" {
+		if _, err := path.Match(rule.RepoNameGlob, ""); err != nil {
+			srv.l.Errorln("invalid repository name glob", rule.RepoNameGlob)
+			return errors.ErrInvalidRoutingRule
+		}
+	}
+
+	if len(rule.Targets) == 0 {
+		srv.l.Errorln("routing rule without targets")
+		return errors.ErrInvalidRoutingRule
+	}
+
+	for _, target := range rule.Targets {
+		if !validDestination(target) {
+			srv.l.Errorln("invalid routing target", target.Name())
+			return errors.ErrInvalidRoutingRule
+		}
+		if target.Template != "" && !srv.validTemplate(target) {
+			srv.l.Errorln("invalid template of routing target", target.Name(), target.Template)
+			return errors.ErrInvalidRoutingRule
+		}
+		if !target.Mode.Valid() {
+			srv.l.Errorln("invalid notification mode of routing target", target.Name(), target.Mode)
+			return errors.ErrInvalidRoutingRule
+		}
+	}
+
+	return nil
+}
+
+// validTemplate tells whether template of the target exists, only chat messages are rendered by templates
+func (srv service) validTemplate(target domain.NotificationTarget) bool {
+
+	switch target.Type {
+	case domain.NotificationTargetSlack, domain.NotificationTargetTeams:
+		return srv.templates.Has(target.Template)
+	default:
+		return false
+	}
+}
+
+func validDestination(target domain.NotificationTarget) bool {
+
+	switch target.Type {
+	case domain.NotificationTargetSlack:
+		return target.Destination != ""
+	case domain.NotificationTargetEmail:
+		_, err := mail.ParseAddress(target.Destination)
+		return err == nil
+	case domain.NotificationTargetTeams, domain.NotificationTargetWebhook:
+		endpoint, err := url.Parse(target.Destination)
+		return err == nil && (endpoint.Scheme == "https" || endpoint.Scheme == "http") && endpoint.Host != ""
+	default:
+		return false
+	}
+}
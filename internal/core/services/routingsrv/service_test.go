@@ -0,0 +1,302 @@
+package routingsrv
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/core/ports/mocks"
+	"secrets-operator/internal/errors"
+	"testing"
+	"time"
+)
+
+type RoutingServiceTestSuite struct {
+	suite.Suite
+	l         *zap.SugaredLogger
+	ctrl      *gomock.Controller
+	templates domain.NotificationTemplates
+}
+
+func TestSuiteRoutingService(t *testing.T) {
+	suite.Run(t, new(RoutingServiceTestSuite))
+}
+
+func (s *RoutingServiceTestSuite) SetupTest() {
+
+	s.l = zap.NewNop().Sugar()
+
+	var err error
+	s.templates, err = domain.NewNotificationTemplates(map[string]string{"payments": "{{.RepoName}}"})
+	s.Require().NoError(err)
+
+	// setup gomock controller
+	s.ctrl = gomock.NewController(s.T())
+	defer s.ctrl.Finish()
+}
+
+func slackTarget(channel string) []domain.NotificationTarget {
+	return []domain.NotificationTarget{{Type: domain.NotificationTargetSlack, Destination: channel}}
+}
+
+func (s *RoutingServiceTestSuite) TestService_CreateRuleTableDriven() {
+
+	tests := []struct {
+		name    string
+		rule    domain.RoutingRule
+		saveErr error
+		wantErr error
+	}{
+		{
+			"slack channel",
+			domain.RoutingRule{RepoNameGlob: "payments-*", Targets: slackTarget("C0123456")},
+			nil,
+			nil,
+		},
+		{
+			"every target type",
+			domain.RoutingRule{GroupPrefix: "platform/payments", Targets: []domain.NotificationTarget{
+				{Type: domain.NotificationTargetTeams, Destination: "https://example.webhook.office.com/webhookb2/1"},
+				{Type: domain.NotificationTargetEmail, Destination: "payments@example.com"},
+				{Type: domain.NotificationTargetWebhook, Destination: "http://hooks.example.com/payments"},
+			}},
+			nil,
+			nil,
+		},
+		{
+			"template of chat target",
+			domain.RoutingRule{Targets: []domain.NotificationTarget{
+				{Type: domain.NotificationTargetSlack, Destination: "C0123456", Template: "payments"},
+				{Type: domain.NotificationTargetTeams, Destination: "https://example.webhook.office.com/webhookb2/1", Template: "slack"},
+			}},
+			nil,
+			nil,
+		},
+		{
+			"unknown template",
+			domain.RoutingRule{Targets: []domain.NotificationTarget{{Type: domain.NotificationTargetSlack, Destination: "C0123456", Template: "missing"}}},
+			nil,
+			errors.ErrInvalidRoutingRule,
+		},
+		{
+			"template of email target",
+			domain.RoutingRule{Targets: []domain.NotificationTarget{{Type: domain.NotificationTargetEmail, Destination: "payments@example.com", Template: "payments"}}},
+			nil,
+			errors.ErrInvalidRoutingRule,
+		},
+		{
+			"digest and suppressed modes",
+			domain.RoutingRule{Targets: []domain.NotificationTarget{
+				{Type: domain.NotificationTargetSlack, Destination: "C0123456", Mode: domain.NotificationDaily},
+				{Type: domain.NotificationTargetEmail, Destination: "payments@example.com", Mode: domain.NotificationSuppressed},
+			}},
+			nil,
+			nil,
+		},
+		{
+			"unknown mode",
+			domain.RoutingRule{Targets: []domain.NotificationTarget{{Type: domain.NotificationTargetSlack, Destination: "C0123456", Mode: "weekly"}}},
+			nil,
+			errors.ErrInvalidRoutingRule,
+		},
+		{
+			"malformed glob",
+			domain.RoutingRule{RepoNameGlob: "payments-[", Targets: slackTarget("C0123456")},
+			nil,
+			errors.ErrInvalidRoutingRule,
+		},
+		{
+			"no targets",
+			domain.RoutingRule{RepoIDs: []int{444}},
+			nil,
+			errors.ErrInvalidRoutingRule,
+		},
+		{
+			"empty slack channel",
+			domain.RoutingRule{Targets: slackTarget("")},
+			nil,
+			errors.ErrInvalidRoutingRule,
+		},
+		{
+			"invalid email address",
+			domain.RoutingRule{Targets: []domain.NotificationTarget{{Type: domain.NotificationTargetEmail, Destination: "payments team"}}},
+			nil,
+			errors.ErrInvalidRoutingRule,
+		},
+		{
+			"webhook without http scheme",
+			domain.RoutingRule{Targets: []domain.NotificationTarget{{Type: domain.NotificationTargetWebhook, Destination: "ftp://hooks.example.com"}}},
+			nil,
+			errors.ErrInvalidRoutingRule,
+		},
+		{
+			"unknown target type",
+			domain.RoutingRule{Targets: []domain.NotificationTarget{{Type: "pager", Destination: "payments"}}},
+			nil,
+			errors.ErrInvalidRoutingRule,
+		},
+		{
+			"repository error",
+			domain.RoutingRule{Targets: slackTarget("C0123456")},
+			assert.AnError,
+			errors.ErrCouldNotSaveRoutingRule,
+		},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// arrange
+			mockRoutingRuleRepository := mocks.NewMockRoutingRuleRepository(s.ctrl)
+
+			var saved domain.RoutingRule
+			mockRoutingRuleRepository.
+				EXPECT().
+				SaveRoutingRule(gomock.Any(), "routingrules").
+				DoAndReturn(func(rule domain.RoutingRule, collectionName string) error {
+					saved = rule
+					return tt.saveErr
+				}).
+				AnyTimes()
+
+			sut := NewRoutingService(s.l, mockRoutingRuleRepository, s.templates)
+
+			// act
+			created, err := sut.CreateRule(tt.rule, "test admin")
+
+			// assert
+			assert.Equal(s.T(), tt.wantErr, err)
+			if tt.wantErr != nil {
+				return
+			}
+			assert.Equal(s.T(), saved, created)
+			assert.Len(s.T(), created.ID, 24)
+			assert.Equal(s.T(), tt.rule.Targets, created.Targets)
+			assert.Equal(s.T(), "test admin", created.CreatedBy)
+			assert.False(s.T(), created.CreatedAt.IsZero())
+		})
+	}
+}
+
+func (s *RoutingServiceTestSuite) TestService_UpdateRuleKeepsAuthorAndCreationTime() {
+
+	// arrange
+	createdAt := time.Date(2022, 12, 3, 12, 48, 14, 0, time.UTC)
+	existing := domain.RoutingRule{ID: "a1", RepoIDs: []int{444}, Targets: slackTarget("C0123456"), CreatedBy: "first admin", CreatedAt: createdAt}
+
+	mockRoutingRuleRepository := mocks.NewMockRoutingRuleRepository(s.ctrl)
+	mockRoutingRuleRepository.EXPECT().GetRoutingRuleById("a1", "routingrules").Return(existing, nil)
+
+	var saved domain.RoutingRule
+	mockRoutingRuleRepository.
+		EXPECT().
+		SaveRoutingRule(gomock.Any(), "routingrules").
+		DoAndReturn(func(rule domain.RoutingRule, collectionName string) error {
+			saved = rule
+			return nil
+		})
+
+	sut := NewRoutingService(s.l, mockRoutingRuleRepository, s.templates)
+
+	// act
+	updated, err := sut.UpdateRule("a1", domain.RoutingRule{ID: "forged", CreatedBy: "someone else", Tags: []string{"key"}, Targets: slackTarget("C0999999")})
+
+	// assert
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), saved, updated)
+	assert.Equal(s.T(), "a1", updated.ID)
+	assert.Equal(s.T(), "first admin", updated.CreatedBy)
+	assert.Equal(s.T(), createdAt, updated.CreatedAt)
+	assert.Empty(s.T(), updated.RepoIDs, "conditions are replaced, not merged")
+	assert.Equal(s.T(), []string{"key"}, updated.Tags)
+	assert.Equal(s.T(), slackTarget("C0999999"), updated.Targets)
+}
+
+func (s *RoutingServiceTestSuite) TestService_UpdateRuleTableDriven() {
+
+	tests := []struct {
+		name    string
+		rule    domain.RoutingRule
+		getErr  error
+		saveErr error
+		wantErr error
+	}{
+		{"invalid rule", domain.RoutingRule{Targets: slackTarget("")}, nil, nil, errors.ErrInvalidRoutingRule},
+		{"unknown rule", domain.RoutingRule{Targets: slackTarget("C0123456")}, errors.ErrRoutingRuleNotFound, nil, errors.ErrRoutingRuleNotFound},
+		{"repository error on get", domain.RoutingRule{Targets: slackTarget("C0123456")}, assert.AnError, nil, errors.ErrCouldNotSaveRoutingRule},
+		{"repository error on save", domain.RoutingRule{Targets: slackTarget("C0123456")}, nil, assert.AnError, errors.ErrCouldNotSaveRoutingRule},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// arrange
+			mockRoutingRuleRepository := mocks.NewMockRoutingRuleRepository(s.ctrl)
+			mockRoutingRuleRepository.EXPECT().GetRoutingRuleById("a1", "routingrules").Return(domain.RoutingRule{ID: "a1"}, tt.getErr).AnyTimes()
+			mockRoutingRuleRepository.EXPECT().SaveRoutingRule(gomock.Any(), "routingrules").Return(tt.saveErr).AnyTimes()
+
+			sut := NewRoutingService(s.l, mockRoutingRuleRepository, s.templates)
+
+			// act
+			_, err := sut.UpdateRule("a1", tt.rule)
+
+			// assert
+			assert.Equal(s.T(), tt.wantErr, err)
+		})
+	}
+}
+
+func (s *RoutingServiceTestSuite) TestService_ListRules() {
+
+	// arrange
+	rules := []domain.RoutingRule{{ID: "a1", Targets: slackTarget("C0123456")}}
+
+	mockRoutingRuleRepository := mocks.NewMockRoutingRuleRepository(s.ctrl)
+	mockRoutingRuleRepository.EXPECT().GetRoutingRules("routingrules").Return(rules, nil)
+	mockRoutingRuleRepository.EXPECT().GetRoutingRules("routingrules").Return(nil, assert.AnError)
+
+	sut := NewRoutingService(s.l, mockRoutingRuleRepository, s.templates)
+
+	// act
+	listed, err := sut.ListRules()
+	_, failedErr := sut.ListRules()
+
+	// assert
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), rules, listed)
+	assert.Equal(s.T(), errors.ErrCouldNotGetRoutingRules, failedErr)
+}
+
+func (s *RoutingServiceTestSuite) TestService_DeleteRuleTableDriven() {
+
+	tests := []struct {
+		name      string
+		deleteErr error
+		wantErr   error
+	}{
+		{"existing rule", nil, nil},
+		{"unknown rule", errors.ErrRoutingRuleNotFound, errors.ErrRoutingRuleNotFound},
+		{"repository error", assert.AnError, errors.ErrCouldNotDeleteRoutingRule},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// arrange
+			mockRoutingRuleRepository := mocks.NewMockRoutingRuleRepository(s.ctrl)
+			mockRoutingRuleRepository.EXPECT().DeleteRoutingRule("a1", "routingrules").Return(tt.deleteErr)
+
+			sut := NewRoutingService(s.l, mockRoutingRuleRepository, s.templates)
+
+			// act
+			err := sut.DeleteRule("a1")
+
+			// assert
+			assert.Equal(s.T(), tt.wantErr, err)
+		})
+	}
+}
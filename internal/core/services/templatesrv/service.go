@@ -0,0 +1,58 @@
+package templatesrv
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/errors"
+)
+
+type service struct {
+	l         *zap.SugaredLogger
+	templates domain.NotificationTemplates
+}
+
+// NewTemplateService creates service previewing the templates notifiers render messages with
+func NewTemplateService(l *zap.SugaredLogger, templates domain.NotificationTemplates) *service {
+
+	return &service{
+		l:         l,
+		templates: templates,
+	}
+}
+
+func (srv service) Names() []string {
+
+	return srv.templates.Names()
+}
+
+// Preview renders source if it is set, otherwise the named template. Errors of templates are wrapped
+// in errors.ErrInvalidTemplate, so their authors can see what is wrong.
+func (srv service) Preview(preview domain.TemplatePreview) (string, error) {
+
+	report := domain.SampleFindingsReport()
+	if preview.Report != nil {
+		report = *preview.Report
+	}
+
+	if preview.Source != "" {
+		rendered, err := srv.templates.Preview(preview.Source, report)
+		if err != nil {
+			srv.l.Debugln("invalid template draft.", err)
+			return "", fmt.Errorf("%w: %v", errors.ErrInvalidTemplate, err)
+		}
+		return rendered, nil
+	}
+
+	if !srv.templates.Has(preview.Name) {
+		return "", errors.ErrTemplateNotFound
+	}
+
+	rendered, err := srv.templates.Render(preview.Name, report)
+	if err != nil {
+		srv.l.Debugln("template can not render the report.", err)
+		return "", fmt.Errorf("%w: %v", errors.ErrInvalidTemplate, err)
+	}
+
+	return rendered, nil
+}
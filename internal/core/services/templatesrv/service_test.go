@@ -0,0 +1,79 @@
+package templatesrv
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/errors"
+	"testing"
+)
+
+type TemplateServiceTestSuite struct {
+	suite.Suite
+	l         *zap.SugaredLogger
+	templates domain.NotificationTemplates
+}
+
+func TestSuiteTemplateService(t *testing.T) {
+	suite.Run(t, new(TemplateServiceTestSuite))
+}
+
+func (s *TemplateServiceTestSuite) SetupTest() {
+
+	s.l = zap.NewNop().Sugar()
+
+	var err error
+	s.templates, err = domain.NewNotificationTemplates(map[string]string{
+		"payments": "{{.RepoName}} has {{len .Findings}} {{plural (len .Findings) \"secret\" \"secrets\"}}",
+		"first":    "{{(index .Findings 0).RuleID}}",
+	})
+	s.Require().NoError(err)
+}
+
+func (s *TemplateServiceTestSuite) TestService_PreviewTableDriven() {
+
+	emptyReport := domain.FindingsReport{RepoName: "testing repo"}
+
+	tests := []struct {
+		name         string
+		preview      domain.TemplatePreview
+		wantRendered string
+		wantErr      error
+	}{
+		{"named template with sample report", domain.TemplatePreview{Name: "payments"}, "payments-api has 1 secret", nil},
+		{"named template with given report", domain.TemplatePreview{Name: "payments", Report: &emptyReport}, "testing repo has 0 secrets", nil},
+		{"draft takes precedence over name", domain.TemplatePreview{Name: "payments", Source: "#{{.PipelineID}}"}, "#2", nil},
+		{"unknown template", domain.TemplatePreview{Name: "missing"}, "", errors.ErrTemplateNotFound},
+		{"invalid draft", domain.TemplatePreview{Source: "{{.Unknown}}"}, "", errors.ErrInvalidTemplate},
+		{"template can not render given report", domain.TemplatePreview{Name: "first", Report: &emptyReport}, "", errors.ErrInvalidTemplate},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// arrange
+			sut := NewTemplateService(s.l, s.templates)
+
+			// act
+			rendered, err := sut.Preview(tt.preview)
+
+			// assert
+			assert.ErrorIs(s.T(), err, tt.wantErr)
+			assert.Equal(s.T(), tt.wantRendered, rendered)
+		})
+	}
+}
+
+func (s *TemplateServiceTestSuite) TestService_Names() {
+
+	// arrange
+	sut := NewTemplateService(s.l, s.templates)
+
+	// act
+	names := sut.Names()
+
+	// assert
+	assert.Equal(s.T(), []string{"first", "payments", "slack", "slack-dm", "teams"}, names)
+}
@@ -0,0 +1,267 @@
+package webhooksrv
+
+import (
+	"context"
+	"encoding/json"
+	"go.uber.org/zap"
+	"net/url"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/core/ports"
+	"secrets-operator/internal/errors"
+	"time"
+)
+
+const (
+	// deliveryBatchSize limits deliveries attempted by a single DeliverDue call
+	deliveryBatchSize = 50
+	// deliveryLogSize limits deliveries returned by GetDeliveries
+	deliveryLogSize = 100
+)
+
+type service struct {
+	l                 *zap.SugaredLogger
+	webhookRepository ports.WebhookRepository
+	sender            ports.WebhookSender
+	retryPolicy       domain.RetryPolicy
+}
+
+// NewWebhookService creates service queueing events for subscribers, queued deliveries are sent by Run
+func NewWebhookService(l *zap.SugaredLogger, webhookRepository ports.WebhookRepository, sender ports.WebhookSender, retryPolicy domain.RetryPolicy) *service {
+
+	return &service{
+		l:                 l,
+		webhookRepository: webhookRepository,
+		sender:            sender,
+		retryPolicy:       retryPolicy,
+	}
+}
+
+func (srv service) Subscribe(subscription domain.WebhookSubscription, createdBy string) (domain.WebhookSubscription, error) {
+
+	endpoint, err := url.Parse(subscription.URL)
+	if err != nil || (endpoint.Scheme != "https" && endpoint.Scheme != "http") || endpoint.Host == "" {
+		srv.l.Errorln("invalid webhook url", subscription.URL)
+		return domain.WebhookSubscription{}, errors.ErrInvalidWebhookSubscription
+	}
+
+	events := []domain.WebhookEvent{}
+	for _, event := range subscription.Events {
+		switch event {
+		case domain.WebhookEventReportReceived, domain.WebhookEventFindingNew, domain.WebhookEventFindingStatusChanged:
+		default:
+			srv.l.Errorln("invalid webhook event", event)
+			return domain.WebhookSubscription{}, errors.ErrInvalidWebhookSubscription
+		}
+		if !containsEvent(events, event) {
+			events = append(events, event)
+		}
+	}
+
+	if len(events) == 0 {
+		srv.l.Errorln("webhook subscription without events")
+		return domain.WebhookSubscription{}, errors.ErrInvalidWebhookSubscription
+	}
+
+	created := domain.WebhookSubscription{
+		ID:          domain.NewID(),
+		URL:         subscription.URL,
+		Events:      events,
+		RepoIDs:     subscription.RepoIDs,
+		Description: subscription.Description,
+		Secret:      domain.NewWebhookSecret(),
+		CreatedBy:   createdBy,
+		CreatedAt:   time.Now().UTC(),
+	}
+
+	err = srv.webhookRepository.SaveWebhookSubscription(created, "webhooks")
+	if err != nil {
+		srv.l.Error(err)
+		return domain.WebhookSubscription{}, errors.ErrCouldNotSaveWebhookSubscription
+	}
+
+	return created, nil
+}
+
+func (srv service) ListSubscriptions() ([]domain.WebhookSubscription, error) {
+
+	subscriptions, err := srv.webhookRepository.GetWebhookSubscriptions("webhooks")
+	if err != nil {
+		srv.l.Error(err)
+		return nil, errors.ErrCouldNotGetWebhookSubscriptions
+	}
+
+	return subscriptions, nil
+}
+
+// Unsubscribe deletes the subscription, its pending deliveries fail on their next attempt
+func (srv service) Unsubscribe(id string) error {
+
+	err := srv.webhookRepository.DeleteWebhookSubscription(id, "webhooks")
+	if err != nil {
+		srv.l.Error(err)
+		if err == errors.ErrWebhookSubscriptionNotFound {
+			return err
+		}
+		return errors.ErrCouldNotDeleteWebhookSubscription
+	}
+
+	return nil
+}
+
+func (srv service) GetDeliveries(subscriptionId string) ([]domain.WebhookDelivery, error) {
+
+	deliveries, err := srv.webhookRepository.GetWebhookDeliveries(subscriptionId, deliveryLogSize, "webhookdeliveries")
+	if err != nil {
+		srv.l.Error(err)
+		return nil, errors.ErrCouldNotGetWebhookDeliveries
+	}
+
+	return deliveries, nil
+}
+
+// Publish queues the payload for every subscription matching its event and repository
+func (srv service) Publish(payload domain.WebhookPayload) error {
+
+	subscriptions, err := srv.webhookRepository.GetWebhookSubscriptions("webhooks")
+	if err != nil {
+		srv.l.Error(err)
+		return errors.ErrCouldNotPublishWebhookEvent
+	}
+
+	now := time.Now().UTC()
+	payload.ID = domain.NewID()
+	payload.CreatedAt = now
+
+	var body []byte
+	failed := false
+
+	for _, subscription := range subscriptions {
+		if !subscription.Matches(payload.Event, payload.RepoID) {
+			continue
+		}
+
+		if body == nil {
+			if body, err = json.Marshal(payload); err != nil {
+				srv.l.Error(err)
+				return errors.ErrCouldNotPublishWebhookEvent
+			}
+		}
+
+		delivery := domain.WebhookDelivery{
+			ID:             domain.NewID(),
+			SubscriptionID: subscription.ID,
+			Event:          payload.Event,
+			Payload:        body,
+			Status:         domain.WebhookDeliveryPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+		}
+
+		// other subscribers still get the event if one delivery could not be queued
+		if err = srv.webhookRepository.SaveWebhookDelivery(delivery, "webhookdeliveries"); err != nil {
+			srv.l.Error(err)
+			failed = true
+		}
+	}
+
+	if failed {
+		return errors.ErrCouldNotPublishWebhookEvent
+	}
+
+	return nil
+}
+
+// DeliverDue attempts deliveries whose next attempt is due and returns how many were attempted
+func (srv service) DeliverDue() (int, error) {
+
+	deliveries, err := srv.webhookRepository.GetDueWebhookDeliveries(time.Now().UTC(), deliveryBatchSize, "webhookdeliveries")
+	if err != nil {
+		return 0, err
+	}
+
+	if len(deliveries) == 0 {
+		return 0, nil
+	}
+
+	subscriptions, err := srv.webhookRepository.GetWebhookSubscriptions("webhooks")
+	if err != nil {
+		return 0, err
+	}
+
+	byID := map[string]domain.WebhookSubscription{}
+	for _, subscription := range subscriptions {
+		byID[subscription.ID] = subscription
+	}
+
+	for _, delivery := range deliveries {
+
+		subscription, ok := byID[delivery.SubscriptionID]
+		if ok {
+			delivery.RecordAttempt(srv.send(subscription, delivery), srv.retryPolicy)
+		} else {
+			delivery.RecordAttempt(domain.WebhookAttempt{At: time.Now().UTC(), Error: "subscription was deleted"}, srv.retryPolicy)
+			delivery.Status = domain.WebhookDeliveryFailed
+		}
+
+		if delivery.Status == domain.WebhookDeliveryFailed {
+			srv.l.Warnf("Webhook delivery %s of subscription %s failed after %d attempts", delivery.ID, delivery.SubscriptionID, delivery.AttemptCount)
+		}
+
+		if err = srv.webhookRepository.SaveWebhookDelivery(delivery, "webhookdeliveries"); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(deliveries), nil
+}
+
+func (srv service) send(subscription domain.WebhookSubscription, delivery domain.WebhookDelivery) domain.WebhookAttempt {
+
+	started := time.Now()
+	statusCode, err := srv.sender.Send(subscription, delivery)
+
+	attempt := domain.WebhookAttempt{
+		At:         time.Now().UTC(),
+		StatusCode: statusCode,
+		DurationMs: time.Since(started).Milliseconds(),
+	}
+	if err != nil {
+		srv.l.Debugf("Webhook delivery %s to %s failed: %v", delivery.ID, subscription.URL, err)
+		attempt.Error = err.Error()
+	}
+
+	return attempt
+}
+
+// Run delivers due deliveries every interval until ctx is done, full batches are followed by the next one right away
+func (srv service) Run(ctx context.Context, interval time.Duration) {
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		attempted, err := srv.DeliverDue()
+		if err != nil {
+			srv.l.Errorln("Could not deliver webhooks.", err)
+		}
+
+		if attempted == deliveryBatchSize && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func containsEvent(events []domain.WebhookEvent, event domain.WebhookEvent) bool {
+	for _, e := range events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,310 @@
+package webhooksrv
+
+import (
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"secrets-operator/internal/core/domain"
+	"secrets-operator/internal/core/ports/mocks"
+	"secrets-operator/internal/errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type WebhookServiceTestSuite struct {
+	suite.Suite
+	l             *zap.SugaredLogger
+	ctrl          *gomock.Controller
+	policy        domain.RetryPolicy
+	subscriptions []domain.WebhookSubscription
+}
+
+func TestSuiteWebhookService(t *testing.T) {
+	suite.Run(t, new(WebhookServiceTestSuite))
+}
+
+func (s *WebhookServiceTestSuite) SetupTest() {
+
+	s.l = zap.NewNop().Sugar()
+
+	s.policy = domain.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour}
+	s.subscriptions = []domain.WebhookSubscription{
+		{ID: "all", URL: "https://hooks.example.com/all", Events: []domain.WebhookEvent{domain.WebhookEventFindingNew, domain.WebhookEventReportReceived}, Secret: "whsec_all"},
+		{ID: "repo", URL: "https://hooks.example.com/repo", Events: []domain.WebhookEvent{domain.WebhookEventFindingNew}, RepoIDs: []int{444}, Secret: "whsec_repo"},
+		{ID: "other", URL: "https://hooks.example.com/other", Events: []domain.WebhookEvent{domain.WebhookEventFindingNew}, RepoIDs: []int{555}, Secret: "whsec_other"},
+	}
+
+	// setup gomock controller
+	s.ctrl = gomock.NewController(s.T())
+	defer s.ctrl.Finish()
+}
+
+func (s *WebhookServiceTestSuite) TestService_SubscribeTableDriven() {
+
+	tests := []struct {
+		name         string
+		subscription domain.WebhookSubscription
+		saveErr      error
+		wantEvents   []domain.WebhookEvent
+		wantErr      error
+	}{
+		{
+			"valid subscription",
+			domain.WebhookSubscription{URL: "https://hooks.example.com", Events: []domain.WebhookEvent{domain.WebhookEventFindingNew, domain.WebhookEventFindingNew}},
+			nil,
+			[]domain.WebhookEvent{domain.WebhookEventFindingNew},
+			nil,
+		},
+		{
+			"unsupported scheme",
+			domain.WebhookSubscription{URL: "ftp://hooks.example.com", Events: []domain.WebhookEvent{domain.WebhookEventFindingNew}},
+			nil,
+			nil,
+			errors.ErrInvalidWebhookSubscription,
+		},
+		{
+			"unknown event",
+			domain.WebhookSubscription{URL: "https://hooks.example.com", Events: []domain.WebhookEvent{"finding.deleted"}},
+			nil,
+			nil,
+			errors.ErrInvalidWebhookSubscription,
+		},
+		{
+			"no events",
+			domain.WebhookSubscription{URL: "https://hooks.example.com"},
+			nil,
+			nil,
+			errors.ErrInvalidWebhookSubscription,
+		},
+		{
+			"repository error",
+			domain.WebhookSubscription{URL: "https://hooks.example.com", Events: []domain.WebhookEvent{domain.WebhookEventFindingNew}},
+			assert.AnError,
+			nil,
+			errors.ErrCouldNotSaveWebhookSubscription,
+		},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// arrange
+			mockWebhookRepository := mocks.NewMockWebhookRepository(s.ctrl)
+
+			var saved domain.WebhookSubscription
+			mockWebhookRepository.
+				EXPECT().
+				SaveWebhookSubscription(gomock.Any(), "webhooks").
+				DoAndReturn(func(subscription domain.WebhookSubscription, collectionName string) error {
+					saved = subscription
+					return tt.saveErr
+				}).
+				AnyTimes()
+
+			sut := NewWebhookService(s.l, mockWebhookRepository, mocks.NewMockWebhookSender(s.ctrl), s.policy)
+
+			// act
+			created, err := sut.Subscribe(tt.subscription, "test admin")
+
+			// assert
+			assert.Equal(s.T(), tt.wantErr, err)
+			if tt.wantErr != nil {
+				return
+			}
+			assert.Equal(s.T(), saved, created)
+			assert.Len(s.T(), created.ID, 24)
+			assert.True(s.T(), strings.HasPrefix(created.Secret, domain.WebhookSecretPrefix))
+			assert.Equal(s.T(), tt.wantEvents, created.Events)
+			assert.Equal(s.T(), "test admin", created.CreatedBy)
+		})
+	}
+}
+
+func (s *WebhookServiceTestSuite) TestService_UnsubscribeTableDriven() {
+
+	tests := []struct {
+		name      string
+		deleteErr error
+		wantErr   error
+	}{
+		{"existing subscription", nil, nil},
+		{"unknown subscription", errors.ErrWebhookSubscriptionNotFound, errors.ErrWebhookSubscriptionNotFound},
+		{"repository error", assert.AnError, errors.ErrCouldNotDeleteWebhookSubscription},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// arrange
+			mockWebhookRepository := mocks.NewMockWebhookRepository(s.ctrl)
+			mockWebhookRepository.EXPECT().DeleteWebhookSubscription("a1", "webhooks").Return(tt.deleteErr)
+
+			sut := NewWebhookService(s.l, mockWebhookRepository, mocks.NewMockWebhookSender(s.ctrl), s.policy)
+
+			// act
+			err := sut.Unsubscribe("a1")
+
+			// assert
+			assert.Equal(s.T(), tt.wantErr, err)
+		})
+	}
+}
+
+func (s *WebhookServiceTestSuite) TestService_PublishQueuesMatchingSubscriptions() {
+
+	// arrange
+	mockWebhookRepository := mocks.NewMockWebhookRepository(s.ctrl)
+	mockWebhookRepository.EXPECT().GetWebhookSubscriptions("webhooks").Return(s.subscriptions, nil)
+
+	queued := map[string]domain.WebhookDelivery{}
+	mockWebhookRepository.
+		EXPECT().
+		SaveWebhookDelivery(gomock.Any(), "webhookdeliveries").
+		DoAndReturn(func(delivery domain.WebhookDelivery, collectionName string) error {
+			queued[delivery.SubscriptionID] = delivery
+			return nil
+		}).
+		Times(2)
+
+	sut := NewWebhookService(s.l, mockWebhookRepository, mocks.NewMockWebhookSender(s.ctrl), s.policy)
+
+	payload := domain.WebhookPayload{
+		Event:      domain.WebhookEventFindingNew,
+		RepoID:     444,
+		Repository: &domain.RepoFindings{RepoID: 444, RepoName: "testing repo", Findings: domain.Findings{{Fingerprint: "f1"}}},
+	}
+
+	// act
+	err := sut.Publish(payload)
+
+	// assert
+	s.Require().NoError(err)
+	s.Require().Contains(queued, "all")
+	s.Require().Contains(queued, "repo")
+
+	delivery := queued["all"]
+	assert.Equal(s.T(), domain.WebhookDeliveryPending, delivery.Status)
+	assert.Equal(s.T(), domain.WebhookEventFindingNew, delivery.Event)
+	assert.False(s.T(), delivery.NextAttemptAt.After(time.Now().UTC()), "new deliveries are due right away")
+	assert.Equal(s.T(), delivery.Payload, queued["repo"].Payload, "every subscriber receives the same event")
+
+	published := domain.WebhookPayload{}
+	s.Require().NoError(json.Unmarshal(delivery.Payload, &published))
+	assert.Len(s.T(), published.ID, 24)
+	assert.Equal(s.T(), 444, published.RepoID)
+	s.Require().NotNil(published.Repository)
+	assert.Equal(s.T(), "f1", published.Repository.Findings[0].Fingerprint)
+	assert.Nil(s.T(), published.Report)
+}
+
+func (s *WebhookServiceTestSuite) TestService_PublishContinuesAfterFailedDelivery() {
+
+	// arrange
+	mockWebhookRepository := mocks.NewMockWebhookRepository(s.ctrl)
+	mockWebhookRepository.EXPECT().GetWebhookSubscriptions("webhooks").Return(s.subscriptions, nil)
+	mockWebhookRepository.EXPECT().SaveWebhookDelivery(gomock.Any(), "webhookdeliveries").Return(assert.AnError)
+	mockWebhookRepository.EXPECT().SaveWebhookDelivery(gomock.Any(), "webhookdeliveries").Return(nil)
+
+	sut := NewWebhookService(s.l, mockWebhookRepository, mocks.NewMockWebhookSender(s.ctrl), s.policy)
+
+	// act
+	err := sut.Publish(domain.WebhookPayload{Event: domain.WebhookEventFindingNew, RepoID: 444})
+
+	// assert
+	assert.Equal(s.T(), errors.ErrCouldNotPublishWebhookEvent, err)
+}
+
+func (s *WebhookServiceTestSuite) TestService_DeliverDueTableDriven() {
+
+	tests := []struct {
+		name             string
+		subscriptionId   string
+		attemptCount     int
+		sendStatusCode   int
+		sendErr          error
+		wantSend         bool
+		wantStatus       domain.WebhookDeliveryStatus
+		wantAttemptCount int
+		wantRetryIn      time.Duration
+	}{
+		{"delivered", "all", 0, 200, nil, true, domain.WebhookDeliveryDelivered, 1, 0},
+		{"first failure is retried after base delay", "all", 0, 500, assert.AnError, true, domain.WebhookDeliveryPending, 1, time.Minute},
+		{"second failure backs off exponentially", "all", 1, 0, assert.AnError, true, domain.WebhookDeliveryPending, 2, 2 * time.Minute},
+		{"last attempt fails the delivery", "all", 2, 500, assert.AnError, true, domain.WebhookDeliveryFailed, 3, 0},
+		{"deleted subscription fails the delivery", "deleted", 0, 0, nil, false, domain.WebhookDeliveryFailed, 1, 0},
+	}
+
+	for _, tt := range tests {
+
+		s.Run(tt.name, func() {
+
+			// arrange
+			due := domain.WebhookDelivery{
+				ID:             "d1",
+				SubscriptionID: tt.subscriptionId,
+				Event:          domain.WebhookEventFindingNew,
+				Payload:        json.RawMessage(`{}`),
+				Status:         domain.WebhookDeliveryPending,
+				AttemptCount:   tt.attemptCount,
+			}
+
+			mockWebhookRepository := mocks.NewMockWebhookRepository(s.ctrl)
+			mockWebhookRepository.EXPECT().GetDueWebhookDeliveries(gomock.Any(), deliveryBatchSize, "webhookdeliveries").Return([]domain.WebhookDelivery{due}, nil)
+			mockWebhookRepository.EXPECT().GetWebhookSubscriptions("webhooks").Return(s.subscriptions, nil)
+
+			var saved domain.WebhookDelivery
+			mockWebhookRepository.
+				EXPECT().
+				SaveWebhookDelivery(gomock.Any(), "webhookdeliveries").
+				DoAndReturn(func(delivery domain.WebhookDelivery, collectionName string) error {
+					saved = delivery
+					return nil
+				})
+
+			mockWebhookSender := mocks.NewMockWebhookSender(s.ctrl)
+			if tt.wantSend {
+				mockWebhookSender.EXPECT().Send(s.subscriptions[0], due).Return(tt.sendStatusCode, tt.sendErr)
+			}
+
+			sut := NewWebhookService(s.l, mockWebhookRepository, mockWebhookSender, s.policy)
+
+			// act
+			attempted, err := sut.DeliverDue()
+
+			// assert
+			s.Require().NoError(err)
+			assert.Equal(s.T(), 1, attempted)
+			assert.Equal(s.T(), tt.wantStatus, saved.Status)
+			assert.Equal(s.T(), tt.wantAttemptCount, saved.AttemptCount)
+			s.Require().Len(saved.Attempts, 1)
+
+			attempt := saved.Attempts[0]
+			assert.Equal(s.T(), tt.sendStatusCode, attempt.StatusCode)
+			assert.Equal(s.T(), tt.wantStatus == domain.WebhookDeliveryDelivered, attempt.Error == "")
+			if tt.wantRetryIn > 0 {
+				assert.Equal(s.T(), attempt.At.Add(tt.wantRetryIn), saved.NextAttemptAt)
+			}
+		})
+	}
+}
+
+func (s *WebhookServiceTestSuite) TestService_DeliverDueWithoutDueDeliveries() {
+
+	// arrange
+	mockWebhookRepository := mocks.NewMockWebhookRepository(s.ctrl)
+	mockWebhookRepository.EXPECT().GetDueWebhookDeliveries(gomock.Any(), deliveryBatchSize, "webhookdeliveries").Return([]domain.WebhookDelivery{}, nil)
+
+	sut := NewWebhookService(s.l, mockWebhookRepository, mocks.NewMockWebhookSender(s.ctrl), s.policy)
+
+	// act
+	attempted, err := sut.DeliverDue()
+
+	// assert
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), 0, attempted)
+}
@@ -0,0 +1,16 @@
+package errors
+
+import (
+	"errors"
+)
+
+var (
+	ErrAPIKeyNotFound       = errors.New("api key not found")
+	ErrUnauthorized         = errors.New("missing, invalid or revoked token")
+	ErrInvalidAPIKey        = errors.New("invalid api key")
+	ErrCouldNotIssueAPIKey  = errors.New("could not issue api key")
+	ErrCouldNotGetAPIKeys   = errors.New("could not get api keys")
+	ErrCouldNotRevokeAPIKey = errors.New("could not revoke api key")
+	ErrCouldNotAuthenticate = errors.New("could not authenticate")
+	ErrInvalidToken         = errors.New("invalid or expired token")
+)
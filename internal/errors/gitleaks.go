@@ -0,0 +1,16 @@
+package errors
+
+import (
+	"errors"
+)
+
+var (
+	ErrGitleaksConfigVersionNotFound  = errors.New("gitleaks config version not found")
+	ErrGitleaksConfigVersionExists    = errors.New("gitleaks config version already exists")
+	ErrGitleaksRuleNotFound           = errors.New("gitleaks rule not found")
+	ErrGitleaksRuleExists             = errors.New("gitleaks rule with given id already exists")
+	ErrInvalidGitleaksConfig          = errors.New("invalid gitleaks config")
+	ErrCouldNotGetGitleaksConfig      = errors.New("could not get gitleaks config")
+	ErrCouldNotSaveGitleaksConfig     = errors.New("could not save gitleaks config")
+	ErrConcurrentGitleaksConfigUpdate = errors.New("gitleaks config was modified concurrently too many times")
+)
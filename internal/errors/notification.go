@@ -0,0 +1,20 @@
+package errors
+
+import (
+	"errors"
+)
+
+var (
+	ErrNotificationChannelsFailed  = errors.New("notification channels failed")
+	ErrOutboxMessageNotFound       = errors.New("outbox message not found")
+	ErrOutboxMessageNotDead        = errors.New("only dead outbox messages can be replayed")
+	ErrCouldNotGetOutboxMessages   = errors.New("could not get outbox messages")
+	ErrCouldNotReplayOutboxMessage = errors.New("could not replay outbox message")
+	ErrRoutingRuleNotFound         = errors.New("routing rule not found")
+	ErrInvalidRoutingRule          = errors.New("invalid routing rule")
+	ErrCouldNotSaveRoutingRule     = errors.New("could not save routing rule")
+	ErrCouldNotGetRoutingRules     = errors.New("could not get routing rules")
+	ErrCouldNotDeleteRoutingRule   = errors.New("could not delete routing rule")
+	ErrTemplateNotFound            = errors.New("notification template not found")
+	ErrInvalidTemplate             = errors.New("invalid notification template")
+)
@@ -0,0 +1,15 @@
+package errors
+
+import (
+	"errors"
+)
+
+var (
+	ErrWebhookSubscriptionNotFound       = errors.New("webhook subscription not found")
+	ErrInvalidWebhookSubscription        = errors.New("invalid webhook subscription")
+	ErrCouldNotSaveWebhookSubscription   = errors.New("could not save webhook subscription")
+	ErrCouldNotGetWebhookSubscriptions   = errors.New("could not get webhook subscriptions")
+	ErrCouldNotDeleteWebhookSubscription = errors.New("could not delete webhook subscription")
+	ErrCouldNotGetWebhookDeliveries      = errors.New("could not get webhook deliveries")
+	ErrCouldNotPublishWebhookEvent       = errors.New("could not publish webhook event")
+)
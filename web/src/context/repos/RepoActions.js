@@ -2,10 +2,39 @@ import axios from 'axios'
 
 const SECRETS_OPERATOR_URL = 'http://localhost:8080'
 
+// identity provider redirects back with the token in URL fragment, e.g. /#access_token=...
+const OIDC_LOGIN_URL = process.env.REACT_APP_OIDC_LOGIN_URL
+const TOKEN_KEY = 'secretsOperatorToken'
+
+const fragment = new URLSearchParams(window.location.hash.substring(1))
+if (fragment.get('access_token')) {
+    sessionStorage.setItem(TOKEN_KEY, fragment.get('access_token'))
+    window.history.replaceState(null, '', window.location.pathname + window.location.search)
+}
+
 const secretsOperator = axios.create({
     baseURL: SECRETS_OPERATOR_URL
 })
 
+secretsOperator.interceptors.request.use((config) => {
+    const token = sessionStorage.getItem(TOKEN_KEY)
+    if (token) {
+        config.headers.Authorization = `Bearer ${token}`
+    }
+    return config
+})
+
+secretsOperator.interceptors.response.use(
+    (response) => response,
+    (error) => {
+        if (error.response && error.response.status === 401 && OIDC_LOGIN_URL) {
+            sessionStorage.removeItem(TOKEN_KEY)
+            window.location.assign(OIDC_LOGIN_URL)
+        }
+        return Promise.reject(error)
+    }
+)
+
 export const searchRepos = async (text) => {
     const params = new URLSearchParams({
 		query: text,